@@ -0,0 +1,284 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"ffvi_editor/io/backup"
+	"ffvi_editor/io/config"
+	"ffvi_editor/models"
+)
+
+// defaultBackupsToKeep mirrors the retention used by the GUI backup manager
+const defaultBackupsToKeep = 10
+
+// handleBackupCommand dispatches the backup subcommands
+func (c *CLI) handleBackupCommand(args []string) error {
+	if len(args) == 0 {
+		return c.showBackupHelp()
+	}
+
+	// Keep "backup --file save.sav" working as shorthand for "backup create"
+	if strings.HasPrefix(args[0], "-") {
+		return c.backupCreate(args)
+	}
+
+	switch args[0] {
+	case "create":
+		return c.backupCreate(args[1:])
+	case "list":
+		return c.backupList(args[1:])
+	case "restore":
+		return c.backupRestore(args[1:])
+	case "delete":
+		return c.backupDelete(args[1:])
+	case "prune":
+		return c.backupPrune(args[1:])
+	case "help", "-h", "--help":
+		return c.showBackupHelp()
+	default:
+		return fmt.Errorf("unknown backup subcommand: %s", args[0])
+	}
+}
+
+// backupCreate stores a copy of a save file in the backup directory
+func (c *CLI) backupCreate(args []string) error {
+	fs := flag.NewFlagSet("backup create", flag.ExitOnError)
+	file := fs.String("file", "", "Save file path (required)")
+	dir := fs.String("dir", "", "Backup directory (defaults to <save dir>/backups)")
+	description := fs.String("desc", "", "Backup description")
+	keep := fs.Int("keep", defaultBackupsToKeep, "Maximum number of backups to retain")
+	// Accepted for compatibility with the original single-command form
+	output := fs.String("output", "", "Backup directory (alias of --dir)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return fmt.Errorf("--file is required")
+	}
+	if *keep <= 0 {
+		return fmt.Errorf("--keep must be greater than zero")
+	}
+	if *dir == "" {
+		*dir = *output
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return fmt.Errorf("failed to read save file: %w", err)
+	}
+
+	m, err := c.openBackupManager(*dir, *keep)
+	if err != nil {
+		return err
+	}
+
+	absPath, err := filepath.Abs(*file)
+	if err != nil {
+		absPath = *file
+	}
+
+	meta, err := m.CreateBackup(absPath, data, *description)
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	fmt.Printf("Created backup %s in %s\n", meta.ID, m.BackupDir())
+	fmt.Printf("  Size: %d bytes\n", meta.FileSize)
+	fmt.Printf("  Hash: %s\n", meta.Hash)
+	return nil
+}
+
+// backupList prints every backup in the backup directory, newest first
+func (c *CLI) backupList(args []string) error {
+	fs := flag.NewFlagSet("backup list", flag.ExitOnError)
+	dir := fs.String("dir", "", "Backup directory (defaults to <save dir>/backups)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := c.openBackupManager(*dir, 0)
+	if err != nil {
+		return err
+	}
+
+	entries := m.ListBackups()
+	if len(entries) == 0 {
+		fmt.Printf("No backups found in %s\n", m.BackupDir())
+		return nil
+	}
+
+	printBackupEntries(entries)
+	return nil
+}
+
+// backupRestore verifies a backup and writes it back to disk
+func (c *CLI) backupRestore(args []string) error {
+	id, args := splitBackupID(args)
+
+	fs := flag.NewFlagSet("backup restore", flag.ExitOnError)
+	dir := fs.String("dir", "", "Backup directory (defaults to <save dir>/backups)")
+	output := fs.String("output", "", "Restore destination (defaults to the original save path)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if id == "" {
+		id = fs.Arg(0)
+	}
+	if id == "" {
+		return fmt.Errorf("backup ID is required")
+	}
+
+	m, err := c.openBackupManager(*dir, 0)
+	if err != nil {
+		return err
+	}
+
+	meta, err := m.GetBackupMetadata(id)
+	if err != nil {
+		return err
+	}
+
+	// RestoreBackup verifies the stored hash before returning the data
+	data, err := m.RestoreBackup(id)
+	if err != nil {
+		return err
+	}
+
+	dest := *output
+	if dest == "" {
+		dest = meta.OriginalPath
+	}
+	if dest == "" {
+		return fmt.Errorf("backup %s has no original path; use --output", id)
+	}
+
+	if err = os.WriteFile(dest, data, 0644); err != nil {
+		return fmt.Errorf("failed to write restored file: %w", err)
+	}
+
+	fmt.Printf("Restored backup %s to %s (hash verified: %s)\n", id, dest, meta.Hash)
+	return nil
+}
+
+// backupDelete removes a single backup
+func (c *CLI) backupDelete(args []string) error {
+	id, args := splitBackupID(args)
+
+	fs := flag.NewFlagSet("backup delete", flag.ExitOnError)
+	dir := fs.String("dir", "", "Backup directory (defaults to <save dir>/backups)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if id == "" {
+		id = fs.Arg(0)
+	}
+	if id == "" {
+		return fmt.Errorf("backup ID is required")
+	}
+
+	m, err := c.openBackupManager(*dir, 0)
+	if err != nil {
+		return err
+	}
+
+	if err = m.DeleteBackup(id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted backup %s\n", id)
+	return nil
+}
+
+// backupPrune removes the oldest backups beyond the retention count
+func (c *CLI) backupPrune(args []string) error {
+	fs := flag.NewFlagSet("backup prune", flag.ExitOnError)
+	dir := fs.String("dir", "", "Backup directory (defaults to <save dir>/backups)")
+	keep := fs.Int("keep", defaultBackupsToKeep, "Number of most recent backups to keep")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := c.openBackupManager(*dir, 0)
+	if err != nil {
+		return err
+	}
+
+	removed, err := m.PruneBackups(*keep)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range removed {
+		fmt.Printf("Removed backup %s\n", id)
+	}
+	fmt.Printf("Pruned %d backup(s); %d remaining\n", len(removed), m.BackupCount())
+	return nil
+}
+
+// openBackupManager opens the backup directory, falling back to the same
+// location the GUI uses when no directory is given
+func (c *CLI) openBackupManager(dir string, keep int) (*backup.Manager, error) {
+	if dir == "" {
+		dir = filepath.Join(config.SaveDir(), "backups")
+	}
+	m, err := backup.NewManager(dir, keep)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup directory: %w", err)
+	}
+	return m, nil
+}
+
+// splitBackupID pulls a leading backup ID off the argument list so it can be
+// given before the flags
+func splitBackupID(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
+// printBackupEntries writes backup metadata as an aligned table
+func printBackupEntries(entries []models.BackupListEntry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCREATED\tAGE\tSIZE\tHASH\tDESCRIPTION")
+	for _, e := range entries {
+		hash := e.Hash
+		if len(hash) > 12 {
+			hash = hash[:12]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			e.ID,
+			e.Timestamp.Format("2006-01-02 15:04:05"),
+			e.TimeSince,
+			e.FileSize,
+			hash,
+			e.Description,
+		)
+	}
+	_ = w.Flush()
+}
+
+// showBackupHelp displays usage for the backup subcommands
+func (c *CLI) showBackupHelp() error {
+	fmt.Println(`Backup commands:
+    backup create  --file save.sav [--dir DIR] [--desc TEXT] [--keep N]
+    backup list    [--dir DIR]
+    backup restore <id> [--dir DIR] [--output PATH]
+    backup delete  <id> [--dir DIR]
+    backup prune   --keep N [--dir DIR]
+
+The backup directory defaults to <save dir>/backups, the same location used
+by the editor's Backup Manager.`)
+	return nil
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ffvi_editor/io/backup"
+)
+
+func TestBackupCreateListRestorePrune(t *testing.T) {
+	tmp := t.TempDir()
+	saveFile := filepath.Join(tmp, "slot1.sav")
+	backupDir := filepath.Join(tmp, "backups")
+
+	original := []byte("original save contents")
+	if err := os.WriteFile(saveFile, original, 0644); err != nil {
+		t.Fatalf("failed to write save: %v", err)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := captureOutput(func() error {
+			return NewCLI(args).Run()
+		})
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return out
+	}
+
+	run("backup", "create", "--file", saveFile, "--dir", backupDir, "--desc", "first")
+	run("backup", "create", "--file", saveFile, "--dir", backupDir, "--desc", "second")
+
+	out := run("backup", "list", "--dir", backupDir)
+	if !strings.Contains(out, "first") || !strings.Contains(out, "second") {
+		t.Fatalf("list output missing backups: %s", out)
+	}
+
+	m, err := backup.NewManager(backupDir, 0)
+	if err != nil {
+		t.Fatalf("failed to open backups: %v", err)
+	}
+	entries := m.ListBackups()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 backups, got %d", len(entries))
+	}
+
+	if err = os.WriteFile(saveFile, []byte("modified"), 0644); err != nil {
+		t.Fatalf("failed to modify save: %v", err)
+	}
+	run("backup", "restore", entries[0].ID, "--dir", backupDir)
+
+	restored, err := os.ReadFile(saveFile)
+	if err != nil {
+		t.Fatalf("failed to read restored save: %v", err)
+	}
+	if string(restored) != string(original) {
+		t.Fatalf("restored contents = %q, want %q", restored, original)
+	}
+
+	run("backup", "prune", "--keep", "1", "--dir", backupDir)
+	if m, err = backup.NewManager(backupDir, 0); err != nil {
+		t.Fatalf("failed to reopen backups: %v", err)
+	}
+	if m.BackupCount() != 1 {
+		t.Fatalf("expected 1 backup after prune, got %d", m.BackupCount())
+	}
+}
+
+func TestBackupRestoreDetectsTampering(t *testing.T) {
+	tmp := t.TempDir()
+	saveFile := filepath.Join(tmp, "slot1.sav")
+	backupDir := filepath.Join(tmp, "backups")
+
+	if err := os.WriteFile(saveFile, []byte("data"), 0644); err != nil {
+		t.Fatalf("failed to write save: %v", err)
+	}
+
+	m, err := backup.NewManager(backupDir, 5)
+	if err != nil {
+		t.Fatalf("failed to open backups: %v", err)
+	}
+	meta, err := m.CreateBackup(saveFile, []byte("data"), "")
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+
+	if err = os.WriteFile(filepath.Join(backupDir, meta.ID+".bak"), []byte("tampered"), 0644); err != nil {
+		t.Fatalf("failed to tamper backup: %v", err)
+	}
+
+	_, err = captureOutput(func() error {
+		return NewCLI([]string{"backup", "restore", meta.ID, "--dir", backupDir}).Run()
+	})
+	if err == nil || !strings.Contains(err.Error(), "integrity") {
+		t.Fatalf("expected integrity error, got %v", err)
+	}
+}
@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+
+	ioCsv "ffvi_editor/io/csv"
+	ioJson "ffvi_editor/io/json"
 )
 
 // CLI represents the command-line interface
@@ -11,6 +15,30 @@ type CLI struct {
 	args []string
 }
 
+// ExitError reports a non-zero exit status that is a result rather than a
+// failure, such as diff finding differences
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// Main runs the command line with args and returns the exit status of the
+// process: the code of an ExitError, 1 for other errors and 0 otherwise
+func Main(args []string) int {
+	if err := NewCLI(args).Run(); err != nil {
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.Code
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
 // NewCLI creates a new CLI instance
 func NewCLI(args []string) *CLI {
 	return &CLI{args: args}
@@ -31,6 +59,8 @@ func (c *CLI) Run() error {
 		return c.exportCommand()
 	case "import":
 		return c.importCommand()
+	case "schema":
+		return c.schemaCommand()
 	case "batch":
 		return c.batchCommand()
 	case "script":
@@ -39,8 +69,24 @@ func (c *CLI) Run() error {
 		return c.validateCommand()
 	case "backup":
 		return c.backupCommand()
+	case "diff":
+		return c.diffCommand()
+	case "apply-patch":
+		return c.applyPatchCommand()
+	case "merge":
+		return c.mergeCommand()
+	case "shell":
+		return c.shellCommand()
+	case "watch":
+		return c.watchCommand()
+	case "history":
+		return c.historyCommand()
+	case "report":
+		return c.reportCommand()
 	case "combat-pack":
 		return c.combatPackCommand()
+	case "source":
+		return c.sourceCommand()
 	case "help", "-h", "--help":
 		return c.showHelp()
 	case "version", "-v", "--version":
@@ -77,7 +123,8 @@ func (c *CLI) exportCommand() error {
 	fs := flag.NewFlagSet("export", flag.ExitOnError)
 	file := fs.String("file", "", "Save file path (required)")
 	output := fs.String("output", "", "Output JSON file (required)")
-	format := fs.String("format", "full", "Export format: full, characters, inventory, party, magic, espers")
+	format := fs.String("format", "full", "Export format: full, characters, inventory, party, equipment, magic, espers")
+	sheet := fs.String("sheet", "", "Write a CSV sheet instead (TSV for .tsv files): inventory, important-items, characters, spells, skills")
 
 	if err := fs.Parse(c.args[1:]); err != nil {
 		return err
@@ -87,6 +134,13 @@ func (c *CLI) exportCommand() error {
 		return fmt.Errorf("--file and --output are required")
 	}
 
+	if *sheet != "" {
+		s, err := ioCsv.ParseSheet(*sheet)
+		if err != nil {
+			return err
+		}
+		return c.handleExportSheetCommand(*file, *output, s)
+	}
 	return c.handleExportCommand(*file, *output, *format)
 }
 
@@ -95,8 +149,12 @@ func (c *CLI) importCommand() error {
 	fs := flag.NewFlagSet("import", flag.ExitOnError)
 	file := fs.String("file", "", "Save file path (required)")
 	input := fs.String("input", "", "Input JSON file (required)")
-	format := fs.String("format", "full", "Import format: full, characters, inventory, party, magic, espers")
+	format := fs.String("format", "full", "Import format: full, characters, inventory, party, magic, espers, equipment")
+	mode := fs.String("mode", "merge", "Import mode: replace, merge, only-listed-fields")
+	sheet := fs.String("sheet", "", "Read a CSV sheet instead (TSV for .tsv files): inventory, important-items, characters, spells")
 	backup := fs.Bool("backup", true, "Create backup before import")
+	dryRun := fs.Bool("dry-run", false, "Print the changes without writing the save")
+	force := fs.Bool("force", false, "Write the save even if validation finds issues")
 
 	if err := fs.Parse(c.args[1:]); err != nil {
 		return err
@@ -106,7 +164,32 @@ func (c *CLI) importCommand() error {
 		return fmt.Errorf("--file and --input are required")
 	}
 
-	return c.handleImportCommand(*file, *input, *format, *backup)
+	if *sheet != "" {
+		s, err := ioCsv.ParseSheet(*sheet)
+		if err != nil {
+			return err
+		}
+		return c.handleImportSheetCommand(*file, *input, s, *dryRun, *backup, *force)
+	}
+
+	importMode, err := ioJson.ParseImportMode(*mode)
+	if err != nil {
+		return err
+	}
+
+	return c.handleImportCommand(*file, *input, *format, ioJson.ImportOptions{Mode: importMode, DryRun: *dryRun}, *backup, *force)
+}
+
+// schemaCommand prints the JSON Schema of export documents
+func (c *CLI) schemaCommand() error {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	output := fs.String("output", "", "Output file path (defaults to stdout)")
+
+	if err := fs.Parse(c.args[1:]); err != nil {
+		return err
+	}
+
+	return c.handleSchemaCommand(*output)
 }
 
 // batchCommand performs batch operations
@@ -150,6 +233,9 @@ func (c *CLI) validateCommand() error {
 	fs := flag.NewFlagSet("validate", flag.ExitOnError)
 	file := fs.String("file", "", "Save file path (required)")
 	fix := fs.Bool("fix", false, "Attempt to fix issues automatically")
+	force := fs.Bool("force", false, "Write the fixed save even if issues remain")
+	mode := fs.String("mode", "normal", "Validation level: strict, normal, permissive or a rule set profile")
+	rules := fs.String("rules", "", "Directory of JSON/YAML rule files (defaults to <config>/rules)")
 
 	if err := fs.Parse(c.args[1:]); err != nil {
 		return err
@@ -159,24 +245,12 @@ func (c *CLI) validateCommand() error {
 		return fmt.Errorf("--file is required")
 	}
 
-	return c.handleValidateCommand(*file, *fix)
+	return c.handleValidateCommand(*file, *fix, *force, *mode, *rules)
 }
 
-// backupCommand creates a backup of a save file
+// backupCommand manages save file backups (create, list, restore, delete, prune)
 func (c *CLI) backupCommand() error {
-	fs := flag.NewFlagSet("backup", flag.ExitOnError)
-	file := fs.String("file", "", "Save file path (required)")
-	output := fs.String("output", "", "Backup output path (optional)")
-
-	if err := fs.Parse(c.args[1:]); err != nil {
-		return err
-	}
-
-	if *file == "" {
-		return fmt.Errorf("--file is required")
-	}
-
-	return c.handleBackupCommand(*file, *output)
+	return c.handleBackupCommand(c.args[1:])
 }
 
 // showHelp displays CLI help
@@ -191,11 +265,20 @@ COMMANDS:
     edit       Edit a save file directly
     export     Export save data to JSON
     import     Import JSON data into save file
+    schema     Print the JSON Schema of export documents
     batch      Perform batch operations
 	script     Run a Lua script on a save file
 	validate   Validate save file integrity
-	backup     Create a backup of a save file
+	backup     Create, list, restore, delete or prune save backups
+	diff       Show differences between two save files
+	apply-patch Apply a JSON patch of save field changes
+	merge      Three-way merge two saves that diverged from a common base
+	shell      Edit a save interactively (tab completion, undo)
+	watch      Back up, validate and diff saves as the game writes them
+	history    Record save points and show the progression timeline
+	report     Render a save or the changes between two saves as HTML or Markdown
 	combat-pack Run Combat Depth Pack helpers (Encounter/Boss/Companion/Smoke)
+	source     Export a save as editable text and build the save back from it
     help       Show this help message
     version    Show version information
 
@@ -209,6 +292,12 @@ EXAMPLES:
     # Import characters from JSON
     ffvi_editor import --file save.json --input characters.json --format characters
 
+    # Export the spell learning matrix for a spreadsheet
+    ffvi_editor export --file save.json --output spells.csv --sheet spells
+
+    # Preview stamping a party build onto a save, replacing its inventory
+    ffvi_editor import --file save.json --input build.json --mode replace --dry-run
+
     # Maximize all stats
     ffvi_editor batch --file save.json --op max-all
 
@@ -218,6 +307,42 @@ EXAMPLES:
     # Validate save file
     ffvi_editor validate --file save.json --fix
 
+    # Validate against the rule files of a challenge run profile
+    ffvi_editor validate --file save.json --rules ./rules --mode low-level
+
+    # Back up a save, then list and restore backups
+    ffvi_editor backup create --file save.json --desc "before boss"
+    ffvi_editor backup list
+    ffvi_editor backup restore 20260101_120000_a1b2c3d4
+
+    # Show what changed between two saves (exit code 1 if they differ)
+    ffvi_editor diff before.sav after.sav --category Character
+
+    # Record the changes as a patch and replay them onto another save
+    ffvi_editor diff before.sav after.sav --format patch > changes.json
+    ffvi_editor apply-patch --file other.sav --patch changes.json
+
+    # Merge two copies of a save that were played separately since base.sav
+    ffvi_editor merge base.sav laptop.sav desktop.sav --output merged.sav
+
+    # Edit a save interactively, e.g. "set char Terra level 99", "give Elixir 99", "save"
+    ffvi_editor shell save.sav
+
+    # Back up and check every save the game writes, enforcing a Lua rule
+    ffvi_editor watch --dir <savedir> --lua rules/low_level.lua
+
+    # Track a playthrough: record every write, then show levels, gil and espers over time
+    ffvi_editor watch --dir <savedir> --history
+    ffvi_editor history timeline --slot <savedir>/<slot>
+
+    # Share a save as a web page, or the changes between two saves as Markdown
+    ffvi_editor report save.sav --output save.html
+    ffvi_editor report before.sav after.sav --format markdown
+
+    # Keep a save as text under version control and rebuild it after editing
+    ffvi_editor source export --file save.sav --output save.ffsrc
+    ffvi_editor source build --input save.ffsrc --output save.sav
+
 For more information, visit: https://github.com/username/ffvi-save-editor
 `
 	fmt.Println(help)
@@ -9,7 +9,10 @@ import (
 	"strconv"
 	"strings"
 
+	ioCsv "ffvi_editor/io/csv"
+	ioJson "ffvi_editor/io/json"
 	"ffvi_editor/io/pr"
+	"ffvi_editor/io/validation"
 	"ffvi_editor/scripting"
 )
 
@@ -22,10 +25,14 @@ func (c *CLI) LoadSaveFile(filepath string) (*pr.PR, error) {
 	return p, nil
 }
 
-// SaveSaveFile saves a save file to the specified path
-func (c *CLI) SaveSaveFile(save *pr.PR, filepath string) error {
-	if err := save.Save(0, filepath, 0); err != nil {
-		return fmt.Errorf("failed to save file: %w", err)
+// SaveSaveFile saves a save file to the specified path once it passes the
+// pre-save check of gate; force accepts issues the editor would ask about
+func (c *CLI) SaveSaveFile(save *pr.PR, filepath string, gate *validation.SaveGate, force bool) error {
+	err := gatedSave(gate, save, force, os.Stdout, func() error {
+		return save.Save(save.SlotID(), filepath, 0)
+	})
+	if err != nil {
+		return err
 	}
 	fmt.Printf("Successfully saved to: %s\n", filepath)
 	return nil
@@ -39,20 +46,48 @@ func (c *CLI) handleEditCommand(file string, charID, level, hp, mp int, output s
 	return fmt.Errorf("CLI edit command not yet implemented (Phase 4)")
 }
 
-// handleExport exports save data to JSON in various formats
-// TODO: Implement export command in CLI (Phase 4)
-// Placeholder for save file export to JSON, CSV, or other formats
-// Will use existing export functions from UI dashboards
+// handleExport exports save data to a JSON document
 func (c *CLI) handleExportCommand(file, output, format string) error {
-	return fmt.Errorf("CLI export command not yet implemented (Phase 4)")
+	save, err := c.LoadSaveFile(file)
+	if err != nil {
+		return err
+	}
+	if err = ioJson.NewExporter(save).ExportToFile(ioJson.ExportFormat(format), output); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %s data to: %s\n", format, output)
+	return nil
 }
 
-// handleImport imports JSON data into a save file
-// TODO: Implement import command in CLI (Phase 4)
-// Placeholder for importing save files from various formats
-// Will integrate with existing save loading infrastructure
-func (c *CLI) handleImportCommand(file, input, format string, backup bool) error {
-	return fmt.Errorf("CLI import command not yet implemented (Phase 4)")
+// handleExportSheet exports save data as a CSV or TSV sheet
+func (c *CLI) handleExportSheetCommand(file, output string, sheet ioCsv.Sheet) error {
+	save, err := c.LoadSaveFile(file)
+	if err != nil {
+		return err
+	}
+	if err = ioCsv.NewExporter(save).ExportToFile(sheet, output); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %s sheet to: %s\n", sheet, output)
+	return nil
+}
+
+// handleSchema writes the export schema to output, or stdout when empty
+func (c *CLI) handleSchemaCommand(output string) error {
+	schema, err := ioJson.ExportSchemaJSON()
+	if err != nil {
+		return err
+	}
+	schema = append(schema, '\n')
+	if output == "" {
+		_, err = os.Stdout.Write(schema)
+		return err
+	}
+	if err = os.WriteFile(output, schema, 0644); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	fmt.Printf("Wrote export schema %s to: %s\n", ioJson.ExportVersion, output)
+	return nil
 }
 
 // handleBatch performs batch operations on a save file
@@ -71,22 +106,6 @@ func (c *CLI) handleScriptCommand(file, scriptFile, output string) error {
 	return fmt.Errorf("CLI script command not yet implemented (Phase 4)")
 }
 
-// handleValidate validates a save file
-// TODO: Implement validate command in CLI (Phase 4)
-// Placeholder for save file validation and integrity checks
-// Will use existing validation infrastructure from saver.go
-func (c *CLI) handleValidateCommand(file string, fix bool) error {
-	return fmt.Errorf("CLI validate command not yet implemented (Phase 4)")
-}
-
-// handleBackup creates a backup of a save file
-// TODO: Implement backup command in CLI (Phase 4)
-// Placeholder for automatic backup and restore operations
-// Will integrate with cloud backup infrastructure from Phase 4
-func (c *CLI) handleBackupCommand(file, output string) error {
-	return fmt.Errorf("CLI backup command not yet implemented (Phase 4)")
-}
-
 // combatPackCommand exposes Combat Depth Pack helpers via CLI
 func (c *CLI) combatPackCommand() error {
 	fs := flag.NewFlagSet("combat-pack", flag.ExitOnError)
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+)
+
+// diffCommand compares two save files
+func (c *CLI) diffCommand() error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := fs.String("format", "text", "Output format: text, json, patch")
+	category := fs.String("category", "", "Only report differences in this category")
+	ps := fs.Bool("ps", false, "Save files use the PlayStation format")
+
+	files, err := parseInterspersed(fs, c.args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(files) != 2 {
+		return fmt.Errorf("usage: diff [--format text|json|patch] [--category NAME] <old save> <new save>")
+	}
+
+	saveType := global.PC
+	if *ps {
+		saveType = global.PS
+	}
+
+	return c.handleDiffCommand(files[0], files[1], *format, *category, saveType)
+}
+
+// handleDiffCommand prints the differences between two saves. It returns an
+// ExitError with code 1 when differences are found, like diff(1).
+func (c *CLI) handleDiffCommand(oldFile, newFile, format, category string, saveType global.SaveFileType) error {
+	report, err := pr.CompareFiles(oldFile, newFile, saveType)
+	if err != nil {
+		return err
+	}
+
+	diffs := report.GetSortedDiffs()
+	if category != "" {
+		diffs = report.GetDiffsByCategory(resolveCategory(&report, category))
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(diffs); err != nil {
+			return err
+		}
+	case "patch":
+		filtered := pr.DiffReport{Diffs: diffs}
+		patch := filtered.ToPatch(fmt.Sprintf("Changes from %s to %s", oldFile, newFile))
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(patch); err != nil {
+			return err
+		}
+	case "text":
+		printDiffs(diffs)
+		if category == "" {
+			fmt.Println(report.Statistics.String())
+		}
+	default:
+		return fmt.Errorf("unknown diff format: %s", format)
+	}
+
+	if len(diffs) > 0 {
+		return &ExitError{Code: 1}
+	}
+	return nil
+}
+
+// resolveCategory matches a user supplied category against the report's
+// categories without regard to case
+func resolveCategory(report *pr.DiffReport, category string) string {
+	for _, c := range report.Categories() {
+		if strings.EqualFold(c, category) {
+			return c
+		}
+	}
+	return category
+}
+
+// printDiffs writes diffs grouped under a heading per category
+func printDiffs(diffs []pr.Diff) {
+	if len(diffs) == 0 {
+		fmt.Println("No differences found")
+		return
+	}
+
+	groups := make(map[string][]pr.Diff)
+	order := make([]string, 0)
+	for _, d := range diffs {
+		if _, ok := groups[d.Category]; !ok {
+			order = append(order, d.Category)
+		}
+		groups[d.Category] = append(groups[d.Category], d)
+	}
+
+	for _, category := range order {
+		fmt.Printf("%s (%d)\n", category, len(groups[category]))
+		for _, d := range groups[category] {
+			fmt.Printf("  %s\n", formatDiff(d))
+		}
+		fmt.Println()
+	}
+}
+
+// formatDiff renders a single diff as one line
+func formatDiff(d pr.Diff) string {
+	label := d.Name
+	if d.Field != "" {
+		label += " " + d.Field
+	}
+	switch d.Type {
+	case pr.DiffAdded:
+		return fmt.Sprintf("+ %s: %v", label, d.NewValue)
+	case pr.DiffRemoved:
+		return fmt.Sprintf("- %s: %v", label, d.OldValue)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", label, d.OldValue, d.NewValue)
+	}
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments and returns the positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
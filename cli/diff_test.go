@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ffvi_editor/io/pr"
+)
+
+const testSaveDir = "../save_data/76561198072182150"
+
+func TestDiffIdenticalSaves(t *testing.T) {
+	save := filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+
+	out, err := captureOutput(func() error {
+		return NewCLI([]string{"diff", save, save}).Run()
+	})
+	if err != nil {
+		t.Fatalf("diff of identical saves returned %v", err)
+	}
+	if !strings.Contains(out, "No differences found") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+
+	out, err = captureOutput(func() error {
+		return NewCLI([]string{"diff", "--format", "json", save, save}).Run()
+	})
+	if err != nil {
+		t.Fatalf("json diff of identical saves returned %v", err)
+	}
+	var diffs []pr.Diff
+	if err = json.Unmarshal([]byte(out), &diffs); err != nil {
+		t.Fatalf("failed to decode json output: %v; raw=%s", err, out)
+	}
+	if len(diffs) != 0 {
+		t.Fatalf("expected no diffs, got %d", len(diffs))
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	tests := []struct {
+		diff pr.Diff
+		want string
+	}{
+		{pr.Diff{Type: pr.DiffModified, Name: "Terra", Field: "Level", OldValue: 6, NewValue: 7}, "~ Terra Level: 6 -> 7"},
+		{pr.Diff{Type: pr.DiffAdded, Name: "Potion", Field: "Count", NewValue: 5}, "+ Potion Count: 5"},
+		{pr.Diff{Type: pr.DiffRemoved, Name: "Ramuh", OldValue: true}, "- Ramuh: true"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDiff(tt.diff); got != tt.want {
+			t.Errorf("formatDiff() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestDiffTypeJSON(t *testing.T) {
+	b, err := json.Marshal(pr.Diff{Type: pr.DiffModified})
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if !strings.Contains(string(b), `"type":"Modified"`) {
+		t.Fatalf("diff type not encoded by name: %s", b)
+	}
+
+	var d pr.Diff
+	if err = json.Unmarshal(b, &d); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if d.Type != pr.DiffModified {
+		t.Fatalf("Type = %v, want Modified", d.Type)
+	}
+}
+
+func TestDiffDifferentSaves(t *testing.T) {
+	oldSave := filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	newSave := filepath.Join(testSaveDir, "vgU2wnuaPje2Or53Iqs8Mp=Al6sdM+GM04Iymv229Ow=")
+
+	out, err := captureOutput(func() error {
+		return NewCLI([]string{"diff", oldSave, newSave}).Run()
+	})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("expected exit status 1, got %v", err)
+	}
+	if !strings.Contains(out, "~ Misc Saves: 17 -> 19") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+
+	_, _ = captureOutput(func() error {
+		if code := Main([]string{"diff", oldSave, newSave}); code != 1 {
+			t.Errorf("Main() = %d for differing saves, want 1", code)
+		}
+		if code := Main([]string{"diff", oldSave, oldSave}); code != 0 {
+			t.Errorf("Main() = %d for identical saves, want 0", code)
+		}
+		return nil
+	})
+}
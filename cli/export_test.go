@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ioJson "ffvi_editor/io/json"
+)
+
+func TestExportCommand(t *testing.T) {
+	file := filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	output := filepath.Join(t.TempDir(), "export.json")
+
+	if _, err := captureOutput(func() error {
+		return NewCLI([]string{"export", "--file", file, "--output", output, "--format", "full"}).Run()
+	}); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var export ioJson.SaveExport
+	if err = json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("export isn't valid JSON: %v", err)
+	}
+	if len(export.Characters) == 0 || export.Inventory == nil || len(export.Inventory.Items) == 0 {
+		t.Errorf("export is missing data:\n%s", data)
+	}
+}
+
+func TestExportSheetCommand(t *testing.T) {
+	file := filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	output := filepath.Join(t.TempDir(), "spells.csv")
+
+	if _, err := captureOutput(func() error {
+		return NewCLI([]string{"export", "--file", file, "--output", output, "--sheet", "spells"}).Run()
+	}); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "id,spell,") || !strings.Contains(string(data), ",Fire,") {
+		t.Errorf("unexpected spell sheet:\n%s", data)
+	}
+}
@@ -0,0 +1,298 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/config"
+	"ffvi_editor/io/history"
+)
+
+// historyCommand manages the progression timeline (capture, import, list, timeline, diff)
+func (c *CLI) historyCommand() error {
+	args := c.args[1:]
+	if len(args) == 0 {
+		return c.showHistoryHelp()
+	}
+
+	switch args[0] {
+	case "capture":
+		return c.historyCapture(args[1:])
+	case "import":
+		return c.historyImport(args[1:])
+	case "list":
+		return c.historyList(args[1:])
+	case "timeline":
+		return c.historyTimeline(args[1:])
+	case "diff":
+		return c.historyDiff(args[1:])
+	case "help", "-h", "--help":
+		return c.showHistoryHelp()
+	default:
+		return fmt.Errorf("unknown history subcommand: %s", args[0])
+	}
+}
+
+// historyCapture records the current contents of a save slot
+func (c *CLI) historyCapture(args []string) error {
+	fs := flag.NewFlagSet("history capture", flag.ExitOnError)
+	file := fs.String("file", "", "Save file path (required)")
+	dir := fs.String("dir", "", "History directory (defaults to <save dir>/history)")
+	description := fs.String("desc", "", "Point description")
+	ps := fs.Bool("ps", false, "Save file uses the PlayStation format")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("--file is required")
+	}
+
+	store, err := c.openHistoryStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	saveType := global.PC
+	if *ps {
+		saveType = global.PS
+	}
+	entry, err := store.RecordFile(*file, saveType, history.SourceManual, *description)
+	if err != nil {
+		return fmt.Errorf("failed to record save: %w", err)
+	}
+	if entry == nil {
+		fmt.Println("Save unchanged since the last point; nothing recorded")
+		return nil
+	}
+	fmt.Printf("Recorded point %s for %s\n", entry.ID, entry.Slot)
+	return nil
+}
+
+// historyImport adds every backup that isn't in the history yet
+func (c *CLI) historyImport(args []string) error {
+	fs := flag.NewFlagSet("history import", flag.ExitOnError)
+	dir := fs.String("dir", "", "History directory (defaults to <save dir>/history)")
+	backupDir := fs.String("backup-dir", "", "Backup directory (defaults to <save dir>/backups)")
+	ps := fs.Bool("ps", false, "Backups use the PlayStation format")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := c.openHistoryStore(*dir)
+	if err != nil {
+		return err
+	}
+	m, err := c.openBackupManager(*backupDir, 0)
+	if err != nil {
+		return err
+	}
+
+	saveType := global.PC
+	if *ps {
+		saveType = global.PS
+	}
+	added, err := store.ImportBackups(m, saveType)
+	if err != nil {
+		return fmt.Errorf("failed to import backups: %w", err)
+	}
+	fmt.Printf("Imported %d backup(s) from %s\n", len(added), m.BackupDir())
+	return nil
+}
+
+// historyList prints the recorded points, oldest first
+func (c *CLI) historyList(args []string) error {
+	fs := flag.NewFlagSet("history list", flag.ExitOnError)
+	dir := fs.String("dir", "", "History directory (defaults to <save dir>/history)")
+	slot := fs.String("slot", "", "Only list points of this save file")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := c.openHistoryStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	entries := store.Entries(*slot)
+	if len(entries) == 0 {
+		fmt.Printf("No history found in %s\n", store.Dir())
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tSLOT\tSOURCE\tGIL\tPLAY TIME\tDESCRIPTION")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			e.ID,
+			e.Timestamp.Format("2006-01-02 15:04:05"),
+			filepath.Base(e.Slot),
+			e.Source,
+			e.Summary.Gil,
+			formatPlayTime(e.Summary.PlayTime),
+			e.Description,
+		)
+	}
+	return w.Flush()
+}
+
+// historyTimeline prints the progression of a slot
+func (c *CLI) historyTimeline(args []string) error {
+	fs := flag.NewFlagSet("history timeline", flag.ExitOnError)
+	dir := fs.String("dir", "", "History directory (defaults to <save dir>/history)")
+	slot := fs.String("slot", "", "Save file to show (required when several slots are recorded)")
+	format := fs.String("format", "text", "Output format: text, json")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := c.openHistoryStore(*dir)
+	if err != nil {
+		return err
+	}
+	if *slot == "" {
+		slots := store.Slots()
+		if len(slots) > 1 {
+			return fmt.Errorf("history has %d slots; choose one with --slot:\n  %s", len(slots), strings.Join(slots, "\n  "))
+		}
+		if len(slots) == 1 {
+			*slot = slots[0]
+		}
+	}
+
+	tl := store.Timeline(*slot)
+	switch strings.ToLower(*format) {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			*history.Timeline
+			Levels   map[string][]history.Point `json:"levels"`
+			Gil      []history.Point            `json:"gil"`
+			Espers   []history.EsperEvent       `json:"espers"`
+			Maps     []history.MapVisit         `json:"maps"`
+			Progress []history.ProgressPoint    `json:"progress"`
+		}{tl, tl.LevelCurves(), tl.Gil(), tl.EspersAcquired(), tl.MapsVisited(), tl.Progress()})
+	case "text":
+		printTimeline(tl)
+		return nil
+	default:
+		return fmt.Errorf("unknown timeline format: %s", *format)
+	}
+}
+
+// historyDiff compares the saves of two points. Like diff, it returns an
+// ExitError with code 1 when they differ.
+func (c *CLI) historyDiff(args []string) error {
+	fs := flag.NewFlagSet("history diff", flag.ExitOnError)
+	dir := fs.String("dir", "", "History directory (defaults to <save dir>/history)")
+
+	ids, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(ids) != 2 {
+		return fmt.Errorf("usage: history diff <from id> <to id> [--dir DIR]")
+	}
+
+	store, err := c.openHistoryStore(*dir)
+	if err != nil {
+		return err
+	}
+	report, err := store.Diff(ids[0], ids[1])
+	if err != nil {
+		return err
+	}
+
+	diffs := report.GetSortedDiffs()
+	printDiffs(diffs)
+	fmt.Println(report.Statistics.String())
+	if len(diffs) > 0 {
+		return &ExitError{Code: 1}
+	}
+	return nil
+}
+
+// openHistoryStore opens the history directory, falling back to the same
+// location the GUI uses when no directory is given
+func (c *CLI) openHistoryStore(dir string) (*history.Store, error) {
+	if dir == "" {
+		dir = filepath.Join(config.SaveDir(), "history")
+	}
+	store, err := history.NewStore(dir, history.DefaultMaxPoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history directory: %w", err)
+	}
+	return store, nil
+}
+
+// printTimeline writes the level curves, gil, espers and maps of a timeline
+func printTimeline(tl *history.Timeline) {
+	if len(tl.Entries) == 0 {
+		fmt.Println("No points recorded")
+		return
+	}
+	first, last := tl.Entries[0], tl.Entries[len(tl.Entries)-1]
+	fmt.Printf("%d point(s) from %s to %s\n\n", len(tl.Entries),
+		first.Timestamp.Format("2006-01-02 15:04"), last.Timestamp.Format("2006-01-02 15:04"))
+
+	fmt.Println("Levels:")
+	curves := tl.LevelCurves()
+	for _, name := range tl.CharacterNames() {
+		levels := make([]string, 0, len(curves[name]))
+		for _, p := range curves[name] {
+			levels = append(levels, fmt.Sprintf("%.0f", p.Value))
+		}
+		fmt.Printf("  %-10s %s\n", name, strings.Join(levels, " -> "))
+	}
+
+	fmt.Println("\nProgress:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TIME\tPLAY TIME\tSTEPS\tBATTLES\tGIL")
+	gil := tl.Gil()
+	for i, p := range tl.Progress() {
+		fmt.Fprintf(w, "  %s\t%s\t%d\t%d\t%.0f\n", p.Time.Format("2006-01-02 15:04"), formatPlayTime(p.PlayTime), p.Steps, p.Battles, gil[i].Value)
+	}
+	_ = w.Flush()
+
+	if espers := tl.EspersAcquired(); len(espers) > 0 {
+		fmt.Println("\nEspers acquired:")
+		for _, e := range espers {
+			fmt.Printf("  %s  %s\n", e.Time.Format("2006-01-02 15:04"), e.Name)
+		}
+	}
+
+	fmt.Println("\nMaps visited:")
+	for _, v := range tl.MapsVisited() {
+		fmt.Printf("  %s  map %d (%.0f, %.0f)\n", v.From.Format("2006-01-02 15:04"), v.MapID, v.X, v.Y)
+	}
+}
+
+// formatPlayTime prints seconds as h:mm:ss
+func formatPlayTime(seconds float64) string {
+	s := int(seconds)
+	return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+}
+
+// showHistoryHelp displays usage for the history subcommands
+func (c *CLI) showHistoryHelp() error {
+	fmt.Println(`History commands:
+    history capture  --file save.sav [--dir DIR] [--desc TEXT] [--ps]
+    history import   [--backup-dir DIR] [--dir DIR] [--ps]
+    history list     [--slot save.sav] [--dir DIR]
+    history timeline [--slot save.sav] [--format text|json] [--dir DIR]
+    history diff     <from id> <to id> [--dir DIR]
+
+The history directory defaults to <save dir>/history, the same location used
+by the editor's Progression Timeline. "watch --history" records every write.`)
+	return nil
+}
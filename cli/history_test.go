@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistoryCaptureAndTimeline(t *testing.T) {
+	dir := t.TempDir()
+	slot := filepath.Join(dir, "slot")
+	historyDir := filepath.Join(dir, "history")
+
+	for _, src := range []string{"7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=", "vgU2wnuaPje2Or53Iqs8Mp=Al6sdM+GM04Iymv229Ow="} {
+		data, err := os.ReadFile(filepath.Join(testSaveDir, src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(slot, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		out, err := captureOutput(func() error {
+			return NewCLI([]string{"history", "capture", "--file", slot, "--dir", historyDir}).Run()
+		})
+		if err != nil || !strings.Contains(out, "Recorded point") {
+			t.Fatalf("capture failed: %v; output=%s", err, out)
+		}
+	}
+
+	out, err := captureOutput(func() error {
+		return NewCLI([]string{"history", "capture", "--file", slot, "--dir", historyDir}).Run()
+	})
+	if err != nil || !strings.Contains(out, "nothing recorded") {
+		t.Fatalf("unchanged capture: %v; output=%s", err, out)
+	}
+
+	out, err = captureOutput(func() error {
+		return NewCLI([]string{"history", "timeline", "--dir", historyDir}).Run()
+	})
+	if err != nil {
+		t.Fatalf("timeline failed: %v", err)
+	}
+	for _, want := range []string{"2 point(s)", "Levels:", "Terra", "Maps visited:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("timeline output missing %q: %s", want, out)
+		}
+	}
+}
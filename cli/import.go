@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	ioCsv "ffvi_editor/io/csv"
+	ioJson "ffvi_editor/io/json"
+	"ffvi_editor/io/pr"
+)
+
+// handleImportCommand applies a JSON document to a save, prints what changed
+// and writes the save once it passes the pre-save check. Fields that couldn't
+// be imported are listed and return an ExitError with code 1 after the rest
+// was written.
+func (c *CLI) handleImportCommand(file, input, format string, opts ioJson.ImportOptions, backup, force bool) error {
+	doc, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("failed to read JSON file: %w", err)
+	}
+
+	save, err := c.LoadSaveFile(file)
+	if err != nil {
+		return err
+	}
+
+	importer := ioJson.NewImporter(save)
+	report, err := importer.Import(doc, ioJson.ExportFormat(format), opts)
+	if err != nil {
+		return err
+	}
+	errs := make([]string, len(importer.GetErrors()))
+	for i, e := range importer.GetErrors() {
+		errs[i] = fmt.Sprintf("%s: %s", e.Field, e.Message)
+	}
+	return c.finishImport(save, file, input, report, errs, opts.DryRun, backup, force)
+}
+
+// handleImportSheetCommand is handleImportCommand for CSV and TSV sheets
+func (c *CLI) handleImportSheetCommand(file, input string, sheet ioCsv.Sheet, dryRun, backup, force bool) error {
+	save, err := c.LoadSaveFile(file)
+	if err != nil {
+		return err
+	}
+
+	importer := ioCsv.NewImporter(save)
+	report, err := importer.ImportFromFile(sheet, input, dryRun)
+	if err != nil {
+		return err
+	}
+	errs := make([]string, len(importer.GetErrors()))
+	for i, e := range importer.GetErrors() {
+		errs[i] = e.Error()
+	}
+	return c.finishImport(save, file, input, report, errs, dryRun, backup, force)
+}
+
+// finishImport prints the changes and errors of an import and writes the
+// save unless it was a dry run or nothing changed
+func (c *CLI) finishImport(save *pr.PR, file, input string, report *pr.DiffReport, errs []string, dryRun, backup, force bool) error {
+	printDiffs(report.Diffs)
+	for _, e := range errs {
+		fmt.Fprintf(os.Stderr, "! %s\n", e)
+	}
+
+	if dryRun {
+		fmt.Println("Dry run: save not written")
+	} else if report.Statistics.TotalDiffs > 0 {
+		if backup {
+			if err := c.backupBeforeImport(file, input); err != nil {
+				return err
+			}
+		}
+		gate, err := newSaveGate()
+		if err != nil {
+			return err
+		}
+		if err = c.SaveSaveFile(save, file, gate, force); err != nil {
+			return err
+		}
+	}
+
+	if len(errs) > 0 {
+		fmt.Printf("%d field(s) could not be imported\n", len(errs))
+		return &ExitError{Code: 1}
+	}
+	return nil
+}
+
+// backupBeforeImport backs the save up into the default backup directory
+func (c *CLI) backupBeforeImport(file, input string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read save file: %w", err)
+	}
+	m, err := c.openBackupManager("", defaultBackupsToKeep)
+	if err != nil {
+		return err
+	}
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		absPath = file
+	}
+	meta, err := m.CreateBackup(absPath, data, "Before import of "+filepath.Base(input))
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	fmt.Printf("Created backup %s in %s\n", meta.ID, m.BackupDir())
+	return nil
+}
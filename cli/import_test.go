@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+)
+
+func TestImportCommand(t *testing.T) {
+	src := filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatalf("failed to read save: %v", err)
+	}
+
+	tmp := t.TempDir()
+	saveFile := filepath.Join(tmp, "slot.sav")
+	if err = os.WriteFile(saveFile, data, 0644); err != nil {
+		t.Fatalf("failed to copy save: %v", err)
+	}
+	input := filepath.Join(tmp, "build.json")
+	doc := `{"characters": [{"rootName": "Terra", "level": 20}, {"rootName": "Nobody", "level": 5}]}`
+	if err = os.WriteFile(input, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"import", "--file", saveFile, "--input", input, "--mode", "only-listed-fields", "--backup=false"}
+
+	// A dry run prints the change and leaves the file alone
+	out, err := captureOutput(func() error {
+		return NewCLI(append(args, "--dry-run")).Run()
+	})
+	var exit *ExitError
+	if !errors.As(err, &exit) || exit.Code != 1 {
+		t.Fatalf("expected exit code 1 for the unknown character, got %v", err)
+	}
+	if !strings.Contains(out, "Level") || !strings.Contains(out, "Dry run") {
+		t.Errorf("unexpected dry run output:\n%s", out)
+	}
+	if written, _ := os.ReadFile(saveFile); !bytes.Equal(written, data) {
+		t.Fatal("dry run wrote the save")
+	}
+
+	if _, err = captureOutput(func() error {
+		return NewCLI(args).Run()
+	}); !errors.As(err, &exit) {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	save := pr.New()
+	if err = save.Load(saveFile, global.PC); err != nil {
+		t.Fatalf("failed to reload imported save: %v", err)
+	}
+	if terra := pr.FindCharacter("Terra"); terra == nil || terra.Level != 20 {
+		t.Fatalf("Terra not imported: %+v", terra)
+	}
+}
+
+func TestImportSheetCommand(t *testing.T) {
+	src := filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatalf("failed to read save: %v", err)
+	}
+
+	tmp := t.TempDir()
+	saveFile := filepath.Join(tmp, "slot.sav")
+	if err = os.WriteFile(saveFile, data, 0644); err != nil {
+		t.Fatalf("failed to copy save: %v", err)
+	}
+	input := filepath.Join(tmp, "stats.tsv")
+	if err = os.WriteFile(input, []byte("rootName\tlevel\nTerra\t20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = captureOutput(func() error {
+		return NewCLI([]string{"import", "--file", saveFile, "--input", input, "--sheet", "characters", "--backup=false"}).Run()
+	}); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	save := pr.New()
+	if err = save.Load(saveFile, global.PC); err != nil {
+		t.Fatalf("failed to reload imported save: %v", err)
+	}
+	if terra := pr.FindCharacter("Terra"); terra == nil || terra.Level != 20 {
+		t.Fatalf("Terra not imported: %+v", terra)
+	}
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+)
+
+// mergeCommand merges two saves that diverged from a common base
+func (c *CLI) mergeCommand() error {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	output := fs.String("output", "", "Output file path (defaults to ours)")
+	prefer := fs.String("prefer", "ours", "Side kept for conflicting positions and equipment: ours, theirs")
+	strategies := fs.String("strategy", "", "Per-group strategies, e.g. map=prefer,inventory=max")
+	format := fs.String("format", "text", "Conflict report format: text, json")
+	ps := fs.Bool("ps", false, "Save files use the PlayStation format")
+	dryRun := fs.Bool("dry-run", false, "Report conflicts without writing the merged save")
+	force := fs.Bool("force", false, "Write the merged save even if validation finds issues")
+
+	files, err := parseInterspersed(fs, c.args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(files) != 3 {
+		return fmt.Errorf("usage: merge [--output FILE] [--prefer ours|theirs] [--strategy GROUP=STRATEGY,...] <base> <ours> <theirs>")
+	}
+
+	opts := pr.DefaultMergeOptions()
+	switch side := pr.MergeSide(strings.ToLower(*prefer)); side {
+	case pr.MergeOurs, pr.MergeTheirs:
+		opts.Prefer = side
+	default:
+		return fmt.Errorf("--prefer must be ours or theirs")
+	}
+	if opts.Strategies, err = pr.ParseMergeStrategies(*strategies); err != nil {
+		return err
+	}
+
+	saveType := global.PC
+	if *ps {
+		saveType = global.PS
+	}
+
+	return c.handleMergeCommand(files[0], files[1], files[2], *output, *format, opts, saveType, *dryRun, *force)
+}
+
+// handleMergeCommand merges the saves, writes the result once it passes the
+// pre-save check and reports the conflicts. It returns an ExitError with code
+// 1 when a conflict was settled by picking a side, since the other side's
+// change was dropped.
+func (c *CLI) handleMergeCommand(baseFile, oursFile, theirsFile, output, format string, opts pr.MergeOptions, saveType global.SaveFileType, dryRun, force bool) error {
+	if output == "" {
+		output = oursFile
+	}
+
+	save, result, err := pr.MergeSaves(baseFile, oursFile, theirsFile, saveType, opts)
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		gate, err := newSaveGate()
+		if err != nil {
+			return err
+		}
+		// Reports go to stdout, so the check's own output goes to stderr
+		err = gatedSave(gate, save, force, os.Stderr, func() error {
+			return save.Save(save.SlotID(), output, saveType)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(result); err != nil {
+			return err
+		}
+	case "text":
+		for _, cf := range result.Conflicts {
+			fmt.Printf("! %s: base %s, ours %s, theirs %s -> %s (%s)\n", cf.Path,
+				formatMergeValue(cf.Base), formatMergeValue(cf.Ours), formatMergeValue(cf.Theirs),
+				formatMergeValue(cf.Merged), cf.Strategy)
+		}
+		fmt.Printf("Took %d change(s) from theirs, %d conflict(s)\n", result.FromTheirs, len(result.Conflicts))
+		if dryRun {
+			fmt.Println("Dry run: merged save not written")
+		} else {
+			fmt.Printf("Successfully saved to: %s\n", output)
+		}
+	default:
+		return fmt.Errorf("unknown merge format: %s", format)
+	}
+
+	for _, cf := range result.Conflicts {
+		if cf.Strategy == pr.MergePrefer {
+			return &ExitError{Code: 1}
+		}
+	}
+	return nil
+}
+
+// formatMergeValue prints a missing value as "(none)"
+func formatMergeValue(v interface{}) string {
+	if v == nil {
+		return "(none)"
+	}
+	return fmt.Sprintf("%v", v)
+}
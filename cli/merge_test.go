@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+)
+
+func TestMergeSaves(t *testing.T) {
+	base := filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	ours := filepath.Join(testSaveDir, "vgU2wnuaPje2Or53Iqs8Mp=Al6sdM+GM04Iymv229Ow=")
+	theirs := filepath.Join(testSaveDir, "ookrbATYovG3tEOXIH4HqWnsv8TrUlRWzM8AlCmW2mk=")
+	output := filepath.Join(t.TempDir(), "merged")
+
+	out, err := captureOutput(func() error {
+		return NewCLI([]string{"merge", "--format", "json", "--output", output, base, ours, theirs}).Run()
+	})
+	var exitErr *ExitError
+	if err != nil && (!errors.As(err, &exitErr) || exitErr.Code != 1) {
+		t.Fatalf("merge failed: %v", err)
+	}
+
+	var result pr.MergeResult
+	if err = json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("failed to decode json output: %v; raw=%s", err, out)
+	}
+	found := false
+	for _, cf := range result.Conflicts {
+		if cf.Path == "misc/saveCount" {
+			found = true
+			if cf.Strategy != pr.MergeMax || cf.Merged != float64(19) {
+				t.Errorf("save count conflict: %+v", cf)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected a save count conflict, got %+v", result.Conflicts)
+	}
+
+	if err = pr.New().Load(output, global.PC); err != nil {
+		t.Fatalf("failed to load merged save: %v", err)
+	}
+	if v, _ := pr.GetPath("misc/saveCount"); v != 19 {
+		t.Errorf("merged save count = %v, want 19", v)
+	}
+
+	if _, err = captureOutput(func() error {
+		return NewCLI([]string{"merge", "--prefer", "both", base, ours, theirs}).Run()
+	}); err == nil {
+		t.Error("expected an error for an unknown --prefer side")
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+)
+
+// applyPatchCommand replays a patch document onto a save file
+func (c *CLI) applyPatchCommand() error {
+	fs := flag.NewFlagSet("apply-patch", flag.ExitOnError)
+	file := fs.String("file", "", "Save file path (required)")
+	patchFile := fs.String("patch", "", "Patch JSON file (required)")
+	output := fs.String("output", "", "Output file path (defaults to input)")
+	slot := fs.Int("slot", -1, "Save slot ID written into the file (defaults to the loaded slot)")
+	ps := fs.Bool("ps", false, "Save file uses the PlayStation format")
+	dryRun := fs.Bool("dry-run", false, "Validate the patch without writing the save")
+	force := fs.Bool("force", false, "Write the save even if validation finds issues")
+
+	if err := fs.Parse(c.args[1:]); err != nil {
+		return err
+	}
+
+	if *file == "" || *patchFile == "" {
+		return fmt.Errorf("--file and --patch are required")
+	}
+
+	saveType := global.PC
+	if *ps {
+		saveType = global.PS
+	}
+
+	return c.handleApplyPatchCommand(*file, *patchFile, *output, *slot, saveType, *dryRun, *force)
+}
+
+// handleApplyPatchCommand loads a save, applies the patch and writes the
+// result once it passes the pre-save check
+func (c *CLI) handleApplyPatchCommand(file, patchFile, output string, slot int, saveType global.SaveFileType, dryRun, force bool) error {
+	patch, err := pr.LoadPatch(patchFile)
+	if err != nil {
+		return err
+	}
+
+	save := pr.New()
+	if err = save.Load(file, saveType); err != nil {
+		return fmt.Errorf("failed to load save file: %w", err)
+	}
+
+	if err = save.ApplyPatch(patch); err != nil {
+		return fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	fmt.Printf("Applied %d operation(s) from %s\n", len(patch.Operations), patchFile)
+	if dryRun {
+		fmt.Println("Dry run: save file not written")
+		return nil
+	}
+
+	if output == "" {
+		output = file
+	}
+	if slot < 0 {
+		slot = save.SlotID()
+	}
+	gate, err := newSaveGate()
+	if err != nil {
+		return err
+	}
+	err = gatedSave(gate, save, force, os.Stdout, func() error {
+		return save.Save(slot, output, saveType)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Successfully saved to: %s\n", output)
+	return nil
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+	"ffvi_editor/models"
+)
+
+func TestApplyPatchToSave(t *testing.T) {
+	src := filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatalf("failed to read save: %v", err)
+	}
+
+	tmp := t.TempDir()
+	saveFile := filepath.Join(tmp, "slot.sav")
+	if err = os.WriteFile(saveFile, data, 0644); err != nil {
+		t.Fatalf("failed to copy save: %v", err)
+	}
+
+	patch := pr.NewPatch("test")
+	patch.Add(pr.PatchSet, "misc/gil", 12345)
+	patch.Add(pr.PatchSet, "characters/Terra/level", 20)
+	patchFile := filepath.Join(tmp, "patch.json")
+	if err = patch.Save(patchFile); err != nil {
+		t.Fatalf("failed to write patch: %v", err)
+	}
+
+	if _, err = captureOutput(func() error {
+		return NewCLI([]string{"apply-patch", "--file", saveFile, "--patch", patchFile}).Run()
+	}); err != nil {
+		t.Fatalf("apply-patch failed: %v", err)
+	}
+
+	save := pr.New()
+	if err = save.Load(saveFile, global.PC); err != nil {
+		t.Fatalf("failed to reload patched save: %v", err)
+	}
+	if gp := models.GetMisc().GP; gp != 12345 {
+		t.Fatalf("gil = %d, want 12345", gp)
+	}
+	if terra := pr.FindCharacter("Terra"); terra == nil || terra.Level != 20 {
+		t.Fatalf("Terra not patched: %+v", terra)
+	}
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+	"ffvi_editor/io/report"
+)
+
+// reportCommand renders a save, or the changes between two saves, as HTML or
+// Markdown
+func (c *CLI) reportCommand() error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	format := fs.String("format", "", "Report format: html, markdown (defaults to the output extension, or html)")
+	output := fs.String("output", "", "Output file path (defaults to stdout)")
+	title := fs.String("title", "", "Report title")
+	ps := fs.Bool("ps", false, "Save files use the PlayStation format")
+
+	files, err := parseInterspersed(fs, c.args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(files) != 1 && len(files) != 2 {
+		return fmt.Errorf("usage: report [--format html|markdown] [--output FILE] <save> [<new save>]")
+	}
+
+	f := report.HTML
+	if *format != "" {
+		if f, err = report.ParseFormat(*format); err != nil {
+			return err
+		}
+	} else if *output != "" {
+		f = report.FormatForFile(*output)
+	}
+
+	saveType := global.PC
+	if *ps {
+		saveType = global.PS
+	}
+
+	return c.handleReportCommand(files, *output, *title, f, saveType)
+}
+
+// handleReportCommand builds the save or diff document and writes it out
+func (c *CLI) handleReportCommand(files []string, output, title string, f report.Format, saveType global.SaveFileType) error {
+	var render func(io.Writer, report.Format) error
+	if len(files) == 1 {
+		if title == "" {
+			title = "Save Report: " + filepath.Base(files[0])
+		}
+		if err := pr.New().Load(files[0], saveType); err != nil {
+			return fmt.Errorf("failed to load save file: %w", err)
+		}
+		render = report.NewSaveDocument(title, pr.TakeSnapshot()).Render
+	} else {
+		if title == "" {
+			title = "Save Changes"
+		}
+		diffs, err := pr.CompareFiles(files[0], files[1], saveType)
+		if err != nil {
+			return err
+		}
+		render = report.NewDiffDocument(title, filepath.Base(files[0]), filepath.Base(files[1]), &diffs).Render
+	}
+
+	if output == "" {
+		return render(os.Stdout, f)
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	if err = render(out, f); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Report written to: %s\n", output)
+	return nil
+}
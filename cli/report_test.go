@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReportCommand(t *testing.T) {
+	save := filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	newer := filepath.Join(testSaveDir, "vgU2wnuaPje2Or53Iqs8Mp=Al6sdM+GM04Iymv229Ow=")
+	output := filepath.Join(t.TempDir(), "report.md")
+
+	if _, err := captureOutput(func() error {
+		return NewCLI([]string{"report", save, "--output", output, "--title", "My Save"}).Run()
+	}); err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "# My Save") || !strings.Contains(string(data), "## Espers") {
+		t.Errorf("expected a Markdown report from the .md extension, got:\n%s", data)
+	}
+
+	out, err := captureOutput(func() error {
+		return NewCLI([]string{"report", "--format", "html", save, newer}).Run()
+	})
+	if err != nil {
+		t.Fatalf("diff report failed: %v", err)
+	}
+	if !strings.Contains(out, "<!DOCTYPE html>") || !strings.Contains(out, `<tr class="modified">`) {
+		t.Errorf("expected an HTML diff report, got:\n%s", out)
+	}
+
+	if _, err = captureOutput(func() error {
+		return NewCLI([]string{"report", "--format", "pdf", save}).Run()
+	}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,591 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+	"ffvi_editor/models"
+	pri "ffvi_editor/models/pr"
+	"ffvi_editor/scripting"
+	"ffvi_editor/ui/state"
+)
+
+const shellPrompt = "ffvi> "
+
+// shellCommands lists the commands understood by the shell
+var shellCommands = []string{"diff", "exit", "get", "give", "help", "lua", "quit", "redo", "remove", "save", "set", "show", "undo"}
+
+// shellAliases maps short category names typed in the shell to logical path categories
+var shellAliases = map[string]string{
+	"char":      pr.PathCharacters,
+	"chars":     pr.PathCharacters,
+	"character": pr.PathCharacters,
+	"inv":       pr.PathInventory,
+	"item":      pr.PathInventory,
+	"items":     pr.PathInventory,
+	"important": pr.PathImportantItems,
+	"esper":     pr.PathEspers,
+}
+
+// Shell is an interactive editing session on a single save file
+type Shell struct {
+	file     string
+	saveType global.SaveFileType
+	save     *pr.PR
+	undo     *state.UndoStack
+	initial  *pr.Snapshot // save values when the session started
+	dirty    bool
+	out      io.Writer
+}
+
+// shellCommand starts an interactive session on a save file
+func (c *CLI) shellCommand() error {
+	fs := flag.NewFlagSet("shell", flag.ExitOnError)
+	ps := fs.Bool("ps", false, "Save file uses the PlayStation format")
+
+	files, err := parseInterspersed(fs, c.args[1:])
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return fmt.Errorf("usage: shell [--ps] <save file>")
+	}
+
+	saveType := global.PC
+	if *ps {
+		saveType = global.PS
+	}
+
+	sh, err := NewShell(files[0], saveType, os.Stdout)
+	if err != nil {
+		return err
+	}
+	return sh.Run(os.Stdin)
+}
+
+// NewShell loads a save file and prepares a session that writes to out
+func NewShell(file string, saveType global.SaveFileType, out io.Writer) (*Shell, error) {
+	save := pr.New()
+	if err := save.Load(file, saveType); err != nil {
+		return nil, fmt.Errorf("failed to load save file: %w", err)
+	}
+	pr.Changes.Sync()
+	return &Shell{
+		file:     file,
+		saveType: saveType,
+		save:     save,
+		undo:     state.NewUndoStack(100),
+		initial:  pr.TakeSnapshot(),
+		out:      out,
+	}, nil
+}
+
+// Run reads and executes commands until quit or end of input. When in is a
+// terminal the line editor offers tab completion and history.
+func (sh *Shell) Run(in io.Reader) error {
+	fmt.Fprintf(sh.out, "Loaded %s. Type 'help' for commands.\n", sh.file)
+
+	var reader lineReader
+	if f, ok := in.(*os.File); ok {
+		reader = newTerminalReader(f, sh.out, sh.Complete)
+	}
+	if reader == nil {
+		reader = &scanReader{scanner: bufio.NewScanner(in), out: sh.out, complete: sh.Complete}
+	}
+
+	warned := false
+	for {
+		line, err := reader.ReadLine(shellPrompt)
+		if err == io.EOF {
+			fmt.Fprintln(sh.out)
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		quit, err := sh.Execute(line)
+		if err != nil {
+			fmt.Fprintf(sh.out, "Error: %v\n", err)
+		}
+		if quit {
+			if sh.dirty && !warned {
+				fmt.Fprintln(sh.out, "There are unsaved changes; 'save' them or quit again to discard")
+				warned = true
+				continue
+			}
+			return nil
+		}
+		warned = false
+	}
+}
+
+// Execute runs a single shell command line and reports whether the session should end
+func (sh *Shell) Execute(line string) (bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return false, nil
+	}
+
+	cmd, rest, _ := strings.Cut(line, " ")
+	if strings.ToLower(cmd) == "lua" {
+		return false, sh.lua(strings.TrimSpace(rest))
+	}
+
+	args, err := splitShellArgs(rest)
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(cmd) {
+	case "help", "?":
+		sh.help()
+	case "quit", "exit":
+		return true, nil
+	case "show":
+		return false, sh.show(args)
+	case "get":
+		return false, sh.get(args)
+	case "set":
+		return false, sh.set(args)
+	case "give":
+		return false, sh.give(args)
+	case "remove":
+		return false, sh.remove(args)
+	case "diff":
+		sh.diff()
+	case "undo":
+		return false, sh.undoChange()
+	case "redo":
+		return false, sh.redoChange()
+	case "save":
+		return false, sh.saveFile(args)
+	default:
+		return false, fmt.Errorf("unknown command %q (type 'help' for commands)", cmd)
+	}
+	return false, nil
+}
+
+func (sh *Shell) help() {
+	fmt.Fprint(sh.out, `Commands:
+  show char [name]              List characters or show one character
+  show inventory|important      List owned items
+  show party|misc|map           Show party members, misc values or map position
+  show espers|rages|lores|dances|blitzes|bushido
+                                List learned skills
+  get <path>                    Print the value at a save path (e.g. misc/gil)
+  set <path...> <value>         Set a value (e.g. set char Terra level 99)
+  give <item> [count]           Add items to the inventory (default 1)
+  remove <path...>              Remove an item, skill or equipment
+  diff                          Show changes made in this session
+  undo / redo                   Undo or redo the last change
+  save [--force] [file]         Write the save (defaults to the loaded file);
+                                --force writes it despite validation issues
+  lua <code>                    Evaluate Lua with the save bindings
+  quit                          Leave the shell
+`)
+}
+
+// show prints a category or a character
+func (sh *Shell) show(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: show <category> [name]")
+	}
+	category := resolveShellCategory(args[0])
+
+	switch category {
+	case pr.PathCharacters:
+		if len(args) == 1 {
+			for _, c := range pri.Characters {
+				if ch := pr.FindCharacter(c.RootName); ch != nil {
+					fmt.Fprintf(sh.out, "  %-10s level %2d  HP %4d/%-4d  MP %3d/%-3d\n", ch.Name, ch.Level, ch.HP.Current, ch.HP.Max, ch.MP.Current, ch.MP.Max)
+				}
+			}
+			return nil
+		}
+		name := strings.Join(args[1:], " ")
+		if pr.FindCharacter(name) == nil {
+			return fmt.Errorf("unknown character %q", name)
+		}
+		sh.printFields(pr.JoinPath(pr.PathCharacters, name), pr.PathFields(pr.PathCharacters))
+		sh.printFields(pr.JoinPath(pr.PathCharacters, name, "equipment"), pr.PathFields("equipment"))
+		return nil
+	case pr.PathInventory, pr.PathImportantItems:
+		for _, name := range pr.PathNames(category) {
+			if v, err := pr.GetPath(pr.JoinPath(category, name)); err == nil && v != 0 {
+				fmt.Fprintf(sh.out, "  %-20s %2v\n", name, v)
+			}
+		}
+		return nil
+	case pr.PathParty:
+		for i := range pri.GetParty().Members {
+			v, _ := pr.GetPath(pr.JoinPath(pr.PathParty, strconv.Itoa(i)))
+			fmt.Fprintf(sh.out, "  %d: %v\n", i, v)
+		}
+		return nil
+	case pr.PathMisc, pr.PathMap:
+		sh.printFields(category, pr.PathFields(category))
+		return nil
+	case pr.PathEspers, pr.PathRages, pr.PathLores, pr.PathDances, pr.PathBlitzes, pr.PathBushido:
+		var learned []string
+		for _, name := range pr.PathNames(category) {
+			if v, err := pr.GetPath(pr.JoinPath(category, name)); err == nil && v == true {
+				learned = append(learned, name)
+			}
+		}
+		fmt.Fprintf(sh.out, "  %d learned: %s\n", len(learned), strings.Join(learned, ", "))
+		return nil
+	}
+	return sh.get(args)
+}
+
+// printFields prints the value of each field under the base path, skipping
+// fields that are not plain values
+func (sh *Shell) printFields(base string, fields []string) {
+	for _, f := range fields {
+		if v, err := pr.GetPath(base + "/" + f); err == nil {
+			fmt.Fprintf(sh.out, "  %-18s %v\n", f, v)
+		}
+	}
+}
+
+func (sh *Shell) get(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: get <path>")
+	}
+	v, err := pr.GetPath(shellPath(args))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(sh.out, "%v\n", v)
+	return nil
+}
+
+func (sh *Shell) set(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: set <path...> <value>")
+	}
+	path := shellPath(args[:len(args)-1])
+	value := args[len(args)-1]
+	return sh.change(path, func() error { return pr.SetPath(path, value) })
+}
+
+// give adds items to the inventory; the item name may contain spaces
+func (sh *Shell) give(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: give <item> [count]")
+	}
+	count := 1
+	if len(args) > 1 {
+		if n, err := strconv.Atoi(args[len(args)-1]); err == nil {
+			count = n
+			args = args[:len(args)-1]
+		}
+	}
+	path := pr.JoinPath(pr.PathInventory, strings.Join(args, " "))
+	return sh.change(path, func() error { return pr.AddPath(path, count) })
+}
+
+func (sh *Shell) remove(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: remove <path...>")
+	}
+	path := shellPath(args)
+	return sh.change(path, func() error { return pr.RemovePath(path) })
+}
+
+// change applies an edit and records it on the undo stack when the value changed
+func (sh *Shell) change(path string, apply func() error) error {
+	before, err := pr.GetPath(path)
+	if err != nil {
+		return err
+	}
+	if err = sh.edit(path, apply); err != nil {
+		return err
+	}
+	after, _ := pr.GetPath(path)
+	if fmt.Sprint(before) == fmt.Sprint(after) {
+		fmt.Fprintf(sh.out, "%s unchanged: %v\n", path, after)
+		return nil
+	}
+
+	fmt.Fprintf(sh.out, "%s: %v -> %v\n", path, before, after)
+	return nil
+}
+
+// edit runs fn through the model change tracker and records what it changed
+// on the undo stack as one step
+func (sh *Shell) edit(name string, fn func() error) error {
+	unsubscribe := pr.Changes.Subscribe(func(g models.ChangeGroup) {
+		sh.dirty = true
+		_ = sh.undo.RecordGroup(g)
+	})
+	defer unsubscribe()
+	return pr.Changes.Edit(name, fn)
+}
+
+// diff prints every value that differs from when the session started
+func (sh *Shell) diff() {
+	report := pr.NewComparator(sh.initial, pr.TakeSnapshot()).Compare()
+	if !report.HasDifferences() {
+		fmt.Fprintln(sh.out, "No changes")
+		return
+	}
+	for _, d := range report.Diffs {
+		fmt.Fprintf(sh.out, "  %s\n", formatDiff(d))
+	}
+}
+
+func (sh *Shell) undoChange() error {
+	changes, _, err := sh.undo.PopUndo()
+	if err != nil {
+		return err
+	}
+	err = pr.Changes.Undo(changes)
+	for i := len(changes) - 1; i >= 0; i-- {
+		fmt.Fprintf(sh.out, "Undid %s: %v -> %v\n", changes[i].FieldName, changes[i].NewValue, changes[i].OldValue)
+	}
+	sh.dirty = true
+	return err
+}
+
+func (sh *Shell) redoChange() error {
+	changes, _, err := sh.undo.PopRedo()
+	if err != nil {
+		return err
+	}
+	err = pr.Changes.Redo(changes)
+	for _, c := range changes {
+		fmt.Fprintf(sh.out, "Redid %s: %v -> %v\n", c.FieldName, c.OldValue, c.NewValue)
+	}
+	sh.dirty = true
+	return err
+}
+
+func (sh *Shell) saveFile(args []string) error {
+	force := len(args) > 0 && args[0] == "--force"
+	if force {
+		args = args[1:]
+	}
+	output := sh.file
+	if len(args) > 0 {
+		output = args[0]
+	}
+	gate, err := newSaveGate()
+	if err != nil {
+		return err
+	}
+	err = gatedSave(gate, sh.save, force, sh.out, func() error {
+		return sh.save.Save(sh.save.SlotID(), output, sh.saveType)
+	})
+	if err != nil {
+		return err
+	}
+	sh.dirty = false
+	fmt.Fprintf(sh.out, "Saved to %s\n", output)
+	return nil
+}
+
+func (sh *Shell) lua(code string) error {
+	var results []string
+	err := sh.edit("lua", func() (err error) {
+		results, err = scripting.EvalWithSave(context.Background(), code, sh.save)
+		return
+	})
+	if err != nil {
+		return err
+	}
+	if len(results) > 0 {
+		fmt.Fprintln(sh.out, strings.Join(results, "\t"))
+	}
+	return nil
+}
+
+// Complete returns the possible completions of line, each as a full line
+func (sh *Shell) Complete(line string) []string {
+	args, err := splitShellArgs(line)
+	if err != nil {
+		return nil
+	}
+	if len(args) == 0 || strings.HasSuffix(line, " ") {
+		args = append(args, "")
+	}
+
+	var candidates []string
+	word := args[len(args)-1]
+	cmd := strings.ToLower(args[0])
+	switch {
+	case len(args) == 1:
+		candidates = shellCommands
+	case cmd == "give":
+		// Item names may contain spaces, so complete the whole remainder
+		word = strings.Join(args[1:], " ")
+		args = args[:2]
+		candidates = pr.PathNames(pr.PathInventory)
+	case cmd == "show" || cmd == "get" || cmd == "set" || cmd == "remove":
+		candidates = completePath(args[1:])
+	}
+
+	prefix := ""
+	if len(args) > 1 {
+		prefix = joinShellArgs(args[:len(args)-1]) + " "
+	}
+	var completions []string
+	for _, c := range candidates {
+		if strings.HasPrefix(strings.ToLower(c), strings.ToLower(word)) {
+			if cmd != "give" {
+				c = quoteShellArg(c)
+			}
+			completions = append(completions, prefix+c)
+		}
+	}
+	sort.Strings(completions)
+	return completions
+}
+
+// completePath returns the candidates for the last of the path segments typed so far
+func completePath(segments []string) []string {
+	if len(segments) == 1 {
+		return append(pr.PathCategories(), "char")
+	}
+	category := resolveShellCategory(segments[0])
+	switch {
+	case category == pr.PathCharacters && len(segments) == 2:
+		return pr.PathNames(category)
+	case category == pr.PathCharacters && len(segments) == 3:
+		return pr.PathFields(category)
+	case category == pr.PathCharacters && len(segments) == 4:
+		switch strings.ToLower(segments[2]) {
+		case "equipment":
+			return pr.PathFields("equipment")
+		case "spells":
+			return pr.PathNames("spells")
+		}
+	case len(segments) == 2:
+		if fields := pr.PathFields(category); fields != nil {
+			return fields
+		}
+		return pr.PathNames(category)
+	}
+	return nil
+}
+
+// shellPath builds a logical path from shell arguments. A single argument is
+// used as a path as typed; otherwise each argument is one segment.
+func shellPath(args []string) string {
+	if len(args) == 1 && strings.Contains(args[0], "/") {
+		segments := pr.SplitPath(args[0])
+		segments[0] = resolveShellCategory(segments[0])
+		return pr.JoinPath(segments...)
+	}
+	segments := append([]string{resolveShellCategory(args[0])}, args[1:]...)
+	if len(segments) == 1 {
+		// Bare misc fields such as "gil"
+		segments = []string{pr.PathMisc, args[0]}
+	}
+	return pr.JoinPath(segments...)
+}
+
+// resolveShellCategory expands shell aliases and matches categories without regard to case
+func resolveShellCategory(name string) string {
+	if c, ok := shellAliases[strings.ToLower(name)]; ok {
+		return c
+	}
+	for _, c := range pr.PathCategories() {
+		if strings.EqualFold(c, name) {
+			return c
+		}
+	}
+	return name
+}
+
+// splitShellArgs splits a line into words, honoring single and double quotes
+func splitShellArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		quote   rune
+		inWord  bool
+	)
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func joinShellArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = quoteShellArg(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func quoteShellArg(arg string) string {
+	if strings.ContainsAny(arg, " \t'") {
+		return `"` + arg + `"`
+	}
+	return arg
+}
+
+// lineReader reads one command line at a time
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// scanReader reads lines from a non-terminal input. A tab typed before
+// pressing enter lists the completions of the text before it.
+type scanReader struct {
+	scanner  *bufio.Scanner
+	out      io.Writer
+	complete func(string) []string
+}
+
+func (r *scanReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	line := r.scanner.Text()
+	if before, _, found := strings.Cut(line, "\t"); found {
+		for _, c := range r.complete(before) {
+			fmt.Fprintf(r.out, "  %s\n", c)
+		}
+		return "", nil
+	}
+	return line, nil
+}
@@ -0,0 +1,179 @@
+//go:build linux
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalReader is a minimal line editor for an interactive terminal with
+// tab completion, history and left/right cursor movement
+type terminalReader struct {
+	in       *os.File
+	out      io.Writer
+	complete func(string) []string
+	history  []string
+}
+
+// newTerminalReader returns a line editor for in, or nil when in is not a terminal
+func newTerminalReader(in *os.File, out io.Writer, complete func(string) []string) lineReader {
+	if _, err := unix.IoctlGetTermios(int(in.Fd()), unix.TCGETS); err != nil {
+		return nil
+	}
+	return &terminalReader{in: in, out: out, complete: complete}
+}
+
+func (r *terminalReader) ReadLine(prompt string) (string, error) {
+	fd := int(r.in.Fd())
+	saved, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return "", err
+	}
+	raw := *saved
+	raw.Lflag &^= unix.ICANON | unix.ECHO | unix.ISIG
+	raw.Cc[unix.VMIN], raw.Cc[unix.VTIME] = 1, 0
+	if err = unix.IoctlSetTermios(fd, unix.TCSETS, &raw); err != nil {
+		return "", err
+	}
+	defer func() { _ = unix.IoctlSetTermios(fd, unix.TCSETS, saved) }()
+
+	var (
+		buf     []rune
+		pos     int
+		histPos = len(r.history)
+		b       = make([]byte, 1)
+		pending []byte
+		partial []byte // Leading bytes of a multi-byte character
+	)
+	redraw := func() {
+		fmt.Fprintf(r.out, "\r%s%s\x1b[K", prompt, string(buf))
+		if back := len(buf) - pos; back > 0 {
+			fmt.Fprintf(r.out, "\x1b[%dD", back)
+		}
+	}
+	fmt.Fprint(r.out, prompt)
+
+	for {
+		if _, err = r.in.Read(b); err != nil {
+			return "", err
+		}
+		c := b[0]
+
+		// Escape sequences for the arrow keys
+		if len(pending) > 0 || c == 0x1b {
+			pending = append(pending, c)
+			if len(pending) < 3 {
+				continue
+			}
+			switch string(pending) {
+			case "\x1b[A":
+				if histPos > 0 {
+					histPos--
+					buf = []rune(r.history[histPos])
+					pos = len(buf)
+				}
+			case "\x1b[B":
+				if histPos < len(r.history) {
+					histPos++
+					buf = nil
+					if histPos < len(r.history) {
+						buf = []rune(r.history[histPos])
+					}
+					pos = len(buf)
+				}
+			case "\x1b[C":
+				if pos < len(buf) {
+					pos++
+				}
+			case "\x1b[D":
+				if pos > 0 {
+					pos--
+				}
+			}
+			pending = nil
+			redraw()
+			continue
+		}
+
+		switch c {
+		case '\r', '\n':
+			fmt.Fprint(r.out, "\r\n")
+			line := string(buf)
+			if strings.TrimSpace(line) != "" {
+				r.history = append(r.history, line)
+			}
+			return line, nil
+		case 3: // Ctrl-C discards the line
+			fmt.Fprint(r.out, "^C\r\n")
+			buf, pos = nil, 0
+			fmt.Fprint(r.out, prompt)
+		case 4: // Ctrl-D ends input on an empty line
+			if len(buf) == 0 {
+				return "", io.EOF
+			}
+		case 127, 8:
+			if pos > 0 {
+				buf = append(buf[:pos-1], buf[pos:]...)
+				pos--
+				redraw()
+			}
+		case '\t':
+			r.completeLine(prompt, &buf, &pos)
+			redraw()
+		default:
+			if c < 0x20 {
+				continue
+			}
+			partial = append(partial, c)
+			if !utf8.FullRune(partial) {
+				continue
+			}
+			ch, _ := utf8.DecodeRune(partial)
+			partial = partial[:0]
+			if ch != utf8.RuneError {
+				buf = append(buf[:pos], append([]rune{ch}, buf[pos:]...)...)
+				pos++
+				redraw()
+			}
+		}
+	}
+}
+
+// completeLine replaces the text before the cursor with its completion, or
+// with the longest common prefix and a list of candidates when it is ambiguous
+func (r *terminalReader) completeLine(prompt string, buf *[]rune, pos *int) {
+	head, tail := string((*buf)[:*pos]), string((*buf)[*pos:])
+	candidates := r.complete(head)
+	switch len(candidates) {
+	case 0:
+		return
+	case 1:
+		head = candidates[0] + " "
+	default:
+		fmt.Fprint(r.out, "\r\n")
+		for _, c := range candidates {
+			fmt.Fprintf(r.out, "  %s\r\n", c)
+		}
+		head = commonPrefix(candidates)
+	}
+	*buf = []rune(head + tail)
+	*pos = len([]rune(head))
+}
+
+// commonPrefix returns the longest prefix shared by all values
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(v, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
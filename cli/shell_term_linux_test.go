@@ -0,0 +1,65 @@
+//go:build linux
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo terminal, skipping the test where there is none
+func openPTY(t *testing.T) (master, slave *os.File) {
+	t.Helper()
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("no pseudo terminals: %v", err)
+	}
+	t.Cleanup(func() { _ = master.Close() })
+	if err = unix.IoctlSetPointerInt(int(master.Fd()), unix.TIOCSPTLCK, 0); err != nil {
+		t.Skipf("unable to unlock the pseudo terminal: %v", err)
+	}
+	n, err := unix.IoctlGetInt(int(master.Fd()), unix.TIOCGPTN)
+	if err != nil {
+		t.Skipf("unable to name the pseudo terminal: %v", err)
+	}
+	if slave, err = os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|unix.O_NOCTTY, 0); err != nil {
+		t.Skipf("unable to open the pseudo terminal: %v", err)
+	}
+	t.Cleanup(func() { _ = slave.Close() })
+	return master, slave
+}
+
+func TestTerminalReaderUTF8(t *testing.T) {
+	master, slave := openPTY(t)
+	var out bytes.Buffer
+	r := newTerminalReader(slave, &out, func(string) []string { return []string{"Célès", "Céline"} })
+	if r == nil {
+		t.Fatal("expected a line editor for a terminal")
+	}
+
+	// "Cé" completes to the common prefix "Cél", then "ès" and a deletion
+	// of the last character edit it by whole characters
+	if _, err := master.Write([]byte("Cé\tès\x7fs\r")); err != nil {
+		t.Fatal(err)
+	}
+	line, err := r.ReadLine("> ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "Célès" {
+		t.Errorf("ReadLine() = %q, want %q", line, "Célès")
+	}
+}
+
+func TestCommonPrefixUTF8(t *testing.T) {
+	if p := commonPrefix([]string{"Ça", "Ëb"}); p != "" {
+		t.Errorf("commonPrefix() = %q, want an empty prefix", p)
+	}
+	if p := commonPrefix([]string{"Célès", "Céline"}); p != "Cél" {
+		t.Errorf("commonPrefix() = %q, want %q", p, "Cél")
+	}
+}
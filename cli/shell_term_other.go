@@ -0,0 +1,14 @@
+//go:build !linux
+
+package cli
+
+import (
+	"io"
+	"os"
+)
+
+// newTerminalReader has no line editor on this platform; the shell falls back
+// to reading whole lines
+func newTerminalReader(in *os.File, out io.Writer, complete func(string) []string) lineReader {
+	return nil
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+func newTestShell(t *testing.T) (*Shell, *bytes.Buffer, string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y="))
+	if err != nil {
+		t.Fatalf("failed to read save: %v", err)
+	}
+	saveFile := filepath.Join(t.TempDir(), "slot.sav")
+	if err = os.WriteFile(saveFile, data, 0644); err != nil {
+		t.Fatalf("failed to copy save: %v", err)
+	}
+
+	var out bytes.Buffer
+	sh, err := NewShell(saveFile, global.PC, &out)
+	if err != nil {
+		t.Fatalf("NewShell failed: %v", err)
+	}
+	return sh, &out, saveFile
+}
+
+func TestShellEditUndoSave(t *testing.T) {
+	sh, out, saveFile := newTestShell(t)
+
+	script := strings.Join([]string{
+		"set char Terra level 42",
+		"give Megalixir 5",
+		"diff",
+		"undo",
+		"get char Terra level",
+		"redo",
+		"lua 40 + 3",
+		"show char Terra",
+		"save",
+		"quit",
+	}, "\n")
+	if err := sh.Run(strings.NewReader(script)); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	output := out.String()
+	for _, want := range []string{
+		"characters/Terra/level: 6 -> 42",
+		"+ Megalixir Count: 5",
+		"Undid inventory/Megalixir",
+		"> 43\n",
+		"weapon",
+		"Saved to",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+
+	reloaded := pr.New()
+	if err := reloaded.Load(saveFile, global.PC); err != nil {
+		t.Fatalf("failed to reload save: %v", err)
+	}
+	if level, _ := pr.GetPath("characters/Terra/level"); level != 42 {
+		t.Fatalf("Terra level = %v, want 42", level)
+	}
+}
+
+func TestShellUndoesLuaAsOneStep(t *testing.T) {
+	sh, out, _ := newTestShell(t)
+	gil, _ := pr.GetPath("misc/gil")
+	level, _ := pr.GetPath("characters/Terra/level")
+
+	if _, err := sh.Execute("lua save.setGil(777) and save.setCharacterLevel(0, 30)"); err != nil {
+		t.Fatalf("lua failed: %v", err)
+	}
+	if v, _ := pr.GetPath("misc/gil"); v != 777 {
+		t.Fatalf("gil = %v after the script, want 777", v)
+	}
+	if v, _ := pr.GetPath("characters/Terra/level"); v != 30 {
+		t.Fatalf("Terra level = %v after the script, want 30", v)
+	}
+	if _, err := sh.Execute("undo"); err != nil {
+		t.Fatalf("undo failed: %v\n%s", err, out.String())
+	}
+	if v, _ := pr.GetPath("misc/gil"); v != gil {
+		t.Errorf("gil = %v after undo, want %v", v, gil)
+	}
+	if v, _ := pr.GetPath("characters/Terra/level"); v != level {
+		t.Errorf("Terra level = %v after undo, want %v", v, level)
+	}
+}
+
+func TestShellQuitWarnsAboutUnsavedChanges(t *testing.T) {
+	sh, out, _ := newTestShell(t)
+
+	if err := sh.Run(strings.NewReader("set gil 100\nquit\nquit\n")); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "unsaved changes") {
+		t.Fatalf("expected unsaved changes warning:\n%s", out.String())
+	}
+}
+
+func TestShellComplete(t *testing.T) {
+	sh, _, _ := newTestShell(t)
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"sh", "show"},
+		{"set char Ter", "set char Terra"},
+		{"set characters Terra lev", "set characters Terra level"},
+		{"give Fenix D", "give Fenix Down"},
+	}
+	for _, tt := range tests {
+		got := sh.Complete(tt.line)
+		found := false
+		for _, g := range got {
+			found = found || g == tt.want
+		}
+		if !found {
+			t.Errorf("Complete(%q) = %v, want it to include %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestSplitShellArgs(t *testing.T) {
+	args, err := splitShellArgs(`set inventory "Phoenix Down" 5`)
+	if err != nil {
+		t.Fatalf("splitShellArgs failed: %v", err)
+	}
+	if len(args) != 4 || args[2] != "Phoenix Down" {
+		t.Fatalf("unexpected args: %q", args)
+	}
+	if _, err = splitShellArgs(`set "unterminated`); err == nil {
+		t.Fatal("expected error for unterminated quote")
+	}
+}
+
+func TestShellSaveChecksValidation(t *testing.T) {
+	sh, out, saveFile := newTestShell(t)
+	// The shell refuses levels over 99, so break the loaded data directly
+	pri.GetCharacter("Terra").Level = 150
+
+	script := "save\nsave --force\nquit\n"
+	if err := sh.Run(strings.NewReader(script)); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	output := out.String()
+	for _, want := range []string{
+		"[error] characters/Terra/level:",
+		"use --force to save anyway",
+		"Saved to " + saveFile,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Count(output, "Saved to") != 1 {
+		t.Errorf("expected only the forced save to be written:\n%s", output)
+	}
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/source"
+)
+
+// sourceCommand converts between save files and their text source
+func (c *CLI) sourceCommand() error {
+	return c.handleSourceCommand(c.args[1:])
+}
+
+// handleSourceCommand dispatches the source subcommands
+func (c *CLI) handleSourceCommand(args []string) error {
+	if len(args) == 0 {
+		return c.showSourceHelp()
+	}
+
+	switch args[0] {
+	case "export":
+		return c.sourceExport(args[1:])
+	case "build":
+		return c.sourceBuild(args[1:])
+	case "help", "-h", "--help":
+		return c.showSourceHelp()
+	default:
+		return fmt.Errorf("unknown source subcommand: %s", args[0])
+	}
+}
+
+// sourceExport writes the text source of a save file
+func (c *CLI) sourceExport(args []string) error {
+	fs := flag.NewFlagSet("source export", flag.ExitOnError)
+	file := fs.String("file", "", "Save file path (required)")
+	output := fs.String("output", "", "Source file to write (required); its comments are kept")
+	ps := fs.Bool("ps", false, "Save file uses the PlayStation format")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *file == "" || *output == "" {
+		return fmt.Errorf("--file and --output are required")
+	}
+
+	saveType := global.PC
+	if *ps {
+		saveType = global.PS
+	}
+	if err := source.ExportToFile(*file, saveType, *output); err != nil {
+		return err
+	}
+	fmt.Printf("Exported source to: %s\n", *output)
+	return nil
+}
+
+// sourceBuild rebuilds a save file from its text source once it passes the
+// pre-save check
+func (c *CLI) sourceBuild(args []string) error {
+	fs := flag.NewFlagSet("source build", flag.ExitOnError)
+	input := fs.String("input", "", "Source file (required)")
+	output := fs.String("output", "", "Save file to write (required)")
+	slot := fs.Int("slot", -1, "Save slot ID written into the file (defaults to the source's slot)")
+	dryRun := fs.Bool("dry-run", false, "Check the source without writing the save")
+	force := fs.Bool("force", false, "Write the save even if validation finds issues")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *input == "" || (*output == "" && !*dryRun) {
+		return fmt.Errorf("--input and --output are required")
+	}
+
+	built, err := source.BuildFile(*input)
+	var berr *source.BuildError
+	if errors.As(err, &berr) {
+		for _, e := range berr.Errors {
+			fmt.Fprintf(os.Stderr, "! %s %s\n", *input, e)
+		}
+		return fmt.Errorf("%d value(s) in %s could not be applied; save not written", len(berr.Errors), *input)
+	} else if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Println("Dry run: save file not written")
+		return nil
+	}
+	if *slot < 0 {
+		*slot = built.Slot
+	}
+	gate, err := newSaveGate()
+	if err != nil {
+		return err
+	}
+	err = gatedSave(gate, built.Save, *force, os.Stdout, func() error {
+		return built.Save.Save(*slot, *output, built.Type)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Successfully saved to: %s\n", *output)
+	return nil
+}
+
+// showSourceHelp describes the source subcommands
+func (c *CLI) showSourceHelp() error {
+	fmt.Println(`Source commands:
+    source export --file save.sav --output save.ffsrc [--ps]
+    source build  --input save.ffsrc --output save.sav [--slot N] [--dry-run] [--force]
+
+A source file is a text version of a save: one section per character, the
+inventories, learned skills, party, map and counters, with save data the
+editor doesn't model carried in an [opaque] section. Edit it in any text
+editor, keep it under version control and build the save back from it.
+Comments you add survive a later export to the same file.`)
+	return nil
+}
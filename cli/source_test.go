@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+)
+
+func TestSourceExportAndBuild(t *testing.T) {
+	src := filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	tmp := t.TempDir()
+	sourceFile := filepath.Join(tmp, "save.ffsrc")
+	saveFile := filepath.Join(tmp, "slot.sav")
+
+	if _, err := captureOutput(func() error {
+		return NewCLI([]string{"source", "export", "--file", src, "--output", sourceFile}).Run()
+	}); err != nil {
+		t.Fatalf("source export failed: %v", err)
+	}
+	data, err := os.ReadFile(sourceFile)
+	if err != nil {
+		t.Fatalf("no source written: %v", err)
+	}
+	text := strings.Replace(string(data), "[characters/Terra]\nname = \"Terra\"\nenabled = true\nlevel = 6\n",
+		"[characters/Terra]\nname = \"Terra\"\nenabled = true\nlevel = 20\n", 1)
+	if text == string(data) {
+		t.Fatal("Terra's level not found in the source")
+	}
+	if err = os.WriteFile(sourceFile, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = captureOutput(func() error {
+		return NewCLI([]string{"source", "build", "--input", sourceFile, "--output", saveFile}).Run()
+	}); err != nil {
+		t.Fatalf("source build failed: %v", err)
+	}
+	save := pr.New()
+	if err = save.Load(saveFile, global.PC); err != nil {
+		t.Fatalf("failed to load the built save: %v", err)
+	}
+	if terra := pr.FindCharacter("Terra"); terra == nil || terra.Level != 20 {
+		t.Fatalf("Terra not rebuilt at level 20: %+v", terra)
+	}
+
+	// Broken values are listed and nothing is written
+	os.Remove(saveFile)
+	text = strings.Replace(text, "\nlevel = 20\n", "\nlevel = 500\n", 1)
+	if err = os.WriteFile(sourceFile, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = captureOutput(func() error {
+		return NewCLI([]string{"source", "build", "--input", sourceFile, "--output", saveFile}).Run()
+	}); err == nil {
+		t.Fatal("expected the build to fail")
+	}
+	if _, err = os.Stat(saveFile); !os.IsNotExist(err) {
+		t.Error("a failed build wrote the save")
+	}
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"ffvi_editor/io/config"
+	"ffvi_editor/io/pr"
+	"ffvi_editor/io/validation"
+	"ffvi_editor/models"
+	"ffvi_editor/settings"
+)
+
+// maxListedIssues is how many issues a blocked save prints
+const maxListedIssues = 20
+
+// handleValidateCommand validates a save file and prints every issue. With
+// fix, fixable issues are repaired and the save is written back. It returns an
+// ExitError with code 1 when the save is still invalid.
+func (c *CLI) handleValidateCommand(file string, fix, force bool, mode, rulesDir string) error {
+	validator, err := newValidator(mode, rulesDir)
+	if err != nil {
+		return err
+	}
+
+	save, err := c.LoadSaveFile(file)
+	if err != nil {
+		return err
+	}
+
+	result := validator.Validate(save)
+	printValidationIssues(result)
+
+	if fix && len(result.FixableIssues()) > 0 {
+		fixed, err := validator.AutoFixIssues(save)
+		if err != nil {
+			fmt.Printf("Some fixes failed: %v\n", err)
+		}
+		fmt.Printf("Fixed %d issue(s)\n", fixed)
+		if fixed > 0 {
+			if err = c.SaveSaveFile(save, file, newSaveGateFor(validator), force); err != nil {
+				return err
+			}
+		}
+		result = validator.Validate(save)
+	}
+
+	fmt.Printf("%d error(s), %d warning(s)\n", len(result.Errors), len(result.Warnings))
+	if !result.Valid {
+		return &ExitError{Code: 1}
+	}
+	return nil
+}
+
+// newValidator creates a validator for a validation level with the rule
+// files of a directory. A custom profile must name a loaded rule set.
+func newValidator(mode, rulesDir string) (*validation.Validator, error) {
+	if rulesDir == "" {
+		rulesDir = filepath.Join(config.SaveDir(), "rules")
+	}
+	v := validation.NewValidator()
+	if err := v.LoadRules(rulesDir); err != nil {
+		return nil, fmt.Errorf("failed to load validation rules: %w", err)
+	}
+
+	cfg := v.GetConfig()
+	cfg.Mode = models.ValidationModeForLevel(mode)
+	if cfg.Mode.IsCustom() {
+		found := false
+		for _, p := range v.Profiles() {
+			if models.ValidationModeForLevel(p) == cfg.Mode {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown validation profile %q (have %v)", mode, v.Profiles())
+		}
+	}
+	v.SetConfig(cfg)
+	return v, nil
+}
+
+// loadSettings reads the editor's settings file, falling back to the
+// defaults when it is missing or broken
+func loadSettings() *settings.Settings {
+	m := settings.NewManager(filepath.Join(config.SaveDir(), "settings.json"))
+	if err := m.Load(); err != nil {
+		return settings.DefaultSettings()
+	}
+	return m.Get()
+}
+
+// newSaveGate creates the pre-save check from the editor's settings, so the
+// CLI refuses the same saves the editor would
+func newSaveGate() (*validation.SaveGate, error) {
+	s := loadSettings()
+	v, err := newValidator(s.ValidationLevel, "")
+	if err != nil {
+		return nil, err
+	}
+	return validation.NewSaveGate(v, s.AutoFix, s.WarnOnSave), nil
+}
+
+// newSaveGateFor creates the pre-save check for a validator chosen on the
+// command line, with the auto-fix and warning settings of the editor
+func newSaveGateFor(v *validation.Validator) *validation.SaveGate {
+	s := loadSettings()
+	return validation.NewSaveGate(v, s.AutoFix, s.WarnOnSave)
+}
+
+// gatedSave calls write when the pre-save check allows it. There is no one
+// to ask, so issues the editor would ask about need force; blocked saves
+// print their issues to out.
+func gatedSave(gate *validation.SaveGate, save *pr.PR, force bool, out io.Writer, write func() error) error {
+	check, err := gate.Save(save, func(validation.SaveCheck) bool { return force }, write)
+	if check.Fixed > 0 {
+		fmt.Fprintf(out, "Auto-fixed %d issue(s) before saving\n", check.Fixed)
+	}
+	if check.FixErr != nil {
+		fmt.Fprintf(out, "Some fixes failed: %v\n", check.FixErr)
+	}
+	switch {
+	case err == nil:
+		return nil
+	case !errors.Is(err, validation.ErrSaveBlocked):
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+	fmt.Fprintln(out, check.Summary(maxListedIssues))
+	if check.Decision == validation.SaveNeedsConfirm {
+		return fmt.Errorf("%w; use --force to save anyway", err)
+	}
+	return err
+}
+
+// printValidationIssues prints one line per issue
+func printValidationIssues(result models.ValidationResult) {
+	for _, issue := range result.AllIssues() {
+		line := fmt.Sprintf("[%s] %s: %s", issue.Severity, issue.Target, issue.Message)
+		if issue.Fixable {
+			line += " (fix: " + issue.FixAction + ")"
+		}
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+func TestValidateCommand(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y="))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "save")
+	if err = os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := captureOutput(func() error {
+		return NewCLI([]string{"validate", "--file", file}).Run()
+	})
+	if err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	if !strings.Contains(out, "[warning] characters/Terra/hp:") || !strings.Contains(out, "0 error(s)") {
+		t.Errorf("unexpected validate output:\n%s", out)
+	}
+
+	out, err = captureOutput(func() error {
+		return NewCLI([]string{"validate", "--file", file, "--fix"}).Run()
+	})
+	if err != nil {
+		t.Fatalf("validate --fix failed: %v", err)
+	}
+	if !strings.Contains(out, "0 error(s), 0 warning(s)") {
+		t.Errorf("expected no issues after fixing:\n%s", out)
+	}
+
+	if err = pr.New().Load(file, global.PC); err != nil {
+		t.Fatalf("failed to load fixed save: %v", err)
+	}
+	if terra := pri.GetCharacter("Terra"); terra.HP.Current != terra.HP.Max {
+		t.Errorf("Terra HP = %d/%d, want current capped at max", terra.HP.Current, terra.HP.Max)
+	}
+}
+
+func TestValidateCommandRules(t *testing.T) {
+	file := filepath.Join(testSaveDir, "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	rules := t.TempDir()
+	rule := "name: low-level\nmodes: [low-level]\nrules:\n  - name: level_cap\n    path: characters/*/level\n    condition: {max: 5}\n"
+	if err := os.WriteFile(filepath.Join(rules, "low-level.yaml"), []byte(rule), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := captureOutput(func() error {
+		return NewCLI([]string{"validate", "--file", file, "--rules", rules, "--mode", "low-level"}).Run()
+	})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("expected exit code 1, got %v", err)
+	}
+	if !strings.Contains(out, "[error] characters/Edgar/level: characters/Edgar/level is 9") {
+		t.Errorf("unexpected validate output:\n%s", out)
+	}
+
+	if _, err = captureOutput(func() error {
+		return NewCLI([]string{"validate", "--file", file, "--rules", rules, "--mode", "speedrun"}).Run()
+	}); err == nil || errors.As(err, &exitErr) {
+		t.Errorf("expected an unknown profile error, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/backup"
+	"ffvi_editor/io/config"
+	"ffvi_editor/io/history"
+	"ffvi_editor/io/validation"
+	"ffvi_editor/io/watch"
+)
+
+// stringList is a flag that may be given more than once
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// watchCommand runs a pipeline every time the game writes a save slot
+func (c *CLI) watchCommand() error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	dir := fs.String("dir", config.SaveDir(), "Save directory to watch")
+	doBackup := fs.Bool("backup", true, "Back up every write")
+	backupDir := fs.String("backup-dir", "", "Backup directory (default: <dir>/backups)")
+	keep := fs.Int("keep", defaultBackupsToKeep, "Number of backups to keep")
+	doValidate := fs.Bool("validate", true, "Validate every write")
+	doDiff := fs.Bool("diff", true, "Show what changed since the previous write")
+	doHistory := fs.Bool("history", false, "Add every write to the progression timeline")
+	historyDir := fs.String("history-dir", "", "History directory (default: <dir>/history)")
+	debounce := fs.Duration("debounce", watch.DefaultDebounce, "Quiet period before a write is processed")
+	ps := fs.Bool("ps", false, "Save files use the PlayStation format")
+	var rules stringList
+	fs.Var(&rules, "lua", "Lua rule script to run on every write (repeatable)")
+
+	if err := fs.Parse(c.args[1:]); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return fmt.Errorf("--dir is required")
+	}
+
+	saveType := global.PC
+	if *ps {
+		saveType = global.PS
+	}
+
+	var steps []watch.Step
+	if *doBackup {
+		if *backupDir == "" {
+			*backupDir = filepath.Join(*dir, "backups")
+		}
+		m, err := backup.NewManager(*backupDir, *keep)
+		if err != nil {
+			return err
+		}
+		steps = append(steps, &watch.BackupStep{Manager: m})
+	}
+	if *doHistory {
+		if *historyDir == "" {
+			*historyDir = filepath.Join(*dir, "history")
+		}
+		store, err := history.NewStore(*historyDir, history.DefaultMaxPoints)
+		if err != nil {
+			return err
+		}
+		steps = append(steps, &watch.HistoryStep{Store: store})
+	}
+	if *doValidate {
+		steps = append(steps, &watch.ValidateStep{Validator: validation.NewValidator()})
+	}
+	if *doDiff {
+		steps = append(steps, &watch.DiffStep{})
+	}
+	for _, script := range rules {
+		step, err := watch.NewLuaRuleStep(script)
+		if err != nil {
+			return err
+		}
+		steps = append(steps, step)
+	}
+
+	w, err := watch.NewWatcher(*dir, saveType, steps, printWatchResult)
+	if err != nil {
+		return err
+	}
+	w.SetDebounce(*debounce)
+	if err = w.Start(); err != nil {
+		return err
+	}
+
+	names := make([]string, len(steps))
+	for i, s := range steps {
+		names[i] = s.Name()
+	}
+	fmt.Printf("Watching %s (pipeline: %s). Press Ctrl+C to stop.\n", *dir, strings.Join(names, ", "))
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	<-stop
+	signal.Stop(stop)
+
+	fmt.Println("Stopping watch")
+	return w.Stop()
+}
+
+// printWatchResult writes one line per pipeline step
+func printWatchResult(r watch.Result) {
+	stamp := time.Now().Format("15:04:05")
+	slot := "-"
+	if r.Event != nil {
+		slot = r.Event.Name()
+	}
+	if r.Err != nil {
+		fmt.Printf("[%s] %s %s: FAILED: %v\n", stamp, slot, r.Step, r.Err)
+		return
+	}
+	fmt.Printf("[%s] %s %s: %s\n", stamp, slot, r.Step, r.Message)
+}
@@ -1,12 +1,21 @@
 package cloud
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+	"ffvi_editor/io/validation"
 )
 
 // TestGoogleDriveProvider tests the Google Drive provider implementation
@@ -203,6 +212,154 @@ func TestSyncConflictResolution(t *testing.T) {
 	}
 }
 
+// TestMergeConflictRequiresBase tests that a merge needs the last synced version
+func TestMergeConflictRequiresBase(t *testing.T) {
+	manager := New()
+	gdrive := NewGoogleDriveProvider("test-id", "test-secret")
+	if err := gdrive.Authenticate(context.Background()); err != nil {
+		t.Fatalf("authentication failed: %v", err)
+	}
+	if err := manager.RegisterProvider(gdrive); err != nil {
+		t.Fatalf("failed to register provider: %v", err)
+	}
+
+	conflict := &Conflict{FileName: "test.sav", LocalID: "test.sav", RemoteID: "remote-id"}
+	err := manager.ResolveConflict(context.Background(), "Google Drive", conflict, ConflictMerge)
+	if err == nil || !strings.Contains(err.Error(), "last synced version") {
+		t.Errorf("expected missing base error, got %v", err)
+	}
+	if conflict.Merge != nil {
+		t.Error("merge result should not be set on failure")
+	}
+}
+
+// memoryProvider keeps uploaded files in memory, by name
+type memoryProvider struct {
+	*GoogleDriveProvider
+	files map[string][]byte
+}
+
+func (p *memoryProvider) Upload(ctx context.Context, filename string, reader io.Reader) (*FileMetadata, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	p.files[filename] = data
+	return &FileMetadata{ID: filename, Name: filename, Size: int64(len(data))}, nil
+}
+
+func (p *memoryProvider) Download(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	data, ok := p.files[fileID]
+	if !ok {
+		return nil, fmt.Errorf("no file %s", fileID)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// newMergeConflict syncs a slot, then changes it on both sides. It returns
+// the manager, the provider holding the remote side and the conflict.
+func newMergeConflict(t *testing.T) (*Manager, *memoryProvider, *Conflict) {
+	t.Helper()
+	const saveDir = "../save_data/76561198072182150"
+	read := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join(saveDir, name))
+		if err != nil {
+			t.Skipf("test save not available: %v", err)
+		}
+		return data
+	}
+	base := read("7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	ours := read("vgU2wnuaPje2Or53Iqs8Mp=Al6sdM+GM04Iymv229Ow=")
+	theirs := read("ookrbATYovG3tEOXIH4HqWnsv8TrUlRWzM8AlCmW2mk=")
+
+	dir := t.TempDir()
+	manager := NewManager(&SyncConfig{BaseFolder: filepath.Join(dir, "synced")})
+	provider := &memoryProvider{GoogleDriveProvider: NewGoogleDriveProvider("test-id", "test-secret"), files: map[string][]byte{}}
+	if err := provider.Authenticate(context.Background()); err != nil {
+		t.Fatalf("authentication failed: %v", err)
+	}
+	if err := manager.RegisterProvider(provider); err != nil {
+		t.Fatalf("failed to register provider: %v", err)
+	}
+
+	local := filepath.Join(dir, "slot.sav")
+	if err := os.WriteFile(local, base, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UploadFile(context.Background(), "Google Drive", local, "slot.sav"); err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	if err := os.WriteFile(local, ours, 0644); err != nil {
+		t.Fatal(err)
+	}
+	provider.files["remote-id"] = theirs
+	return manager, provider, &Conflict{FileName: "slot.sav", LocalID: local, RemoteID: "remote-id", SaveType: global.PC}
+}
+
+// TestMergeConflictUsesSyncedCopy tests that a merge finds the copy kept by
+// the last sync and merges both sides against it, leaving the loaded save
+// alone
+func TestMergeConflictUsesSyncedCopy(t *testing.T) {
+	manager, provider, conflict := newMergeConflict(t)
+	local := conflict.LocalID
+
+	// The save open in the editor
+	if err := pr.New().Load("../save_data/76561198072182150/uhHNR4g5QL5twqCc+IhexaltjtBjJnzzcxh5RBSy4G4=", global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	open := pr.TakeSnapshot()
+
+	if err := manager.ResolveConflict(context.Background(), "Google Drive", conflict, ConflictMerge); err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	if conflict.BasePath == "" || conflict.Merge == nil {
+		t.Fatalf("expected a merge against the synced copy, got base %q", conflict.BasePath)
+	}
+	if report := pr.NewComparator(open, pr.TakeSnapshot()).Compare(); report.HasDifferences() {
+		t.Error("the merge replaced the loaded save")
+	}
+	if err := pr.New().Load(local, global.PC); err != nil {
+		t.Fatalf("failed to load merged save: %v", err)
+	}
+	if v, _ := pr.GetPath("misc/saveCount"); v != 19 {
+		t.Errorf("merged save count = %v, want 19", v)
+	}
+	if merged, err := os.ReadFile(local); err != nil || !bytes.Equal(provider.files["slot.sav"], merged) {
+		t.Errorf("the merged save wasn't uploaded: %v", err)
+	}
+}
+
+// TestMergeConflictChecksSave tests that a merged save with issues is only
+// written when the user accepts them
+func TestMergeConflictChecksSave(t *testing.T) {
+	manager, provider, conflict := newMergeConflict(t)
+	ours, _ := os.ReadFile(conflict.LocalID)
+	synced := provider.files["slot.sav"]
+
+	v := validation.NewValidator()
+	config := v.GetConfig()
+	config.MaxCharacterLevel = 1
+	v.SetConfig(config)
+	var asked int
+	manager.SetSaveGate(func() *validation.SaveGate {
+		return validation.NewSaveGate(v, false, true)
+	}, func(validation.SaveCheck) bool {
+		asked++
+		return false
+	})
+
+	err := manager.ResolveConflict(context.Background(), "Google Drive", conflict, ConflictMerge)
+	if !errors.Is(err, validation.ErrSaveBlocked) || asked != 1 {
+		t.Fatalf("expected the user to refuse the merged save, got %v after %d question(s)", err, asked)
+	}
+	if local, _ := os.ReadFile(conflict.LocalID); !bytes.Equal(local, ours) {
+		t.Error("a refused merge overwrote the local save")
+	}
+	if conflict.Merge != nil || !bytes.Equal(provider.files["slot.sav"], synced) {
+		t.Error("a refused merge was uploaded")
+	}
+}
+
 // TestFileMetadata tests file metadata structures
 func TestFileMetadata(t *testing.T) {
 	now := time.Now()
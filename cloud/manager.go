@@ -13,6 +13,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"ffvi_editor/io/validation"
 )
 
 // Manager handles cloud sync operations
@@ -23,6 +25,9 @@ type Manager struct {
 	mu         sync.RWMutex
 	stopCh     chan struct{}
 	syncTicker *time.Ticker
+
+	newSaveGate func() *validation.SaveGate
+	confirmSave func(validation.SaveCheck) bool
 }
 
 // New creates a new cloud sync manager with default config
@@ -53,6 +58,36 @@ func NewManager(config *SyncConfig) *Manager {
 	}
 }
 
+// SetBaseFolder sets the local folder that keeps the last synced copy of each
+// file, which ConflictMerge needs
+func (m *Manager) SetBaseFolder(dir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config.BaseFolder = dir
+}
+
+// SetSaveGate sets the pre-save check that a merged save must pass before it
+// overwrites the local file. confirm answers the issues that need the user's
+// approval; nil refuses them.
+func (m *Manager) SetSaveGate(newGate func() *validation.SaveGate, confirm func(validation.SaveCheck) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.newSaveGate, m.confirmSave = newGate, confirm
+}
+
+// saveGate returns the pre-save check of merged saves; without one set, saves
+// with issues are refused
+func (m *Manager) saveGate() (func() *validation.SaveGate, func(validation.SaveCheck) bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.newSaveGate == nil {
+		return func() *validation.SaveGate {
+			return validation.NewSaveGate(validation.NewValidator(), false, true)
+		}, nil
+	}
+	return m.newSaveGate, m.confirmSave
+}
+
 // RegisterProvider registers a cloud storage provider
 func (m *Manager) RegisterProvider(provider Provider) error {
 	m.mu.Lock()
@@ -254,11 +289,19 @@ func (m *Manager) UploadFile(ctx context.Context, providerName, localPath, remot
 		return fmt.Errorf("upload failed: %w", err)
 	}
 
-	return nil
+	return m.recordSynced(filepath.Base(remotePath), localPath)
 }
 
 // DownloadFile downloads a single file from cloud storage via specified provider
 func (m *Manager) DownloadFile(ctx context.Context, providerName, fileID, localPath string) error {
+	if err := m.download(ctx, providerName, fileID, localPath); err != nil {
+		return err
+	}
+	return m.recordSynced(filepath.Base(localPath), localPath)
+}
+
+// download writes a cloud file to a local path without recording it as synced
+func (m *Manager) download(ctx context.Context, providerName, fileID, localPath string) error {
 	provider, err := m.GetProvider(providerName)
 	if err != nil {
 		return err
@@ -299,6 +342,37 @@ func (m *Manager) DownloadFile(ctx context.Context, providerName, fileID, localP
 	return nil
 }
 
+// recordSynced keeps a copy of a file as it was last uploaded or downloaded,
+// the common ancestor ConflictMerge merges both sides against
+func (m *Manager) recordSynced(name, localPath string) error {
+	if m.config.BaseFolder == "" {
+		return nil
+	}
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to read synced file: %w", err)
+	}
+	if err = os.MkdirAll(m.config.BaseFolder, 0755); err != nil {
+		return fmt.Errorf("failed to create base folder: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(m.config.BaseFolder, name), data, 0644); err != nil {
+		return fmt.Errorf("failed to keep synced copy: %w", err)
+	}
+	return nil
+}
+
+// syncedCopy returns the last synced copy of a file, or "" when none is kept
+func (m *Manager) syncedCopy(name string) string {
+	if m.config.BaseFolder == "" || name == "" {
+		return ""
+	}
+	path := filepath.Join(m.config.BaseFolder, filepath.Base(name))
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
 // GetStatus returns the sync status for a specific provider
 func (m *Manager) GetStatus(providerName string) (*SyncStatus, error) {
 	m.mu.RLock()
@@ -368,6 +442,9 @@ func (m *Manager) ResolveConflict(ctx context.Context, providerName string, conf
 		}
 		return os.Remove(conflict.LocalID)
 
+	case ConflictMerge:
+		return m.mergeConflict(ctx, providerName, conflict)
+
 	case ConflictCreateCopy:
 		// Keep both, rename remote
 		timestamp := time.Now().Format("2006-01-02_15-04-05")
@@ -0,0 +1,55 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"ffvi_editor/io/pr"
+)
+
+// mergeConflict resolves a conflict by downloading the remote save, merging it
+// with the local save against the last synced copy, writing the result over
+// the local file once it passes the pre-save check and uploading it. The save
+// loaded before, such as the one open in the editor, is put back afterwards.
+func (m *Manager) mergeConflict(ctx context.Context, providerName string, conflict *Conflict) error {
+	remoteName := conflict.FileName
+	if remoteName == "" {
+		remoteName = conflict.LocalID
+	}
+	if conflict.BasePath == "" {
+		conflict.BasePath = m.syncedCopy(remoteName)
+	}
+	if conflict.BasePath == "" {
+		return fmt.Errorf("merge requires the last synced version of %s", conflict.FileName)
+	}
+
+	remote, err := os.CreateTemp("", "ffvi_remote_*.sav")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	remotePath := remote.Name()
+	remote.Close()
+	defer os.Remove(remotePath)
+
+	if err = m.download(ctx, providerName, conflict.RemoteID, remotePath); err != nil {
+		return err
+	}
+
+	state := pr.SaveModelState()
+	defer state.Restore()
+	save, result, err := pr.MergeSaves(conflict.BasePath, conflict.LocalID, remotePath, conflict.SaveType, pr.DefaultMergeOptions())
+	if err != nil {
+		return fmt.Errorf("merge failed: %w", err)
+	}
+	newGate, confirm := m.saveGate()
+	_, err = newGate().Save(save, confirm, func() error {
+		return save.Save(save.SlotID(), conflict.LocalID, conflict.SaveType)
+	})
+	if err != nil {
+		return fmt.Errorf("merged save not written: %w", err)
+	}
+	conflict.Merge = result
+	conflict.Resolution = ConflictMerge
+	return m.UploadFile(ctx, providerName, conflict.LocalID, remoteName)
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"io"
 	"time"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
 )
 
 // Provider defines the interface for cloud storage providers
@@ -106,6 +109,8 @@ const (
 	ConflictPromptUser
 	// ConflictNewest keeps the newest version by timestamp
 	ConflictNewest
+	// ConflictMerge three-way merges the save data of both versions
+	ConflictMerge
 )
 
 // SyncConfig contains configuration for cloud sync
@@ -122,6 +127,7 @@ type SyncConfig struct {
 	RetryDelay         time.Duration       // Delay between retries
 	VerifyHashes       bool                // Verify file integrity via hashing
 	CompressFiles      bool                // Compress files before upload
+	BaseFolder         string              // Local folder keeping the last synced copy of each file, used by ConflictMerge
 }
 
 // SyncStatus represents the status of a sync operation
@@ -152,5 +158,8 @@ type Conflict struct {
 	RemoteSize   int64
 	LocalID      string  // Local file ID/path
 	RemoteID     string  // Remote file ID
+	BasePath     string  // Last synced version, used by ConflictMerge
+	SaveType     global.SaveFileType // Save format, used by ConflictMerge
 	Resolution   ConflictResolution
+	Merge        *pr.MergeResult // Outcome of ConflictMerge
 }
@@ -93,11 +93,13 @@ func (m *Manager) ListBackups() []models.BackupListEntry {
 	entries := make([]models.BackupListEntry, 0, len(m.backups))
 	for _, meta := range m.backups {
 		entries = append(entries, models.BackupListEntry{
-			ID:          meta.ID,
-			Timestamp:   meta.Timestamp,
-			Description: meta.Description,
-			FileSize:    meta.FileSize,
-			TimeSince:   formatTimeSince(time.Since(meta.Timestamp)),
+			ID:           meta.ID,
+			Timestamp:    meta.Timestamp,
+			Description:  meta.Description,
+			FileSize:     meta.FileSize,
+			Hash:         meta.Hash,
+			OriginalPath: meta.OriginalPath,
+			TimeSince:    formatTimeSince(time.Since(meta.Timestamp)),
 		})
 	}
 
@@ -194,11 +196,42 @@ func (m *Manager) BackupCount() int {
 	return len(m.backups)
 }
 
+// PruneBackups removes the oldest backups so that at most keep remain and
+// returns the IDs of the removed backups
+func (m *Manager) PruneBackups(keep int) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if keep < 0 {
+		return nil, fmt.Errorf("invalid number of backups to keep: %d", keep)
+	}
+
+	removed, err := m.removeOldest(keep)
+	if err != nil {
+		return removed, err
+	}
+	if len(removed) == 0 {
+		return removed, nil
+	}
+
+	return removed, m.saveMetadata()
+}
+
 // cleanupOldBackups removes oldest backups if count exceeds maximum
 func (m *Manager) cleanupOldBackups() error {
-	if m.maxBackups <= 0 || len(m.backups) <= m.maxBackups {
+	if m.maxBackups <= 0 {
 		return nil
 	}
+	_, err := m.removeOldest(m.maxBackups)
+	return err
+}
+
+// removeOldest deletes the oldest backups until at most keep remain.
+// The caller must hold the write lock.
+func (m *Manager) removeOldest(keep int) ([]string, error) {
+	if len(m.backups) <= keep {
+		return nil, nil
+	}
 
 	// Sort by timestamp to find oldest
 	backups := make([]*models.BackupMetadata, 0, len(m.backups))
@@ -211,16 +244,23 @@ func (m *Manager) cleanupOldBackups() error {
 	})
 
 	// Delete oldest backups
-	toDelete := len(backups) - m.maxBackups
+	toDelete := len(backups) - keep
+	removed := make([]string, 0, toDelete)
 	for i := 0; i < toDelete; i++ {
 		backupPath := filepath.Join(m.backupDir, backups[i].ID+".bak")
 		if err := os.Remove(backupPath); err != nil && !os.IsNotExist(err) {
-			return err
+			return removed, err
 		}
 		delete(m.backups, backups[i].ID)
+		removed = append(removed, backups[i].ID)
 	}
 
-	return nil
+	return removed, nil
+}
+
+// BackupDir returns the directory backups are stored in
+func (m *Manager) BackupDir() string {
+	return m.backupDir
 }
 
 // loadMetadata loads backup metadata from file
@@ -0,0 +1,152 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	ipr "ffvi_editor/io/pr"
+	"ffvi_editor/models/consts"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+// Exporter writes save data as sheets
+type Exporter struct {
+	prData *ipr.PR
+}
+
+// NewExporter creates a new exporter for the given save data
+func NewExporter(pr *ipr.PR) *Exporter {
+	return &Exporter{
+		prData: pr,
+	}
+}
+
+// Rows returns a sheet as rows of cells, header first
+func (e *Exporter) Rows(sheet Sheet) ([][]string, error) {
+	if e.prData == nil {
+		return nil, fmt.Errorf("no save data loaded")
+	}
+
+	switch sheet {
+	case SheetInventory:
+		return inventoryRows(pri.GetInventory(), pr.ItemsByID), nil
+	case SheetImportantItems:
+		return inventoryRows(pri.GetImportantInventory(), pr.ImportantItemsByID), nil
+	case SheetCharacters:
+		return e.characterRows(), nil
+	case SheetSpells:
+		return e.spellRows(), nil
+	case SheetSkills:
+		return skillRows(), nil
+	}
+	return nil, fmt.Errorf("unknown sheet: %s", sheet)
+}
+
+// Write writes a sheet with the given field separator
+func (e *Exporter) Write(w io.Writer, sheet Sheet, comma rune) error {
+	rows, err := e.Rows(sheet)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err = cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write %s sheet: %w", sheet, err)
+	}
+	return nil
+}
+
+// ExportToFile writes a sheet to a file, tab separated for .tsv files
+func (e *Exporter) ExportToFile(sheet Sheet, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create sheet file: %w", err)
+	}
+	if err = e.Write(f, sheet, CommaFor(filePath)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// inventoryRows lists the owned rows in inventory order
+func inventoryRows(inv *pri.Inventory, byID map[int]string) [][]string {
+	rows := [][]string{{colID, colName, colCount}}
+	for _, r := range inv.GetRows() {
+		if r == nil || r.ItemID == 0 || r.Count <= 0 {
+			continue
+		}
+		name := strconv.Itoa(r.ItemID)
+		if n, ok := byID[r.ItemID]; ok {
+			name = strings.TrimSpace(n)
+		}
+		rows = append(rows, []string{strconv.Itoa(r.ItemID), name, strconv.Itoa(r.Count)})
+	}
+	return rows
+}
+
+// characterRows has a row of the character fields per loaded character
+func (e *Exporter) characterRows() [][]string {
+	rows := [][]string{append([]string{colRootName, colID}, characterColumns...)}
+	for _, c := range e.prData.LoadedCharacters() {
+		row := []string{c.RootName, strconv.Itoa(c.ID)}
+		for _, col := range characterColumns {
+			v, err := ipr.GetPath(ipr.JoinPath(ipr.PathCharacters, c.RootName, col))
+			if err != nil {
+				v = ""
+			}
+			row = append(row, fmt.Sprint(v))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// spellRows has a row per spell with the learn percentage of each loaded
+// character
+func (e *Exporter) spellRows() [][]string {
+	characters := e.prData.LoadedCharacters()
+	header := []string{colID, colSpell}
+	for _, c := range characters {
+		header = append(header, c.RootName)
+	}
+	rows := [][]string{header}
+	for _, s := range pr.Spells {
+		row := []string{strconv.Itoa(s.Value), s.Name}
+		for _, c := range characters {
+			learned := 0
+			if spell, ok := c.SpellsByID[s.Value]; ok {
+				learned = spell.Value
+			}
+			row = append(row, strconv.Itoa(learned))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// skillRows lists every entry of the learned/owned lists
+func skillRows() [][]string {
+	rows := [][]string{{colCategory, colID, colName, colLearned}}
+	for _, l := range []struct {
+		category string
+		list     []*consts.NameValueChecked
+	}{
+		{ipr.PathEspers, pr.Espers},
+		{ipr.PathRages, pr.Rages},
+		{ipr.PathLores, pr.Lores},
+		{ipr.PathDances, pr.Dances},
+		{ipr.PathBlitzes, pr.Blitzes},
+		{ipr.PathBushido, pr.Bushidos},
+	} {
+		for _, v := range l.list {
+			rows = append(rows, []string{l.category, strconv.Itoa(v.Value), v.Name, strconv.FormatBool(v.Checked)})
+		}
+	}
+	return rows
+}
@@ -0,0 +1,72 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"testing"
+
+	"ffvi_editor/global"
+	ipr "ffvi_editor/io/pr"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+const testSave = "../../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y="
+
+func loadSave(t *testing.T) *ipr.PR {
+	t.Helper()
+	p := ipr.New()
+	if err := p.Load(testSave, global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	return p
+}
+
+func TestExportSheets(t *testing.T) {
+	p := loadSave(t)
+	e := NewExporter(p)
+
+	rows, err := e.Rows(SheetInventory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := pri.GetInventory().GetRowsForPrSave()[0]
+	if len(rows) < 2 || rows[1][0] != strconv.Itoa(first.ItemID) || rows[1][2] != strconv.Itoa(first.Count) {
+		t.Errorf("inventory sheet starts %v, want item %d x%d", rows[:2], first.ItemID, first.Count)
+	}
+
+	terra := pri.GetCharacter("Terra")
+	rows, _ = e.Rows(SheetCharacters)
+	if rows[0][0] != colRootName || len(rows) != len(p.LoadedCharacters())+1 {
+		t.Fatalf("unexpected character sheet %v", rows[0])
+	}
+	for _, row := range rows {
+		if row[0] == "Terra" && row[4] != strconv.Itoa(terra.Level) {
+			t.Errorf("Terra's level exported as %s, want %d", row[4], terra.Level)
+		}
+	}
+
+	terra.SpellsByIndex[0].Value = 40
+	rows, _ = e.Rows(SheetSpells)
+	if len(rows) != len(pr.Spells)+1 || rows[0][2] != p.LoadedCharacters()[0].RootName {
+		t.Fatalf("unexpected spell matrix header %v", rows[0])
+	}
+
+	rows, _ = e.Rows(SheetSkills)
+	if want := 1 + len(pr.Espers) + len(pr.Rages) + len(pr.Lores) + len(pr.Dances) + len(pr.Blitzes) + len(pr.Bushidos); len(rows) != want {
+		t.Errorf("skills sheet has %d rows, want %d", len(rows), want)
+	}
+
+	// TSV output is tab separated
+	var buf bytes.Buffer
+	if err = e.Write(&buf, SheetSpells, '\t'); err != nil {
+		t.Fatal(err)
+	}
+	r := csv.NewReader(&buf)
+	r.Comma = '\t'
+	tsv, err := r.ReadAll()
+	if err != nil || len(tsv) != len(pr.Spells)+1 {
+		t.Fatalf("TSV didn't read back: %v", err)
+	}
+}
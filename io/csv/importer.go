@@ -0,0 +1,356 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	ipr "ffvi_editor/io/pr"
+	"ffvi_editor/models"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+// ImportError is a cell or row that couldn't be imported
+type ImportError struct {
+	Line    int    // Line of the file; the header is line 1
+	Column  string // Header of the cell, "" for the whole row
+	Message string
+}
+
+func (e ImportError) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("line %d, %s: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// ImportOptions controls how a sheet is read and applied
+type ImportOptions struct {
+	Comma rune // Field separator, ',' when zero
+	// DryRun reports the changes without keeping them
+	DryRun bool
+}
+
+// Importer applies sheets to save data
+type Importer struct {
+	prData *ipr.PR
+	errors []ImportError
+}
+
+// NewImporter creates a new importer for the given save data
+func NewImporter(pr *ipr.PR) *Importer {
+	return &Importer{
+		prData: pr,
+		errors: make([]ImportError, 0),
+	}
+}
+
+// ImportFromFile imports a sheet file, tab separated for .tsv files
+func (i *Importer) ImportFromFile(sheet Sheet, filePath string, dryRun bool) (*ipr.DiffReport, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sheet file: %w", err)
+	}
+	defer f.Close()
+	return i.Import(f, sheet, ImportOptions{Comma: CommaFor(filePath), DryRun: dryRun})
+}
+
+// Import reads a sheet exported by Exporter, or edited from one, and applies
+// it to the save. Columns are found by their header, so they may be
+// reordered or left out, and empty cells are left alone. Item and spell
+// cells take an ID or a name. A cell that can't be applied is recorded as an
+// ImportError and skipped; only a sheet that can't be read fails the import.
+func (i *Importer) Import(r io.Reader, sheet Sheet, opts ImportOptions) (*ipr.DiffReport, error) {
+	if i.prData == nil {
+		return nil, fmt.Errorf("no save data loaded")
+	}
+	if sheet == SheetSkills {
+		return nil, fmt.Errorf("the %s sheet can't be imported", sheet)
+	}
+
+	t, err := readTable(r, opts.Comma)
+	if err != nil {
+		return nil, err
+	}
+
+	i.errors = make([]ImportError, 0)
+	var state *ipr.ModelState
+	if opts.DryRun {
+		state = ipr.SaveModelState()
+	}
+	before := ipr.TakeSnapshot()
+
+	switch sheet {
+	case SheetInventory:
+		i.importInventory(t, ipr.PathInventory, pr.ItemsByName, pr.ItemsByID)
+	case SheetImportantItems:
+		i.importInventory(t, ipr.PathImportantItems, pr.ImportantItemsByName, pr.ImportantItemsByID)
+	case SheetCharacters:
+		i.importCharacters(t)
+	case SheetSpells:
+		i.importSpells(t)
+	default:
+		return nil, fmt.Errorf("unknown sheet: %s", sheet)
+	}
+
+	report := ipr.NewComparator(before, ipr.TakeSnapshot()).Compare()
+	if state != nil {
+		state.Restore()
+	}
+	return &report, nil
+}
+
+// GetErrors returns any errors that occurred during import
+func (i *Importer) GetErrors() []ImportError {
+	return i.errors
+}
+
+// importInventory sets the count of every listed item
+func (i *Importer) importInventory(t *table, category string, byName map[string]int, byID map[int]string) {
+	if t.column(colCount) < 0 || (t.column(colID) < 0 && t.column(colName) < 0) {
+		i.addError(1, "", "an inventory sheet needs a count column and an id or name column")
+		return
+	}
+	for _, row := range t.rows {
+		item := row.cell(colID)
+		if item == "" {
+			item = row.cell(colName)
+		}
+		count := row.cell(colCount)
+		if item == "" && count == "" {
+			continue
+		}
+		id, err := ipr.ResolveItemID(item, byName, byID)
+		if err != nil {
+			i.addError(row.line, "", err.Error())
+			continue
+		}
+		if count == "" {
+			i.addError(row.line, colCount, "missing count")
+			continue
+		}
+		i.setPath(row.line, colCount, ipr.JoinPath(category, strconv.Itoa(id)), count)
+	}
+}
+
+// importCharacters sets the listed fields of each row's character
+func (i *Importer) importCharacters(t *table) {
+	if t.column(colRootName) < 0 && t.column(colID) < 0 && t.column(colName) < 0 {
+		i.addError(1, "", "a character sheet needs a rootName, id or name column")
+		return
+	}
+	for _, h := range t.header {
+		if h != "" && !strings.EqualFold(h, colRootName) && !strings.EqualFold(h, colID) && characterColumn(h) == "" {
+			i.addError(1, h, "unknown column")
+		}
+	}
+
+	for _, row := range t.rows {
+		c, err := i.findCharacter(row.cell(colRootName), row.cell(colID), row.cell(colName))
+		if err != nil {
+			i.addError(row.line, "", err.Error())
+			continue
+		}
+		for n, h := range t.header {
+			field := characterColumn(h)
+			value := row.at(n)
+			if field == "" || value == "" {
+				continue
+			}
+			i.setPath(row.line, h, ipr.JoinPath(ipr.PathCharacters, c.RootName, field), value)
+		}
+	}
+}
+
+// importSpells sets the learn percentages of the spell matrix
+func (i *Importer) importSpells(t *table) {
+	if t.column(colID) < 0 && t.column(colSpell) < 0 {
+		i.addError(1, "", "a spell sheet needs an id or spell column")
+		return
+	}
+	characters := make(map[int]*models.Character)
+	for n, h := range t.header {
+		if h == "" || strings.EqualFold(h, colID) || strings.EqualFold(h, colSpell) {
+			continue
+		}
+		c, err := i.findCharacter(h, "", "")
+		if err != nil {
+			i.addError(1, h, err.Error())
+			continue
+		}
+		characters[n] = c
+	}
+
+	for _, row := range t.rows {
+		spell := row.cell(colID)
+		if spell == "" {
+			spell = row.cell(colSpell)
+		}
+		if spell == "" {
+			continue
+		}
+		id, err := resolveSpellID(spell)
+		if err != nil {
+			i.addError(row.line, "", err.Error())
+			continue
+		}
+		for n, c := range characters {
+			if value := row.at(n); value != "" {
+				i.setPath(row.line, t.header[n], ipr.JoinPath(ipr.PathCharacters, c.RootName, "spells", strconv.Itoa(id)), value)
+			}
+		}
+	}
+}
+
+// findCharacter finds a character of the save by root name, then ID, then
+// the name given in the game
+func (i *Importer) findCharacter(rootName, id, name string) (*models.Character, error) {
+	var c *models.Character
+	switch {
+	case rootName != "":
+		c = ipr.FindCharacter(rootName)
+	case id != "":
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid character ID %q", id)
+		}
+		if c = pri.GetCharacterByID(n); c != nil && c.ID != n {
+			c = nil
+		}
+	case name != "":
+		c = ipr.FindCharacter(name)
+	default:
+		return nil, fmt.Errorf("row names no character")
+	}
+	if c == nil {
+		return nil, fmt.Errorf("unknown character %q", rootName+id+name)
+	}
+	for _, loaded := range i.prData.LoadedCharacters() {
+		if loaded == c {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%s isn't in this save", c.RootName)
+}
+
+// setPath writes a save path, recording a failure against the cell
+func (i *Importer) setPath(line int, column, path string, value string) {
+	if err := ipr.SetPath(path, value); err != nil {
+		msg := err.Error()
+		msg = strings.TrimPrefix(msg, path+": ")
+		i.addError(line, column, msg)
+	}
+}
+
+// addError adds an import error to the error list
+func (i *Importer) addError(line int, column, message string) {
+	i.errors = append(i.errors, ImportError{
+		Line:    line,
+		Column:  column,
+		Message: message,
+	})
+}
+
+// characterColumn returns the save path field of a character sheet column,
+// or "" for columns that aren't fields
+func characterColumn(header string) string {
+	for _, col := range characterColumns {
+		if strings.EqualFold(header, col) {
+			return col
+		}
+	}
+	return ""
+}
+
+// resolveSpellID converts a spell name or ID into a spell ID
+func resolveSpellID(s string) (int, error) {
+	if id, err := strconv.Atoi(s); err == nil {
+		for _, spell := range pr.Spells {
+			if spell.Value == id {
+				return id, nil
+			}
+		}
+		return 0, fmt.Errorf("unknown spell ID %d", id)
+	}
+	for _, spell := range pr.Spells {
+		if strings.EqualFold(spell.Name, s) {
+			return spell.Value, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown spell %q", s)
+}
+
+// table is a read sheet: the header and the rows after it
+type table struct {
+	header []string
+	rows   []tableRow
+}
+
+type tableRow struct {
+	t     *table
+	line  int
+	cells []string
+}
+
+// readTable reads a sheet, dropping the byte order mark spreadsheet programs
+// put in front of the header
+func readTable(r io.Reader, comma rune) (*table, error) {
+	cr := csv.NewReader(r)
+	if comma != 0 {
+		cr.Comma = comma
+	}
+	cr.FieldsPerRecord = -1
+
+	t := &table{}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sheet: %w", err)
+		}
+		for n := range record {
+			record[n] = strings.TrimSpace(record[n])
+		}
+		if t.header == nil {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			t.header = record
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		t.rows = append(t.rows, tableRow{t: t, line: line, cells: record})
+	}
+	if t.header == nil {
+		return nil, fmt.Errorf("sheet is empty")
+	}
+	return t, nil
+}
+
+// column returns the index of a header, ignoring case, or -1
+func (t *table) column(name string) int {
+	for n, h := range t.header {
+		if strings.EqualFold(h, name) {
+			return n
+		}
+	}
+	return -1
+}
+
+// cell returns the cell under a header, "" when missing
+func (r tableRow) cell(name string) string {
+	return r.at(r.t.column(name))
+}
+
+// at returns the cell of a column index, "" when missing
+func (r tableRow) at(n int) string {
+	if n < 0 || n >= len(r.cells) {
+		return ""
+	}
+	return r.cells[n]
+}
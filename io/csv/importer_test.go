@@ -0,0 +1,108 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	ipr "ffvi_editor/io/pr"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+func TestImportRoundTrip(t *testing.T) {
+	p := loadSave(t)
+	terra := pri.GetCharacter("Terra")
+	fire := terra.SpellsByID[pr.Spells[9].Value]
+	level, fireLearned := terra.Level, fire.Value
+
+	for _, sheet := range ImportSheets {
+		var buf bytes.Buffer
+		if err := NewExporter(p).Write(&buf, sheet, ','); err != nil {
+			t.Fatal(err)
+		}
+		terra.Level, fire.Value = 1, 1
+
+		i := NewImporter(p)
+		if _, err := i.Import(&buf, sheet, ImportOptions{}); err != nil {
+			t.Fatalf("%s: Import failed: %v", sheet, err)
+		}
+		for _, e := range i.GetErrors() {
+			// The fixture holds an item missing from the item tables
+			if e.Message != "unknown item ID 197" {
+				t.Errorf("%s: unexpected error %v", sheet, e)
+			}
+		}
+		switch sheet {
+		case SheetCharacters:
+			if terra.Level != level {
+				t.Errorf("Terra's level imported as %d, want %d", terra.Level, level)
+			}
+		case SheetSpells:
+			if fire.Value != fireLearned {
+				t.Errorf("%s imported as %d, want %d", fire.Name, fire.Value, fireLearned)
+			}
+		}
+		terra.Level, fire.Value = level, fireLearned
+	}
+}
+
+func TestImportEditedSheets(t *testing.T) {
+	p := loadSave(t)
+	terra := pri.GetCharacter("Terra")
+	vigor := terra.Vigor
+
+	// Reordered columns, names instead of IDs, a spreadsheet byte order mark
+	// and empty cells left alone
+	sheet := "\ufeffcount\tname\n7\tPotion\n\tEther\n5\tNo Such Item\n"
+	i := NewImporter(p)
+	report, err := i.Import(strings.NewReader(sheet), SheetInventory, ImportOptions{Comma: '\t'})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if got, _ := ipr.GetPath("inventory/Potion"); got != 7 {
+		t.Errorf("Potion count is %v", got)
+	}
+	if report.Statistics.TotalDiffs == 0 {
+		t.Error("the report should list the Potion change")
+	}
+	errs := i.GetErrors()
+	if len(errs) != 2 || errs[0].Line != 3 || errs[0].Column != colCount || errs[1].Line != 4 {
+		t.Errorf("expected errors for lines 3 and 4, got %v", errs)
+	}
+
+	sheet = "rootName,level,vigor,defense\nTerra,45,\nLocke,150,30\n"
+	i = NewImporter(p)
+	if _, err = i.Import(strings.NewReader(sheet), SheetCharacters, ImportOptions{DryRun: true}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if terra.Level == 45 {
+		t.Error("a dry run changed the save")
+	}
+	if _, err = i.Import(strings.NewReader(sheet), SheetCharacters, ImportOptions{}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if terra.Level != 45 || terra.Vigor != vigor {
+		t.Errorf("Terra imported as level %d vigor %d", terra.Level, terra.Vigor)
+	}
+	errs = i.GetErrors()
+	if len(errs) != 2 || errs[0].Column != "defense" || errs[1].Line != 3 || errs[1].Column != "level" {
+		t.Errorf("expected errors for the defense column and Locke's level, got %v", errs)
+	}
+
+	sheet = "spell,Terra,Nobody\nFire,100,50\nMeteor,25,\n"
+	i = NewImporter(p)
+	if _, err = i.Import(strings.NewReader(sheet), SheetSpells, ImportOptions{}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if got, _ := ipr.GetPath("characters/Terra/spells/Fire"); got != 100 {
+		t.Errorf("Terra's Fire is %v", got)
+	}
+	if errs = i.GetErrors(); len(errs) != 1 || errs[0].Column != "Nobody" {
+		t.Errorf("expected an error for the Nobody column, got %v", errs)
+	}
+
+	if _, err = i.Import(strings.NewReader("category\n"), SheetSkills, ImportOptions{}); err == nil {
+		t.Error("expected the skills sheet to be refused")
+	}
+}
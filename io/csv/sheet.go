@@ -0,0 +1,74 @@
+// Package csv reads and writes save data as spreadsheet tables: inventory
+// rows, character stat sheets, a character × spell learning matrix and the
+// skill completion lists. Files ending in .tsv are tab separated.
+package csv
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Sheet selects the table to read or write
+type Sheet string
+
+const (
+	// SheetInventory has one row per inventory row: id, name, count
+	SheetInventory Sheet = "inventory"
+	// SheetImportantItems is SheetInventory for the important items
+	SheetImportantItems Sheet = "important-items"
+	// SheetCharacters has one row of stats per character
+	SheetCharacters Sheet = "characters"
+	// SheetSpells has one row per spell and one learn percentage column per
+	// character
+	SheetSpells Sheet = "spells"
+	// SheetSkills lists every esper, rage, lore, dance, blitz and bushido
+	// technique and whether it is learned
+	SheetSkills Sheet = "skills"
+)
+
+// Sheets lists every sheet
+var Sheets = []Sheet{SheetInventory, SheetImportantItems, SheetCharacters, SheetSpells, SheetSkills}
+
+// ImportSheets lists the sheets that can be imported
+var ImportSheets = []Sheet{SheetInventory, SheetImportantItems, SheetCharacters, SheetSpells}
+
+// ParseSheet checks a sheet name
+func ParseSheet(s string) (Sheet, error) {
+	for _, sheet := range Sheets {
+		if string(sheet) == s {
+			return sheet, nil
+		}
+	}
+	names := make([]string, len(Sheets))
+	for i, sheet := range Sheets {
+		names[i] = string(sheet)
+	}
+	return "", fmt.Errorf("unknown sheet %q (want %s)", s, strings.Join(names, ", "))
+}
+
+// CommaFor returns the field separator for a file: tab for .tsv, else comma
+func CommaFor(path string) rune {
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		return '\t'
+	}
+	return ','
+}
+
+// Column names shared by the exporter and importer
+const (
+	colID       = "id"
+	colName     = "name"
+	colCount    = "count"
+	colRootName = "rootName"
+	colSpell    = "spell"
+	colCategory = "category"
+	colLearned  = "learned"
+)
+
+// characterColumns are the character sheet columns after rootName and id;
+// each is a field under characters/<name> in the save paths
+var characterColumns = []string{
+	"name", "enabled", "level", "exp", "hp", "maxHp", "mp", "maxMp",
+	"vigor", "speed", "stamina", "magic", "esper",
+}
@@ -20,6 +20,11 @@ func LoadFile(fromFile string, saveType global.SaveFileType) (out []byte, trimme
 	if b, err = os.ReadFile(fromFile); err != nil {
 		return
 	}
+	return DecodeFile(b, saveType)
+}
+
+// DecodeFile decodes the contents of a save file as LoadFile does
+func DecodeFile(b []byte, saveType global.SaveFileType) (out []byte, trimmed []byte, err error) {
 	if saveType == global.PS {
 		return b, nil, nil
 	}
@@ -42,9 +47,13 @@ func LoadFile(fromFile string, saveType global.SaveFileType) (out []byte, trimme
 		return
 	}
 	// Decrypt
+	padded := len(b)
 	if b, err = rijndael.New().Decrypt(b); err != nil {
 		return
 	}
+	// The cypher drops every trailing zero of the last block, the zero padding
+	// and any zeros ending the deflate stream alike; put them back
+	b = append(b, make([]byte, padded-len(b))...)
 
 	// Flate
 	zr := flate.NewReader(bytes.NewReader(b))
@@ -3,6 +3,7 @@ package file
 import (
 	"bytes"
 	"encoding/base64"
+	"os"
 	"testing"
 
 	"ffvi_editor/global"
@@ -221,3 +222,46 @@ func TestSaveFileCompressionWorks(t *testing.T) {
 		t.Fatal("SaveFile() compression may not be working (output not smaller than input)")
 	}
 }
+
+// TestSaveLoadRoundTrip tests that a saved PC file loads back unchanged,
+// including deflate streams that end in a zero byte
+func TestSaveLoadRoundTrip(t *testing.T) {
+	testData := []byte(`{"character": {"name": "Terra", "level": 1}}`)
+	tmpFile := t.TempDir() + "/test.save"
+
+	if err := SaveFile(testData, tmpFile, nil, global.PC); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	out, _, err := LoadFile(tmpFile, global.PC)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if !bytes.Equal(out, testData) {
+		t.Fatalf("LoadFile() = %q, want %q", out, testData)
+	}
+}
+
+// TestLoadFileTruncated tests that a save missing its last block fails to load
+func TestLoadFileTruncated(t *testing.T) {
+	testData := bytes.Repeat([]byte(`{"character": {"name": "Terra", "level": 1}}`), 20)
+	tmpFile := t.TempDir() + "/test.save"
+
+	if err := SaveFile(testData, tmpFile, nil, global.PC); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	b, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err = base64.StdEncoding.DecodeString(string(b)); err != nil {
+		t.Fatal(err)
+	}
+	b = []byte(base64.StdEncoding.EncodeToString(b[:len(b)-32]))
+	if err = os.WriteFile(tmpFile, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = LoadFile(tmpFile, global.PC); err == nil {
+		t.Fatal("LoadFile() should return an error for a truncated file")
+	}
+}
@@ -0,0 +1,362 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/backup"
+	"ffvi_editor/io/pr"
+	"ffvi_editor/models"
+)
+
+// DefaultMaxPoints is the number of points whose save data is kept
+const DefaultMaxPoints = 500
+
+// Source is what recorded a timeline point
+type Source string
+
+const (
+	SourceWatch  Source = "watch"
+	SourceBackup Source = "backup"
+	SourceManual Source = "manual"
+	SourceEditor Source = "editor"
+)
+
+// Entry is one point on a slot's timeline. The save data is kept by the
+// store's backup manager under BackupID until it is pruned; the summary is
+// kept for good.
+type Entry struct {
+	ID          string              `json:"id"`
+	Timestamp   time.Time           `json:"timestamp"`
+	Slot        string              `json:"slot"` // Save file path
+	Source      Source              `json:"source"`
+	Description string              `json:"description"`
+	Hash        string              `json:"hash"`
+	SaveType    global.SaveFileType `json:"saveType"`
+	BackupID    string              `json:"backupId"`
+	Origin      string              `json:"origin,omitempty"` // ID of an imported backup
+	Summary     Summary             `json:"summary"`
+}
+
+// Capture describes a save to record
+type Capture struct {
+	Path        string
+	Data        []byte
+	SaveType    global.SaveFileType
+	Source      Source
+	Description string
+	// Time defaults to now
+	Time time.Time
+	// Origin is the ID of the backup the capture was imported from
+	Origin string
+	// Snapshot of the save; when nil one is taken from the data
+	Snapshot *pr.Snapshot
+}
+
+// Store records snapshot summaries of save slots over time
+type Store struct {
+	dir     string
+	file    string
+	saves   *backup.Manager
+	mu      sync.RWMutex
+	entries []*Entry
+}
+
+// NewStore opens the history kept in dir, keeping the save data of at most
+// maxPoints points
+func NewStore(dir string, maxPoints int) (*Store, error) {
+	if maxPoints <= 0 {
+		return nil, fmt.Errorf("invalid number of points to keep: %d", maxPoints)
+	}
+	saves, err := backup.NewManager(filepath.Join(dir, "saves"), maxPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		dir:     dir,
+		file:    filepath.Join(dir, "history.json"),
+		saves:   saves,
+		entries: make([]*Entry, 0),
+	}
+	if err = s.load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Dir returns the directory the history is stored in
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Record adds a point for the captured save. It returns nil without error
+// when the save is identical to the slot's latest point or the backup was
+// already imported.
+func (s *Store) Record(c Capture) (*Entry, error) {
+	if c.Path == "" {
+		return nil, fmt.Errorf("capture has no slot path")
+	}
+	if abs, err := filepath.Abs(c.Path); err == nil {
+		c.Path = abs
+	}
+	if c.Time.IsZero() {
+		c.Time = time.Now()
+	}
+	if c.Source == "" {
+		c.Source = SourceManual
+	}
+	hash := models.CalculateHash(c.Data)
+
+	s.mu.RLock()
+	recorded := s.recorded(c, hash)
+	s.mu.RUnlock()
+	if recorded {
+		return nil, nil
+	}
+
+	snapshot := c.Snapshot
+	if snapshot == nil {
+		var err error
+		if snapshot, err = snapshotData(c.Path, c.Data, c.SaveType); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.recorded(c, hash) {
+		return nil, nil
+	}
+	meta, err := s.saves.CreateBackup(c.Path, c.Data, fmt.Sprintf("%s: %s", c.Source, c.Description))
+	if err != nil {
+		return nil, err
+	}
+	e := &Entry{
+		ID:          meta.ID,
+		Timestamp:   c.Time,
+		Slot:        c.Path,
+		Source:      c.Source,
+		Description: c.Description,
+		Hash:        hash,
+		SaveType:    c.SaveType,
+		BackupID:    meta.ID,
+		Origin:      c.Origin,
+		Summary:     Summarize(snapshot),
+	}
+	s.entries = append(s.entries, e)
+	sort.SliceStable(s.entries, func(i, j int) bool {
+		return s.entries[i].Timestamp.Before(s.entries[j].Timestamp)
+	})
+	s.prune()
+
+	if err = s.save(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// RecordFile reads a save file and records it
+func (s *Store) RecordFile(path string, saveType global.SaveFileType, source Source, description string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read save file: %w", err)
+	}
+	return s.Record(Capture{Path: path, Data: data, SaveType: saveType, Source: source, Description: description})
+}
+
+// ImportBackups records every backup of the manager that isn't in the
+// history yet, dated when the backup was made, and returns the new points
+func (s *Store) ImportBackups(m *backup.Manager, saveType global.SaveFileType) ([]*Entry, error) {
+	list := m.ListBackups()
+	added := make([]*Entry, 0)
+	for i := len(list) - 1; i >= 0; i-- {
+		b := list[i]
+		data, err := m.RestoreBackup(b.ID)
+		if err != nil {
+			return added, err
+		}
+		e, err := s.Record(Capture{
+			Path:        b.OriginalPath,
+			Data:        data,
+			SaveType:    saveType,
+			Source:      SourceBackup,
+			Description: b.Description,
+			Time:        b.Timestamp,
+			Origin:      b.ID,
+		})
+		if err != nil {
+			return added, fmt.Errorf("backup %s: %w", b.ID, err)
+		}
+		if e != nil {
+			added = append(added, e)
+		}
+	}
+	return added, nil
+}
+
+// Entries returns the points of a slot, oldest first; an empty slot returns
+// the points of every slot
+func (s *Store) Entries(slot string) []*Entry {
+	if abs, err := filepath.Abs(slot); err == nil && slot != "" {
+		slot = abs
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]*Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		if slot == "" || e.Slot == slot {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Slots returns the paths of every slot with recorded points
+func (s *Store) Slots() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	slots := make([]string, 0)
+	for _, e := range s.entries {
+		if !seen[e.Slot] {
+			seen[e.Slot] = true
+			slots = append(slots, e.Slot)
+		}
+	}
+	sort.Strings(slots)
+	return slots
+}
+
+// Get returns a point by ID
+func (s *Store) Get(id string) (*Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, e := range s.entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("history point not found: %s", id)
+}
+
+// Data returns the save data of a point
+func (s *Store) Data(id string) ([]byte, error) {
+	e, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if e.BackupID == "" {
+		return nil, fmt.Errorf("save data of history point %s was pruned", id)
+	}
+	return s.saves.RestoreBackup(e.BackupID)
+}
+
+// Diff compares the saves of two points. The save open in the editor is
+// left alone.
+func (s *Store) Diff(fromID, toID string) (pr.DiffReport, error) {
+	from, err := s.Get(fromID)
+	if err != nil {
+		return pr.DiffReport{}, err
+	}
+
+	snapshots := make([]*pr.Snapshot, 0, 2)
+	for _, id := range []string{fromID, toID} {
+		data, err := s.Data(id)
+		if err != nil {
+			return pr.DiffReport{}, err
+		}
+		snapshot, err := pr.SnapshotData(data, from.SaveType)
+		if err != nil {
+			return pr.DiffReport{}, fmt.Errorf("failed to load history point %s: %w", id, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return pr.NewComparator(snapshots[0], snapshots[1]).Compare(), nil
+}
+
+// Timeline returns the timeline of a slot; an empty slot covers every slot
+func (s *Store) Timeline(slot string) *Timeline {
+	return &Timeline{Slot: slot, Entries: s.Entries(slot)}
+}
+
+// recorded reports whether a capture is already in the history. The caller
+// must hold the lock.
+func (s *Store) recorded(c Capture, hash string) bool {
+	if c.Origin != "" {
+		for _, e := range s.entries {
+			if e.Origin == c.Origin {
+				return true
+			}
+		}
+	}
+	latest := s.latest(c.Path)
+	return latest != nil && latest.Hash == hash
+}
+
+// latest returns the newest point of a slot. The caller must hold the lock.
+func (s *Store) latest(slot string) *Entry {
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if s.entries[i].Slot == slot {
+			return s.entries[i]
+		}
+	}
+	return nil
+}
+
+// prune clears the backup ID of points whose save data the backup manager
+// removed. The caller must hold the write lock.
+func (s *Store) prune() {
+	for _, e := range s.entries {
+		if e.BackupID == "" {
+			continue
+		}
+		if _, err := s.saves.GetBackupMetadata(e.BackupID); err != nil {
+			e.BackupID = ""
+		}
+	}
+}
+
+// load reads the history index
+func (s *Store) load() error {
+	data, err := os.ReadFile(s.file)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, &s.entries); err != nil {
+		return fmt.Errorf("failed to parse history: %w", err)
+	}
+	return nil
+}
+
+// save writes the history index. The caller must hold the write lock.
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+	if err = os.WriteFile(s.file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// snapshotData snapshots save data without disturbing the loaded save
+func snapshotData(path string, data []byte, saveType global.SaveFileType) (*pr.Snapshot, error) {
+	snapshot, err := pr.SnapshotData(data, saveType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filepath.Base(path), err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,151 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/backup"
+	"ffvi_editor/io/pr"
+)
+
+const testSave = "../../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y="
+
+// editedSave writes a copy of the test save with the patch applied
+func editedSave(t *testing.T, file string, edits *pr.Patch) []byte {
+	t.Helper()
+	p := pr.New()
+	if err := p.Load(testSave, global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	if err := p.ApplyPatch(edits); err != nil {
+		t.Fatalf("ApplyPatch failed: %v", err)
+	}
+	if err := p.Save(p.SlotID(), file, global.PC); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestRecordAndTimeline(t *testing.T) {
+	dir := t.TempDir()
+	slot := filepath.Join(dir, "slot")
+	store, err := NewStore(filepath.Join(dir, "history"), DefaultMaxPoints)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	first := pr.NewPatch("first")
+	first.Add(pr.PatchSet, "characters/Terra/level", 10)
+	first.Add(pr.PatchSet, "misc/gil", 100)
+	e1, err := store.Record(Capture{Path: slot, Data: editedSave(t, slot, first), Source: SourceManual, Time: start})
+	if err != nil || e1 == nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	// Recording the same save again adds nothing
+	if e, err := store.RecordFile(slot, global.PC, SourceWatch, ""); err != nil || e != nil {
+		t.Fatalf("unchanged save recorded again: %v %v", e, err)
+	}
+
+	second := pr.NewPatch("second")
+	second.Add(pr.PatchSet, "characters/Terra/level", 20)
+	second.Add(pr.PatchSet, "misc/gil", 5000)
+	second.Add(pr.PatchAdd, "espers/Ramuh", nil)
+	e2, err := store.Record(Capture{Path: slot, Data: editedSave(t, slot, second), Source: SourceWatch, Time: start.Add(time.Hour)})
+	if err != nil || e2 == nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	// The history survives reopening
+	store, err = NewStore(filepath.Join(dir, "history"), DefaultMaxPoints)
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	tl := store.Timeline(slot)
+	if len(tl.Entries) != 2 {
+		t.Fatalf("got %d points, want 2", len(tl.Entries))
+	}
+
+	terra := tl.LevelCurves()["Terra"]
+	if len(terra) != 2 || terra[0].Value != 10 || terra[1].Value != 20 {
+		t.Errorf("Terra level curve: %+v", terra)
+	}
+	if gil := tl.Gil(); gil[0].Value != 100 || gil[1].Value != 5000 {
+		t.Errorf("gil: %+v", gil)
+	}
+	espers := tl.EspersAcquired()
+	if len(espers) != 1 || espers[0].Name != "Ramuh" || espers[0].Entry != e2.ID {
+		t.Errorf("espers acquired: %+v", espers)
+	}
+	if progress := tl.Progress(); len(progress) != 2 {
+		t.Errorf("progress: %+v", progress)
+	}
+	if visits := tl.MapsVisited(); len(visits) != 1 || !visits[0].To.Equal(start.Add(time.Hour)) {
+		t.Errorf("maps visited: %+v", visits)
+	}
+
+	// Comparing leaves the save open in the editor, unsaved edits included
+	if err = pr.New().Load(testSave, global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	if err = pr.SetPath("misc/gil", 4242); err != nil {
+		t.Fatal(err)
+	}
+	opened := pr.TakeSnapshot()
+
+	report, err := store.Diff(e1.ID, e2.ID)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if left := pr.NewComparator(opened, pr.TakeSnapshot()).Compare(); left.HasDifferences() {
+		t.Errorf("Diff changed the open save: %+v", left.Diffs)
+	}
+	found := false
+	for _, d := range report.Diffs {
+		if d.Path == "characters/Terra/level" && d.OldValue == 10 && d.NewValue == 20 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("level change missing from diff: %+v", report.Diffs)
+	}
+}
+
+func TestImportBackups(t *testing.T) {
+	dir := t.TempDir()
+	backups, err := backup.NewManager(filepath.Join(dir, "backups"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(testSave)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, err := backups.CreateBackup(filepath.Join(dir, "slot"), data, "before boss")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewStore(filepath.Join(dir, "history"), DefaultMaxPoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	added, err := store.ImportBackups(backups, global.PC)
+	if err != nil {
+		t.Fatalf("ImportBackups failed: %v", err)
+	}
+	if len(added) != 1 || added[0].Origin != meta.ID || added[0].Source != SourceBackup || !added[0].Timestamp.Equal(meta.Timestamp) {
+		t.Fatalf("imported: %+v", added)
+	}
+	if added, _ = store.ImportBackups(backups, global.PC); len(added) != 0 {
+		t.Errorf("backup imported twice")
+	}
+}
@@ -0,0 +1,109 @@
+package history
+
+import (
+	"sort"
+	"strings"
+
+	"ffvi_editor/io/pr"
+)
+
+// CharacterSummary is a character's progress at one point in time
+type CharacterSummary struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Level   int    `json:"level"`
+	Exp     int    `json:"exp"`
+}
+
+// Summary is the progress recorded for a timeline point. It is small enough
+// to keep for every point, so timelines don't need to reload each save.
+type Summary struct {
+	Characters map[string]CharacterSummary `json:"characters"` // By root name
+	Espers     []string                    `json:"espers"`
+	Gil        int                         `json:"gil"`
+	Steps      int                         `json:"steps"`
+	Battles    int                         `json:"battles"`
+	SaveCount  int                         `json:"saveCount"`
+	PlayTime   float64                     `json:"playTime"` // Seconds
+	MapID      int                         `json:"mapId"`
+	X          float64                     `json:"x"`
+	Y          float64                     `json:"y"`
+}
+
+// Summarize extracts the timeline values from a snapshot
+func Summarize(s *pr.Snapshot) Summary {
+	sum := Summary{
+		Characters: make(map[string]CharacterSummary),
+		Espers:     make([]string, 0),
+	}
+	for _, path := range s.Paths() {
+		v, _ := s.Get(path)
+		segments := pr.SplitPath(path)
+		switch segments[0] {
+		case pr.PathCharacters:
+			if len(segments) != 3 {
+				continue
+			}
+			c := sum.Characters[segments[1]]
+			switch segments[2] {
+			case "name":
+				c.Name, _ = v.(string)
+			case "enabled":
+				c.Enabled, _ = v.(bool)
+			case "level":
+				c.Level = intValue(v)
+			case "exp":
+				c.Exp = intValue(v)
+			}
+			sum.Characters[segments[1]] = c
+		case pr.PathEspers:
+			sum.Espers = append(sum.Espers, segments[1])
+		case pr.PathMisc:
+			switch segments[1] {
+			case "gil":
+				sum.Gil = intValue(v)
+			case "steps":
+				sum.Steps = intValue(v)
+			case "battleCount":
+				sum.Battles = intValue(v)
+			case "saveCount":
+				sum.SaveCount = intValue(v)
+			case "playTime":
+				sum.PlayTime = floatValue(v)
+			}
+		case pr.PathMap:
+			switch segments[1] {
+			case "mapId":
+				sum.MapID = intValue(v)
+			case "x":
+				sum.X = floatValue(v)
+			case "y":
+				sum.Y = floatValue(v)
+			}
+		}
+	}
+	sort.Slice(sum.Espers, func(i, j int) bool {
+		return strings.ToLower(sum.Espers[i]) < strings.ToLower(sum.Espers[j])
+	})
+	return sum
+}
+
+func intValue(v interface{}) int {
+	switch t := v.(type) {
+	case int:
+		return t
+	case float64:
+		return int(t)
+	}
+	return 0
+}
+
+func floatValue(v interface{}) float64 {
+	switch t := v.(type) {
+	case float64:
+		return t
+	case int:
+		return float64(t)
+	}
+	return 0
+}
@@ -0,0 +1,135 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// Point is a value at a point in time
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// EsperEvent is the first point an esper was owned at
+type EsperEvent struct {
+	Time  time.Time `json:"time"`
+	Name  string    `json:"name"`
+	Entry string    `json:"entry"`
+}
+
+// ProgressPoint relates play time to steps and battles
+type ProgressPoint struct {
+	Time     time.Time `json:"time"`
+	PlayTime float64   `json:"playTime"` // Seconds
+	Steps    int       `json:"steps"`
+	Battles  int       `json:"battles"`
+}
+
+// MapVisit is a stay on one map; consecutive points on the same map are
+// merged into a single visit
+type MapVisit struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	MapID int       `json:"mapId"`
+	X     float64   `json:"x"`
+	Y     float64   `json:"y"`
+}
+
+// Timeline is the ordered list of points recorded for a slot
+type Timeline struct {
+	Slot    string   `json:"slot"`
+	Entries []*Entry `json:"entries"`
+}
+
+// LevelCurves returns the level of every character over time, keyed by
+// character name. Points before a character joined are left out.
+func (t *Timeline) LevelCurves() map[string][]Point {
+	curves := make(map[string][]Point)
+	for _, e := range t.Entries {
+		for root, c := range e.Summary.Characters {
+			if !c.Enabled {
+				continue
+			}
+			name := c.Name
+			if name == "" {
+				name = root
+			}
+			curves[name] = append(curves[name], Point{Time: e.Timestamp, Value: float64(c.Level)})
+		}
+	}
+	return curves
+}
+
+// Gil returns the party's gil over time
+func (t *Timeline) Gil() []Point {
+	points := make([]Point, 0, len(t.Entries))
+	for _, e := range t.Entries {
+		points = append(points, Point{Time: e.Timestamp, Value: float64(e.Summary.Gil)})
+	}
+	return points
+}
+
+// EspersAcquired returns when each esper first appeared, in order
+func (t *Timeline) EspersAcquired() []EsperEvent {
+	seen := make(map[string]bool)
+	events := make([]EsperEvent, 0)
+	for i, e := range t.Entries {
+		for _, name := range e.Summary.Espers {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			// Espers owned at the first point weren't acquired during the timeline
+			if i > 0 {
+				events = append(events, EsperEvent{Time: e.Timestamp, Name: name, Entry: e.ID})
+			}
+		}
+	}
+	return events
+}
+
+// Progress returns play time against steps and battles
+func (t *Timeline) Progress() []ProgressPoint {
+	points := make([]ProgressPoint, 0, len(t.Entries))
+	for _, e := range t.Entries {
+		points = append(points, ProgressPoint{
+			Time:     e.Timestamp,
+			PlayTime: e.Summary.PlayTime,
+			Steps:    e.Summary.Steps,
+			Battles:  e.Summary.Battles,
+		})
+	}
+	return points
+}
+
+// MapsVisited returns the maps the slot was saved on, in order
+func (t *Timeline) MapsVisited() []MapVisit {
+	visits := make([]MapVisit, 0)
+	for _, e := range t.Entries {
+		if n := len(visits); n > 0 && visits[n-1].MapID == e.Summary.MapID {
+			visits[n-1].To = e.Timestamp
+			visits[n-1].X, visits[n-1].Y = e.Summary.X, e.Summary.Y
+			continue
+		}
+		visits = append(visits, MapVisit{
+			From:  e.Timestamp,
+			To:    e.Timestamp,
+			MapID: e.Summary.MapID,
+			X:     e.Summary.X,
+			Y:     e.Summary.Y,
+		})
+	}
+	return visits
+}
+
+// CharacterNames returns the names of the characters with a level curve
+func (t *Timeline) CharacterNames() []string {
+	curves := t.LevelCurves()
+	names := make([]string, 0, len(curves))
+	for name := range curves {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,351 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+	"ffvi_editor/models"
+)
+
+// DefaultMaxSteps is the number of undo steps kept when none is given
+const DefaultMaxSteps = 100
+
+// Value is the value a path had after the latest change to it
+type Value struct {
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// Journal is the edit history of one save file. It is written after every
+// change while the save is edited, so the edits outlive a crash.
+type Journal struct {
+	File     string              `json:"file"`
+	SaveType global.SaveFileType `json:"saveType"`
+	// Hash is the hash of the file as last loaded or saved; the history only
+	// applies to the file while it has this hash
+	Hash string `json:"hash"`
+	// Open is set while the save is edited. A journal still open when the
+	// editor starts was left by a crash.
+	Open    bool      `json:"open"`
+	Updated time.Time `json:"updated"`
+	// Undo and Redo are the undo history, oldest first
+	Undo []models.ChangeGroup `json:"undo"`
+	Redo []models.ChangeGroup `json:"redo"`
+	// Unsaved holds the paths changed since the file was loaded or saved, in
+	// the order they were first changed
+	Unsaved []Value `json:"unsaved"`
+}
+
+// Recoverable reports whether the journal holds edits lost by a crash
+func (j *Journal) Recoverable() bool {
+	return j.Open && len(j.Unsaved) > 0
+}
+
+// Matches reports whether the journal describes the file as it is on disk
+func (j *Journal) Matches() bool {
+	data, err := os.ReadFile(j.File)
+	return err == nil && models.CalculateHash(data) == j.Hash
+}
+
+// Replay writes the unsaved values onto the loaded save, which should be the
+// journal's file as it was loaded. Values that fail are skipped and the
+// first error is returned.
+func (j *Journal) Replay() (err error) {
+	for _, v := range j.Unsaved {
+		if e := pr.RestoreValue(v.Path, v.Value); e != nil && err == nil {
+			err = fmt.Errorf("%s: %w", v.Path, e)
+		}
+	}
+	return
+}
+
+// History returns the undo history with changes that can be applied and
+// reverted again
+func (j *Journal) History() (undo, redo []models.ChangeGroup) {
+	return bindGroups(j.Undo), bindGroups(j.Redo)
+}
+
+// track notes the values the changes leave behind
+func (j *Journal) track(changes []models.Change, reverted bool) {
+	for _, c := range changes {
+		value := c.NewValue
+		if reverted {
+			value = c.OldValue
+		}
+		found := false
+		for n := range j.Unsaved {
+			if j.Unsaved[n].Path == c.FieldName {
+				j.Unsaved[n].Value, found = value, true
+				break
+			}
+		}
+		if !found {
+			j.Unsaved = append(j.Unsaved, Value{Path: c.FieldName, Value: value})
+		}
+	}
+}
+
+// Store keeps the journals of edited saves in a directory, one file per save
+type Store struct {
+	dir      string
+	maxSteps int
+	mu       sync.Mutex
+	current  *Journal
+}
+
+// NewStore opens the journals kept in dir. Each keeps at most maxSteps undo
+// and redo steps; zero or less keeps DefaultMaxSteps.
+func NewStore(dir string, maxSteps int) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	s := &Store{dir: dir}
+	s.SetMaxSteps(maxSteps)
+	return s, nil
+}
+
+// Dir returns the directory the journals are stored in
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// SetMaxSteps changes how many undo and redo steps the journals keep
+func (s *Store) SetMaxSteps(maxSteps int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if maxSteps <= 0 {
+		maxSteps = DefaultMaxSteps
+	}
+	s.maxSteps = maxSteps
+}
+
+// Get returns the journal of a save file, or nil when it has none
+func (s *Store) Get(file string) (*Journal, error) {
+	return s.read(s.path(absPath(file)))
+}
+
+// Unclean returns the journals left open with unsaved edits, newest first.
+// Journals whose file changed since can't be replayed, so they are closed.
+func (s *Store) Unclean() ([]*Journal, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	journals := make([]*Journal, 0)
+	for _, f := range files {
+		j, err := s.read(f)
+		if err != nil {
+			return journals, err
+		}
+		if j == nil || !j.Recoverable() {
+			continue
+		}
+		if !j.Matches() {
+			if err = s.Discard(j); err != nil {
+				return journals, err
+			}
+			continue
+		}
+		journals = append(journals, j)
+	}
+	sort.Slice(journals, func(a, b int) bool {
+		return journals[a].Updated.After(journals[b].Updated)
+	})
+	return journals, nil
+}
+
+// Open starts journaling a save file that was just loaded. It returns the
+// file's previous journal when that still describes the file, or nil.
+// Journaling starts over with an empty history; Resume carries the previous
+// one over.
+func (s *Store) Open(file string, saveType global.SaveFileType) (previous *Journal, err error) {
+	file = absPath(file)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read save file: %w", err)
+	}
+	if previous, err = s.Get(file); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err = s.closeLocked(); err != nil {
+		return nil, err
+	}
+	s.current = &Journal{
+		File:     file,
+		SaveType: saveType,
+		Hash:     models.CalculateHash(data),
+		Open:     true,
+		Undo:     make([]models.ChangeGroup, 0),
+		Redo:     make([]models.ChangeGroup, 0),
+		Unsaved:  make([]Value, 0),
+	}
+	if previous != nil && previous.Hash != s.current.Hash {
+		previous = nil
+	}
+	return previous, s.writeLocked()
+}
+
+// Resume carries the history and unsaved edits of a journal returned by Open
+// over to the open save. Callers replay the unsaved edits or discard them
+// first, as the history covers them.
+func (s *Store) Resume(previous *Journal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil {
+		return fmt.Errorf("no save is journaled")
+	}
+	s.current.Undo, s.current.Redo = s.trim(previous.Undo), s.trim(previous.Redo)
+	s.current.Unsaved = append(make([]Value, 0, len(previous.Unsaved)), previous.Unsaved...)
+	return s.writeLocked()
+}
+
+// Track records changes made to the open save, reverted when they were
+// undone, along with the undo history after them
+func (s *Store) Track(changes []models.Change, reverted bool, undo, redo []models.ChangeGroup) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil {
+		return nil
+	}
+	s.current.track(changes, reverted)
+	s.current.Undo, s.current.Redo = s.trim(undo), s.trim(redo)
+	return s.writeLocked()
+}
+
+// Saved notes that the open save was written to file; its edits are no
+// longer unsaved. Writing to another file moves the journal there.
+func (s *Store) Saved(file string, saveType global.SaveFileType) error {
+	file = absPath(file)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read save file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil {
+		return nil
+	}
+	if s.current.File != file {
+		if err = s.closeLocked(); err != nil {
+			return err
+		}
+		j := *s.current
+		s.current = &j
+		s.current.File, s.current.Open = file, true
+	}
+	s.current.SaveType = saveType
+	s.current.Hash = models.CalculateHash(data)
+	s.current.Unsaved = make([]Value, 0)
+	return s.writeLocked()
+}
+
+// Close ends journaling the open save. Its history is kept for the next time
+// the file is loaded.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.closeLocked()
+	s.current = nil
+	return err
+}
+
+// Discard drops the unsaved edits of a journal left by a crash, keeping its
+// history
+func (s *Store) Discard(j *Journal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.Open = false
+	j.Unsaved = make([]Value, 0)
+	return s.write(j)
+}
+
+func (s *Store) closeLocked() error {
+	if s.current == nil {
+		return nil
+	}
+	s.current.Open = false
+	return s.writeLocked()
+}
+
+// trim keeps the newest steps
+func (s *Store) trim(groups []models.ChangeGroup) []models.ChangeGroup {
+	if len(groups) > s.maxSteps {
+		groups = groups[len(groups)-s.maxSteps:]
+	}
+	return groups
+}
+
+// writeLocked writes the open journal. The caller must hold the lock.
+func (s *Store) writeLocked() error {
+	return s.write(s.current)
+}
+
+// write replaces the journal's file through a temporary file, so a crash
+// while writing leaves the previous version
+func (s *Store) write(j *Journal) error {
+	j.Updated = time.Now()
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal: %w", err)
+	}
+	file := s.path(j.File)
+	tmp := file + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err = os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// read loads a journal file; a missing file returns nil
+func (s *Store) read(file string) (*Journal, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	j := &Journal{}
+	if err = json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("failed to parse journal %s: %w", filepath.Base(file), err)
+	}
+	return j, nil
+}
+
+// path returns the journal file of a save file
+func (s *Store) path(file string) string {
+	return filepath.Join(s.dir, models.CalculateHash([]byte(file))[:16]+".json")
+}
+
+func bindGroups(groups []models.ChangeGroup) []models.ChangeGroup {
+	bound := make([]models.ChangeGroup, len(groups))
+	for n, g := range groups {
+		bound[n] = g
+		bound[n].Changes = make([]models.Change, len(g.Changes))
+		for i, c := range g.Changes {
+			bound[n].Changes[i] = pr.BindChange(c)
+		}
+	}
+	return bound
+}
+
+func absPath(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return file
+}
@@ -0,0 +1,163 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+	"ffvi_editor/models"
+)
+
+const testSave = "../../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y="
+
+// loadCopy copies the test save into dir and loads the copy
+func loadCopy(t *testing.T, dir string) string {
+	t.Helper()
+	file := filepath.Join(dir, "slot")
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		data, err := os.ReadFile(testSave)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(file, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pr.New().Load(file, global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	pr.Changes.Sync()
+	return file
+}
+
+// edit makes an edit through the change tracker and journals it
+func edit(t *testing.T, s *Store, undo *[]models.ChangeGroup, fn func() error) {
+	t.Helper()
+	unsubscribe := pr.Changes.Subscribe(func(g models.ChangeGroup) {
+		*undo = append(*undo, g)
+		if err := s.Track(g.Changes, false, *undo, nil); err != nil {
+			t.Fatalf("Track failed: %v", err)
+		}
+	})
+	defer unsubscribe()
+	if err := pr.Changes.Edit("test", fn); err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+}
+
+func TestRecoverAfterCrash(t *testing.T) {
+	dir := t.TempDir()
+	file := loadCopy(t, dir)
+	s, err := NewStore(filepath.Join(dir, "journal"), 10)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	if _, err = s.Open(file, global.PC); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	var undo []models.ChangeGroup
+	edit(t, s, &undo, func() error { return pr.SetPath("misc/gil", 1234) })
+	edit(t, s, &undo, func() error { return pr.SetPath("characters/Terra/level", 42) })
+	edit(t, s, &undo, func() error { return pr.SetPath("misc/gil", 4321) })
+
+	// The editor crashes: the journal is never closed
+	s, err = NewStore(filepath.Join(dir, "journal"), 10)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	unclean, err := s.Unclean()
+	if err != nil || len(unclean) != 1 {
+		t.Fatalf("expected one unclean journal, got %d: %v", len(unclean), err)
+	}
+	if len(unclean[0].Unsaved) != 2 {
+		t.Fatalf("expected the two changed paths to be unsaved, got %+v", unclean[0].Unsaved)
+	}
+
+	loadCopy(t, dir)
+	previous, err := s.Open(file, global.PC)
+	if err != nil || previous == nil {
+		t.Fatalf("Open didn't return the previous journal: %v", err)
+	}
+	if err = previous.Replay(); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if v, _ := pr.GetPath("misc/gil"); v != 4321 {
+		t.Errorf("expected replayed gil 4321, got %v", v)
+	}
+	if v, _ := pr.GetPath("characters/Terra/level"); v != 42 {
+		t.Errorf("expected replayed level 42, got %v", v)
+	}
+
+	// The history survives too and undoes the replayed edits
+	restored, _ := previous.History()
+	if len(restored) != 3 {
+		t.Fatalf("expected 3 undo steps, got %d", len(restored))
+	}
+	for i := len(restored) - 1; i >= 0; i-- {
+		if err = restored[i].Undo(); err != nil {
+			t.Fatalf("undo of restored step %d failed: %v", i, err)
+		}
+	}
+	if v, _ := pr.GetPath("misc/gil"); v == 4321 || v == 1234 {
+		t.Errorf("expected the original gil back, got %v", v)
+	}
+	if err = s.Resume(previous); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+}
+
+func TestSaveAndCloseLeaveNothingToRecover(t *testing.T) {
+	dir := t.TempDir()
+	file := loadCopy(t, dir)
+	s, err := NewStore(filepath.Join(dir, "journal"), 2)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	if _, err = s.Open(file, global.PC); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	var undo []models.ChangeGroup
+	for _, gil := range []int{10, 20, 30} {
+		gil := gil
+		edit(t, s, &undo, func() error { return pr.SetPath("misc/gil", gil) })
+	}
+	if j, _ := s.Get(file); j == nil || !j.Recoverable() || len(j.Undo) != 2 {
+		t.Fatalf("expected a recoverable journal with 2 steps, got %+v", j)
+	}
+
+	// Saving makes the edits part of the file
+	p := pr.New()
+	if err = p.Load(file, global.PC); err != nil {
+		t.Fatal(err)
+	}
+	if err = pr.SetPath("misc/gil", 30); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Save(p.SlotID(), file, global.PC); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err = s.Saved(file, global.PC); err != nil {
+		t.Fatalf("Saved failed: %v", err)
+	}
+	if j, _ := s.Get(file); j == nil || j.Recoverable() || !j.Matches() {
+		t.Fatalf("expected a saved journal matching the file, got %+v", j)
+	}
+
+	if err = s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if unclean, err := s.Unclean(); err != nil || len(unclean) != 0 {
+		t.Fatalf("expected no unclean journals, got %d: %v", len(unclean), err)
+	}
+
+	// The history is kept for the next time the file is loaded
+	loadCopy(t, dir)
+	previous, err := s.Open(file, global.PC)
+	if err != nil || previous == nil || len(previous.Undo) != 2 {
+		t.Fatalf("expected the saved history back, got %+v: %v", previous, err)
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"time"
 
 	ipr "ffvi_editor/io/pr"
+	"ffvi_editor/models"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
 )
 
 // ExportFormat determines which parts of the save to export
@@ -23,15 +26,24 @@ const (
 	FormatEquipment  ExportFormat = "equipment"
 )
 
+// ExportFormats lists every export format
+var ExportFormats = []ExportFormat{
+	FormatFull, FormatCharacters, FormatInventory, FormatParty, FormatMagic, FormatEspers, FormatEquipment,
+}
+
+// ExportVersion is the version of the document layout the exporter writes.
+// Older versions are upgraded on import, see MigrateDocument.
+const ExportVersion = "2.0"
+
 // SaveExport represents exported save data in human-readable JSON format
 type SaveExport struct {
-	Format     string                     `json:"format"`
-	Version    string                     `json:"version"`
+	Format     string                     `json:"format,omitempty"`
+	Version    string                     `json:"version,omitempty"`
 	Characters []CharacterExport          `json:"characters,omitempty"`
 	Party      *PartyExport               `json:"party,omitempty"`
 	Inventory  *InventoryExport           `json:"inventory,omitempty"`
-	Equipment  map[string]EquipmentExport `json:"equipment,omitempty"`
-	Magic      map[string]MagicExport     `json:"magic,omitempty"`
+	Equipment  map[string]EquipmentExport `json:"equipment,omitempty"` // By character root name
+	Magic      map[string]MagicExport     `json:"magic,omitempty"`     // By character root name
 	Espers     *EsperExport               `json:"espers,omitempty"`
 	Metadata   ExportMetadata             `json:"metadata"`
 }
@@ -40,74 +52,96 @@ type SaveExport struct {
 type ExportMetadata struct {
 	ExportedAt string `json:"exportedAt"`
 	Format     string `json:"format"`
+	Schema     string `json:"schema,omitempty"` // $id of the schema the document follows, see SchemaID
 	Note       string `json:"note,omitempty"`
 }
 
+// Ref names a game object by its stable ID. The name is for readers;
+// importers go by the ID when both are given.
+type Ref struct {
+	ID   int    `json:"id" schema:"min=0"`
+	Name string `json:"name"`
+}
+
 // CharacterExport represents a character for export
 type CharacterExport struct {
-	Name     string     `json:"name"`
-	Level    uint8      `json:"level"`
-	HP       uint16     `json:"hp"`
-	MaxHP    uint16     `json:"maxHp"`
-	MP       uint8      `json:"mp"`
-	MaxMP    uint8      `json:"maxMp"`
-	Exp      uint32     `json:"exp"`
+	ID       int        `json:"id" schema:"min=0"`
+	RootName string     `json:"rootName"` // Character the data belongs to, e.g. Terra
+	Name     string     `json:"name"`     // Name given in the game
+	Enabled  bool       `json:"enabled"`
+	Level    int        `json:"level" schema:"min=1,max=99"`
+	Exp      int        `json:"exp" schema:"min=0,max=9999999"`
+	HP       int        `json:"hp" schema:"min=0,max=9999"`
+	MaxHP    int        `json:"maxHp" schema:"min=0,max=9999"`
+	MP       int        `json:"mp" schema:"min=0,max=999"`
+	MaxMP    int        `json:"maxMp" schema:"min=0,max=999"`
 	Stats    StatExport `json:"stats"`
-	Status   []string   `json:"status,omitempty"`
-	Commands []string   `json:"commands,omitempty"`
-	Espers   []string   `json:"espers,omitempty"`
-	Relic1   string     `json:"relic1,omitempty"`
-	Relic2   string     `json:"relic2,omitempty"`
-	RowState string     `json:"rowState,omitempty"`
+	Commands []Ref      `json:"commands,omitempty"`
+	Esper    *Ref       `json:"esper,omitempty"` // Equipped esper
 }
 
 // StatExport represents character stats for export
 type StatExport struct {
-	Vigor    uint8 `json:"vigor"`
-	Speed    uint8 `json:"speed"`
-	Stamina  uint8 `json:"stamina"`
-	MagicPwr uint8 `json:"magicPwr"`
-	Defense  uint8 `json:"defense"`
-	MagicDef uint8 `json:"magicDef"`
+	Vigor   int `json:"vigor" schema:"min=0,max=255"`
+	Speed   int `json:"speed" schema:"min=0,max=255"`
+	Stamina int `json:"stamina" schema:"min=0,max=255"`
+	Magic   int `json:"magic" schema:"min=0,max=255"`
 }
 
 // PartyExport represents party composition for export
 type PartyExport struct {
-	Members []string `json:"members"`
+	Members []PartyMemberExport `json:"members"`
+}
+
+// PartyMemberExport is one party slot; ID 0 is an empty slot
+type PartyMemberExport struct {
+	Slot int    `json:"slot" schema:"min=0,max=3,required"`
+	ID   int    `json:"id" schema:"min=0"`
+	Name string `json:"name,omitempty"`
 }
 
 // InventoryExport represents inventory for export
 type InventoryExport struct {
-	Items []ItemExport `json:"items"`
+	Items          []ItemExport `json:"items"`
+	ImportantItems []ItemExport `json:"importantItems,omitempty"`
 }
 
-// ItemExport represents a single item for export
+// ItemExport represents a single inventory row for export
 type ItemExport struct {
-	Name     string `json:"name"`
-	Quantity uint8  `json:"quantity"`
+	ID    int    `json:"id" schema:"min=0"`
+	Name  string `json:"name"`
+	Count int    `json:"count" schema:"min=0,max=99,required"`
 }
 
-// EquipmentExport represents equipment for export
+// EquipmentExport represents equipment for export. Empty slots hold the
+// game's empty item.
 type EquipmentExport struct {
 	Character string `json:"character"`
-	Weapon    string `json:"weapon,omitempty"`
-	Armor     string `json:"armor,omitempty"`
-	Shield    string `json:"shield,omitempty"`
-	Helmet    string `json:"helmet,omitempty"`
-	Relic1    string `json:"relic1,omitempty"`
-	Relic2    string `json:"relic2,omitempty"`
+	Weapon    Ref    `json:"weapon"`
+	Shield    Ref    `json:"shield"`
+	Helmet    Ref    `json:"helmet"`
+	Armor     Ref    `json:"armor"`
+	Relic1    Ref    `json:"relic1"`
+	Relic2    Ref    `json:"relic2"`
 }
 
-// MagicExport represents magic learned for export
+// MagicExport represents the spells a character knows or is learning
 type MagicExport struct {
-	Character string   `json:"character"`
-	Spells    []string `json:"spells"`
+	Character string        `json:"character"`
+	Spells    []SpellExport `json:"spells"`
+}
+
+// SpellExport is a spell with its learn percentage
+type SpellExport struct {
+	ID      int    `json:"id" schema:"min=0"`
+	Name    string `json:"name"`
+	Learned int    `json:"learned" schema:"min=0,max=100,required"`
 }
 
 // EsperExport represents esper information for export
 type EsperExport struct {
-	Unlocked []string          `json:"unlocked"`
-	Equipped map[string]string `json:"equipped"`
+	Owned    []Ref          `json:"owned"`
+	Equipped map[string]Ref `json:"equipped"` // By character root name
 }
 
 // Exporter handles exporting save data to JSON format
@@ -126,10 +160,11 @@ func NewExporter(pr *ipr.PR) *Exporter {
 func (e *Exporter) ExportToJSON(format ExportFormat) ([]byte, error) {
 	export := &SaveExport{
 		Format:  string(format),
-		Version: "1.0",
+		Version: ExportVersion,
 		Metadata: ExportMetadata{
 			ExportedAt: time.Now().Format(time.RFC3339),
 			Format:     string(format),
+			Schema:     SchemaID(ExportVersion),
 		},
 	}
 
@@ -215,52 +250,158 @@ func (e *Exporter) ExportToFile(format ExportFormat, filePath string) error {
 	return os.WriteFile(filePath, jsonBytes, 0644)
 }
 
-// populateCharacters adds character data to export
+// populateCharacters adds the characters present in the save
 func (e *Exporter) populateCharacters(export *SaveExport) error {
-	export.Characters = make([]CharacterExport, 0)
-
-	// Note: Full implementation would require parsing the PR OrderedMap structure
-	// For now, this is a placeholder for schema definition
-	// In production, this would extract character data from pr.Characters OrderedMaps
-
+	characters, err := e.loadedCharacters()
+	if err != nil {
+		return err
+	}
+	export.Characters = make([]CharacterExport, 0, len(characters))
+	for _, c := range characters {
+		ce := CharacterExport{
+			ID:       c.ID,
+			RootName: c.RootName,
+			Name:     c.Name,
+			Enabled:  c.IsEnabled,
+			Level:    c.Level,
+			Exp:      c.Exp,
+			HP:       c.HP.Current,
+			MaxHP:    c.HP.Max,
+			MP:       c.MP.Current,
+			MaxMP:    c.MP.Max,
+			Stats: StatExport{
+				Vigor:   c.Vigor,
+				Speed:   c.Speed,
+				Stamina: c.Stamina,
+				Magic:   c.Magic,
+			},
+			Commands: make([]Ref, 0, len(c.Commands)),
+		}
+		for _, cmd := range c.Commands {
+			if cmd != nil {
+				ce.Commands = append(ce.Commands, Ref{ID: cmd.Value, Name: cmd.Name})
+			}
+		}
+		if c.EsperID != 0 {
+			ce.Esper = &Ref{ID: c.EsperID, Name: ipr.EsperName(c.EsperID)}
+		}
+		export.Characters = append(export.Characters, ce)
+	}
 	return nil
 }
 
-// populateParty adds party data to export
+// populateParty adds the four party slots
 func (e *Exporter) populateParty(export *SaveExport) error {
-	partyExport := &PartyExport{
-		Members: make([]string, 0),
+	party := pri.GetParty()
+	export.Party = &PartyExport{
+		Members: make([]PartyMemberExport, len(party.Members)),
+	}
+	for i, m := range party.Members {
+		export.Party.Members[i] = PartyMemberExport{Slot: i}
+		if m != nil && m.CharacterID != 0 {
+			export.Party.Members[i].ID = m.CharacterID
+			export.Party.Members[i].Name = m.Name
+		}
 	}
-	export.Party = partyExport
 	return nil
 }
 
-// populateInventory adds inventory data to export
+// populateInventory adds the inventory and important items in row order
 func (e *Exporter) populateInventory(export *SaveExport) error {
-	inventoryExport := &InventoryExport{
-		Items: make([]ItemExport, 0),
+	export.Inventory = &InventoryExport{
+		Items:          exportRows(pri.GetInventory()),
+		ImportantItems: exportRows(pri.GetImportantInventory()),
 	}
-	export.Inventory = inventoryExport
 	return nil
 }
 
-// populateEquipment adds equipment data to export
+// populateEquipment adds each character's equipment
 func (e *Exporter) populateEquipment(export *SaveExport) error {
-	export.Equipment = make(map[string]EquipmentExport)
+	characters, err := e.loadedCharacters()
+	if err != nil {
+		return err
+	}
+	export.Equipment = make(map[string]EquipmentExport, len(characters))
+	for _, c := range characters {
+		eq := c.Equipment
+		export.Equipment[c.RootName] = EquipmentExport{
+			Character: c.RootName,
+			Weapon:    itemRef(eq.WeaponID),
+			Shield:    itemRef(eq.ShieldID),
+			Helmet:    itemRef(eq.HelmetID),
+			Armor:     itemRef(eq.ArmorID),
+			Relic1:    itemRef(eq.Relic1ID),
+			Relic2:    itemRef(eq.Relic2ID),
+		}
+	}
 	return nil
 }
 
-// populateMagic adds magic data to export
+// populateMagic adds the spells each character has started learning
 func (e *Exporter) populateMagic(export *SaveExport) error {
-	export.Magic = make(map[string]MagicExport)
+	characters, err := e.loadedCharacters()
+	if err != nil {
+		return err
+	}
+	export.Magic = make(map[string]MagicExport, len(characters))
+	for _, c := range characters {
+		me := MagicExport{
+			Character: c.RootName,
+			Spells:    make([]SpellExport, 0),
+		}
+		for _, s := range c.SpellsByIndex {
+			if s.Value > 0 {
+				me.Spells = append(me.Spells, SpellExport{ID: s.Index, Name: s.Name, Learned: s.Value})
+			}
+		}
+		export.Magic[c.RootName] = me
+	}
 	return nil
 }
 
-// populateEspers adds esper data to export
+// populateEspers adds the owned espers and who has them equipped
 func (e *Exporter) populateEspers(export *SaveExport) error {
+	characters, err := e.loadedCharacters()
+	if err != nil {
+		return err
+	}
 	export.Espers = &EsperExport{
-		Unlocked: make([]string, 0),
-		Equipped: make(map[string]string),
+		Owned:    make([]Ref, 0),
+		Equipped: make(map[string]Ref),
+	}
+	for _, esper := range pr.Espers {
+		if esper.Checked {
+			export.Espers.Owned = append(export.Espers.Owned, Ref{ID: esper.Value, Name: esper.Name})
+		}
+	}
+	for _, c := range characters {
+		if c.EsperID != 0 {
+			export.Espers.Equipped[c.RootName] = Ref{ID: c.EsperID, Name: ipr.EsperName(c.EsperID)}
+		}
 	}
 	return nil
 }
+
+// loadedCharacters returns the characters present in the save
+func (e *Exporter) loadedCharacters() ([]*models.Character, error) {
+	if e.prData == nil {
+		return nil, fmt.Errorf("no save data loaded")
+	}
+	return e.prData.LoadedCharacters(), nil
+}
+
+// exportRows returns the non-empty rows of an inventory
+func exportRows(inv *pri.Inventory) []ItemExport {
+	items := make([]ItemExport, 0)
+	for _, r := range inv.GetRows() {
+		if r != nil && r.ItemID != 0 {
+			items = append(items, ItemExport{ID: r.ItemID, Name: ipr.ItemName(r.ItemID), Count: r.Count})
+		}
+	}
+	return items
+}
+
+// itemRef names an item ID
+func itemRef(id int) Ref {
+	return Ref{ID: id, Name: ipr.ItemName(id)}
+}
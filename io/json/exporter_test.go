@@ -0,0 +1,118 @@
+package json
+
+import (
+	"encoding/json"
+	"testing"
+
+	"ffvi_editor/global"
+	ipr "ffvi_editor/io/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+const testSave = "../../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y="
+
+func loadSave(t *testing.T) *ipr.PR {
+	t.Helper()
+	p := ipr.New()
+	if err := p.Load(testSave, global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	return p
+}
+
+func exportFull(t *testing.T, p *ipr.PR) SaveExport {
+	t.Helper()
+	data, err := NewExporter(p).ExportToJSON(FormatFull)
+	if err != nil {
+		t.Fatalf("ExportToJSON failed: %v", err)
+	}
+	var export SaveExport
+	if err = json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("export isn't valid JSON: %v", err)
+	}
+	return export
+}
+
+func TestExportFull(t *testing.T) {
+	p := loadSave(t)
+	terra := pri.GetCharacter("Terra")
+	terra.EsperID = 62
+	terra.SpellsByIndex[0].Value = 40
+
+	export := exportFull(t, p)
+	if export.Version != ExportVersion || export.Format != string(FormatFull) {
+		t.Errorf("unexpected header %q %q", export.Format, export.Version)
+	}
+
+	if len(export.Characters) != len(p.LoadedCharacters()) {
+		t.Fatalf("exported %d characters, save has %d", len(export.Characters), len(p.LoadedCharacters()))
+	}
+	var ce *CharacterExport
+	for i := range export.Characters {
+		if export.Characters[i].RootName == "Terra" {
+			ce = &export.Characters[i]
+		}
+	}
+	if ce == nil {
+		t.Fatal("Terra missing from the export")
+	}
+	if ce.Level != terra.Level || ce.Exp != terra.Exp || ce.MaxHP != terra.HP.Max || ce.Stats.Vigor != terra.Vigor {
+		t.Errorf("Terra exported as %+v", ce)
+	}
+	if len(ce.Commands) != len(terra.Commands) || ce.Commands[0].ID != terra.Commands[0].Value {
+		t.Errorf("Terra's commands exported as %+v", ce.Commands)
+	}
+	if ce.Esper == nil || ce.Esper.Name != "Ramuh" || export.Espers.Equipped["Terra"].ID != 62 {
+		t.Errorf("Terra's esper exported as %+v / %+v", ce.Esper, export.Espers.Equipped)
+	}
+
+	eq := export.Equipment["Terra"]
+	if eq.Weapon.ID != terra.Equipment.WeaponID || eq.Weapon.Name != ipr.ItemName(terra.Equipment.WeaponID) {
+		t.Errorf("Terra's weapon exported as %+v", eq.Weapon)
+	}
+	spells := export.Magic["Terra"].Spells
+	first := terra.SpellsByIndex[0]
+	if len(spells) == 0 || spells[0].ID != first.Index || spells[0].Learned != 40 {
+		t.Errorf("Terra's spells exported as %+v", spells)
+	}
+
+	rows := 0
+	for _, r := range pri.GetInventory().GetRows() {
+		if r.ItemID != 0 {
+			rows++
+		}
+	}
+	if len(export.Inventory.Items) != rows {
+		t.Errorf("exported %d inventory rows, save has %d", len(export.Inventory.Items), rows)
+	}
+	if item := export.Inventory.Items[0]; item.Name != ipr.ItemName(item.ID) || item.Count == 0 {
+		t.Errorf("first item exported as %+v", item)
+	}
+
+	party := pri.GetParty()
+	if len(export.Party.Members) != len(party.Members) || export.Party.Members[0].ID != party.Members[0].CharacterID {
+		t.Errorf("party exported as %+v", export.Party.Members)
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	p := loadSave(t)
+	data, err := NewExporter(p).ExportToJSON(FormatInventory)
+	if err != nil {
+		t.Fatalf("ExportToJSON failed: %v", err)
+	}
+	var export SaveExport
+	if err = json.Unmarshal(data, &export); err != nil {
+		t.Fatal(err)
+	}
+	if export.Inventory == nil || export.Characters != nil || export.Party != nil {
+		t.Errorf("inventory export holds more than the inventory: %s", data)
+	}
+
+	if _, err = NewExporter(nil).ExportToJSON(FormatCharacters); err == nil {
+		t.Error("expected an error without save data")
+	}
+	if _, err = NewExporter(p).ExportToJSON("bogus"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
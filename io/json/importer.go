@@ -1,11 +1,16 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 
 	ipr "ffvi_editor/io/pr"
+	"ffvi_editor/models"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
 )
 
 // ImportError represents an error during import
@@ -21,10 +26,47 @@ func (e ImportError) Error() string {
 	return fmt.Sprintf("import error: %s", e.Message)
 }
 
+// ImportMode decides what happens to save data a document leaves out
+type ImportMode string
+
+const (
+	// ModeReplace makes each imported section match the document: inventory
+	// rows, spells, owned espers and party slots it doesn't list are cleared
+	ModeReplace ImportMode = "replace"
+	// ModeMerge writes every field of the entries the document lists and
+	// keeps the rest of the save
+	ModeMerge ImportMode = "merge"
+	// ModeOnlyListed is ModeMerge limited to the fields written out in the
+	// document, so a character with only a level sets only the level
+	ModeOnlyListed ImportMode = "only-listed-fields"
+)
+
+// ImportModes lists the modes in the order the UI offers them
+var ImportModes = []ImportMode{ModeMerge, ModeReplace, ModeOnlyListed}
+
+// ParseImportMode checks a mode name
+func ParseImportMode(s string) (ImportMode, error) {
+	for _, m := range ImportModes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown import mode %q (want replace, merge or only-listed-fields)", s)
+}
+
+// ImportOptions controls how a document is applied
+type ImportOptions struct {
+	Mode ImportMode
+	// DryRun reports the changes without keeping them
+	DryRun bool
+}
+
 // Importer handles importing JSON data into save data
 type Importer struct {
 	prData *ipr.PR
 	errors []ImportError
+	mode   ImportMode
+	listed map[string]bool // Document paths present in the JSON, see listFields
 }
 
 // NewImporter creates a new importer for the given save data
@@ -32,94 +74,118 @@ func NewImporter(pr *ipr.PR) *Importer {
 	return &Importer{
 		prData: pr,
 		errors: make([]ImportError, 0),
+		mode:   ModeMerge,
 	}
 }
 
-// ImportFromJSON imports save data from JSON bytes
+// ImportFromJSON imports save data from JSON bytes in merge mode. Field
+// errors don't stop the import; see GetErrors.
 func (i *Importer) ImportFromJSON(jsonBytes []byte, format ExportFormat) error {
+	_, err := i.Import(jsonBytes, format, ImportOptions{Mode: ModeMerge})
+	return err
+}
+
+// ImportFromFile imports save data from a JSON file
+func (i *Importer) ImportFromFile(filePath string, format ExportFormat) error {
+	jsonBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read JSON file: %w", err)
+	}
+
+	return i.ImportFromJSON(jsonBytes, format)
+}
+
+// Import applies the sections of a document selected by format and returns
+// what changed. Older export versions are upgraded first, and a document that
+// doesn't match the export schema fails with a *SchemaError before anything
+// is applied. Names are resolved to IDs where no ID is given. A field that
+// can't be applied is recorded as an ImportError and skipped. A dry run
+// restores the save after taking the report.
+func (i *Importer) Import(jsonBytes []byte, format ExportFormat, opts ImportOptions) (*ipr.DiffReport, error) {
+	if i.prData == nil {
+		return nil, fmt.Errorf("no save data loaded")
+	}
+	if opts.Mode == "" {
+		opts.Mode = ModeMerge
+	} else if _, err := ParseImportMode(string(opts.Mode)); err != nil {
+		return nil, err
+	}
+
+	var raw interface{}
+	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	doc, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("document must be a JSON object, got %s", jsonType(raw))
+	}
+	dropped, err := MigrateDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	if violations := ExportSchema().Validate(doc); len(violations) > 0 {
+		return nil, &SchemaError{Violations: violations}
+	}
+
+	// The checked document can't fail to decode
 	var export SaveExport
-	if err := json.Unmarshal(jsonBytes, &export); err != nil {
-		return fmt.Errorf("failed to parse JSON: %w", err)
+	migrated, err := json.Marshal(doc)
+	if err == nil {
+		err = json.Unmarshal(migrated, &export)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
-	i.errors = make([]ImportError, 0)
+	i.errors = append(make([]ImportError, 0), dropped...)
+	i.mode = opts.Mode
+	i.listed = make(map[string]bool)
+	listFields(i.listed, "", doc)
+
+	var state *ipr.ModelState
+	if opts.DryRun {
+		state = ipr.SaveModelState()
+	}
+	before := ipr.TakeSnapshot()
 
 	switch format {
 	case FormatFull:
-		if err := i.importCharacters(export.Characters); err != nil {
-			return err
-		}
-		if err := i.importParty(export.Party); err != nil {
-			return err
-		}
-		if err := i.importInventory(export.Inventory); err != nil {
-			return err
-		}
-		if err := i.importEquipment(export.Equipment); err != nil {
-			return err
-		}
-		if err := i.importMagic(export.Magic); err != nil {
-			return err
-		}
-		if err := i.importEspers(export.Espers); err != nil {
-			return err
-		}
+		i.importCharacters(export.Characters)
+		i.importParty(export.Party)
+		i.importInventory(export.Inventory)
+		i.importEquipment(export.Equipment)
+		i.importMagic(export.Magic)
+		i.importEspers(export.Espers)
 
 	case FormatCharacters:
-		if err := i.importCharacters(export.Characters); err != nil {
-			return err
-		}
+		i.importCharacters(export.Characters)
 
 	case FormatInventory:
-		if err := i.importInventory(export.Inventory); err != nil {
-			return err
-		}
+		i.importInventory(export.Inventory)
 
 	case FormatParty:
-		if err := i.importParty(export.Party); err != nil {
-			return err
-		}
+		i.importParty(export.Party)
 
 	case FormatMagic:
-		if err := i.importMagic(export.Magic); err != nil {
-			return err
-		}
+		i.importMagic(export.Magic)
 
 	case FormatEspers:
-		if err := i.importEspers(export.Espers); err != nil {
-			return err
-		}
+		i.importEspers(export.Espers)
 
 	case FormatEquipment:
-		if err := i.importEquipment(export.Equipment); err != nil {
-			return err
-		}
+		i.importEquipment(export.Equipment)
 
 	default:
-		return fmt.Errorf("unknown import format: %s", format)
+		return nil, fmt.Errorf("unknown import format: %s", format)
 	}
 
-	// Return errors if any occurred
-	if len(i.errors) > 0 {
-		errMsg := "import completed with errors:\n"
-		for _, err := range i.errors {
-			errMsg += fmt.Sprintf("  - %s\n", err.Error())
-		}
-		// Log errors but don't fail import
-		fmt.Println(errMsg)
-	}
-
-	return nil
-}
-
-// ImportFromFile imports save data from a JSON file
-func (i *Importer) ImportFromFile(filePath string, format ExportFormat) error {
-	jsonBytes, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read JSON file: %w", err)
+	report := ipr.NewComparator(before, ipr.TakeSnapshot()).Compare()
+	if state != nil {
+		state.Restore()
 	}
-
-	return i.ImportFromJSON(jsonBytes, format)
+	return &report, nil
 }
 
 // GetErrors returns any errors that occurred during import
@@ -128,58 +194,305 @@ func (i *Importer) GetErrors() []ImportError {
 }
 
 // importCharacters applies character data from export
-func (i *Importer) importCharacters(characters []CharacterExport) error {
-	if len(characters) == 0 {
-		return nil
+func (i *Importer) importCharacters(characters []CharacterExport) {
+	for n, ce := range characters {
+		doc := "characters/" + strconv.Itoa(n)
+		c, err := i.findCharacter(ce.RootName, ce.ID, ce.Name)
+		if err != nil {
+			i.addError(doc, err.Error())
+			continue
+		}
+		base := ipr.JoinPath(ipr.PathCharacters, c.RootName)
+
+		for _, f := range []struct {
+			key   string
+			field string
+			value interface{}
+		}{
+			{"name", "name", ce.Name},
+			{"enabled", "enabled", ce.Enabled},
+			{"level", "level", ce.Level},
+			{"exp", "exp", ce.Exp},
+			{"maxHp", "maxHp", ce.MaxHP},
+			{"hp", "hp", ce.HP},
+			{"maxMp", "maxMp", ce.MaxMP},
+			{"mp", "mp", ce.MP},
+			{"stats/vigor", "vigor", ce.Stats.Vigor},
+			{"stats/speed", "speed", ce.Stats.Speed},
+			{"stats/stamina", "stamina", ce.Stats.Stamina},
+			{"stats/magic", "magic", ce.Stats.Magic},
+		} {
+			if f.key == "name" && ce.Name == "" {
+				// The name also identifies the character; don't blank it
+				continue
+			}
+			if i.has(doc + "/" + f.key) {
+				i.setPath(base+"/"+f.field, f.value)
+			}
+		}
+
+		if i.has(doc + "/commands") {
+			for slot, cmd := range ce.Commands {
+				if cmd.ID == 0 && cmd.Name == "" {
+					continue
+				}
+				i.setPath(base+"/commands/"+strconv.Itoa(slot), refValue(cmd))
+			}
+		}
+		if i.has(doc + "/esper") {
+			if ce.Esper == nil {
+				i.setPath(base+"/esper", 0)
+			} else {
+				i.setPath(base+"/esper", refValue(*ce.Esper))
+			}
+		} else if i.mode == ModeReplace {
+			i.setPath(base+"/esper", 0)
+		}
 	}
-	// Note: Full implementation would require modifying PR OrderedMap structure
-	// For now, this is a placeholder for schema definition
-	return nil
 }
 
 // importParty applies party data from export
-func (i *Importer) importParty(party *PartyExport) error {
-	if party == nil || len(party.Members) == 0 {
-		return nil
+func (i *Importer) importParty(party *PartyExport) {
+	if party == nil {
+		return
+	}
+	p := pri.GetParty()
+	listed := make(map[int]bool)
+	for n, m := range party.Members {
+		field := ipr.JoinPath(ipr.PathParty, strconv.Itoa(m.Slot))
+		if m.Slot < 0 || m.Slot >= len(p.Members) {
+			i.addError("party/members/"+strconv.Itoa(n), fmt.Sprintf("invalid party slot %d", m.Slot))
+			continue
+		}
+		listed[m.Slot] = true
+		switch {
+		case m.ID != 0:
+			if err := p.SetMemberByID(m.Slot, m.ID); err != nil {
+				i.addError(field, err.Error())
+				continue
+			}
+			p.Enabled = true
+		case m.Name != "":
+			i.setPath(field, m.Name)
+		default:
+			i.removePath(field)
+		}
+	}
+	if i.mode == ModeReplace {
+		for slot := range p.Members {
+			if !listed[slot] {
+				i.removePath(ipr.JoinPath(ipr.PathParty, strconv.Itoa(slot)))
+			}
+		}
 	}
-	// Note: Placeholder for party import logic
-	return nil
 }
 
 // importInventory applies inventory data from export
-func (i *Importer) importInventory(inventory *InventoryExport) error {
-	if inventory == nil || len(inventory.Items) == 0 {
-		return nil
+func (i *Importer) importInventory(inventory *InventoryExport) {
+	if inventory == nil {
+		return
+	}
+	i.importRows(ipr.PathInventory, pri.GetInventory(), pr.ItemsByName, pr.ItemsByID, inventory.Items)
+	if inventory.ImportantItems != nil || i.mode == ModeReplace {
+		i.importRows(ipr.PathImportantItems, pri.GetImportantInventory(), pr.ImportantItemsByName, pr.ImportantItemsByID, inventory.ImportantItems)
+	}
+}
+
+// importRows sets the counts of the listed items. Replace mode rebuilds the
+// inventory with the listed rows in document order.
+func (i *Importer) importRows(category string, inv *pri.Inventory, byName map[string]int, byID map[int]string, items []ItemExport) {
+	rows := make([]pri.Row, 0, len(items))
+	for n, item := range items {
+		doc := category + "/" + strconv.Itoa(n)
+		id, err := ipr.ResolveItemID(refValue(Ref{ID: item.ID, Name: item.Name}), byName, byID)
+		if err != nil {
+			i.addError(doc, err.Error())
+			continue
+		}
+		if item.Count < 0 || item.Count > pri.MaxItemCount {
+			i.addError(ipr.JoinPath(category, ipr.ItemName(id)), fmt.Sprintf("count %d out of range 0-%d", item.Count, pri.MaxItemCount))
+			continue
+		}
+		rows = append(rows, pri.Row{ItemID: id, Count: item.Count})
+	}
+
+	if i.mode != ModeReplace {
+		for _, r := range rows {
+			i.setPath(ipr.JoinPath(category, strconv.Itoa(r.ItemID)), r.Count)
+		}
+		return
+	}
+
+	if len(rows) > inv.Size {
+		i.addError(category, fmt.Sprintf("%d rows don't fit in %d slots; the rest were dropped", len(rows), inv.Size))
+		rows = rows[:inv.Size]
+	}
+	inv.Reset()
+	for n, r := range rows {
+		inv.Set(n, r)
 	}
-	// Note: Placeholder for inventory import logic
-	return nil
 }
 
 // importEquipment applies equipment data from export
-func (i *Importer) importEquipment(equipment map[string]EquipmentExport) error {
-	if len(equipment) == 0 {
-		return nil
+func (i *Importer) importEquipment(equipment map[string]EquipmentExport) {
+	for key, ee := range equipment {
+		doc := "equipment/" + key
+		name := ee.Character
+		if name == "" {
+			name = key
+		}
+		c, err := i.findCharacter(name, 0, "")
+		if err != nil {
+			i.addError(doc, err.Error())
+			continue
+		}
+		base := ipr.JoinPath(ipr.PathCharacters, c.RootName, "equipment")
+		for _, slot := range []struct {
+			name string
+			ref  Ref
+		}{
+			{"weapon", ee.Weapon},
+			{"shield", ee.Shield},
+			{"helmet", ee.Helmet},
+			{"armor", ee.Armor},
+			{"relic1", ee.Relic1},
+			{"relic2", ee.Relic2},
+		} {
+			if !i.has(doc + "/" + slot.name) {
+				continue
+			}
+			if slot.ref.ID == 0 && slot.ref.Name == "" {
+				i.removePath(base + "/" + slot.name)
+			} else {
+				i.setPath(base+"/"+slot.name, refValue(slot.ref))
+			}
+		}
 	}
-	// Note: Placeholder for equipment import logic
-	return nil
 }
 
 // importMagic applies magic data from export
-func (i *Importer) importMagic(magic map[string]MagicExport) error {
-	if len(magic) == 0 {
-		return nil
+func (i *Importer) importMagic(magic map[string]MagicExport) {
+	for key, me := range magic {
+		doc := "magic/" + key
+		name := me.Character
+		if name == "" {
+			name = key
+		}
+		c, err := i.findCharacter(name, 0, "")
+		if err != nil {
+			i.addError(doc, err.Error())
+			continue
+		}
+		base := ipr.JoinPath(ipr.PathCharacters, c.RootName, "spells")
+
+		listed := make(map[int]bool)
+		for n, s := range me.Spells {
+			spell := findSpell(c, s)
+			if spell == nil {
+				i.addError(doc+"/spells/"+strconv.Itoa(n), fmt.Sprintf("unknown spell %s", describeRef(Ref{ID: s.ID, Name: s.Name})))
+				continue
+			}
+			listed[spell.Index] = true
+			i.setPath(base+"/"+strconv.Itoa(spell.Index), s.Learned)
+		}
+		if i.mode == ModeReplace {
+			for _, spell := range c.SpellsByIndex {
+				if !listed[spell.Index] && spell.Value != 0 {
+					i.setPath(base+"/"+strconv.Itoa(spell.Index), 0)
+				}
+			}
+		}
 	}
-	fmt.Printf("Magic import requested for %d characters\n", len(magic))
-	return nil
 }
 
 // importEspers applies esper data from export
-func (i *Importer) importEspers(espers *EsperExport) error {
+func (i *Importer) importEspers(espers *EsperExport) {
 	if espers == nil {
-		return nil
+		return
+	}
+
+	owned := make(map[int]bool)
+	for n, ref := range espers.Owned {
+		id, err := ipr.ResolveEsperID(refValue(ref))
+		if err != nil || id == 0 {
+			i.addError("espers/owned/"+strconv.Itoa(n), fmt.Sprintf("unknown esper %s", describeRef(ref)))
+			continue
+		}
+		owned[id] = true
+		i.setPath(ipr.JoinPath(ipr.PathEspers, strconv.Itoa(id)), true)
+	}
+	if i.mode == ModeReplace {
+		for _, e := range pr.Espers {
+			if e.Checked && !owned[e.Value] {
+				i.removePath(ipr.JoinPath(ipr.PathEspers, strconv.Itoa(e.Value)))
+			}
+		}
+	}
+
+	equipped := make(map[*models.Character]bool)
+	for key, ref := range espers.Equipped {
+		c, err := i.findCharacter(key, 0, "")
+		if err != nil {
+			i.addError("espers/equipped/"+key, err.Error())
+			continue
+		}
+		equipped[c] = true
+		i.setPath(ipr.JoinPath(ipr.PathCharacters, c.RootName, "esper"), refValue(ref))
+	}
+	if i.mode == ModeReplace {
+		for _, c := range i.prData.LoadedCharacters() {
+			if !equipped[c] && c.EsperID != 0 {
+				i.setPath(ipr.JoinPath(ipr.PathCharacters, c.RootName, "esper"), 0)
+			}
+		}
+	}
+}
+
+// findCharacter finds a character of the save by root name, then ID, then
+// the name given in the game
+func (i *Importer) findCharacter(rootName string, id int, name string) (*models.Character, error) {
+	var c *models.Character
+	switch {
+	case rootName != "":
+		c = ipr.FindCharacter(rootName)
+	case id != 0:
+		if c = pri.GetCharacterByID(id); c != nil && c.ID != id {
+			c = nil
+		}
+	case name != "":
+		c = ipr.FindCharacter(name)
+	default:
+		return nil, fmt.Errorf("character has no rootName, id or name")
+	}
+	if c == nil {
+		return nil, fmt.Errorf("unknown character %s", describeRef(Ref{ID: id, Name: rootName + name}))
+	}
+	for _, loaded := range i.prData.LoadedCharacters() {
+		if loaded == c {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%s isn't in this save", c.RootName)
+}
+
+// has reports whether a document field should be applied: every field of a
+// listed entry, or only those written in the JSON for ModeOnlyListed
+func (i *Importer) has(docPath string) bool {
+	return i.mode != ModeOnlyListed || i.listed[docPath]
+}
+
+// setPath writes a save path, recording a failure as an error for the path
+func (i *Importer) setPath(path string, value interface{}) {
+	if err := ipr.SetPath(path, value); err != nil {
+		i.addError(path, unwrapPathError(path, err))
+	}
+}
+
+// removePath clears a save path, recording a failure as an error for the path
+func (i *Importer) removePath(path string) {
+	if err := ipr.RemovePath(path); err != nil {
+		i.addError(path, unwrapPathError(path, err))
 	}
-	fmt.Printf("Esper import requested with %d unlocked espers\n", len(espers.Unlocked))
-	return nil
 }
 
 // addError adds an import error to the error list
@@ -189,3 +502,71 @@ func (i *Importer) addError(field string, message string) {
 		Message: message,
 	})
 }
+
+// unwrapPathError drops the path prefix the path resolver puts on errors,
+// since ImportError already names the field
+func unwrapPathError(path string, err error) string {
+	msg := err.Error()
+	if prefix := path + ": "; len(msg) > len(prefix) && msg[:len(prefix)] == prefix {
+		return msg[len(prefix):]
+	}
+	return msg
+}
+
+// refValue is the value the save paths resolve for a reference: the ID when
+// set, else the name
+func refValue(r Ref) interface{} {
+	if r.ID != 0 {
+		return r.ID
+	}
+	return r.Name
+}
+
+// describeRef names a reference in errors
+func describeRef(r Ref) string {
+	switch {
+	case r.ID != 0 && r.Name != "":
+		return fmt.Sprintf("%q (%d)", r.Name, r.ID)
+	case r.ID != 0:
+		return strconv.Itoa(r.ID)
+	}
+	return strconv.Quote(r.Name)
+}
+
+// findSpell resolves a spell by ID, then name
+func findSpell(c *models.Character, s SpellExport) *models.Spell {
+	if s.ID != 0 {
+		if spell, ok := c.SpellsByID[s.ID]; ok {
+			return spell
+		}
+		return nil
+	}
+	for _, spell := range c.SpellsByIndex {
+		if spell.Name == s.Name {
+			return spell
+		}
+	}
+	return nil
+}
+
+// listFields records the path of every object key and array element of a
+// decoded document, e.g. "characters/0/stats/vigor" or "equipment/Terra/weapon"
+func listFields(listed map[string]bool, prefix string, v interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			p := k
+			if prefix != "" {
+				p = prefix + "/" + k
+			}
+			listed[p] = true
+			listFields(listed, p, child)
+		}
+	case []interface{}:
+		for n, child := range t {
+			p := prefix + "/" + strconv.Itoa(n)
+			listed[p] = true
+			listFields(listed, p, child)
+		}
+	}
+}
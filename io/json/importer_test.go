@@ -0,0 +1,121 @@
+package json
+
+import (
+	"encoding/json"
+	"testing"
+
+	ipr "ffvi_editor/io/pr"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+func TestImportRoundTrip(t *testing.T) {
+	p := loadSave(t)
+	data, err := NewExporter(p).ExportToJSON(FormatFull)
+	if err != nil {
+		t.Fatalf("ExportToJSON failed: %v", err)
+	}
+
+	terra := pri.GetCharacter("Terra")
+	level, vigor := terra.Level, terra.Vigor
+	terra.Level, terra.Vigor = 1, 1
+	pri.GetInventory().Reset()
+
+	i := NewImporter(p)
+	report, err := i.Import(data, FormatFull, ImportOptions{Mode: ModeReplace})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	// The fixture holds an item missing from the item tables, which can't be
+	// imported back
+	for _, e := range i.GetErrors() {
+		if e.Message != "unknown item ID 197" {
+			t.Errorf("unexpected import error: %v", e)
+		}
+	}
+	if terra.Level != level || terra.Vigor != vigor {
+		t.Errorf("Terra imported as level %d vigor %d, want %d %d", terra.Level, terra.Vigor, level, vigor)
+	}
+	if report.Statistics.TotalDiffs == 0 {
+		t.Error("the report should list the restored fields")
+	}
+
+	// Importing the same document again changes nothing
+	if report, _ = i.Import(data, FormatFull, ImportOptions{Mode: ModeReplace}); report.Statistics.TotalDiffs != 0 {
+		t.Errorf("re-import changed %d fields: %+v", report.Statistics.TotalDiffs, report.Diffs)
+	}
+}
+
+func TestImportModes(t *testing.T) {
+	p := loadSave(t)
+	terra := pri.GetCharacter("Terra")
+	level, vigor, hp := terra.Level, terra.Vigor, terra.HP.Max
+	potion := pr.ItemsByName["Potion"]
+
+	doc := `{"characters": [{"rootName": "Terra", "level": 50, "stats": {"vigor": 60}}],
+		"inventory": {"items": [{"name": "Potion", "count": 42}, {"name": "No Such Item", "count": 1}]}}`
+
+	// A dry run reports without changing anything
+	i := NewImporter(p)
+	report, err := i.Import([]byte(doc), FormatFull, ImportOptions{Mode: ModeOnlyListed, DryRun: true})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if terra.Level != level || pri.GetInventory().GetItemLookup()[potion] == 42 {
+		t.Error("a dry run changed the save")
+	}
+	found := false
+	for _, d := range report.Diffs {
+		if d.Path == "characters/Terra/level" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("the dry run should report the level change: %+v", report.Diffs)
+	}
+
+	// Only the listed fields are written; unknown items are reported
+	if _, err = i.Import([]byte(doc), FormatFull, ImportOptions{Mode: ModeOnlyListed}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if terra.Level != 50 || terra.Vigor != 60 || terra.HP.Max != hp {
+		t.Errorf("Terra imported as level %d vigor %d maxHp %d", terra.Level, terra.Vigor, terra.HP.Max)
+	}
+	if pri.GetInventory().GetItemLookup()[potion] != 42 {
+		t.Error("Potion count wasn't imported")
+	}
+	if errs := i.GetErrors(); len(errs) != 1 || errs[0].Field != "inventory/1" {
+		t.Errorf("expected one error for the unknown item, got %v", errs)
+	}
+
+	// Merge writes every field of the entry, so the missing vigor is zeroed
+	terra.Level, terra.Vigor = level, vigor
+	doc = `{"characters": [{"rootName": "Terra", "level": 50, "maxHp": 500}]}`
+	if _, err = i.Import([]byte(doc), FormatCharacters, ImportOptions{Mode: ModeMerge}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if terra.Level != 50 || terra.Vigor != 0 || terra.HP.Max != 500 {
+		t.Errorf("merge should write every field, got level %d vigor %d maxHp %d", terra.Level, terra.Vigor, terra.HP.Max)
+	}
+}
+
+func TestImportReplaceInventory(t *testing.T) {
+	p := loadSave(t)
+	export := SaveExport{Inventory: &InventoryExport{Items: []ItemExport{
+		{Name: "Potion", Count: 3},
+		{ID: pr.ItemsByName["Ether"], Count: 2},
+	}}}
+	data, _ := json.Marshal(export)
+
+	i := NewImporter(p)
+	if _, err := i.Import(data, FormatInventory, ImportOptions{Mode: ModeReplace}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	rows := pri.GetInventory().GetRowsForPrSave()
+	if len(rows) != 2 || rows[0].ItemID != pr.ItemsByName["Potion"] || rows[1].Count != 2 {
+		t.Errorf("inventory replaced with %+v", rows)
+	}
+	if got, _ := ipr.GetPath("inventory/Ether"); got != 2 {
+		t.Errorf("Ether count is %v", got)
+	}
+}
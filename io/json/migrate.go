@@ -0,0 +1,223 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// migration upgrades a decoded document from one export version to the
+// next. It reports the fields holding data the newer layout can't carry.
+type migration struct {
+	from, to string
+	apply    func(doc map[string]interface{}, dropped func(path, reason string))
+}
+
+// migrations are applied in order until the document is at ExportVersion
+var migrations = []migration{
+	{from: "1.0", to: "2.0", apply: migrate1To2},
+}
+
+// SupportedVersions lists the export versions that can be imported
+func SupportedVersions() []string {
+	versions := make([]string, 0, len(migrations)+1)
+	for _, m := range migrations {
+		versions = append(versions, m.from)
+	}
+	return append(versions, ExportVersion)
+}
+
+// MigrateDocument upgrades a document decoded with json.Decoder.UseNumber to
+// ExportVersion in place. A document without a version is taken to be
+// current. Fields that were dropped because they held data the current
+// layout has no place for are returned as import errors.
+func MigrateDocument(doc map[string]interface{}) ([]ImportError, error) {
+	version, ok := doc["version"].(string)
+	if v, found := doc["version"]; !found || v == "" {
+		doc["version"] = ExportVersion
+		return nil, nil
+	} else if !ok {
+		return nil, fmt.Errorf("version must be a string")
+	}
+
+	var dropped []ImportError
+	for _, m := range migrations {
+		if version != m.from {
+			continue
+		}
+		m.apply(doc, func(path, reason string) {
+			dropped = append(dropped, ImportError{
+				Field:   path,
+				Message: fmt.Sprintf("dropped when upgrading from %s to %s: %s", m.from, m.to, reason),
+			})
+		})
+		version = m.to
+		doc["version"] = version
+		if meta, ok := doc["metadata"].(map[string]interface{}); ok {
+			meta["schema"] = SchemaID(version)
+		}
+	}
+	if version != ExportVersion {
+		return nil, fmt.Errorf("unsupported export version %q (supported: %s)", version, strings.Join(SupportedVersions(), ", "))
+	}
+	return dropped, nil
+}
+
+// migrate1To2 moves 1.0 documents, which named everything by display name,
+// to the ID based 2.0 layout. Names are kept so the importer can resolve them.
+func migrate1To2(doc map[string]interface{}, dropped func(path, reason string)) {
+	for n, v := range asList(doc["characters"]) {
+		c, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := "characters/" + strconv.Itoa(n)
+		if stats, ok := c["stats"].(map[string]interface{}); ok {
+			if magic, found := stats["magicPwr"]; found {
+				if _, taken := stats["magic"]; !taken {
+					stats["magic"] = magic
+				}
+				delete(stats, "magicPwr")
+			}
+			for _, key := range []string{"defense", "magicDef"} {
+				dropField(stats, key, path+"/stats", "derived from equipment in the game", dropped)
+			}
+		}
+		if commands, found := c["commands"]; found {
+			c["commands"] = namesToRefs(commands)
+		}
+		if espers := asList(c["espers"]); len(espers) > 0 {
+			c["esper"] = nameRef(espers[0])
+			if len(espers) > 1 {
+				dropped(path+"/espers", "a character equips one esper; kept the first")
+			}
+		}
+		delete(c, "espers")
+		dropField(c, "status", path, "status effects aren't exported", dropped)
+		dropField(c, "rowState", path, "the battle row isn't exported", dropped)
+		dropField(c, "relic1", path, "relics are imported through equipment", dropped)
+		dropField(c, "relic2", path, "relics are imported through equipment", dropped)
+	}
+
+	if party, ok := doc["party"].(map[string]interface{}); ok {
+		members := asList(party["members"])
+		for slot, v := range members {
+			m := map[string]interface{}{"slot": json.Number(strconv.Itoa(slot))}
+			if name, ok := v.(string); ok && name != "" {
+				m["name"] = name
+			}
+			members[slot] = m
+		}
+	}
+
+	if inv, ok := doc["inventory"].(map[string]interface{}); ok {
+		for _, v := range asList(inv["items"]) {
+			if item, ok := v.(map[string]interface{}); ok {
+				if count, found := item["quantity"]; found {
+					item["count"] = count
+					delete(item, "quantity")
+				}
+			}
+		}
+	}
+
+	for _, v := range asMap(doc["equipment"]) {
+		if eq, ok := v.(map[string]interface{}); ok {
+			for _, slot := range []string{"weapon", "shield", "helmet", "armor", "relic1", "relic2"} {
+				if name, found := eq[slot]; found {
+					eq[slot] = nameRef(name)
+				}
+			}
+		}
+	}
+
+	for _, v := range asMap(doc["magic"]) {
+		if magic, ok := v.(map[string]interface{}); ok {
+			if _, found := magic["spells"]; !found {
+				continue
+			}
+			spells := namesToRefs(magic["spells"])
+			for _, s := range asList(spells) {
+				if ref, ok := s.(map[string]interface{}); ok {
+					ref["learned"] = json.Number("100")
+				}
+			}
+			magic["spells"] = spells
+		}
+	}
+
+	if espers, ok := doc["espers"].(map[string]interface{}); ok {
+		if unlocked, found := espers["unlocked"]; found {
+			espers["owned"] = namesToRefs(unlocked)
+			delete(espers, "unlocked")
+		}
+		equipped := asMap(espers["equipped"])
+		for name, v := range equipped {
+			equipped[name] = nameRef(v)
+		}
+	}
+}
+
+// dropField removes a field, reporting it when it held data
+func dropField(obj map[string]interface{}, key, path, reason string, dropped func(path, reason string)) {
+	v, found := obj[key]
+	if !found {
+		return
+	}
+	delete(obj, key)
+	if !isEmptyValue(v) {
+		dropped(path+"/"+key, reason)
+	}
+}
+
+// namesToRefs turns a list of names into a list of {"name": ...} references
+func namesToRefs(v interface{}) interface{} {
+	list := asList(v)
+	if list == nil {
+		return v
+	}
+	for n, name := range list {
+		list[n] = nameRef(name)
+	}
+	return list
+}
+
+// nameRef turns a name into a {"name": ...} reference, leaving other values
+// for the schema check to report
+func nameRef(v interface{}) interface{} {
+	if name, ok := v.(string); ok {
+		return map[string]interface{}{"name": name}
+	}
+	return v
+}
+
+func asList(v interface{}) []interface{} {
+	list, _ := v.([]interface{})
+	return list
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+// isEmptyValue reports whether a decoded value is null, zero, false or empty
+func isEmptyValue(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !t
+	case string:
+		return t == ""
+	case json.Number:
+		f, err := t.Float64()
+		return err == nil && f == 0
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package json
+
+import (
+	"testing"
+
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+func TestImportVersion1(t *testing.T) {
+	p := loadSave(t)
+	terra := pri.GetCharacter("Terra")
+	terra.EsperID = 0
+	fire := terra.SpellsByIndex[0]
+	fire.Value = 0
+
+	doc := `{"format": "full", "version": "1.0",
+		"characters": [{"name": "Terra", "level": 30, "stats": {"vigor": 31, "magicPwr": 40, "defense": 12, "magicDef": 0},
+			"espers": ["Ramuh"], "relic1": ""}],
+		"inventory": {"items": [{"name": "Potion", "quantity": 7}]},
+		"magic": {"Terra": {"character": "Terra", "spells": ["` + fire.Name + `"]}},
+		"espers": {"unlocked": ["Ramuh"], "equipped": {}},
+		"metadata": {"exportedAt": "", "format": "full"}}`
+
+	i := NewImporter(p)
+	if _, err := i.Import([]byte(doc), FormatFull, ImportOptions{Mode: ModeOnlyListed}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if terra.Level != 30 || terra.Vigor != 31 || terra.Magic != 40 {
+		t.Errorf("Terra imported as level %d vigor %d magic %d", terra.Level, terra.Vigor, terra.Magic)
+	}
+	if terra.EsperID != 62 || !pr.EspersByValue[62].Checked {
+		t.Errorf("Ramuh not owned and equipped: %d", terra.EsperID)
+	}
+	if fire.Value != 100 {
+		t.Errorf("%s learned %d%%, want 100", fire.Name, fire.Value)
+	}
+	if pri.GetInventory().GetItemLookup()[pr.ItemsByName["Potion"]] != 7 {
+		t.Error("Potion quantity wasn't imported")
+	}
+
+	// Only the dropped field that held data is reported
+	errs := i.GetErrors()
+	if len(errs) != 1 || errs[0].Field != "characters/0/stats/defense" {
+		t.Errorf("expected the defense to be reported, got %v", errs)
+	}
+
+	if _, err := i.Import([]byte(`{"version": "0.9"}`), FormatFull, ImportOptions{}); err == nil {
+		t.Error("expected an unsupported version to fail")
+	}
+}
@@ -0,0 +1,310 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SchemaDraft is the JSON Schema dialect of the export schema
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaID returns the $id of the schema for an export version. Exports name
+// it in their metadata.
+func SchemaID(version string) string {
+	return "urn:ffvi-editor:export:" + version
+}
+
+// Schema is the subset of JSON Schema the export schema is written in
+type Schema struct {
+	Draft      string // $schema
+	ID         string // $id
+	Title      string
+	Type       string
+	Properties map[string]*Schema
+	Required   []string
+	Items      *Schema
+	Enum       []string
+	Minimum    *int
+	Maximum    *int
+	// AdditionalProperties is the schema of map values; objects built from
+	// structs are Closed and reject unknown fields instead
+	AdditionalProperties *Schema
+	Closed               bool
+	// Nullable also accepts null, for fields the exporter may leave out
+	Nullable bool
+}
+
+// MarshalJSON writes Nullable as a type list and Closed as
+// additionalProperties false
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Draft                string             `json:"$schema,omitempty"`
+		ID                   string             `json:"$id,omitempty"`
+		Title                string             `json:"title,omitempty"`
+		Type                 interface{}        `json:"type,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		Enum                 []string           `json:"enum,omitempty"`
+		Minimum              *int               `json:"minimum,omitempty"`
+		Maximum              *int               `json:"maximum,omitempty"`
+	}{
+		Draft:      s.Draft,
+		ID:         s.ID,
+		Title:      s.Title,
+		Properties: s.Properties,
+		Required:   s.Required,
+		Items:      s.Items,
+		Enum:       s.Enum,
+		Minimum:    s.Minimum,
+		Maximum:    s.Maximum,
+	}
+	if s.Type != "" {
+		out.Type = s.Type
+		if s.Nullable {
+			out.Type = []string{s.Type, "null"}
+		}
+	}
+	if s.Closed {
+		out.AdditionalProperties = false
+	} else if s.AdditionalProperties != nil {
+		out.AdditionalProperties = s.AdditionalProperties
+	}
+	return json.Marshal(out)
+}
+
+// ExportSchema generates the schema of ExportVersion documents from the
+// export types. Field ranges come from their schema tags:
+//
+//	Level int `json:"level" schema:"min=1,max=99"`
+//
+// Fields tagged required must be present; the rest may be left out.
+func ExportSchema() *Schema {
+	s := schemaFor(reflect.TypeOf(SaveExport{}))
+	s.Draft = SchemaDraft
+	s.ID = SchemaID(ExportVersion)
+	s.Title = "FF6 Save Editor export " + ExportVersion
+	s.Nullable = false
+	for _, f := range ExportFormats {
+		s.Properties["format"].Enum = append(s.Properties["format"].Enum, string(f))
+	}
+	s.Properties["version"].Enum = []string{ExportVersion}
+	return s
+}
+
+// ExportSchemaJSON returns the schema as indented JSON, as published in
+// docs/api
+func ExportSchemaJSON() ([]byte, error) {
+	return json.MarshalIndent(ExportSchema(), "", "  ")
+}
+
+func schemaFor(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := schemaFor(t.Elem())
+		s.Nullable = true
+		return s
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema), Closed: true}
+		for n := 0; n < t.NumField(); n++ {
+			f := t.Field(n)
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if !f.IsExported() || name == "-" || name == "" {
+				continue
+			}
+			p := schemaFor(f.Type)
+			for _, opt := range strings.Split(f.Tag.Get("schema"), ",") {
+				key, value, _ := strings.Cut(opt, "=")
+				switch key {
+				case "min":
+					p.Minimum = intTag(value)
+				case "max":
+					p.Maximum = intTag(value)
+				case "required":
+					s.Required = append(s.Required, name)
+				}
+			}
+			s.Properties[name] = p
+		}
+		return s
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaFor(t.Elem())}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	}
+	return &Schema{Type: "string"}
+}
+
+func intTag(value string) *int {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		panic(fmt.Sprintf("bad schema tag value %q", value))
+	}
+	return &n
+}
+
+// SchemaViolation is a place where a document breaks the schema. Path is
+// slash-separated from the document root, e.g. characters/0/level.
+type SchemaViolation struct {
+	Path    string
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	if v.Path == "" {
+		return "document: " + v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// SchemaError is returned for a document that doesn't match the schema
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaError) Error() string {
+	msg := "document doesn't match the export schema: " + e.Violations[0].String()
+	if len(e.Violations) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(e.Violations)-1)
+	}
+	return msg
+}
+
+// Validate checks a document decoded with json.Decoder.UseNumber and returns
+// every violation, ordered by path
+func (s *Schema) Validate(doc interface{}) []SchemaViolation {
+	var violations []SchemaViolation
+	s.validate("", doc, &violations)
+	sort.SliceStable(violations, func(a, b int) bool {
+		return violations[a].Path < violations[b].Path
+	})
+	return violations
+}
+
+func (s *Schema) validate(path string, v interface{}, out *[]SchemaViolation) {
+	fail := func(path, format string, args ...interface{}) {
+		*out = append(*out, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if v == nil {
+		if !s.Nullable {
+			fail(path, "expected %s, got null", s.Type)
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			fail(path, "expected object, got %s", jsonType(v))
+			return
+		}
+		for _, name := range s.Required {
+			if _, found := obj[name]; !found {
+				fail(joinDocPath(path, name), "missing required field")
+			}
+		}
+		for name, child := range obj {
+			p := joinDocPath(path, name)
+			switch {
+			case s.Properties[name] != nil:
+				s.Properties[name].validate(p, child, out)
+			case s.AdditionalProperties != nil:
+				s.AdditionalProperties.validate(p, child, out)
+			case s.Closed:
+				fail(p, "unknown field")
+			}
+		}
+
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			fail(path, "expected array, got %s", jsonType(v))
+			return
+		}
+		for n, child := range arr {
+			s.Items.validate(joinDocPath(path, strconv.Itoa(n)), child, out)
+		}
+
+	case "integer", "number":
+		num, ok := v.(json.Number)
+		if !ok {
+			fail(path, "expected %s, got %s", s.Type, jsonType(v))
+			return
+		}
+		f, err := num.Float64()
+		if err != nil {
+			fail(path, "invalid number %s", num)
+			return
+		}
+		if _, err = num.Int64(); err != nil && s.Type == "integer" {
+			fail(path, "expected integer, got %s", num)
+			return
+		}
+		if s.Minimum != nil && f < float64(*s.Minimum) {
+			fail(path, "%s is below the minimum %d", num, *s.Minimum)
+		}
+		if s.Maximum != nil && f > float64(*s.Maximum) {
+			fail(path, "%s is above the maximum %d", num, *s.Maximum)
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail(path, "expected string, got %s", jsonType(v))
+			return
+		}
+		if len(s.Enum) > 0 {
+			for _, e := range s.Enum {
+				if str == e {
+					return
+				}
+			}
+			fail(path, "%q is not one of %s", str, strings.Join(s.Enum, ", "))
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail(path, "expected boolean, got %s", jsonType(v))
+		}
+	}
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// joinDocPath appends a key or index to a document path
+func joinDocPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "/" + key
+}
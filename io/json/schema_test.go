@@ -0,0 +1,72 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestExportSchemaPublished(t *testing.T) {
+	published, err := os.ReadFile("../../docs/api/export-" + ExportVersion + ".schema.json")
+	if err != nil {
+		t.Fatalf("schema for %s isn't published: %v", ExportVersion, err)
+	}
+	generated, err := ExportSchemaJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(published), generated) {
+		t.Error("docs/api schema is out of date; regenerate it with the schema command")
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	p := loadSave(t)
+	data, err := NewExporter(p).ExportToJSON(FormatFull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if violations := ExportSchema().Validate(decodeDocument(t, data)); len(violations) != 0 {
+		t.Errorf("the exporter's own output breaks the schema: %v", violations)
+	}
+
+	doc := decodeDocument(t, []byte(`{"format": "everything",
+		"characters": [{"rootName": "Terra", "level": "high", "levl": 3, "exp": 1.5}],
+		"inventory": {"items": [{"id": 1}, {"id": 2, "count": 100}]}}`))
+	want := []SchemaViolation{
+		{"characters/0/exp", "expected integer, got 1.5"},
+		{"characters/0/level", "expected integer, got string"},
+		{"characters/0/levl", "unknown field"},
+		{"format", `"everything" is not one of full, characters, inventory, party, magic, espers, equipment`},
+		{"inventory/items/0/count", "missing required field"},
+		{"inventory/items/1/count", "100 is above the maximum 99"},
+	}
+	got := ExportSchema().Validate(doc)
+	if len(got) != len(want) {
+		t.Fatalf("got violations %v, want %v", got, want)
+	}
+	for n := range want {
+		if got[n] != want[n] {
+			t.Errorf("violation %d is %v, want %v", n, got[n], want[n])
+		}
+	}
+
+	_, err = NewImporter(p).Import([]byte(`{"party": {"members": [{"slot": 7}]}}`), FormatFull, ImportOptions{})
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || schemaErr.Violations[0].Path != "party/members/0/slot" {
+		t.Errorf("expected a schema error for the slot, got %v", err)
+	}
+}
+
+func decodeDocument(t *testing.T, data []byte) map[string]interface{} {
+	t.Helper()
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
@@ -3,6 +3,9 @@ package pr
 import (
 	"fmt"
 	"sort"
+	"strings"
+
+	"ffvi_editor/global"
 )
 
 // DiffType represents the type of difference
@@ -30,14 +33,31 @@ func (d DiffType) String() string {
 	}
 }
 
+// MarshalText encodes the diff type by name so JSON reports stay readable
+func (d DiffType) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a diff type written by MarshalText
+func (d *DiffType) UnmarshalText(b []byte) error {
+	for _, t := range []DiffType{DiffAdded, DiffRemoved, DiffModified, DiffSame} {
+		if t.String() == string(b) {
+			*d = t
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown diff type: %s", b)
+}
+
 // Diff represents a single difference
 type Diff struct {
-	Type     DiffType
-	Category string      // e.g., "Character", "Equipment", "Inventory"
-	Name     string      // e.g., "Terra", "Item #5"
-	Field    string      // e.g., "Level", "HP", "Equipped"
-	OldValue interface{} // Previous value
-	NewValue interface{} // Current value
+	Type     DiffType    `json:"type"`
+	Category string      `json:"category"`       // e.g., "Character", "Equipment", "Inventory"
+	Name     string      `json:"name"`           // e.g., "Terra", "Item #5"
+	Field    string      `json:"field"`          // e.g., "Level", "HP", "Equipped"
+	OldValue interface{} `json:"oldValue"`       // Previous value
+	NewValue interface{} `json:"newValue"`       // Current value
+	Path     string      `json:"path,omitempty"` // Logical save path, see paths.go
 }
 
 // DiffReport represents comparison results
@@ -69,9 +89,9 @@ type CharacterDiffStats struct {
 
 // EquipmentDiffStats tracks equipment changes
 type EquipmentDiffStats struct {
-	ChangedCount    int
-	WeaponChanges   int
-	ArmorChanges    int
+	ChangedCount     int
+	WeaponChanges    int
+	ArmorChanges     int
 	AccessoryChanges int
 }
 
@@ -89,131 +109,252 @@ type EsperDiffStats struct {
 	EspersLearned int
 }
 
-// Comparator compares two save files
+// Comparator compares two save snapshots
 type Comparator struct {
-	old *PR
-	new *PR
+	old *Snapshot
+	new *Snapshot
 }
 
 // NewComparator creates a new comparator
-func NewComparator(oldSave, newSave *PR) *Comparator {
+func NewComparator(oldSave, newSave *Snapshot) *Comparator {
 	return &Comparator{
 		old: oldSave,
 		new: newSave,
 	}
 }
 
+// CompareFiles loads two save files and compares them. The save open in the
+// editor is left alone.
+func CompareFiles(oldFile, newFile string, saveType global.SaveFileType) (DiffReport, error) {
+	oldSnapshot, err := SnapshotFile(oldFile, saveType)
+	if err != nil {
+		return DiffReport{}, fmt.Errorf("failed to load %s: %w", oldFile, err)
+	}
+	newSnapshot, err := SnapshotFile(newFile, saveType)
+	if err != nil {
+		return DiffReport{}, fmt.Errorf("failed to load %s: %w", newFile, err)
+	}
+	return NewComparator(oldSnapshot, newSnapshot).Compare(), nil
+}
+
 // Compare generates a comprehensive diff report
 func (c *Comparator) Compare() DiffReport {
 	report := DiffReport{
 		Diffs: make([]Diff, 0),
 		Statistics: DiffStatistics{
-			CharacterDiff:   CharacterDiffStats{},
-			EquipmentDiff:   EquipmentDiffStats{},
-			InventoryDiff:   InventoryDiffStats{},
-			EsperDiff:       EsperDiffStats{},
+			CharacterDiff: CharacterDiffStats{},
+			EquipmentDiff: EquipmentDiffStats{},
+			InventoryDiff: InventoryDiffStats{},
+			EsperDiff:     EsperDiffStats{},
 		},
 	}
 
-	// Compare characters
-	c.compareCharacters(&report)
+	// Paths of the old save in order, then paths only the new save has
+	paths := c.old.Paths()
+	for _, path := range c.new.Paths() {
+		if _, ok := c.old.Values[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
 
-	// Compare map data
-	c.compareMapData(&report)
+	for _, path := range paths {
+		oldVal, inOld := c.old.Values[path]
+		newVal, inNew := c.new.Values[path]
 
-	// Update totals
-	report.Statistics.TotalDiffs = len(report.Diffs)
-	for _, diff := range report.Diffs {
-		switch diff.Type {
-		case DiffAdded:
-			report.Statistics.Added++
-		case DiffRemoved:
-			report.Statistics.Removed++
-		case DiffModified:
-			report.Statistics.Modified++
+		diff := describePath(path)
+		switch {
+		case inOld && inNew:
+			if oldVal == newVal {
+				continue
+			}
+			diff.Type = DiffModified
+			diff.OldValue, diff.NewValue = oldVal, newVal
+		case inNew:
+			diff.Type = DiffAdded
+			diff.NewValue = newVal
+		default:
+			diff.Type = DiffRemoved
+			diff.OldValue = oldVal
 		}
+		report.Diffs = append(report.Diffs, diff)
 	}
 
+	c.updateStatistics(&report)
 	return report
 }
 
-// compareCharacters compares character data
-func (c *Comparator) compareCharacters(report *DiffReport) {
-	for i := 0; i < len(c.old.Characters) && i < len(c.new.Characters); i++ {
-		oldChar := c.old.Characters[i]
-		newChar := c.new.Characters[i]
+// updateStatistics fills the summary counters from the report's diffs
+func (c *Comparator) updateStatistics(report *DiffReport) {
+	stats := &report.Statistics
+	changedCharacters := make(map[string]bool)
+	changedEquipment := make(map[string]bool)
 
-		if oldChar == nil || newChar == nil {
-			continue
+	for _, diff := range report.Diffs {
+		switch diff.Type {
+		case DiffAdded:
+			stats.Added++
+		case DiffRemoved:
+			stats.Removed++
+		case DiffModified:
+			stats.Modified++
 		}
 
-		changedCount := 0
-
-		// Get character name or fallback to index
-		charName := fmt.Sprintf("Character %d", i)
-		if oldName := oldChar.Get("Name"); oldName != nil {
-			if nameStr, ok := oldName.(string); ok {
-				charName = nameStr
+		segments := SplitPath(diff.Path)
+		switch segments[0] {
+		case PathCharacters:
+			changedCharacters[diff.Name] = true
+			switch field := strings.ToLower(segments[2]); field {
+			case "level":
+				stats.CharacterDiff.LevelChanges++
+			case "hp", "maxhp":
+				stats.CharacterDiff.HPChanges++
+			case "mp", "maxmp":
+				stats.CharacterDiff.MPChanges++
+			case "vigor", "stamina", "speed", "magic":
+				stats.CharacterDiff.StatChanges++
+			case "equipment":
+				changedEquipment[diff.Name] = true
+				switch strings.ToLower(segments[3]) {
+				case "weapon":
+					stats.EquipmentDiff.WeaponChanges++
+				case "relic1", "relic2":
+					stats.EquipmentDiff.AccessoryChanges++
+				default:
+					stats.EquipmentDiff.ArmorChanges++
+				}
+			case "spells":
+				if n, _ := diff.NewValue.(int); n == 100 {
+					stats.EsperDiff.EspersLearned++
+				}
 			}
-		} else if newName := newChar.Get("Name"); newName != nil {
-			if nameStr, ok := newName.(string); ok {
-				charName = nameStr
+		case PathInventory, PathImportantItems:
+			switch diff.Type {
+			case DiffAdded:
+				stats.InventoryDiff.ItemsAdded++
+			case DiffRemoved:
+				stats.InventoryDiff.ItemsRemoved++
+			}
+		case PathEspers:
+			switch diff.Type {
+			case DiffAdded:
+				stats.EsperDiff.EspersAdded++
+			case DiffRemoved:
+				stats.EsperDiff.EspersRemoved++
 			}
 		}
+	}
 
-		// Compare basic stats
-		for _, field := range []string{"Level", "HP", "MaxHP", "MP", "MaxMP"} {
-			oldVal := oldChar.Get(field)
-			newVal := newChar.Get(field)
-
-			if oldVal != newVal {
-				report.Diffs = append(report.Diffs, Diff{
-					Type:     DiffModified,
-					Category: "Character",
-					Name:     charName,
-					Field:    field,
-					OldValue: oldVal,
-					NewValue: newVal,
-				})
-				changedCount++
-
-				switch field {
-				case "Level":
-					report.Statistics.CharacterDiff.LevelChanges++
-				case "HP":
-					report.Statistics.CharacterDiff.HPChanges++
-				case "MP":
-					report.Statistics.CharacterDiff.MPChanges++
-				}
-			}
+	stats.TotalDiffs = len(report.Diffs)
+	stats.CharacterDiff.ChangedCount = len(changedCharacters)
+	stats.EquipmentDiff.ChangedCount = len(changedEquipment)
+	stats.InventoryDiff.ItemsMoved = countMoved(c.old.inventoryOrder, c.new.inventoryOrder)
+}
+
+// countMoved counts the items kept in both orderings whose position relative
+// to the other kept items changed
+func countMoved(oldOrder, newOrder []string) int {
+	inNew := make(map[string]bool, len(newOrder))
+	for _, p := range newOrder {
+		inNew[p] = true
+	}
+	inOld := make(map[string]bool, len(oldOrder))
+	kept := make([]string, 0, len(oldOrder))
+	for _, p := range oldOrder {
+		inOld[p] = true
+		if inNew[p] {
+			kept = append(kept, p)
 		}
+	}
 
-		if changedCount > 0 {
-			report.Statistics.CharacterDiff.ChangedCount++
+	moved, i := 0, 0
+	for _, p := range newOrder {
+		if !inOld[p] {
+			continue
+		}
+		if kept[i] != p {
+			moved++
 		}
+		i++
 	}
+	return moved
 }
 
-// compareMapData compares map data
-func (c *Comparator) compareMapData(report *DiffReport) {
-	// Compare specific common fields
-	commonFields := []string{"MapID", "PlayerPosX", "PlayerPosY", "PlayerFacing", "Airship", "ReliquaryLocations"}
+// diffCategories names the report category of each path category
+var diffCategories = map[string]string{
+	PathInventory:      "Inventory",
+	PathImportantItems: "Important Items",
+	PathEspers:         "Espers",
+	PathRages:          "Rages",
+	PathLores:          "Lores",
+	PathDances:         "Dances",
+	PathBlitzes:        "Blitzes",
+	PathBushido:        "Bushido",
+	PathVeldt:          "Veldt",
+	PathParty:          "Party",
+	PathMap:            "Map",
+	PathTransportation: "Transportation",
+	PathMisc:           "Misc",
+}
 
-	for _, field := range commonFields {
-		oldVal := c.old.MapData.Get(field)
-		newVal := c.new.MapData.Get(field)
+// fieldLabels are the display names of path fields
+var fieldLabels = map[string]string{
+	"name": "Name", "enabled": "Enabled", "level": "Level", "exp": "Experience",
+	"hp": "HP", "maxHp": "Max HP", "mp": "MP", "maxMp": "Max MP",
+	"vigor": "Vigor", "stamina": "Stamina", "speed": "Speed", "magic": "Magic",
+	"weapon": "Weapon", "shield": "Shield", "helmet": "Helmet", "armor": "Armor",
+	"relic1": "Relic 1", "relic2": "Relic 2",
+	"mapId": "Map ID", "pointIn": "Entrance Point", "transportationId": "Transportation ID",
+	"carryingHoverShip": "Carrying Hover Ship", "x": "X", "y": "Y", "z": "Z", "direction": "Direction",
+	"playableCharacterCorpsId": "Playable Character Corps ID", "gpsMapId": "GPS Map ID",
+	"gpsAreaId": "GPS Area ID", "gpsId": "GPS ID", "gpsWidth": "GPS Width", "gpsHeight": "GPS Height",
+	"gil": "Gil", "steps": "Steps", "escapeCount": "Escapes", "battleCount": "Battles",
+	"saveCount": "Saves", "monstersKilled": "Monsters Killed", "cursedShieldFights": "Cursed Shield Fights",
+	"openedChests": "Opened Chests", "playTime": "Play Time", "isComplete": "Game Complete",
+}
 
-		if oldVal != newVal && oldVal != nil && newVal != nil {
-			report.Diffs = append(report.Diffs, Diff{
-				Type:     DiffModified,
-				Category: "MapData",
-				Name:     "Map",
-				Field:    field,
-				OldValue: oldVal,
-				NewValue: newVal,
-			})
+// describePath fills in the category, name, field and path of a diff from a
+// snapshot path. Names come from the consts/pr lookups the paths are built on.
+func describePath(path string) Diff {
+	segments := SplitPath(path)
+	d := Diff{Path: path, Category: diffCategories[segments[0]]}
+
+	switch segments[0] {
+	case PathCharacters:
+		d.Category, d.Name = "Character", segments[1]
+		d.Field = FieldLabel(segments[2])
+		if len(segments) == 4 {
+			switch segments[2] {
+			case "equipment":
+				d.Category, d.Field = "Equipment", FieldLabel(segments[3])
+			case "spells":
+				d.Category, d.Field = "Spells", segments[3]
+			case "commands":
+				d.Category, d.Field = "Commands", fmt.Sprintf("Slot %s", segments[3])
+			}
 		}
+	case PathInventory, PathImportantItems, PathEspers, PathRages, PathLores, PathDances, PathBlitzes, PathBushido:
+		d.Name = segments[1]
+		if segments[0] == PathInventory || segments[0] == PathImportantItems {
+			d.Field = "Count"
+		}
+	case PathVeldt:
+		d.Name, d.Field = fmt.Sprintf("Encounter %s", segments[1]), "Available"
+	case PathParty:
+		d.Name, d.Field = fmt.Sprintf("Slot %s", segments[1]), "Member"
+	case PathTransportation:
+		d.Name, d.Field = fmt.Sprintf("Vehicle %s", segments[1]), FieldLabel(segments[2])
+	case PathMap, PathMisc:
+		d.Name, d.Field = d.Category, FieldLabel(segments[1])
 	}
+	return d
+}
+
+// FieldLabel returns the display name of a path field
+func FieldLabel(field string) string {
+	if label, ok := fieldLabels[field]; ok {
+		return label
+	}
+	return field
 }
 
 // GetSortedDiffs returns diffs sorted by category
@@ -221,7 +362,7 @@ func (r *DiffReport) GetSortedDiffs() []Diff {
 	diffs := make([]Diff, len(r.Diffs))
 	copy(diffs, r.Diffs)
 
-	sort.Slice(diffs, func(i, j int) bool {
+	sort.SliceStable(diffs, func(i, j int) bool {
 		if diffs[i].Category != diffs[j].Category {
 			return diffs[i].Category < diffs[j].Category
 		}
@@ -242,12 +383,37 @@ func (r *DiffReport) GetDiffsByCategory(category string) []Diff {
 	return filtered
 }
 
+// Categories returns the distinct categories present in the report, sorted
+func (r *DiffReport) Categories() []string {
+	seen := make(map[string]bool)
+	categories := make([]string, 0)
+	for _, diff := range r.Diffs {
+		if !seen[diff.Category] {
+			seen[diff.Category] = true
+			categories = append(categories, diff.Category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// HasDifferences reports whether the comparison found any change
+func (r *DiffReport) HasDifferences() bool {
+	return len(r.Diffs) > 0
+}
+
 // String provides human-readable summary
 func (s *DiffStatistics) String() string {
 	return fmt.Sprintf(
 		"Differences: %d total (%d added, %d removed, %d modified)\n"+
-			"Characters: %d changed (%d level, %d HP, %d MP, %d stat)",
+			"Characters: %d changed (%d level, %d HP, %d MP, %d stat)\n"+
+			"Equipment: %d changed (%d weapon, %d armor, %d relic)\n"+
+			"Inventory: %d added, %d removed, %d moved\n"+
+			"Espers: %d added, %d removed, %d spells learned",
 		s.TotalDiffs, s.Added, s.Removed, s.Modified,
 		s.CharacterDiff.ChangedCount, s.CharacterDiff.LevelChanges, s.CharacterDiff.HPChanges, s.CharacterDiff.MPChanges, s.CharacterDiff.StatChanges,
+		s.EquipmentDiff.ChangedCount, s.EquipmentDiff.WeaponChanges, s.EquipmentDiff.ArmorChanges, s.EquipmentDiff.AccessoryChanges,
+		s.InventoryDiff.ItemsAdded, s.InventoryDiff.ItemsRemoved, s.InventoryDiff.ItemsMoved,
+		s.EsperDiff.EspersAdded, s.EsperDiff.EspersRemoved, s.EsperDiff.EspersLearned,
 	)
 }
@@ -0,0 +1,107 @@
+package pr
+
+import (
+	"testing"
+
+	"ffvi_editor/global"
+)
+
+const testSave = "../../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y="
+
+func loadTestSave(t *testing.T) *PR {
+	t.Helper()
+	p := New()
+	if err := p.Load(testSave, global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	return p
+}
+
+func TestCompareIdenticalSnapshots(t *testing.T) {
+	loadTestSave(t)
+	report := NewComparator(TakeSnapshot(), TakeSnapshot()).Compare()
+	if report.HasDifferences() {
+		t.Fatalf("expected no differences, got %+v", report.Diffs)
+	}
+}
+
+func TestCompareDomainChanges(t *testing.T) {
+	loadTestSave(t)
+	before := TakeSnapshot()
+
+	edits := NewPatch("edits")
+	edits.Add(PatchSet, "characters/Terra/level", 30)
+	edits.Add(PatchSet, "characters/Terra/equipment/weapon", "Dagger")
+	edits.Add(PatchSet, "characters/Terra/spells/Ultima", 100)
+	edits.Add(PatchAdd, "inventory/Megalixir", 3)
+	edits.Add(PatchRemove, "inventory/Potion", nil)
+	edits.Add(PatchAdd, "espers/Ramuh", nil)
+	edits.Add(PatchSet, "map/x", 12.5)
+	edits.Add(PatchSet, "misc/gil", 1234)
+	if err := New().ApplyPatch(edits); err != nil {
+		t.Fatalf("ApplyPatch failed: %v", err)
+	}
+	after := TakeSnapshot()
+
+	report := NewComparator(before, after).Compare()
+	byPath := make(map[string]Diff)
+	for _, d := range report.Diffs {
+		byPath[d.Path] = d
+	}
+
+	tests := []struct {
+		path     string
+		typ      DiffType
+		category string
+		name     string
+		field    string
+	}{
+		{"characters/Terra/level", DiffModified, "Character", "Terra", "Level"},
+		{"characters/Terra/equipment/weapon", DiffModified, "Equipment", "Terra", "Weapon"},
+		{"characters/Terra/spells/Ultima", DiffModified, "Spells", "Terra", "Ultima"},
+		{"inventory/Megalixir", DiffAdded, "Inventory", "Megalixir", "Count"},
+		{"inventory/Potion", DiffRemoved, "Inventory", "Potion", "Count"},
+		{"espers/Ramuh", DiffAdded, "Espers", "Ramuh", ""},
+		{"map/x", DiffModified, "Map", "Map", "X"},
+		{"misc/gil", DiffModified, "Misc", "Misc", "Gil"},
+	}
+	for _, tt := range tests {
+		d, ok := byPath[tt.path]
+		if !ok {
+			t.Errorf("missing diff for %s", tt.path)
+			continue
+		}
+		if d.Type != tt.typ || d.Category != tt.category || d.Name != tt.name || d.Field != tt.field {
+			t.Errorf("%s: got %s %q %q %q, want %s %q %q %q", tt.path, d.Type, d.Category, d.Name, d.Field, tt.typ, tt.category, tt.name, tt.field)
+		}
+	}
+
+	stats := report.Statistics
+	if stats.CharacterDiff.LevelChanges != 1 || stats.EquipmentDiff.WeaponChanges != 1 {
+		t.Errorf("character/equipment stats not filled: %+v %+v", stats.CharacterDiff, stats.EquipmentDiff)
+	}
+	if stats.InventoryDiff.ItemsAdded != 1 || stats.InventoryDiff.ItemsRemoved != 1 {
+		t.Errorf("inventory stats not filled: %+v", stats.InventoryDiff)
+	}
+	if stats.EsperDiff.EspersAdded != 1 || stats.EsperDiff.EspersLearned != 1 {
+		t.Errorf("esper stats not filled: %+v", stats.EsperDiff)
+	}
+
+	// The report as a patch turns the original save into the edited one
+	loadTestSave(t)
+	if err := New().ApplyPatch(report.ToPatch("replay")); err != nil {
+		t.Fatalf("replaying report failed: %v", err)
+	}
+	if replay := NewComparator(after, TakeSnapshot()).Compare(); replay.HasDifferences() {
+		t.Fatalf("replayed save differs: %+v", replay.Diffs)
+	}
+}
+
+func TestCountMoved(t *testing.T) {
+	if n := countMoved([]string{"a", "b", "c"}, []string{"a", "b", "c", "d"}); n != 0 {
+		t.Errorf("appending moved %d items", n)
+	}
+	if n := countMoved([]string{"a", "b", "c"}, []string{"c", "a", "b"}); n != 3 {
+		t.Errorf("rotation moved %d items, want 3", n)
+	}
+}
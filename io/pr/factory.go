@@ -1,6 +1,9 @@
 package pr
 
 import (
+	"ffvi_editor/models"
+	pri "ffvi_editor/models/pr"
+
 	jo "gitlab.com/c0b/go-ordered-json"
 )
 
@@ -26,3 +29,39 @@ func New() *PR {
 func (p *PR) HasUnicodeNames() bool {
 	return len(p.names) > 0
 }
+
+// SlotID returns the save slot ID stored in the loaded file
+func (p *PR) SlotID() int {
+	if id, err := p.getInt(p.Base, "id"); err == nil {
+		return id
+	}
+	return 0
+}
+
+// LoadedCharacters returns the decoded characters that are present in the
+// loaded file, in file order. Characters the save doesn't contain keep their
+// zero values in the models and are left out.
+func (p *PR) LoadedCharacters() []*models.Character {
+	characters := make([]*models.Character, 0, len(p.Characters))
+	seen := make(map[*models.Character]bool)
+	for _, d := range p.Characters {
+		if d == nil {
+			continue
+		}
+		id, err := p.getInt(d, ID)
+		if err != nil {
+			continue
+		}
+		jobID, err := p.getInt(d, JobID)
+		if err != nil {
+			continue
+		}
+		if o, found := pri.GetCharacterBaseOffset(id, jobID); found {
+			if c := pri.GetCharacter(o.Name); !seen[c] {
+				seen[c] = true
+				characters = append(characters, c)
+			}
+		}
+	}
+	return characters
+}
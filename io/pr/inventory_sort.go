@@ -0,0 +1,147 @@
+package pr
+
+import (
+	"fmt"
+
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+	jo "gitlab.com/c0b/go-ordered-json"
+)
+
+// SortOrderProblems is how the inventory sort-id list, the content IDs of
+// the owned items in menu order, disagrees with the inventory rows the saver
+// writes. An empty list is fine: the game rebuilds it.
+type SortOrderProblems struct {
+	Duplicates []int // IDs listed more than once
+	NotOwned   []int // IDs listed without a row
+	Missing    []int // Owned IDs the list leaves out
+}
+
+// Empty reports whether the list matches the inventory
+func (s SortOrderProblems) Empty() bool {
+	return len(s.Duplicates) == 0 && len(s.NotOwned) == 0 && len(s.Missing) == 0
+}
+
+func (s SortOrderProblems) String() string {
+	return fmt.Sprintf("%d duplicate, %d not owned, %d missing", len(s.Duplicates), len(s.NotOwned), len(s.Missing))
+}
+
+// InventorySortIDs returns the sort-id list of the normal inventory
+func (p *PR) InventorySortIDs() ([]int, error) {
+	raw, err := SafeGetFromTargetRaw(p.UserData, NormalOwnedItemSortIdList)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is a %T, not a list", NormalOwnedItemSortIdList, raw)
+	}
+	ids := make([]int, len(values))
+	for i, v := range values {
+		if ids[i], err = toInt(v); err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", NormalOwnedItemSortIdList, i, err)
+		}
+	}
+	return ids, nil
+}
+
+// SetInventorySortIDs replaces the sort-id list of the normal inventory
+func (p *PR) SetInventorySortIDs(ids []int) error {
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	slTarget := jo.NewOrderedMap()
+	slTarget.Set(targetKey, values)
+	return p.marshalTo(p.UserData, NormalOwnedItemSortIdList, slTarget)
+}
+
+// CheckInventorySortOrder compares the sort-id list with the inventory
+func (p *PR) CheckInventorySortOrder() (problems SortOrderProblems, err error) {
+	var ids []int
+	if pri.GetInventory().ResetSortOrder {
+		// The saver clears the list
+		return
+	}
+	if ids, err = p.InventorySortIDs(); err != nil || len(ids) == 0 {
+		return
+	}
+	owned := ownedItemIDs(pri.GetInventory())
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		switch {
+		case seen[id]:
+			problems.Duplicates = append(problems.Duplicates, id)
+		case !owned[id]:
+			problems.NotOwned = append(problems.NotOwned, id)
+		}
+		seen[id] = true
+	}
+	for _, r := range pri.GetInventory().GetRows() {
+		if r != nil && owned[r.ItemID] && !seen[r.ItemID] {
+			problems.Missing = append(problems.Missing, r.ItemID)
+			seen[r.ItemID] = true
+		}
+	}
+	return
+}
+
+// RepairInventorySortOrder rewrites the sort-id list to match the inventory.
+// Listed items keep their place and missing items go at the end in row order.
+func (p *PR) RepairInventorySortOrder() error {
+	ids, err := p.InventorySortIDs()
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	owned := ownedItemIDs(pri.GetInventory())
+	seen := make(map[int]bool)
+	sorted := make([]int, 0, len(owned))
+	for _, id := range ids {
+		if owned[id] && !seen[id] {
+			sorted = append(sorted, id)
+			seen[id] = true
+		}
+	}
+	for _, r := range pri.GetInventory().GetRows() {
+		if r != nil && owned[r.ItemID] && !seen[r.ItemID] {
+			sorted = append(sorted, r.ItemID)
+			seen[r.ItemID] = true
+		}
+	}
+	return p.SetInventorySortIDs(sorted)
+}
+
+// InventoryRepair lists what RepairInventory changed
+type InventoryRepair struct {
+	Inventory      []pri.InventoryProblem
+	ImportantItems []pri.InventoryProblem
+	SortOrder      SortOrderProblems
+}
+
+// Changed reports whether the repair changed anything
+func (r InventoryRepair) Changed() bool {
+	return len(r.Inventory) > 0 || len(r.ImportantItems) > 0 || !r.SortOrder.Empty()
+}
+
+// RepairInventory fixes every row problem of both inventories, then brings
+// the sort-id list in line with the result
+func (p *PR) RepairInventory() (repair InventoryRepair, err error) {
+	repair.Inventory = pri.GetInventory().Repair(pr.ItemsByID)
+	repair.ImportantItems = pri.GetImportantInventory().Repair(pr.ImportantItemsByID)
+	if repair.SortOrder, err = p.CheckInventorySortOrder(); err != nil || repair.SortOrder.Empty() {
+		return
+	}
+	err = p.RepairInventorySortOrder()
+	return
+}
+
+// ownedItemIDs returns the items the saver writes rows for
+func ownedItemIDs(inv *pri.Inventory) map[int]bool {
+	owned := make(map[int]bool)
+	for _, r := range inv.GetRows() {
+		if r != nil && r.ItemID > 0 && r.Count > 0 && !crashingItems[r.ItemID] {
+			owned[r.ItemID] = true
+		}
+	}
+	return owned
+}
@@ -0,0 +1,46 @@
+package pr
+
+import (
+	"reflect"
+	"testing"
+
+	pri "ffvi_editor/models/pr"
+)
+
+func TestInventorySortOrder(t *testing.T) {
+	helpers := NewTestHelpers(t)
+	p := New()
+	p.UserData = helpers.CreateOrderedMap(`{
+		"normalOwnedItemSortIdList": "{\"target\": [3, 2, 2, 9]}"
+	}`)
+
+	inv := pri.GetInventory()
+	inv.Clear()
+	inv.Set(0, pri.Row{ItemID: 2, Count: 1})
+	inv.Set(1, pri.Row{ItemID: 3, Count: 4})
+	inv.Set(2, pri.Row{ItemID: 5, Count: 7})
+	defer inv.Clear()
+
+	problems, err := p.CheckInventorySortOrder()
+	if err != nil {
+		t.Fatalf("CheckInventorySortOrder failed: %v", err)
+	}
+	want := SortOrderProblems{Duplicates: []int{2}, NotOwned: []int{9}, Missing: []int{5}}
+	if !reflect.DeepEqual(problems, want) {
+		t.Fatalf("got %+v, want %+v", problems, want)
+	}
+
+	if err = p.RepairInventorySortOrder(); err != nil {
+		t.Fatalf("RepairInventorySortOrder failed: %v", err)
+	}
+	ids, err := p.InventorySortIDs()
+	if err != nil {
+		t.Fatalf("InventorySortIDs failed: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int{3, 2, 5}) {
+		t.Errorf("got sort order %v, want [3 2 5]", ids)
+	}
+	if problems, _ = p.CheckInventorySortOrder(); !problems.Empty() {
+		t.Errorf("problems left after repair: %+v", problems)
+	}
+}
@@ -26,14 +26,25 @@ import (
 
 func (p *PR) Load(fromFile string, saveType global.SaveFileType) (err error) {
 	var (
-		out   []byte
-		s     string
-		names []unicodeNameReplace
+		out     []byte
+		trimmed []byte
 	)
 
-	if out, p.fileTrimmed, err = file.LoadFile(fromFile, saveType); err != nil {
+	if out, trimmed, err = file.LoadFile(fromFile, saveType); err != nil {
 		return
 	}
+	return p.LoadJSON(out, trimmed)
+}
+
+// LoadJSON loads save data already decoded from a file by file.LoadFile.
+// trimmed is what the file held ahead of the data, which Save writes back.
+func (p *PR) LoadJSON(out []byte, trimmed []byte) (err error) {
+	var (
+		s     string
+		names []unicodeNameReplace
+	)
+
+	p.fileTrimmed = trimmed
 	s = string(out)
 
 	if err = p.loadBase(s); err != nil {
@@ -194,6 +205,13 @@ func (p *PR) loadCharacters() (err error) {
 			return
 		}
 
+		c.EsperID = 0
+		if d.Has(MagicStoneId) {
+			if c.EsperID, err = p.getInt(d, MagicStoneId); err != nil {
+				return
+			}
+		}
+
 		// TODO Status
 
 		var values interface{}
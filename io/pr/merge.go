@@ -0,0 +1,296 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+
+	"ffvi_editor/global"
+)
+
+// MergeStrategy decides the merged value of a path that both sides changed
+type MergeStrategy string
+
+const (
+	// MergeMax keeps the larger value; used for counters and progress
+	MergeMax MergeStrategy = "max"
+	// MergeUnion keeps anything owned or learned on either side, even if the
+	// other side removed it
+	MergeUnion MergeStrategy = "union"
+	// MergePrefer keeps the value of the preferred side
+	MergePrefer MergeStrategy = "prefer"
+)
+
+// MergeSide names one of the two diverged saves
+type MergeSide string
+
+const (
+	MergeOurs   MergeSide = "ours"
+	MergeTheirs MergeSide = "theirs"
+)
+
+// MergeOptions configures a three-way merge
+type MergeOptions struct {
+	// Prefer is the side kept by the prefer strategy
+	Prefer MergeSide
+	// Strategies overrides the default strategy per merge group; groups are
+	// the path categories plus "equipment", "spells" and "commands"
+	Strategies map[string]MergeStrategy
+}
+
+// DefaultMergeStrategies are the strategies used for groups not set in MergeOptions
+var DefaultMergeStrategies = map[string]MergeStrategy{
+	PathCharacters:     MergeMax,
+	"equipment":        MergePrefer,
+	"spells":           MergeMax,
+	"commands":         MergePrefer,
+	PathInventory:      MergeMax,
+	PathImportantItems: MergeMax,
+	PathEspers:         MergeUnion,
+	PathRages:          MergeUnion,
+	PathLores:          MergeUnion,
+	PathDances:         MergeUnion,
+	PathBlitzes:        MergeUnion,
+	PathBushido:        MergeUnion,
+	PathVeldt:          MergeUnion,
+	PathParty:          MergePrefer,
+	PathMap:            MergePrefer,
+	PathTransportation: MergePrefer,
+	PathMisc:           MergeMax,
+}
+
+// DefaultMergeOptions prefers our side
+func DefaultMergeOptions() MergeOptions {
+	return MergeOptions{Prefer: MergeOurs}
+}
+
+// MergeConflict is a path both sides changed to different values
+type MergeConflict struct {
+	Path     string        `json:"path"`
+	Base     interface{}   `json:"base"`
+	Ours     interface{}   `json:"ours"`
+	Theirs   interface{}   `json:"theirs"`
+	Merged   interface{}   `json:"merged"`
+	Strategy MergeStrategy `json:"strategy"`
+}
+
+// MergeResult holds the outcome of a three-way merge
+type MergeResult struct {
+	// Conflicts lists every path both sides changed, with how it was resolved
+	Conflicts []MergeConflict `json:"conflicts"`
+	// Patch turns our save into the merged save
+	Patch *Patch `json:"-"`
+	// FromTheirs counts the changes taken from their side without conflict
+	FromTheirs int `json:"fromTheirs"`
+}
+
+// Merge combines two snapshots that diverged from base. Paths changed on one
+// side take that side's value; paths changed on both sides are resolved with
+// the group's strategy, and union groups keep anything present on either side.
+func Merge(base, ours, theirs *Snapshot, opts MergeOptions) *MergeResult {
+	if opts.Prefer == "" {
+		opts.Prefer = MergeOurs
+	}
+	result := &MergeResult{
+		Conflicts: make([]MergeConflict, 0),
+		Patch:     NewPatch("Three-way merge"),
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, s := range []*Snapshot{base, ours, theirs} {
+		for _, p := range s.paths {
+			if !seen[p] {
+				seen[p] = true
+				paths = append(paths, p)
+			}
+		}
+	}
+
+	for _, path := range paths {
+		b, inBase := base.Values[path]
+		o, inOurs := ours.Values[path]
+		t, inTheirs := theirs.Values[path]
+		strategy := opts.strategyFor(path)
+
+		merged, present := o, inOurs
+		switch {
+		case strategy == MergeUnion && (inOurs || inTheirs):
+			merged, present = unionValue(o, inOurs, t, inTheirs), true
+		case inOurs == inTheirs && o == t:
+			// Both sides agree
+		case inOurs == inBase && o == b:
+			merged, present = t, inTheirs
+		case inTheirs == inBase && t == b:
+			// Only our side changed
+		default:
+			merged, present = resolveConflict(strategy, opts.Prefer, o, inOurs, t, inTheirs)
+			result.Conflicts = append(result.Conflicts, MergeConflict{
+				Path:     path,
+				Base:     valueOrNil(b, inBase),
+				Ours:     valueOrNil(o, inOurs),
+				Theirs:   valueOrNil(t, inTheirs),
+				Merged:   valueOrNil(merged, present),
+				Strategy: strategy,
+			})
+		}
+
+		switch {
+		case present && (!inOurs || merged != o):
+			result.Patch.Add(PatchSet, path, merged)
+		case !present && inOurs:
+			result.Patch.Add(PatchRemove, path, nil)
+		default:
+			continue
+		}
+		if present == inTheirs && merged == t {
+			result.FromTheirs++
+		}
+	}
+	return result
+}
+
+// MergeFiles merges ours and theirs, which both descend from base, and
+// writes the merged save to output. Output may be empty for a dry run. Our
+// save is left loaded with the merge applied.
+func MergeFiles(baseFile, oursFile, theirsFile, output string, saveType global.SaveFileType, opts MergeOptions) (*MergeResult, error) {
+	save, result, err := MergeSaves(baseFile, oursFile, theirsFile, saveType, opts)
+	if err != nil {
+		return nil, err
+	}
+	if output != "" {
+		if err := save.Save(save.SlotID(), output, saveType); err != nil {
+			return nil, fmt.Errorf("failed to save merged file: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// MergeSaves merges ours and theirs, which both descend from base, and
+// returns our save loaded with the merge applied, for callers that check it
+// before writing
+func MergeSaves(baseFile, oursFile, theirsFile string, saveType global.SaveFileType, opts MergeOptions) (*PR, *MergeResult, error) {
+	snapshots := make([]*Snapshot, 0, 3)
+	var save *PR
+	for _, file := range []string{baseFile, theirsFile, oursFile} {
+		save = New()
+		if err := save.Load(file, saveType); err != nil {
+			return nil, nil, fmt.Errorf("failed to load %s: %w", file, err)
+		}
+		snapshots = append(snapshots, TakeSnapshot())
+	}
+
+	result := Merge(snapshots[0], snapshots[2], snapshots[1], opts)
+	if err := save.ApplyPatch(result.Patch); err != nil {
+		return nil, nil, fmt.Errorf("failed to apply merge: %w", err)
+	}
+	return save, result, nil
+}
+
+// ParseMergeStrategies parses "group=strategy" pairs separated by commas,
+// such as "map=prefer,inventory=max"
+func ParseMergeStrategies(s string) (map[string]MergeStrategy, error) {
+	strategies := make(map[string]MergeStrategy)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		group, strategy, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid strategy %q, expected group=strategy", pair)
+		}
+		found := false
+		for g := range DefaultMergeStrategies {
+			if strings.EqualFold(g, group) {
+				group, found = g, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown merge group %q", group)
+		}
+		switch st := MergeStrategy(strings.ToLower(strategy)); st {
+		case MergeMax, MergeUnion, MergePrefer:
+			strategies[group] = st
+		default:
+			return nil, fmt.Errorf("unknown merge strategy %q", strategy)
+		}
+	}
+	return strategies, nil
+}
+
+// strategyFor returns the strategy of the group a path belongs to
+func (o MergeOptions) strategyFor(path string) MergeStrategy {
+	segments := SplitPath(path)
+	group := segments[0]
+	if group == PathCharacters && len(segments) == 4 {
+		group = segments[2]
+	}
+	if s, ok := o.Strategies[group]; ok {
+		return s
+	}
+	if s, ok := DefaultMergeStrategies[group]; ok {
+		return s
+	}
+	return MergePrefer
+}
+
+// resolveConflict picks the merged value of a path both sides changed
+func resolveConflict(strategy MergeStrategy, prefer MergeSide, o interface{}, inOurs bool, t interface{}, inTheirs bool) (interface{}, bool) {
+	if strategy == MergeMax || strategy == MergeUnion {
+		if !inOurs || !inTheirs {
+			// Something beats nothing
+			if inOurs {
+				return o, true
+			}
+			return t, inTheirs
+		}
+		if greater, ok := compareValues(t, o); ok {
+			if greater {
+				return t, true
+			}
+			return o, true
+		}
+	}
+	if prefer == MergeTheirs {
+		return t, inTheirs
+	}
+	return o, inOurs
+}
+
+// unionValue keeps whatever either side has
+func unionValue(o interface{}, inOurs bool, t interface{}, inTheirs bool) interface{} {
+	if !inTheirs {
+		return o
+	}
+	if !inOurs {
+		return t
+	}
+	if greater, ok := compareValues(t, o); ok && greater {
+		return t
+	}
+	return o
+}
+
+// compareValues reports whether a is greater than b; ok is false for values
+// that have no order
+func compareValues(a, b interface{}) (greater bool, ok bool) {
+	switch av := a.(type) {
+	case int:
+		bv, isInt := b.(int)
+		return av > bv, isInt
+	case float64:
+		bv, isFloat := b.(float64)
+		return av > bv, isFloat
+	case bool:
+		bv, isBool := b.(bool)
+		return av && !bv, isBool
+	}
+	return false, false
+}
+
+func valueOrNil(v interface{}, present bool) interface{} {
+	if !present {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,185 @@
+package pr
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"ffvi_editor/global"
+)
+
+func snapshotOf(values map[string]interface{}) *Snapshot {
+	s := &Snapshot{Values: values}
+	for p := range values {
+		s.paths = append(s.paths, p)
+	}
+	sort.Strings(s.paths)
+	return s
+}
+
+func TestMergeStrategies(t *testing.T) {
+	base := snapshotOf(map[string]interface{}{
+		"misc/steps":                        100,
+		"misc/gil":                          500,
+		"inventory/Potion":                  5,
+		"characters/Terra/name":             "Terra",
+		"characters/Terra/spells/Fire":      10,
+		"characters/Terra/equipment/weapon": "Dagger",
+		"espers/Ramuh":                      true,
+		"map/x":                             1.0,
+	})
+	ours := snapshotOf(map[string]interface{}{
+		"misc/steps":                        150,
+		"misc/gil":                          500,
+		"inventory/Potion":                  3,
+		"characters/Terra/name":             "Tina",
+		"characters/Terra/spells/Fire":      40,
+		"characters/Terra/equipment/weapon": "Mythril Knife",
+		"espers/Ramuh":                      true,
+		"espers/Siren":                      true,
+		"map/x":                             2.0,
+	})
+	theirs := snapshotOf(map[string]interface{}{
+		"misc/steps":                        120,
+		"misc/gil":                          900,
+		"inventory/Potion":                  8,
+		"inventory/Megalixir":               1,
+		"characters/Terra/name":             "Terra",
+		"characters/Terra/spells/Fire":      100,
+		"characters/Terra/equipment/weapon": "Dagger",
+		"espers/Kirin":                      true,
+		"map/x":                             3.0,
+	})
+
+	check := func(result *MergeResult, want map[string]interface{}, removed []string) {
+		t.Helper()
+		got := make(map[string]PatchOperation)
+		for _, op := range result.Patch.Operations {
+			got[op.Path] = op
+		}
+		for path, v := range want {
+			if op, ok := got[path]; !ok || op.Op != PatchSet || op.Value != v {
+				t.Errorf("%s: got %+v, want set %v", path, op, v)
+			}
+		}
+		for _, path := range removed {
+			if op := got[path]; op.Op != PatchRemove {
+				t.Errorf("%s: got %+v, want remove", path, op)
+			}
+		}
+		if len(got) != len(want)+len(removed) {
+			t.Errorf("got %d operations, want %d: %+v", len(got), len(want)+len(removed), result.Patch.Operations)
+		}
+	}
+
+	// Counters take the max, only-theirs changes are taken, learned espers
+	// are kept from both sides and positions keep our side
+	result := Merge(base, ours, theirs, DefaultMergeOptions())
+	check(result, map[string]interface{}{
+		"misc/gil":                     900,
+		"inventory/Potion":             8,
+		"inventory/Megalixir":          1,
+		"characters/Terra/spells/Fire": 100,
+		"espers/Kirin":                 true,
+	}, nil)
+	if len(result.Conflicts) != 4 {
+		t.Errorf("got %d conflicts, want 4: %+v", len(result.Conflicts), result.Conflicts)
+	}
+	for _, cf := range result.Conflicts {
+		if cf.Path == "map/x" && (cf.Strategy != MergePrefer || cf.Merged != 2.0) {
+			t.Errorf("map/x conflict: %+v", cf)
+		}
+	}
+
+	// Preferring their side and overriding the inventory strategy
+	result = Merge(base, ours, theirs, MergeOptions{
+		Prefer:     MergeTheirs,
+		Strategies: map[string]MergeStrategy{PathMisc: MergePrefer},
+	})
+	check(result, map[string]interface{}{
+		"misc/steps":                   120,
+		"misc/gil":                     900,
+		"inventory/Potion":             8,
+		"inventory/Megalixir":          1,
+		"characters/Terra/spells/Fire": 100,
+		"espers/Kirin":                 true,
+		"map/x":                        3.0,
+	}, nil)
+
+	// Losing an item on one side removes it when the other side kept it as is
+	delete(ours.Values, "inventory/Potion")
+	result = Merge(base, ours, base, DefaultMergeOptions())
+	for _, op := range result.Patch.Operations {
+		if op.Path == "inventory/Potion" {
+			t.Errorf("our removal should stand, got %+v", op)
+		}
+	}
+}
+
+func TestParseMergeStrategies(t *testing.T) {
+	s, err := ParseMergeStrategies("Map=max, inventory=prefer")
+	if err != nil {
+		t.Fatalf("ParseMergeStrategies failed: %v", err)
+	}
+	if s[PathMap] != MergeMax || s[PathInventory] != MergePrefer {
+		t.Errorf("got %v", s)
+	}
+	for _, bad := range []string{"map", "nowhere=max", "map=newest"} {
+		if _, err = ParseMergeStrategies(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestMergeFiles(t *testing.T) {
+	dir := t.TempDir()
+	baseFile := filepath.Join(dir, "base")
+	oursFile := filepath.Join(dir, "ours")
+	theirsFile := filepath.Join(dir, "theirs")
+	output := filepath.Join(dir, "merged")
+
+	write := func(file string, edits *Patch) {
+		t.Helper()
+		p := loadTestSave(t)
+		if err := p.ApplyPatch(edits); err != nil {
+			t.Fatalf("ApplyPatch failed: %v", err)
+		}
+		if err := p.Save(p.SlotID(), file, global.PC); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	write(baseFile, NewPatch("base"))
+
+	ours := NewPatch("ours")
+	ours.Add(PatchSet, "misc/gil", 1000)
+	ours.Add(PatchAdd, "espers/Ramuh", nil)
+	write(oursFile, ours)
+
+	theirs := NewPatch("theirs")
+	theirs.Add(PatchSet, "misc/gil", 2000)
+	theirs.Add(PatchAdd, "espers/Siren", nil)
+	theirs.Add(PatchSet, "characters/Terra/level", 40)
+	write(theirsFile, theirs)
+
+	result, err := MergeFiles(baseFile, oursFile, theirsFile, output, global.PC, DefaultMergeOptions())
+	if err != nil {
+		t.Fatalf("MergeFiles failed: %v", err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Path != "misc/gil" {
+		t.Errorf("conflicts: %+v", result.Conflicts)
+	}
+
+	if err = New().Load(output, global.PC); err != nil {
+		t.Fatalf("failed to load merged save: %v", err)
+	}
+	for path, want := range map[string]interface{}{
+		"misc/gil":               2000,
+		"espers/Ramuh":           true,
+		"espers/Siren":           true,
+		"characters/Terra/level": 40,
+	} {
+		if v, err := GetPath(path); err != nil || v != want {
+			t.Errorf("%s: got %v (%v), want %v", path, v, err, want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package pr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// PatchVersion is the current version of the patch document format
+const PatchVersion = 1
+
+// PatchOp is the kind of change a patch operation makes
+type PatchOp string
+
+const (
+	// PatchSet replaces the value at the path
+	PatchSet PatchOp = "set"
+	// PatchAdd increases counts or grants collection members
+	PatchAdd PatchOp = "add"
+	// PatchRemove drops items, revokes collection members or empties slots
+	PatchRemove PatchOp = "remove"
+)
+
+// PatchOperation is a single change to a logical save path
+type PatchOperation struct {
+	Op    PatchOp     `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Patch is a portable list of changes that can be replayed onto any save
+type Patch struct {
+	Version     int              `json:"version"`
+	Description string           `json:"description,omitempty"`
+	Created     time.Time        `json:"created"`
+	Operations  []PatchOperation `json:"operations"`
+}
+
+// PatchError reports the operation a patch failed on
+type PatchError struct {
+	Index     int
+	Operation PatchOperation
+	Err       error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("operation %d (%s %s): %v", e.Index, e.Operation.Op, e.Operation.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// NewPatch creates an empty patch
+func NewPatch(description string) *Patch {
+	return &Patch{
+		Version:     PatchVersion,
+		Description: description,
+		Created:     time.Now(),
+		Operations:  make([]PatchOperation, 0),
+	}
+}
+
+// LoadPatch reads a patch document from a file
+func LoadPatch(path string) (*Patch, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch: %w", err)
+	}
+	return ParsePatch(data)
+}
+
+// ParsePatch decodes a patch document
+func ParsePatch(data []byte) (*Patch, error) {
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+
+	var patch Patch
+	if err := dec.Decode(&patch); err != nil {
+		return nil, fmt.Errorf("failed to parse patch: %w", err)
+	}
+	if patch.Version > PatchVersion {
+		return nil, fmt.Errorf("unsupported patch version %d (max %d)", patch.Version, PatchVersion)
+	}
+	for i, op := range patch.Operations {
+		switch op.Op {
+		case PatchSet, PatchAdd, PatchRemove:
+		default:
+			return nil, &PatchError{Index: i, Operation: op, Err: fmt.Errorf("unknown op %q", op.Op)}
+		}
+		if op.Op == PatchSet && op.Value == nil {
+			return nil, &PatchError{Index: i, Operation: op, Err: fmt.Errorf("set requires a value")}
+		}
+	}
+	return &patch, nil
+}
+
+// Save writes the patch document to a file
+func (pt *Patch) Save(path string) error {
+	data, err := json.MarshalIndent(pt, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal patch: %w", err)
+	}
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write patch: %w", err)
+	}
+	return nil
+}
+
+// Add appends an operation to the patch
+func (pt *Patch) Add(op PatchOp, path string, value interface{}) {
+	pt.Operations = append(pt.Operations, PatchOperation{Op: op, Path: path, Value: value})
+}
+
+// ApplyPatch applies a patch to the loaded save data. Every path is resolved
+// before anything is written, and when an operation fails the ones before it
+// are undone, so a patch that fails changes nothing.
+func (p *PR) ApplyPatch(patch *Patch) error {
+	for i, op := range patch.Operations {
+		a, err := resolvePath(op.Path)
+		if err == nil && op.Op == PatchAdd && a.add == nil {
+			err = fmt.Errorf("add is not supported")
+		} else if err == nil && op.Op == PatchRemove && a.remove == nil {
+			err = fmt.Errorf("remove is not supported")
+		}
+		if err != nil {
+			return &PatchError{Index: i, Operation: op, Err: err}
+		}
+	}
+
+	var (
+		state    = SaveModelState()
+		restores = make([]func(), 0, len(patch.Operations))
+	)
+	for i, op := range patch.Operations {
+		if old, err := loadPath(op.Path); err == nil {
+			path := op.Path
+			restores = append(restores, func() { _ = restorePath(path, old) })
+		}
+
+		var err error
+		switch op.Op {
+		case PatchSet:
+			err = SetPath(op.Path, op.Value)
+		case PatchAdd:
+			err = AddPath(op.Path, op.Value)
+		case PatchRemove:
+			err = RemovePath(op.Path)
+		default:
+			err = fmt.Errorf("unknown op %q", op.Op)
+		}
+		if err != nil {
+			for n := len(restores) - 1; n >= 0; n-- {
+				restores[n]()
+			}
+			state.Restore()
+			return &PatchError{Index: i, Operation: op, Err: err}
+		}
+	}
+	return nil
+}
+
+// ToPatch converts the differences in a report into a patch that turns the
+// old save into the new one. Diffs without a logical path are skipped.
+func (r *DiffReport) ToPatch(description string) *Patch {
+	patch := NewPatch(description)
+	for _, d := range r.Diffs {
+		if d.Path == "" {
+			continue
+		}
+		switch d.Type {
+		case DiffAdded, DiffModified:
+			patch.Add(PatchSet, d.Path, d.NewValue)
+		case DiffRemoved:
+			patch.Add(PatchRemove, d.Path, nil)
+		}
+	}
+	return patch
+}
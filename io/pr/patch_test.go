@@ -0,0 +1,129 @@
+package pr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"ffvi_editor/models"
+)
+
+func TestSplitJoinPath(t *testing.T) {
+	segments := []string{"inventory", "Potion/Ether", "a~b"}
+	path := JoinPath(segments...)
+	if path != "inventory/Potion~1Ether/a~0b" {
+		t.Fatalf("JoinPath = %q", path)
+	}
+	if got := SplitPath("/" + path); !reflect.DeepEqual(got, segments) {
+		t.Fatalf("SplitPath = %v, want %v", got, segments)
+	}
+	if got := SplitPath(""); got != nil {
+		t.Fatalf("SplitPath of empty path = %v", got)
+	}
+}
+
+func TestParsePatch(t *testing.T) {
+	patch, err := ParsePatch([]byte(`{"version":1,"operations":[{"op":"set","path":"misc/gil","value":500}]}`))
+	if err != nil {
+		t.Fatalf("ParsePatch failed: %v", err)
+	}
+	if len(patch.Operations) != 1 || patch.Operations[0].Path != "misc/gil" {
+		t.Fatalf("unexpected operations: %+v", patch.Operations)
+	}
+
+	if _, err = ParsePatch([]byte(`{"version":1,"operations":[{"op":"bogus","path":"misc/gil"}]}`)); err == nil {
+		t.Fatal("expected error for unknown op")
+	}
+	if _, err = ParsePatch([]byte(`{"version":99,"operations":[]}`)); err == nil {
+		t.Fatal("expected error for future version")
+	}
+}
+
+func TestApplyPatchMisc(t *testing.T) {
+	misc := models.GetMisc()
+	saved := *misc
+	defer func() { *misc = saved }()
+	misc.GP = 100
+
+	patch := NewPatch("test")
+	patch.Add(PatchSet, "misc/gil", 2500)
+	patch.Add(PatchSet, "/misc/steps", "42")
+	if err := New().ApplyPatch(patch); err != nil {
+		t.Fatalf("ApplyPatch failed: %v", err)
+	}
+	if misc.GP != 2500 || misc.Steps != 42 {
+		t.Fatalf("gil=%d steps=%d, want 2500 and 42", misc.GP, misc.Steps)
+	}
+	if v, err := GetPath("misc/gil"); err != nil || v != 2500 {
+		t.Fatalf("GetPath = %v, %v", v, err)
+	}
+}
+
+func TestApplyPatchIsAtomicOnUnknownPath(t *testing.T) {
+	misc := models.GetMisc()
+	saved := *misc
+	defer func() { *misc = saved }()
+	misc.GP = 100
+
+	patch := NewPatch("test")
+	patch.Add(PatchSet, "misc/gil", 2500)
+	patch.Add(PatchSet, "misc/notAField", 1)
+
+	err := New().ApplyPatch(patch)
+	var patchErr *PatchError
+	if !errors.As(err, &patchErr) || patchErr.Index != 1 {
+		t.Fatalf("expected PatchError at index 1, got %v", err)
+	}
+	if misc.GP != 100 {
+		t.Fatalf("gil changed to %d by a rejected patch", misc.GP)
+	}
+}
+
+func TestApplyPatchRejectsOutOfRange(t *testing.T) {
+	misc := models.GetMisc()
+	saved := *misc
+	defer func() { *misc = saved }()
+
+	patch := NewPatch("test")
+	patch.Add(PatchSet, "misc/gil", -5)
+	if err := New().ApplyPatch(patch); err == nil {
+		t.Fatal("expected error for negative gil")
+	}
+}
+
+func TestApplyPatchIsAtomicOnBadValue(t *testing.T) {
+	misc := models.GetMisc()
+	saved := *misc
+	defer func() { *misc = saved }()
+	misc.GP = 100
+	misc.Steps = 7
+
+	patch := NewPatch("test")
+	patch.Add(PatchSet, "misc/gil", 2500)
+	patch.Add(PatchSet, "misc/steps", 1.5)
+
+	err := New().ApplyPatch(patch)
+	var patchErr *PatchError
+	if !errors.As(err, &patchErr) || patchErr.Index != 1 {
+		t.Fatalf("expected PatchError at index 1, got %v", err)
+	}
+	if misc.GP != 100 || misc.Steps != 7 {
+		t.Fatalf("gil=%d steps=%d changed by a rejected patch", misc.GP, misc.Steps)
+	}
+}
+
+func TestDiffReportToPatch(t *testing.T) {
+	report := DiffReport{Diffs: []Diff{
+		{Type: DiffModified, Path: "misc/gil", NewValue: 10},
+		{Type: DiffRemoved, Path: "inventory/Potion"},
+		{Type: DiffModified, NewValue: "no path"},
+	}}
+	patch := report.ToPatch("")
+	want := []PatchOperation{
+		{Op: PatchSet, Path: "misc/gil", Value: 10},
+		{Op: PatchRemove, Path: "inventory/Potion"},
+	}
+	if !reflect.DeepEqual(patch.Operations, want) {
+		t.Fatalf("ToPatch = %+v, want %+v", patch.Operations, want)
+	}
+}
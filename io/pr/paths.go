@@ -0,0 +1,1007 @@
+package pr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ffvi_editor/models"
+	"ffvi_editor/models/consts"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+// Logical save paths address fields of the loaded save by name rather than by
+// their position in the raw JSON. Segments are separated by "/" (a leading "/"
+// is optional), names are matched without regard to case and "~1"/"~0" escape
+// "/" and "~" as in RFC 6901.
+//
+//	characters/<name>/<field>           name, enabled, level, exp, hp, maxHp, mp, maxMp, vigor, stamina, speed, magic,
+//	                                    esper (equipped esper name or ID, "" for none)
+//	characters/<name>/equipment/<slot>  weapon, shield, helmet, armor, relic1, relic2 (item name or ID)
+//	characters/<name>/spells/<spell>    learn percentage 0-100
+//	characters/<name>/commands/<index>  command name or ID
+//	inventory/<item>                    item count
+//	importantItems/<item>               item count
+//	espers/<esper>                      owned
+//	rages|lores|dances|blitzes|bushido/<name>  learned
+//	veldt/<index>                       encounter available
+//	party/<slot>                        character name (slot 0-3)
+//	map/<field>                         mapId, pointIn, transportationId, carryingHoverShip, x, y, z, direction,
+//	                                    playableCharacterCorpsId, gpsMapId, gpsAreaId, gpsId, gpsWidth, gpsHeight
+//	transportation/<index>/<field>      enabled, mapId, x, y, z, direction
+//	misc/<field>                        gil, steps, escapeCount, battleCount, saveCount, monstersKilled,
+//	                                    cursedShieldFights, openedChests, playTime, isComplete
+
+// Path categories
+const (
+	PathCharacters     = "characters"
+	PathInventory      = "inventory"
+	PathImportantItems = "importantItems"
+	PathEspers         = "espers"
+	PathRages          = "rages"
+	PathLores          = "lores"
+	PathDances         = "dances"
+	PathBlitzes        = "blitzes"
+	PathBushido        = "bushido"
+	PathVeldt          = "veldt"
+	PathParty          = "party"
+	PathMap            = "map"
+	PathTransportation = "transportation"
+	PathMisc           = "misc"
+)
+
+// fieldAccessor reads and writes a single logical save field. add and remove
+// are optional; fields without them reject those operations. load and store
+// are optional too: they read and write the stored value as is, without name
+// lookups or range checks, so that a recorded value can be put back exactly.
+type fieldAccessor struct {
+	path   string
+	get    func() interface{}
+	set    func(v interface{}) error
+	add    func(v interface{}) error
+	remove func() error
+	load   func() interface{}
+	store  func(v interface{})
+}
+
+// GetPath returns the current value at a logical save path
+func GetPath(path string) (interface{}, error) {
+	a, err := resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return a.get(), nil
+}
+
+// SetPath writes a value at a logical save path
+func SetPath(path string, value interface{}) error {
+	a, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+	return a.set(value)
+}
+
+// AddPath adds to the value at a logical save path: counts are increased,
+// collection members (espers, skills, spells) are granted
+func AddPath(path string, value interface{}) error {
+	a, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if a.add == nil {
+		return fmt.Errorf("%s: add is not supported", a.path)
+	}
+	return a.add(value)
+}
+
+// RemovePath removes the value at a logical save path: inventory rows are
+// dropped, collection members are revoked, party slots are emptied
+func RemovePath(path string) error {
+	a, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if a.remove == nil {
+		return fmt.Errorf("%s: remove is not supported", a.path)
+	}
+	return a.remove()
+}
+
+// loadPath returns the stored value at a path, for restorePath
+func loadPath(path string) (interface{}, error) {
+	a, err := resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if a.load == nil {
+		return a.get(), nil
+	}
+	return a.load(), nil
+}
+
+// restorePath puts back a value returned by loadPath
+func restorePath(path string, value interface{}) error {
+	a, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if a.store == nil {
+		return a.set(value)
+	}
+	a.store(value)
+	return nil
+}
+
+// JoinPath builds a logical path from segments, escaping them as needed
+func JoinPath(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, s := range segments {
+		s = strings.ReplaceAll(s, "~", "~0")
+		escaped[i] = strings.ReplaceAll(s, "/", "~1")
+	}
+	return strings.Join(escaped, "/")
+}
+
+// SplitPath breaks a logical path into its unescaped segments
+func SplitPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "/")
+	if path == "" {
+		return nil
+	}
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		s = strings.ReplaceAll(s, "~1", "/")
+		segments[i] = strings.ReplaceAll(s, "~0", "~")
+	}
+	return segments
+}
+
+// resolvePath finds the accessor for a logical path
+func resolvePath(path string) (*fieldAccessor, error) {
+	segments := SplitPath(path)
+	if len(segments) < 2 {
+		return nil, fmt.Errorf("invalid save path %q", path)
+	}
+
+	var (
+		a   *fieldAccessor
+		err error
+	)
+	switch strings.ToLower(segments[0]) {
+	case strings.ToLower(PathCharacters):
+		a, err = resolveCharacterPath(segments[1:])
+	case strings.ToLower(PathInventory):
+		a, err = resolveInventoryPath(pri.GetInventory(), pr.ItemsByName, pr.ItemsByID, segments[1:])
+	case strings.ToLower(PathImportantItems):
+		a, err = resolveInventoryPath(pri.GetImportantInventory(), pr.ImportantItemsByName, pr.ImportantItemsByID, segments[1:])
+	case strings.ToLower(PathEspers):
+		a, err = resolveCheckedPath(pr.Espers, segments[1:])
+	case strings.ToLower(PathRages):
+		a, err = resolveCheckedPath(pr.Rages, segments[1:])
+	case strings.ToLower(PathLores):
+		a, err = resolveCheckedPath(pr.Lores, segments[1:])
+	case strings.ToLower(PathDances):
+		a, err = resolveCheckedPath(pr.Dances, segments[1:])
+	case strings.ToLower(PathBlitzes):
+		a, err = resolveCheckedPath(pr.Blitzes, segments[1:])
+	case strings.ToLower(PathBushido):
+		a, err = resolveCheckedPath(pr.Bushidos, segments[1:])
+	case strings.ToLower(PathVeldt):
+		a, err = resolveVeldtPath(segments[1:])
+	case strings.ToLower(PathParty):
+		a, err = resolvePartyPath(segments[1:])
+	case strings.ToLower(PathMap):
+		a, err = resolveMapPath(segments[1:])
+	case strings.ToLower(PathTransportation):
+		a, err = resolveTransportationPath(segments[1:])
+	case strings.ToLower(PathMisc):
+		a, err = resolveMiscPath(segments[1:])
+	default:
+		err = fmt.Errorf("unknown save path category %q", segments[0])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	a.path = path
+	return a, nil
+}
+
+func resolveCharacterPath(segments []string) (*fieldAccessor, error) {
+	c := FindCharacter(segments[0])
+	if c == nil {
+		return nil, fmt.Errorf("unknown character %q", segments[0])
+	}
+	if len(segments) < 2 {
+		return nil, fmt.Errorf("missing character field")
+	}
+
+	field := strings.ToLower(segments[1])
+	if len(segments) == 3 {
+		switch field {
+		case "equipment":
+			return equipmentAccessor(c, segments[2])
+		case "spells":
+			return spellAccessor(c, segments[2])
+		case "commands":
+			return commandAccessor(c, segments[2])
+		}
+	}
+	if len(segments) != 2 {
+		return nil, fmt.Errorf("invalid character path")
+	}
+
+	switch field {
+	case "name":
+		return &fieldAccessor{
+			get: func() interface{} { return c.Name },
+			set: func(v interface{}) error {
+				s, err := toString(v)
+				if err == nil {
+					c.Name = s
+				}
+				return err
+			},
+		}, nil
+	case "enabled":
+		return boolAccessor(&c.IsEnabled), nil
+	case "level":
+		return intAccessor(&c.Level, 1, 99), nil
+	case "exp":
+		return intAccessor(&c.Exp, 0, 9999999), nil
+	case "hp":
+		return intAccessor(&c.HP.Current, 0, pri.MaxHP), nil
+	case "maxhp":
+		return intAccessor(&c.HP.Max, 0, pri.MaxHP), nil
+	case "mp":
+		return intAccessor(&c.MP.Current, 0, pri.MaxMP), nil
+	case "maxmp":
+		return intAccessor(&c.MP.Max, 0, pri.MaxMP), nil
+	case "vigor":
+		return intAccessor(&c.Vigor, 0, 255), nil
+	case "stamina":
+		return intAccessor(&c.Stamina, 0, 255), nil
+	case "speed":
+		return intAccessor(&c.Speed, 0, 255), nil
+	case "magic":
+		return intAccessor(&c.Magic, 0, 255), nil
+	case "esper":
+		return &fieldAccessor{
+			get: func() interface{} { return EsperName(c.EsperID) },
+			set: func(v interface{}) error {
+				id, err := ResolveEsperID(v)
+				if err == nil {
+					c.EsperID = id
+				}
+				return err
+			},
+			remove: func() error {
+				c.EsperID = 0
+				return nil
+			},
+			load:  func() interface{} { return c.EsperID },
+			store: func(v interface{}) { c.EsperID = v.(int) },
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown character field %q", segments[1])
+}
+
+func equipmentAccessor(c *models.Character, slot string) (*fieldAccessor, error) {
+	var (
+		id      *int
+		emptyID int
+	)
+	switch strings.ToLower(slot) {
+	case "weapon":
+		id, emptyID = &c.Equipment.WeaponID, 93
+	case "shield":
+		id, emptyID = &c.Equipment.ShieldID, 93
+	case "helmet":
+		id, emptyID = &c.Equipment.HelmetID, 198
+	case "armor":
+		id, emptyID = &c.Equipment.ArmorID, 199
+	case "relic1":
+		id, emptyID = &c.Equipment.Relic1ID, 200
+	case "relic2":
+		id, emptyID = &c.Equipment.Relic2ID, 200
+	default:
+		return nil, fmt.Errorf("unknown equipment slot %q", slot)
+	}
+	return &fieldAccessor{
+		get: func() interface{} { return ItemName(*id) },
+		set: func(v interface{}) error {
+			i, err := ResolveItemID(v, pr.ItemsByName, pr.ItemsByID)
+			if err == nil {
+				*id = i
+			}
+			return err
+		},
+		remove: func() error {
+			*id = emptyID
+			return nil
+		},
+		load:  func() interface{} { return *id },
+		store: func(v interface{}) { *id = v.(int) },
+	}, nil
+}
+
+func spellAccessor(c *models.Character, name string) (*fieldAccessor, error) {
+	var spell *models.Spell
+	for _, s := range c.SpellsByIndex {
+		if matchName(s.Name, name) || strconv.Itoa(s.Index) == name {
+			spell = s
+			break
+		}
+	}
+	if spell == nil {
+		return nil, fmt.Errorf("unknown spell %q", name)
+	}
+	a := intAccessor(&spell.Value, 0, 100)
+	a.add = func(interface{}) error {
+		spell.Value = 100
+		return nil
+	}
+	a.remove = func() error {
+		spell.Value = 0
+		return nil
+	}
+	return a, nil
+}
+
+func commandAccessor(c *models.Character, index string) (*fieldAccessor, error) {
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 || i >= len(c.Commands) {
+		return nil, fmt.Errorf("invalid command slot %q", index)
+	}
+	return &fieldAccessor{
+		get: func() interface{} {
+			if c.Commands[i] == nil {
+				return ""
+			}
+			return c.Commands[i].Name
+		},
+		set: func(v interface{}) error {
+			cmd, err := ResolveCommand(v)
+			if err != nil {
+				return err
+			}
+			c.Commands[i] = cmd
+			c.EnableCommandsSave = true
+			return nil
+		},
+		load: func() interface{} { return c.Commands[i] },
+		store: func(v interface{}) {
+			c.Commands[i] = v.(*models.Command)
+			c.EnableCommandsSave = true
+		},
+	}, nil
+}
+
+func resolveInventoryPath(inv *pri.Inventory, byName map[string]int, byID map[int]string, segments []string) (*fieldAccessor, error) {
+	if len(segments) != 1 {
+		return nil, fmt.Errorf("invalid inventory path")
+	}
+	id, err := ResolveItemID(segments[0], byName, byID)
+	if err != nil {
+		return nil, err
+	}
+	var putCount func(count int) error
+	find := func() *pri.Row {
+		for _, r := range inv.Rows {
+			if r != nil && r.ItemID == id && r.Count > 0 {
+				return r
+			}
+		}
+		return nil
+	}
+	setCount := func(count int) error {
+		if count < 0 || count > 99 {
+			return fmt.Errorf("count %d out of range 0-99", count)
+		}
+		return putCount(count)
+	}
+	putCount = func(count int) error {
+		if r := find(); r != nil {
+			r.Count = count
+			return nil
+		}
+		if count == 0 {
+			return nil
+		}
+		for _, r := range inv.Rows {
+			if r != nil && (r.ItemID == 0 || r.Count == 0) {
+				r.ItemID, r.Count = id, count
+				return nil
+			}
+		}
+		if len(inv.Rows) >= inv.Size {
+			return fmt.Errorf("inventory is full")
+		}
+		inv.Set(len(inv.Rows), pri.Row{ItemID: id, Count: count})
+		return nil
+	}
+	return &fieldAccessor{
+		get: func() interface{} {
+			if r := find(); r != nil {
+				return r.Count
+			}
+			return 0
+		},
+		set: func(v interface{}) error {
+			count, err := toInt(v)
+			if err != nil {
+				return err
+			}
+			return setCount(count)
+		},
+		add: func(v interface{}) error {
+			count := 1
+			if v != nil {
+				var err error
+				if count, err = toInt(v); err != nil {
+					return err
+				}
+			}
+			current := 0
+			if r := find(); r != nil {
+				current = r.Count
+			}
+			if current+count > 99 {
+				count = 99 - current
+			}
+			return setCount(current + count)
+		},
+		remove: func() error {
+			for _, r := range inv.Rows {
+				if r != nil && r.ItemID == id {
+					r.ItemID, r.Count = 0, 0
+				}
+			}
+			return nil
+		},
+		load: func() interface{} {
+			if r := find(); r != nil {
+				return r.Count
+			}
+			return 0
+		},
+		store: func(v interface{}) { _ = putCount(v.(int)) },
+	}, nil
+}
+
+func resolveCheckedPath(list []*consts.NameValueChecked, segments []string) (*fieldAccessor, error) {
+	if len(segments) != 1 {
+		return nil, fmt.Errorf("invalid path")
+	}
+	var nvc *consts.NameValueChecked
+	for _, v := range list {
+		if matchName(v.Name, segments[0]) || strconv.Itoa(v.Value) == segments[0] {
+			nvc = v
+			break
+		}
+	}
+	if nvc == nil {
+		return nil, fmt.Errorf("unknown name %q", segments[0])
+	}
+	a := boolAccessor(&nvc.Checked)
+	a.add = func(interface{}) error {
+		nvc.Checked = true
+		return nil
+	}
+	a.remove = func() error {
+		nvc.Checked = false
+		return nil
+	}
+	return a, nil
+}
+
+func resolveVeldtPath(segments []string) (*fieldAccessor, error) {
+	veldt := pri.GetVeldt()
+	i, err := strconv.Atoi(segments[0])
+	if err != nil || len(segments) != 1 || i < 0 || i >= len(veldt.Encounters) {
+		return nil, fmt.Errorf("invalid veldt encounter %q", strings.Join(segments, "/"))
+	}
+	a := boolAccessor(&veldt.Encounters[i])
+	a.add = func(interface{}) error {
+		veldt.Encounters[i] = true
+		return nil
+	}
+	a.remove = func() error {
+		veldt.Encounters[i] = false
+		return nil
+	}
+	return a, nil
+}
+
+func resolvePartyPath(segments []string) (*fieldAccessor, error) {
+	party := pri.GetParty()
+	slot, err := strconv.Atoi(segments[0])
+	if err != nil || len(segments) != 1 || slot < 0 || slot >= len(party.Members) {
+		return nil, fmt.Errorf("invalid party slot %q", strings.Join(segments, "/"))
+	}
+	return &fieldAccessor{
+		get: func() interface{} {
+			if m := party.Members[slot]; m != nil {
+				return m.Name
+			}
+			return pri.EmptyPartyMember.Name
+		},
+		set: func(v interface{}) error {
+			name, err := toString(v)
+			if err != nil {
+				return err
+			}
+			for possible := range party.Possible {
+				if matchName(possible, name) {
+					name = possible
+					break
+				}
+			}
+			if err = party.SetMemberByName(slot, name); err != nil {
+				return err
+			}
+			party.Enabled = true
+			return nil
+		},
+		remove: func() error {
+			party.Members[slot] = pri.EmptyPartyMember
+			party.Enabled = true
+			return nil
+		},
+		load:  func() interface{} { return party.Members[slot] },
+		store: func(v interface{}) { party.Members[slot] = v.(*pri.Member) },
+	}, nil
+}
+
+func resolveMapPath(segments []string) (*fieldAccessor, error) {
+	if len(segments) != 1 {
+		return nil, fmt.Errorf("invalid map path")
+	}
+	md := pri.GetMapData()
+	switch strings.ToLower(segments[0]) {
+	case "mapid":
+		return intAccessor(&md.MapID, -1, 1<<31-1), nil
+	case "pointin":
+		return intAccessor(&md.PointIn, -1, 1<<31-1), nil
+	case "transportationid":
+		return intAccessor(&md.TransportationID, -1, 1<<31-1), nil
+	case "carryinghovership":
+		return boolAccessor(&md.CarryingHoverShip), nil
+	case "x":
+		return floatAccessor(&md.Player.X), nil
+	case "y":
+		return floatAccessor(&md.Player.Y), nil
+	case "z":
+		return floatAccessor(&md.Player.Z), nil
+	case "direction":
+		return intAccessor(&md.PlayerDirection, 0, 7), nil
+	case "playablecharactercorpsid":
+		return intAccessor(&md.PlayableCharacterCorpsID, -1, 1<<31-1), nil
+	case "gpsmapid":
+		return intAccessor(&md.Gps.MapID, -1, 1<<31-1), nil
+	case "gpsareaid":
+		return intAccessor(&md.Gps.AreaID, -1, 1<<31-1), nil
+	case "gpsid":
+		return intAccessor(&md.Gps.GpsID, -1, 1<<31-1), nil
+	case "gpswidth":
+		return intAccessor(&md.Gps.Width, 0, 1<<31-1), nil
+	case "gpsheight":
+		return intAccessor(&md.Gps.Height, 0, 1<<31-1), nil
+	}
+	return nil, fmt.Errorf("unknown map field %q", segments[0])
+}
+
+func resolveTransportationPath(segments []string) (*fieldAccessor, error) {
+	i, err := strconv.Atoi(segments[0])
+	if err != nil || len(segments) != 2 || i < 0 || i >= len(pri.Transportations) || pri.Transportations[i] == nil {
+		return nil, fmt.Errorf("invalid transportation path %q", strings.Join(segments, "/"))
+	}
+	t := pri.Transportations[i]
+	switch strings.ToLower(segments[1]) {
+	case "enabled":
+		return &fieldAccessor{
+			get: func() interface{} { return t.Enabled },
+			set: func(v interface{}) error {
+				b, err := toBool(v)
+				if err != nil {
+					return err
+				}
+				t.Enabled = b
+				t.ForcedEnabled = b
+				t.ForcedDisabled = !b
+				return nil
+			},
+			load: func() interface{} { return [3]bool{t.Enabled, t.ForcedEnabled, t.ForcedDisabled} },
+			store: func(v interface{}) {
+				b := v.([3]bool)
+				t.Enabled, t.ForcedEnabled, t.ForcedDisabled = b[0], b[1], b[2]
+			},
+		}, nil
+	case "mapid":
+		return intAccessor(&t.MapID, -1, 1<<31-1), nil
+	case "x":
+		return floatAccessor(&t.Position.X), nil
+	case "y":
+		return floatAccessor(&t.Position.Y), nil
+	case "z":
+		return floatAccessor(&t.Position.Z), nil
+	case "direction":
+		return intAccessor(&t.Direction, 0, 7), nil
+	}
+	return nil, fmt.Errorf("unknown transportation field %q", segments[1])
+}
+
+func resolveMiscPath(segments []string) (*fieldAccessor, error) {
+	if len(segments) != 1 {
+		return nil, fmt.Errorf("invalid misc path")
+	}
+	misc := models.GetMisc()
+	cheats := pri.GetCheats()
+	switch strings.ToLower(segments[0]) {
+	case "gil":
+		return intAccessor(&misc.GP, 0, 9999999), nil
+	case "steps":
+		return intAccessor(&misc.Steps, 0, 9999999), nil
+	case "escapecount":
+		return intAccessor(&misc.EscapeCount, 0, 9999999), nil
+	case "battlecount":
+		return intAccessor(&misc.BattleCount, 0, 9999999), nil
+	case "savecount":
+		return intAccessor(&misc.NumberOfSaves, 0, 9999999), nil
+	case "monsterskilled":
+		return intAccessor(&misc.MonstersKilledCount, 0, 9999999), nil
+	case "cursedshieldfights":
+		return intAccessor(&misc.CursedShieldFightCount, 0, 255), nil
+	case "openedchests":
+		return intAccessor(&cheats.OpenedChestCount, 0, 9999999), nil
+	case "playtime":
+		return floatAccessor(&cheats.PlayTime), nil
+	case "iscomplete":
+		return boolAccessor(&cheats.IsCompleteFlag), nil
+	}
+	return nil, fmt.Errorf("unknown misc field %q", segments[0])
+}
+
+func intAccessor(p *int, min, max int) *fieldAccessor {
+	return &fieldAccessor{
+		get: func() interface{} { return *p },
+		set: func(v interface{}) error {
+			i, err := toInt(v)
+			if err != nil {
+				return err
+			}
+			if i < min || i > max {
+				return fmt.Errorf("value %d out of range %d-%d", i, min, max)
+			}
+			*p = i
+			return nil
+		},
+		load:  func() interface{} { return *p },
+		store: func(v interface{}) { *p = v.(int) },
+	}
+}
+
+func floatAccessor(p *float64) *fieldAccessor {
+	return &fieldAccessor{
+		get: func() interface{} { return *p },
+		set: func(v interface{}) error {
+			f, err := toFloat(v)
+			if err == nil {
+				*p = f
+			}
+			return err
+		},
+	}
+}
+
+func boolAccessor(p *bool) *fieldAccessor {
+	return &fieldAccessor{
+		get: func() interface{} { return *p },
+		set: func(v interface{}) error {
+			b, err := toBool(v)
+			if err == nil {
+				*p = b
+			}
+			return err
+		},
+	}
+}
+
+// FindCharacter looks up a character by root name or current name, ignoring case
+func FindCharacter(name string) *models.Character {
+	for _, c := range pri.Characters {
+		if matchName(c.RootName, name) {
+			return c
+		}
+	}
+	for _, c := range pri.Characters {
+		if matchName(c.Name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// ItemName returns the display name of an item ID, or the ID itself if unknown
+func ItemName(id int) string {
+	if name, ok := pr.ItemsByID[id]; ok {
+		return strings.TrimSpace(name)
+	}
+	if name, ok := pr.ImportantItemsByID[id]; ok {
+		return strings.TrimSpace(name)
+	}
+	return strconv.Itoa(id)
+}
+
+// ResolveItemID converts an item name or numeric ID into a known item ID
+func ResolveItemID(v interface{}, byName map[string]int, byID map[int]string) (int, error) {
+	if s, ok := v.(string); ok {
+		if id, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+			v = id
+		} else {
+			for name, id := range byName {
+				if matchName(name, s) {
+					return id, nil
+				}
+			}
+			return 0, fmt.Errorf("unknown item %q", s)
+		}
+	}
+	id, err := toInt(v)
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := byID[id]; !ok {
+		return 0, fmt.Errorf("unknown item ID %d", id)
+	}
+	return id, nil
+}
+
+// EsperName returns the name of an esper ID, "" for none or the ID itself if
+// unknown
+func EsperName(id int) string {
+	if id == 0 {
+		return ""
+	}
+	if e, ok := pr.EspersByValue[id]; ok {
+		return e.Name
+	}
+	return strconv.Itoa(id)
+}
+
+// ResolveEsperID converts an esper name or numeric ID into a known esper ID;
+// "" and 0 mean none
+func ResolveEsperID(v interface{}) (int, error) {
+	if s, ok := v.(string); ok {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return 0, nil
+		}
+		if id, err := strconv.Atoi(s); err == nil {
+			v = id
+		} else {
+			for _, e := range pr.Espers {
+				if matchName(e.Name, s) {
+					return e.Value, nil
+				}
+			}
+			return 0, fmt.Errorf("unknown esper %q", s)
+		}
+	}
+	id, err := toInt(v)
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := pr.EspersByValue[id]; !ok && id != 0 {
+		return 0, fmt.Errorf("unknown esper ID %d", id)
+	}
+	return id, nil
+}
+
+// ResolveCommand converts a command name or numeric ID into a command
+func ResolveCommand(v interface{}) (*models.Command, error) {
+	if s, ok := v.(string); ok {
+		if id, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+			v = id
+		} else {
+			for name, cmd := range pr.CommandLookupByName {
+				if matchName(name, s) {
+					return cmd, nil
+				}
+			}
+			return nil, fmt.Errorf("unknown command %q", s)
+		}
+	}
+	id, err := toInt(v)
+	if err != nil {
+		return nil, err
+	}
+	if cmd, ok := pr.CommandLookupByValue[id]; ok {
+		return cmd, nil
+	}
+	return nil, fmt.Errorf("unknown command ID %d", id)
+}
+
+// PathNames returns the names that may follow a path category, for use in
+// completion and documentation
+func PathNames(category string) []string {
+	var names []string
+	addChecked := func(list []*consts.NameValueChecked) {
+		for _, v := range list {
+			names = append(names, v.Name)
+		}
+	}
+	switch strings.ToLower(category) {
+	case strings.ToLower(PathCharacters):
+		for _, c := range pri.Characters {
+			names = append(names, c.RootName)
+		}
+	case strings.ToLower(PathInventory):
+		for name, id := range pr.ItemsByName {
+			if id != 0 {
+				names = append(names, strings.TrimSpace(name))
+			}
+		}
+	case strings.ToLower(PathImportantItems):
+		for name := range pr.ImportantItemsByName {
+			names = append(names, strings.TrimSpace(name))
+		}
+	case strings.ToLower(PathEspers):
+		addChecked(pr.Espers)
+	case strings.ToLower(PathRages):
+		addChecked(pr.Rages)
+	case strings.ToLower(PathLores):
+		addChecked(pr.Lores)
+	case strings.ToLower(PathDances):
+		addChecked(pr.Dances)
+	case strings.ToLower(PathBlitzes):
+		addChecked(pr.Blitzes)
+	case strings.ToLower(PathBushido):
+		addChecked(pr.Bushidos)
+	case "spells":
+		for _, s := range pr.Spells {
+			names = append(names, s.Name)
+		}
+	case "commands":
+		for _, c := range pr.Commands {
+			names = append(names, c.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PathCategories returns the top level segments of the logical path namespace
+func PathCategories() []string {
+	return []string{
+		PathCharacters, PathInventory, PathImportantItems, PathEspers, PathRages, PathLores,
+		PathDances, PathBlitzes, PathBushido, PathVeldt, PathParty, PathMap, PathTransportation, PathMisc,
+	}
+}
+
+// PathFields returns the field names addressable under a category (for
+// completion). Characters also accept "equipment", "spells" and "commands",
+// and "equipment" lists its slots.
+func PathFields(category string) []string {
+	switch strings.ToLower(category) {
+	case strings.ToLower(PathCharacters):
+		return []string{"name", "enabled", "level", "exp", "hp", "maxHp", "mp", "maxMp",
+			"vigor", "stamina", "speed", "magic", "esper", "equipment", "spells", "commands"}
+	case "equipment":
+		return []string{"weapon", "shield", "helmet", "armor", "relic1", "relic2"}
+	case strings.ToLower(PathMap):
+		return []string{"mapId", "pointIn", "transportationId", "carryingHoverShip", "x", "y", "z", "direction",
+			"playableCharacterCorpsId", "gpsMapId", "gpsAreaId", "gpsId", "gpsWidth", "gpsHeight"}
+	case strings.ToLower(PathTransportation):
+		return []string{"enabled", "mapId", "x", "y", "z", "direction"}
+	case strings.ToLower(PathMisc):
+		return []string{"gil", "steps", "escapeCount", "battleCount", "saveCount", "monstersKilled",
+			"cursedShieldFights", "openedChests", "playTime", "isComplete"}
+	}
+	return nil
+}
+
+func matchName(name, query string) bool {
+	return strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(query))
+}
+
+func toInt(v interface{}) (int, error) {
+	switch t := v.(type) {
+	case int:
+		return t, nil
+	case int64:
+		return int(t), nil
+	case int32:
+		return int(t), nil
+	case float64:
+		return wholeNumber(t)
+	case float32:
+		return wholeNumber(float64(t))
+	case uint64:
+		return int(t), nil
+	case json.Number:
+		i, err := t.Int64()
+		if err != nil {
+			f, ferr := t.Float64()
+			if ferr != nil {
+				return 0, err
+			}
+			return wholeNumber(f)
+		}
+		return int(i), nil
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(t))
+		if err != nil {
+			return 0, fmt.Errorf("expected a number, got %q", t)
+		}
+		return i, nil
+	case bool:
+		if t {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("expected a number, got %T", v)
+}
+
+// wholeNumber converts a float without a fraction, such as a JSON or Lua
+// number, to an int
+func wholeNumber(f float64) (int, error) {
+	if f != math.Trunc(f) || math.Abs(f) > 1<<53 {
+		return 0, fmt.Errorf("expected a whole number, got %v", f)
+	}
+	return int(f), nil
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch t := v.(type) {
+	case float64:
+		return t, nil
+	case float32:
+		return float64(t), nil
+	case json.Number:
+		return t.Float64()
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		if err != nil {
+			return 0, fmt.Errorf("expected a number, got %q", t)
+		}
+		return f, nil
+	}
+	i, err := toInt(v)
+	return float64(i), err
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch t := v.(type) {
+	case bool:
+		return t, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(t))
+		if err != nil {
+			return false, fmt.Errorf("expected true or false, got %q", t)
+		}
+		return b, nil
+	}
+	i, err := toInt(v)
+	if err != nil {
+		return false, fmt.Errorf("expected true or false, got %T", v)
+	}
+	return i != 0, nil
+}
+
+func toString(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case fmt.Stringer:
+		return t.String(), nil
+	case nil:
+		return "", fmt.Errorf("expected a string, got nothing")
+	}
+	return fmt.Sprint(v), nil
+}
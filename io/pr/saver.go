@@ -185,6 +185,12 @@ func (p *PR) saveCharacters(addedItems *[]int) (err error) {
 			return
 		}
 
+		if d.Has(MagicStoneId) {
+			if err = p.setValue(d, MagicStoneId, c.EsperID); err != nil {
+				return
+			}
+		}
+
 		if c.EnableCommandsSave {
 			sl := make([]interface{}, len(c.Commands))
 			for i, cmd := range c.Commands {
@@ -515,6 +521,9 @@ func (p *PR) saveEspers() (err error) {
 	return p.setTarget(p.UserData, OwnedMagicStoneList, sl)
 }
 
+// crashingItems are item IDs the game crashes on
+var crashingItems = map[int]bool{184: true, 243: true}
+
 func (p *PR) saveInventory(baseKey string, sortKey string, inventory *pri.Inventory, addedItems []int) (err error) {
 	var (
 		rows             = inventory.GetRows()
@@ -533,7 +542,7 @@ func (p *PR) saveInventory(baseKey string, sortKey string, inventory *pri.Invent
 			found[r.ItemID] = true
 		}
 		// Skip known crashing items
-		if r.ItemID == 184 || r.ItemID == 243 {
+		if crashingItems[r.ItemID] {
 			continue
 		}
 		// Skip Empty rows
@@ -659,8 +668,15 @@ func (p *PR) saveMapData() (err error) {
 		return
 	}
 
+	// Keep the keys the editor doesn't model
 	pe := jo.NewOrderedMap()
-	pos := jo.NewOrderedMap()
+	if err = p.unmarshalFrom(p.MapData, PlayerEntity, pe); err != nil {
+		return
+	}
+	pos, ok := pe.Get(PlayerPosition).(*jo.OrderedMap)
+	if !ok {
+		pos = jo.NewOrderedMap()
+	}
 	pos.Set("x", md.Player.X)
 	pos.Set("y", md.Player.Y)
 	pos.Set("z", md.Player.Z)
@@ -671,6 +687,9 @@ func (p *PR) saveMapData() (err error) {
 	}
 
 	gps := jo.NewOrderedMap()
+	if err = p.unmarshalFrom(p.MapData, GpsData, gps); err != nil {
+		return
+	}
 	gps.Set(GpsDataMapID, md.Gps.MapID)
 	gps.Set(GpsDataAreaID, md.Gps.AreaID)
 	gps.Set(GpsDataID, md.Gps.GpsID)
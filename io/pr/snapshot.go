@@ -0,0 +1,175 @@
+package pr
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/file"
+	"ffvi_editor/models/consts"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+// Snapshot is a copy of every logical save value (see paths.go) taken from
+// the loaded models. Loading a save replaces the models, so snapshots are how
+// two saves are held side by side.
+//
+// Inventory items and learned espers and skills are only present while owned,
+// so gaining or losing one shows up as an added or removed path.
+type Snapshot struct {
+	Taken  time.Time
+	Values map[string]interface{}
+	paths  []string // Values keys in namespace order
+
+	inventoryOrder []string // Inventory paths in row order
+}
+
+// SnapshotFile loads a save file and snapshots it. The models of the save
+// loaded before are put back, so the save open in the editor is left alone.
+func SnapshotFile(fromFile string, saveType global.SaveFileType) (*Snapshot, error) {
+	out, trimmed, err := file.LoadFile(fromFile, saveType)
+	if err != nil {
+		return nil, err
+	}
+	return snapshotJSON(out, trimmed)
+}
+
+// SnapshotData snapshots the contents of a save file as SnapshotFile does
+func SnapshotData(data []byte, saveType global.SaveFileType) (*Snapshot, error) {
+	out, trimmed, err := file.DecodeFile(data, saveType)
+	if err != nil {
+		return nil, err
+	}
+	return snapshotJSON(out, trimmed)
+}
+
+func snapshotJSON(out, trimmed []byte) (*Snapshot, error) {
+	state := SaveModelState()
+	defer state.Restore()
+	if err := New().LoadJSON(out, trimmed); err != nil {
+		return nil, err
+	}
+	return TakeSnapshot(), nil
+}
+
+// TakeSnapshot copies the values of the currently loaded save
+func TakeSnapshot() *Snapshot {
+	s := &Snapshot{
+		Taken:  time.Now(),
+		Values: make(map[string]interface{}),
+	}
+
+	for _, c := range pri.Characters {
+		base := JoinPath(PathCharacters, c.RootName)
+		for _, f := range PathFields(PathCharacters) {
+			s.capture(base + "/" + f)
+		}
+		for _, slot := range PathFields("equipment") {
+			s.capture(base + "/equipment/" + slot)
+		}
+		for _, spell := range c.SpellsByIndex {
+			s.capture(base + "/spells/" + JoinPath(spell.Name))
+		}
+		for i := range c.Commands {
+			s.capture(base + "/commands/" + strconv.Itoa(i))
+		}
+	}
+
+	s.inventoryOrder = s.captureInventory(PathInventory, pri.GetInventory())
+	s.captureInventory(PathImportantItems, pri.GetImportantInventory())
+
+	for _, l := range []struct {
+		category string
+		list     []*consts.NameValueChecked
+	}{
+		{PathEspers, pr.Espers},
+		{PathRages, pr.Rages},
+		{PathLores, pr.Lores},
+		{PathDances, pr.Dances},
+		{PathBlitzes, pr.Blitzes},
+		{PathBushido, pr.Bushidos},
+	} {
+		for _, v := range l.list {
+			if v.Checked {
+				s.capture(JoinPath(l.category, v.Name))
+			}
+		}
+	}
+
+	for i := range pri.GetVeldt().Encounters {
+		s.capture(JoinPath(PathVeldt, strconv.Itoa(i)))
+	}
+	for i := range pri.GetParty().Members {
+		s.capture(JoinPath(PathParty, strconv.Itoa(i)))
+	}
+	for _, f := range PathFields(PathMap) {
+		s.capture(JoinPath(PathMap, f))
+	}
+	for i, t := range pri.Transportations {
+		if t == nil {
+			continue
+		}
+		for _, f := range PathFields(PathTransportation) {
+			s.capture(JoinPath(PathTransportation, strconv.Itoa(i), f))
+		}
+	}
+	for _, f := range PathFields(PathMisc) {
+		s.capture(JoinPath(PathMisc, f))
+	}
+	return s
+}
+
+// Paths returns the snapshot's paths in namespace order
+func (s *Snapshot) Paths() []string {
+	paths := make([]string, len(s.paths))
+	copy(paths, s.paths)
+	return paths
+}
+
+// Get returns the value at a path and whether the snapshot has it
+func (s *Snapshot) Get(path string) (interface{}, bool) {
+	v, ok := s.Values[path]
+	return v, ok
+}
+
+// capture records the current value of a path; paths that don't resolve
+// (such as the non-value character fields "spells") are skipped
+func (s *Snapshot) capture(path string) {
+	if _, ok := s.Values[path]; ok {
+		return
+	}
+	v, err := GetPath(path)
+	if err != nil {
+		return
+	}
+	s.Values[path] = v
+	s.paths = append(s.paths, path)
+}
+
+// captureInventory records the count of every owned item and returns the
+// item paths in row order
+func (s *Snapshot) captureInventory(category string, inv *pri.Inventory) []string {
+	byID := pr.ItemsByID
+	if category == PathImportantItems {
+		byID = pr.ImportantItemsByID
+	}
+
+	order := make([]string, 0, len(inv.Rows))
+	for _, r := range inv.Rows {
+		if r == nil || r.ItemID == 0 || r.Count <= 0 {
+			continue
+		}
+		name := strconv.Itoa(r.ItemID)
+		if n, ok := byID[r.ItemID]; ok {
+			name = strings.TrimSpace(n)
+		}
+		path := JoinPath(category, name)
+		if _, ok := s.Values[path]; !ok {
+			order = append(order, path)
+		}
+		s.capture(path)
+	}
+	return order
+}
@@ -0,0 +1,136 @@
+package pr
+
+import (
+	"ffvi_editor/models"
+	"ffvi_editor/models/consts"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+// ModelState is a copy of every model a save loads into that can be put
+// back exactly, row order included. Unlike a Snapshot it isn't meant for
+// comparing, only for undoing a trial edit such as an import dry run, or a
+// load that must leave the save open in the editor alone.
+type ModelState struct {
+	characters      []models.Character
+	spells          [][]int
+	commands        [][]*models.Command
+	party           pri.Party
+	inventory       []pri.Row
+	important       []pri.Row
+	inventoryRows   []*pri.Row
+	importantRows   []*pri.Row
+	checked         [][]bool // By checkedLists
+	misc            models.Misc
+	mapData         pri.MapData
+	cheats          pri.Cheats
+	veldt           []bool
+	transportations []*pri.Transportation
+	transportValues []pri.Transportation
+}
+
+// checkedLists are the learned/owned lists a ModelState covers
+func checkedLists() [][]*consts.NameValueChecked {
+	return [][]*consts.NameValueChecked{pr.Espers, pr.Rages, pr.Lores, pr.Dances, pr.Blitzes, pr.Bushidos}
+}
+
+// SaveModelState copies the current models
+func SaveModelState() *ModelState {
+	s := &ModelState{
+		characters: make([]models.Character, len(pri.Characters)),
+		spells:     make([][]int, len(pri.Characters)),
+		commands:   make([][]*models.Command, len(pri.Characters)),
+		party:      *pri.GetParty(),
+		inventory:  copyRows(pri.GetInventory()),
+		important:  copyRows(pri.GetImportantInventory()),
+		misc:       *models.GetMisc(),
+		mapData:    *pri.GetMapData(),
+		cheats:     *pri.GetCheats(),
+		veldt:      append([]bool(nil), pri.GetVeldt().Encounters...),
+
+		inventoryRows:   append([]*pri.Row(nil), pri.GetInventory().Rows...),
+		importantRows:   append([]*pri.Row(nil), pri.GetImportantInventory().Rows...),
+		transportations: append([]*pri.Transportation(nil), pri.Transportations...),
+	}
+	for _, t := range pri.Transportations {
+		var v pri.Transportation
+		if t != nil {
+			v = *t
+		}
+		s.transportValues = append(s.transportValues, v)
+	}
+	for n, c := range pri.Characters {
+		s.characters[n] = *c
+		s.commands[n] = append([]*models.Command(nil), c.Commands...)
+		s.spells[n] = make([]int, len(c.SpellsByIndex))
+		for i, spell := range c.SpellsByIndex {
+			s.spells[n][i] = spell.Value
+		}
+	}
+	for _, list := range checkedLists() {
+		checked := make([]bool, len(list))
+		for i, v := range list {
+			checked[i] = v.Checked
+		}
+		s.checked = append(s.checked, checked)
+	}
+	return s
+}
+
+// Restore puts the copied models back. Rows and transportations are the same
+// objects as before, so widgets bound to them stay bound.
+func (s *ModelState) Restore() {
+	for n, c := range pri.Characters {
+		*c = s.characters[n]
+		c.Commands = s.commands[n]
+		for i, spell := range c.SpellsByIndex {
+			spell.Value = s.spells[n][i]
+		}
+	}
+	*pri.GetParty() = s.party
+	putRows(pri.GetInventory(), s.inventoryRows, s.inventory)
+	putRows(pri.GetImportantInventory(), s.importantRows, s.important)
+	for l, list := range checkedLists() {
+		for i, v := range list {
+			v.Checked = s.checked[l][i]
+		}
+	}
+	*models.GetMisc() = s.misc
+	*pri.GetMapData() = s.mapData
+	*pri.GetCheats() = s.cheats
+	pri.GetVeldt().Encounters = append([]bool(nil), s.veldt...)
+	pri.Transportations = s.transportations
+	for n, t := range s.transportations {
+		if t != nil {
+			*t = s.transportValues[n]
+		}
+	}
+}
+
+func copyRows(inv *pri.Inventory) []pri.Row {
+	rows := make([]pri.Row, len(inv.Rows))
+	for n, r := range inv.Rows {
+		if r != nil {
+			rows[n] = *r
+		}
+	}
+	return rows
+}
+
+// putRows gives an inventory back the rows it held, with the values they held
+func putRows(inv *pri.Inventory, rows []*pri.Row, values []pri.Row) {
+	inv.Rows = rows
+	for n, r := range rows {
+		if r != nil {
+			*r = values[n]
+		}
+	}
+}
+
+func restoreRows(inv *pri.Inventory, rows []pri.Row) {
+	inv.Rows = make([]*pri.Row, len(rows))
+	for n := range rows {
+		r := rows[n]
+		inv.Rows[n] = &r
+	}
+}
@@ -0,0 +1,437 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ffvi_editor/models"
+	pri "ffvi_editor/models/pr"
+)
+
+// Changes tracks the edits of the save open in the editor. Opening a save
+// starts it over with Sync; loads that only read a save, such as for a
+// comparison, leave it alone.
+var Changes = NewTracker()
+
+// Tracker turns edits of the loaded models into models.Change records, each
+// with the functions that apply and revert it, and hands them to its
+// subscribers as one models.ChangeGroup per edit.
+//
+// Edits made through Edit (and Set, Add and Remove) are grouped and emitted
+// when they finish, nested edits joining the outermost one. Models changed
+// directly, such as by widgets bound to model fields, are emitted by the next
+// Commit, or ahead of the next Edit so they aren't credited to it.
+//
+// Changes are found by diffing snapshots of the logical save paths, not
+// emitted by setters. The models are plain structs written through pointers
+// by fyne bindings, batch operations, scripts and the CLI alike; a diff sees
+// every writer, and compares the same paths that changes are written back
+// through. A commit costs a snapshot and a comparison of the whole save,
+// about 10ms, so edits are committed when they end, not on every write.
+type Tracker struct {
+	mu        sync.Mutex
+	base      *trackedState
+	depth     int
+	listeners map[int]func(models.ChangeGroup)
+	nextID    int
+	groups    int
+}
+
+// trackedState is what the tracker compares edits against. stored keeps the
+// raw value of each snapshot path, which changes write back.
+type trackedState struct {
+	snapshot  *Snapshot
+	stored    map[string]interface{}
+	inventory []pri.Row
+	important []pri.Row
+}
+
+// NewTracker creates a tracker; it compares against the models as they are
+// when first used
+func NewTracker() *Tracker {
+	return &Tracker{listeners: make(map[int]func(models.ChangeGroup))}
+}
+
+func captureState() *trackedState {
+	s := &trackedState{
+		snapshot:  TakeSnapshot(),
+		stored:    make(map[string]interface{}),
+		inventory: copyRows(pri.GetInventory()),
+		important: copyRows(pri.GetImportantInventory()),
+	}
+	for path := range s.snapshot.Values {
+		if v, err := loadPath(path); err == nil {
+			s.stored[path] = v
+		}
+	}
+	return s
+}
+
+// Subscribe calls fn with every change group from now on, until the returned
+// function is called
+func (t *Tracker) Subscribe(fn func(models.ChangeGroup)) (unsubscribe func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := t.nextID
+	t.nextID++
+	t.listeners[id] = fn
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.listeners, id)
+	}
+}
+
+// Sync accepts the current models without emitting changes. It is called
+// when a save is opened for editing and after changes are undone or redone.
+func (t *Tracker) Sync() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.base = captureState()
+}
+
+// Commit emits the changes made since the last edit, commit or sync as one
+// group. It returns false when nothing changed.
+func (t *Tracker) Commit(name string) (models.ChangeGroup, bool) {
+	t.mu.Lock()
+	if t.depth > 0 {
+		// The open edit will pick the changes up
+		t.mu.Unlock()
+		return models.ChangeGroup{}, false
+	}
+	g, ok := t.commitLocked(name)
+	listeners := t.listenersLocked()
+	t.mu.Unlock()
+
+	if ok {
+		for _, fn := range listeners {
+			fn(g)
+		}
+	}
+	return g, ok
+}
+
+// Edit runs fn and emits the changes it made as one group. Edits started by
+// fn join this one. The changes are emitted even when fn fails part way.
+func (t *Tracker) Edit(name string, fn func() error) error {
+	t.mu.Lock()
+	outer := t.depth == 0
+	var pending []models.ChangeGroup
+	if outer {
+		if g, ok := t.commitLocked("Edit"); ok {
+			pending = append(pending, g)
+		}
+	}
+	t.depth++
+	listeners := t.listenersLocked()
+	t.mu.Unlock()
+	t.notify(listeners, pending)
+
+	err := fn()
+
+	t.mu.Lock()
+	t.depth--
+	if outer {
+		pending = pending[:0]
+		if g, ok := t.commitLocked(name); ok {
+			pending = append(pending, g)
+		}
+	}
+	listeners = t.listenersLocked()
+	t.mu.Unlock()
+	t.notify(listeners, pending)
+	return err
+}
+
+// Set writes a logical save path as an edit
+func (t *Tracker) Set(path string, value interface{}) error {
+	return t.Edit(path, func() error { return SetPath(path, value) })
+}
+
+// Add adds to a logical save path as an edit
+func (t *Tracker) Add(path string, value interface{}) error {
+	return t.Edit(path, func() error { return AddPath(path, value) })
+}
+
+// Remove removes a logical save path as an edit
+func (t *Tracker) Remove(path string) error {
+	return t.Edit(path, func() error { return RemovePath(path) })
+}
+
+// Undo reverts the changes, last first, then accepts the result. Changes
+// that fail to revert are skipped and the first error is returned.
+func (t *Tracker) Undo(changes []models.Change) (err error) {
+	for i := len(changes) - 1; i >= 0; i-- {
+		if e := changes[i].Undo(); e != nil && err == nil {
+			err = e
+		}
+	}
+	t.Sync()
+	return
+}
+
+// Redo applies the changes again in order, then accepts the result
+func (t *Tracker) Redo(changes []models.Change) (err error) {
+	for _, c := range changes {
+		if e := c.Redo(); e != nil && err == nil {
+			err = e
+		}
+	}
+	t.Sync()
+	return
+}
+
+func (t *Tracker) notify(listeners []func(models.ChangeGroup), groups []models.ChangeGroup) {
+	for _, g := range groups {
+		for _, fn := range listeners {
+			fn(g)
+		}
+	}
+}
+
+func (t *Tracker) listenersLocked() []func(models.ChangeGroup) {
+	listeners := make([]func(models.ChangeGroup), 0, len(t.listeners))
+	for id := 0; id < t.nextID; id++ {
+		if fn, ok := t.listeners[id]; ok {
+			listeners = append(listeners, fn)
+		}
+	}
+	return listeners
+}
+
+// commitLocked compares the models with the base state and moves the base
+// to the current models
+func (t *Tracker) commitLocked(name string) (models.ChangeGroup, bool) {
+	current := captureState()
+	base := t.base
+	t.base = current
+	if base == nil {
+		return models.ChangeGroup{}, false
+	}
+
+	report := NewComparator(base.snapshot, current.snapshot).Compare()
+	changes := make([]models.Change, 0, len(report.Diffs)+2)
+	for _, d := range report.Diffs {
+		changes = append(changes, diffChange(d, base.stored[d.Path], current.stored[d.Path]))
+	}
+	if c, ok := rowOrderChange(PathInventory, pri.GetInventory(), base.inventory, current.inventory); ok {
+		changes = append(changes, c)
+	}
+	if c, ok := rowOrderChange(PathImportantItems, pri.GetImportantInventory(), base.important, current.important); ok {
+		changes = append(changes, c)
+	}
+	if len(changes) == 0 {
+		return models.ChangeGroup{}, false
+	}
+
+	t.groups++
+	g := models.ChangeGroup{
+		ID:      fmt.Sprintf("group_%d_%d", time.Now().UnixNano(), t.groups),
+		Name:    name,
+		Changes: changes,
+		Time:    time.Now(),
+	}
+	for i := range g.Changes {
+		g.Changes[i].Batch, g.Changes[i].BatchID, g.Changes[i].BatchName = true, g.ID, name
+	}
+	return g, true
+}
+
+// diffChange converts a snapshot difference into a change that writes the
+// stored values of the path back. Values missing on one side are owned items
+// or skills, so their zero value stands in.
+func diffChange(d Diff, oldStored, newStored interface{}) models.Change {
+	oldValue, newValue := d.OldValue, d.NewValue
+	switch d.Type {
+	case DiffAdded:
+		oldValue = zeroValue(newValue)
+	case DiffRemoved:
+		newValue = zeroValue(oldValue)
+	}
+
+	c := models.NewChange(SplitPath(d.Path)[0], d.Path, oldValue, newValue)
+	path := d.Path
+	c.Apply = func() error {
+		if d.Type == DiffRemoved {
+			return RemovePath(path)
+		}
+		return restorePath(path, newStored)
+	}
+	c.Revert = func() error {
+		if d.Type == DiffAdded {
+			return RemovePath(path)
+		}
+		return restorePath(path, oldStored)
+	}
+	return c
+}
+
+func zeroValue(v interface{}) interface{} {
+	switch v.(type) {
+	case bool:
+		return false
+	case string:
+		return ""
+	case float64:
+		return 0.0
+	}
+	return 0
+}
+
+// rowOrderChange records the row layout of an inventory when items kept
+// through the edit changed places, as sorting does. Writing counts by path
+// keeps the rows in place, so this change restores the exact layout.
+func rowOrderChange(category string, inv *pri.Inventory, before, after []pri.Row) (models.Change, bool) {
+	if !reordered(before, after) {
+		return models.Change{}, false
+	}
+	c := models.NewChange(category, category+orderSuffix, rowIDs(before), rowIDs(after))
+	c.Apply = func() error {
+		restoreRows(inv, after)
+		return nil
+	}
+	c.Revert = func() error {
+		restoreRows(inv, before)
+		return nil
+	}
+	return c, true
+}
+
+// orderSuffix ends the field name of changes that rearrange an inventory
+const orderSuffix = " (order)"
+
+// BindChange gives a change read back from storage, which keeps only its
+// values, the functions that apply and revert it
+func BindChange(c models.Change) models.Change {
+	path, oldValue, newValue := c.FieldName, c.OldValue, c.NewValue
+	c.Apply = func() error { return RestoreValue(path, newValue) }
+	c.Revert = func() error { return RestoreValue(path, oldValue) }
+	return c
+}
+
+// RestoreValue writes a value recorded by a change, which may have been read
+// back from JSON. Numbers are stored as they are, so values that edits
+// reject, such as the level 0 of characters that never joined, come back too.
+// The values of order changes are the item IDs in inventory order.
+func RestoreValue(path string, value interface{}) error {
+	if category := strings.TrimSuffix(path, orderSuffix); category != path {
+		ids, err := toInts(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		switch category {
+		case PathInventory:
+			reorderRows(pri.GetInventory(), ids)
+		case PathImportantItems:
+			reorderRows(pri.GetImportantInventory(), ids)
+		default:
+			return fmt.Errorf("unknown path %q", path)
+		}
+		return nil
+	}
+
+	a, err := resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if a.load != nil {
+		if _, ok := a.load().(int); ok {
+			if i, err := toInt(value); err == nil {
+				a.store(i)
+				return nil
+			}
+		}
+	}
+	return a.set(value)
+}
+
+// reorderRows puts the owned items listed in ids in that order, using the
+// rows they hold now; other rows stay where they are
+func reorderRows(inv *pri.Inventory, ids []int) {
+	rows := copyRows(inv)
+	listed := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		listed[id] = true
+	}
+	byID := make(map[int]pri.Row)
+	var slots []int
+	for n, r := range rows {
+		if r.Count > 0 && listed[r.ItemID] {
+			if _, ok := byID[r.ItemID]; !ok {
+				byID[r.ItemID] = r
+				slots = append(slots, n)
+			}
+		}
+	}
+	next := 0
+	for _, id := range ids {
+		if r, ok := byID[id]; ok {
+			rows[slots[next]] = r
+			next++
+			delete(byID, id)
+		}
+	}
+	restoreRows(inv, rows)
+}
+
+func toInts(v interface{}) ([]int, error) {
+	switch t := v.(type) {
+	case []int:
+		return t, nil
+	case []interface{}:
+		ids := make([]int, len(t))
+		for n, e := range t {
+			i, err := toInt(e)
+			if err != nil {
+				return nil, err
+			}
+			ids[n] = i
+		}
+		return ids, nil
+	case nil:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("expected a list of item IDs, got %T", v)
+}
+
+// reordered reports whether the items owned both before and after appear in
+// a different order
+func reordered(before, after []pri.Row) bool {
+	kept := func(rows, other []pri.Row) []int {
+		owned := make(map[int]bool)
+		for _, r := range other {
+			if r.ItemID != 0 && r.Count > 0 {
+				owned[r.ItemID] = true
+			}
+		}
+		var ids []int
+		for _, r := range rows {
+			if r.Count > 0 && owned[r.ItemID] {
+				ids = append(ids, r.ItemID)
+			}
+		}
+		return ids
+	}
+	a, b := kept(before, after), kept(after, before)
+	if len(a) != len(b) {
+		return true
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func rowIDs(rows []pri.Row) []int {
+	ids := make([]int, 0, len(rows))
+	for _, r := range rows {
+		if r.ItemID != 0 && r.Count > 0 {
+			ids = append(ids, r.ItemID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,121 @@
+package pr
+
+import (
+	"reflect"
+	"testing"
+
+	"ffvi_editor/models"
+	pri "ffvi_editor/models/pr"
+)
+
+func trackTestSave(t *testing.T) (*Tracker, *[]models.ChangeGroup) {
+	t.Helper()
+	loadTestSave(t)
+	tracker := NewTracker()
+	tracker.Sync()
+	var groups []models.ChangeGroup
+	unsubscribe := tracker.Subscribe(func(g models.ChangeGroup) { groups = append(groups, g) })
+	t.Cleanup(unsubscribe)
+	return tracker, &groups
+}
+
+func TestTrackerGroupsEdits(t *testing.T) {
+	tracker, groups := trackTestSave(t)
+	before := TakeSnapshot()
+
+	err := tracker.Edit("Max Terra", func() error {
+		if err := tracker.Set("characters/Terra/level", 99); err != nil {
+			return err
+		}
+		if err := tracker.Add("inventory/Megalixir", 3); err != nil {
+			return err
+		}
+		pri.GetCharacter("Terra").Vigor = 80
+		return tracker.Remove("espers/Ramuh")
+	})
+	if err != nil {
+		t.Fatalf("Edit failed: %v", err)
+	}
+	if len(*groups) != 1 {
+		t.Fatalf("expected one group, got %d", len(*groups))
+	}
+	g := (*groups)[0]
+	if g.Name != "Max Terra" {
+		t.Errorf("group named %q", g.Name)
+	}
+	paths := make(map[string]models.Change)
+	for _, c := range g.Changes {
+		paths[c.FieldName] = c
+		if !models.ValidateChange(c) || c.BatchID != g.ID {
+			t.Errorf("invalid change %+v", c)
+		}
+	}
+	if c, ok := paths["characters/Terra/level"]; !ok || c.NewValue != 99 {
+		t.Errorf("level change %+v", c)
+	}
+	if c, ok := paths["inventory/Megalixir"]; !ok || c.OldValue != 0 || c.NewValue != 3 {
+		t.Errorf("item change %+v", c)
+	}
+	if _, ok := paths["characters/Terra/vigor"]; !ok {
+		t.Error("the direct vigor edit wasn't recorded")
+	}
+
+	if err = tracker.Undo(g.Changes); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if report := NewComparator(before, TakeSnapshot()).Compare(); report.HasDifferences() {
+		t.Errorf("undo left differences: %+v", report.Diffs)
+	}
+	if err = tracker.Redo(g.Changes); err != nil {
+		t.Fatalf("Redo failed: %v", err)
+	}
+	if terra := pri.GetCharacter("Terra"); terra.Level != 99 || terra.Vigor != 80 {
+		t.Errorf("redo left Terra at level %d vigor %d", terra.Level, terra.Vigor)
+	}
+	if len(*groups) != 1 {
+		t.Errorf("undo and redo emitted %d groups", len(*groups)-1)
+	}
+}
+
+func TestTrackerCommitsDirectEdits(t *testing.T) {
+	tracker, groups := trackTestSave(t)
+
+	if _, ok := tracker.Commit("Nothing"); ok {
+		t.Fatal("commit without changes emitted a group")
+	}
+	models.GetMisc().GP = 4321
+	if err := tracker.Set("misc/steps", 7); err != nil {
+		t.Fatal(err)
+	}
+	// The gil edit is emitted ahead of the set, not credited to it
+	if len(*groups) != 2 || len((*groups)[0].Changes) != 1 || (*groups)[0].Changes[0].FieldName != "misc/gil" {
+		t.Fatalf("expected the gil edit, then the steps edit: %+v", *groups)
+	}
+	if (*groups)[1].Name != "misc/steps" || len((*groups)[1].Changes) != 1 {
+		t.Errorf("unexpected set group %+v", (*groups)[1])
+	}
+}
+
+func TestTrackerRestoresRowOrder(t *testing.T) {
+	tracker, groups := trackTestSave(t)
+	inv := pri.GetInventory()
+	before := copyRows(inv)
+
+	if err := tracker.Edit("Sort", func() error {
+		for i, j := 0, len(inv.Rows)-1; i < j; i, j = i+1, j-1 {
+			inv.Rows[i], inv.Rows[j] = inv.Rows[j], inv.Rows[i]
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*groups) != 1 || len((*groups)[0].Changes) != 1 || (*groups)[0].Changes[0].Target != PathInventory {
+		t.Fatalf("expected a single row order change: %+v", *groups)
+	}
+	if err := tracker.Undo((*groups)[0].Changes); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(copyRows(inv), before) {
+		t.Error("undo didn't restore the row order")
+	}
+}
@@ -0,0 +1,349 @@
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"ffvi_editor/io/pr"
+	"ffvi_editor/models/consts"
+	cpr "ffvi_editor/models/consts/pr"
+)
+
+// Format is the document format a report is rendered in
+type Format string
+
+const (
+	HTML     Format = "html"
+	Markdown Format = "markdown"
+)
+
+// ParseFormat accepts "html", "htm", "markdown" and "md"
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(s, ".")) {
+	case "html", "htm":
+		return HTML, nil
+	case "markdown", "md":
+		return Markdown, nil
+	}
+	return "", fmt.Errorf("unknown report format: %s", s)
+}
+
+// FormatForFile picks the format from a file extension, defaulting to HTML
+func FormatForFile(name string) Format {
+	if f, err := ParseFormat(filepath.Ext(name)); err == nil {
+		return f
+	}
+	return HTML
+}
+
+// Extension returns the file extension of the format
+func (f Format) Extension() string {
+	if f == Markdown {
+		return ".md"
+	}
+	return ".html"
+}
+
+// Field is a labelled value
+type Field struct {
+	Label string
+	Value interface{}
+}
+
+// Spell is a spell a character is learning
+type Spell struct {
+	Name    string
+	Percent int
+}
+
+// Character is a party member's stats, equipment and magic
+type Character struct {
+	Name      string
+	RootName  string
+	Level     int
+	Exp       int
+	HP        int
+	MaxHP     int
+	MP        int
+	MaxMP     int
+	Stats     []Field
+	Equipment []Field
+	Learned   []string
+	Learning  []Spell
+}
+
+// Item is an inventory row
+type Item struct {
+	Name  string
+	Count int
+}
+
+// Completion is how much of a learnable list is owned
+type Completion struct {
+	Name    string
+	Learned []string
+	Missing []string
+}
+
+// Total is the size of the list
+func (c Completion) Total() int {
+	return len(c.Learned) + len(c.Missing)
+}
+
+// Percent is the share of the list that is learned
+func (c Completion) Percent() int {
+	if c.Total() == 0 {
+		return 0
+	}
+	return len(c.Learned) * 100 / c.Total()
+}
+
+// SaveDocument describes a loaded save for sharing
+type SaveDocument struct {
+	Title          string
+	Generated      time.Time
+	Characters     []Character
+	Inventory      []Item
+	ImportantItems []Item
+	Espers         Completion
+	Skills         []Completion
+	Location       []Field
+	Progress       []Field
+}
+
+// NewSaveDocument builds a document from a snapshot of a save
+func NewSaveDocument(title string, s *pr.Snapshot) *SaveDocument {
+	doc := &SaveDocument{Title: title, Generated: time.Now()}
+	characters := make(map[string]*Character)
+	var order []string
+
+	for _, path := range s.Paths() {
+		v, _ := s.Get(path)
+		segments := pr.SplitPath(path)
+		switch segments[0] {
+		case pr.PathCharacters:
+			c, ok := characters[segments[1]]
+			if !ok {
+				c = &Character{RootName: segments[1]}
+				characters[segments[1]] = c
+				order = append(order, segments[1])
+			}
+			addCharacterValue(c, segments[2:], v)
+		case pr.PathInventory:
+			doc.Inventory = append(doc.Inventory, Item{Name: segments[1], Count: intValue(v)})
+		case pr.PathImportantItems:
+			doc.ImportantItems = append(doc.ImportantItems, Item{Name: segments[1], Count: intValue(v)})
+		case pr.PathMap:
+			switch segments[1] {
+			case "mapId", "x", "y", "direction":
+				doc.Location = append(doc.Location, Field{Label: pr.FieldLabel(segments[1]), Value: v})
+			}
+		case pr.PathMisc:
+			if segments[1] == "playTime" {
+				seconds := int(floatValue(v))
+				v = fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+			}
+			doc.Progress = append(doc.Progress, Field{Label: pr.FieldLabel(segments[1]), Value: v})
+		}
+	}
+
+	for _, root := range order {
+		c := characters[root]
+		if v, ok := s.Get(pr.JoinPath(pr.PathCharacters, root, "enabled")); ok && v == false {
+			continue
+		}
+		doc.Characters = append(doc.Characters, *c)
+	}
+
+	doc.Espers = completion("Espers", pr.PathEspers, cpr.Espers, s)
+	for _, l := range []struct {
+		name, category string
+		list           []*consts.NameValueChecked
+	}{
+		{"Rages", pr.PathRages, cpr.Rages},
+		{"Lores", pr.PathLores, cpr.Lores},
+		{"Dances", pr.PathDances, cpr.Dances},
+		{"Blitzes", pr.PathBlitzes, cpr.Blitzes},
+		{"Bushido", pr.PathBushido, cpr.Bushidos},
+	} {
+		doc.Skills = append(doc.Skills, completion(l.name, l.category, l.list, s))
+	}
+	return doc
+}
+
+// Render writes the document in the given format
+func (d *SaveDocument) Render(w io.Writer, f Format) error {
+	return render(w, f, "save", d)
+}
+
+// DiffSection is the changes of one category
+type DiffSection struct {
+	Category string
+	Diffs    []pr.Diff
+}
+
+// DiffDocument describes the changes between two saves
+type DiffDocument struct {
+	Title      string
+	Generated  time.Time
+	Old        string
+	New        string
+	Statistics pr.DiffStatistics
+	Sections   []DiffSection
+}
+
+// NewDiffDocument builds a document from a comparison of the old and new saves
+func NewDiffDocument(title, oldName, newName string, r *pr.DiffReport) *DiffDocument {
+	doc := &DiffDocument{
+		Title:      title,
+		Generated:  time.Now(),
+		Old:        oldName,
+		New:        newName,
+		Statistics: r.Statistics,
+	}
+	for _, category := range r.Categories() {
+		doc.Sections = append(doc.Sections, DiffSection{Category: category, Diffs: r.GetDiffsByCategory(category)})
+	}
+	return doc
+}
+
+// Render writes the document in the given format
+func (d *DiffDocument) Render(w io.Writer, f Format) error {
+	return render(w, f, "diff", d)
+}
+
+// addCharacterValue files a character path value under the right field
+func addCharacterValue(c *Character, segments []string, v interface{}) {
+	if len(segments) == 2 {
+		switch segments[0] {
+		case "equipment":
+			c.Equipment = append(c.Equipment, Field{Label: pr.FieldLabel(segments[1]), Value: v})
+		case "spells":
+			switch p := intValue(v); {
+			case p >= 100:
+				c.Learned = append(c.Learned, segments[1])
+			case p > 0:
+				c.Learning = append(c.Learning, Spell{Name: segments[1], Percent: p})
+			}
+		}
+		return
+	}
+	switch segments[0] {
+	case "name":
+		c.Name, _ = v.(string)
+	case "level":
+		c.Level = intValue(v)
+	case "exp":
+		c.Exp = intValue(v)
+	case "hp":
+		c.HP = intValue(v)
+	case "maxHp":
+		c.MaxHP = intValue(v)
+	case "mp":
+		c.MP = intValue(v)
+	case "maxMp":
+		c.MaxMP = intValue(v)
+	case "vigor", "stamina", "speed", "magic":
+		c.Stats = append(c.Stats, Field{Label: pr.FieldLabel(segments[0]), Value: v})
+	}
+}
+
+// completion splits a learnable list into what the snapshot has and lacks
+func completion(name, category string, list []*consts.NameValueChecked, s *pr.Snapshot) Completion {
+	c := Completion{Name: name, Learned: make([]string, 0), Missing: make([]string, 0)}
+	for _, v := range list {
+		if _, ok := s.Get(pr.JoinPath(category, v.Name)); ok {
+			c.Learned = append(c.Learned, v.Name)
+		} else {
+			c.Missing = append(c.Missing, v.Name)
+		}
+	}
+	return c
+}
+
+// render executes the named template of the format
+func render(w io.Writer, f Format, name string, data interface{}) error {
+	switch f {
+	case HTML:
+		t, err := htmltemplate.New(name).Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(htmlTemplates[name])
+		if err != nil {
+			return err
+		}
+		return t.Execute(w, data)
+	case Markdown:
+		t, err := texttemplate.New(name).Funcs(templateFuncs).Parse(markdownTemplates[name])
+		if err != nil {
+			return err
+		}
+		return t.Execute(w, data)
+	}
+	return fmt.Errorf("unknown report format: %s", f)
+}
+
+var templateFuncs = texttemplate.FuncMap{
+	"value": formatValue,
+	"md":    escapeMarkdown,
+	"join":  strings.Join,
+	"present": func(v interface{}) bool {
+		return v != nil
+	},
+	"date": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04")
+	},
+	"change": func(t pr.DiffType) string {
+		switch t {
+		case pr.DiffAdded:
+			return "added"
+		case pr.DiffRemoved:
+			return "removed"
+		}
+		return "modified"
+	},
+}
+
+// formatValue prints a report value; missing values print as a dash
+func formatValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "-"
+	case bool:
+		if t {
+			return "Yes"
+		}
+		return "No"
+	case float64:
+		return fmt.Sprintf("%g", t)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// escapeMarkdown keeps values from breaking tables and emphasis
+func escapeMarkdown(v interface{}) string {
+	s := formatValue(v)
+	return strings.NewReplacer("|", "\\|", "*", "\\*", "_", "\\_", "`", "\\`", "\n", " ").Replace(s)
+}
+
+func intValue(v interface{}) int {
+	switch t := v.(type) {
+	case int:
+		return t
+	case float64:
+		return int(t)
+	}
+	return 0
+}
+
+func floatValue(v interface{}) float64 {
+	switch t := v.(type) {
+	case float64:
+		return t
+	case int:
+		return float64(t)
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+)
+
+const testSave = "../../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y="
+
+func loadSnapshot(t *testing.T) *pr.Snapshot {
+	t.Helper()
+	if err := pr.New().Load(testSave, global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	return pr.TakeSnapshot()
+}
+
+func TestSaveDocument(t *testing.T) {
+	doc := NewSaveDocument("Autosave <test>", loadSnapshot(t))
+	if len(doc.Characters) == 0 || len(doc.Inventory) == 0 || len(doc.Skills) != 5 {
+		t.Fatalf("document not filled: %d characters, %d items, %d skills", len(doc.Characters), len(doc.Inventory), len(doc.Skills))
+	}
+	if doc.Espers.Total() == 0 {
+		t.Errorf("esper completion has no total")
+	}
+
+	var html bytes.Buffer
+	if err := doc.Render(&html, HTML); err != nil {
+		t.Fatalf("HTML render failed: %v", err)
+	}
+	out := html.String()
+	for _, want := range []string{"<!DOCTYPE html>", "<style>", "Autosave &lt;test&gt;", doc.Characters[0].Name, "Inventory", "Rages", "Magic learned"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML report missing %q", want)
+		}
+	}
+
+	var md bytes.Buffer
+	if err := doc.Render(&md, Markdown); err != nil {
+		t.Fatalf("Markdown render failed: %v", err)
+	}
+	out = md.String()
+	for _, want := range []string{"# Autosave <test>", "## Characters", "| Item | Count |", "## Skills", "| Rages |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Markdown report missing %q", want)
+		}
+	}
+}
+
+func TestDiffDocument(t *testing.T) {
+	before := loadSnapshot(t)
+	edits := pr.NewPatch("edits")
+	edits.Add(pr.PatchSet, "characters/Terra/level", 42)
+	edits.Add(pr.PatchAdd, "inventory/Megalixir", 3)
+	if err := pr.New().ApplyPatch(edits); err != nil {
+		t.Fatalf("ApplyPatch failed: %v", err)
+	}
+	report := pr.NewComparator(before, pr.TakeSnapshot()).Compare()
+	doc := NewDiffDocument("Changes", "old.sav", "new.sav", &report)
+
+	var html bytes.Buffer
+	if err := doc.Render(&html, HTML); err != nil {
+		t.Fatalf("HTML render failed: %v", err)
+	}
+	for _, want := range []string{`<tr class="modified">`, `<tr class="added">`, "Megalixir", "42"} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("HTML diff missing %q", want)
+		}
+	}
+
+	var md bytes.Buffer
+	if err := doc.Render(&md, Markdown); err != nil {
+		t.Fatalf("Markdown render failed: %v", err)
+	}
+	for _, want := range []string{"## Character", "| ~ | Terra | Level |", "**42**", "| + | Megalixir |"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Markdown diff missing %q:\n%s", want, md.String())
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"html": HTML, "HTM": HTML, "md": Markdown, "markdown": Markdown} {
+		if f, err := ParseFormat(in); err != nil || f != want {
+			t.Errorf("ParseFormat(%q) = %v, %v", in, f, err)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("expected an error for pdf")
+	}
+	if FormatForFile("report.md") != Markdown || FormatForFile("report") != HTML {
+		t.Error("FormatForFile picked the wrong format")
+	}
+}
@@ -0,0 +1,163 @@
+package report
+
+// htmlStyle is inlined so reports are a single self-contained file
+const htmlStyle = `<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 960px; color: #222; background: #fafafa; }
+h1 { border-bottom: 2px solid #446; padding-bottom: .3em; }
+h2 { margin-top: 1.6em; color: #335; }
+h3 { margin-bottom: .3em; }
+table { border-collapse: collapse; margin: .5em 0 1em; }
+th, td { border: 1px solid #ccc; padding: .25em .6em; text-align: left; vertical-align: top; }
+th { background: #e8e8f0; }
+.meta { color: #666; font-size: .9em; }
+.card { background: #fff; border: 1px solid #ddd; border-radius: 6px; padding: .6em 1em; margin: .8em 0; }
+.bar { background: #ddd; border-radius: 3px; height: .8em; width: 12em; display: inline-block; vertical-align: middle; }
+.bar span { background: #5a8; border-radius: 3px; height: 100%; display: block; }
+.missing { color: #888; font-size: .9em; }
+tr.added td { background: #e6ffec; }
+tr.removed td { background: #ffebe9; }
+tr.modified td { background: #fff8c5; }
+td.old { text-decoration: line-through; color: #a33; }
+td.new { font-weight: bold; color: #264; }
+</style>`
+
+var htmlTemplates = map[string]string{
+	"save": `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+` + htmlStyle + `
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Generated {{date .Generated}}</p>
+
+<h2>Location</h2>
+<table>{{range .Location}}<tr><th>{{.Label}}</th><td>{{value .Value}}</td></tr>{{end}}</table>
+
+<h2>Progress</h2>
+<table>{{range .Progress}}<tr><th>{{.Label}}</th><td>{{value .Value}}</td></tr>{{end}}</table>
+
+<h2>Characters</h2>
+{{range .Characters}}<div class="card">
+<h3>{{.Name}}{{if ne .Name .RootName}} ({{.RootName}}){{end}} &mdash; Level {{.Level}}</h3>
+<table>
+<tr><th>Experience</th><td>{{.Exp}}</td><th>HP</th><td>{{.HP}} / {{.MaxHP}}</td><th>MP</th><td>{{.MP}} / {{.MaxMP}}</td></tr>
+<tr>{{range .Stats}}<th>{{.Label}}</th><td>{{value .Value}}</td>{{end}}</tr>
+</table>
+<table>{{range .Equipment}}<tr><th>{{.Label}}</th><td>{{value .Value}}</td></tr>{{end}}</table>
+<p><strong>Magic learned ({{len .Learned}}):</strong> {{if .Learned}}{{join .Learned ", "}}{{else}}none{{end}}</p>
+{{if .Learning}}<p><strong>Learning:</strong> {{range $i, $s := .Learning}}{{if $i}}, {{end}}{{$s.Name}} {{$s.Percent}}%{{end}}</p>{{end}}
+</div>
+{{end}}
+
+<h2>Inventory</h2>
+<table><tr><th>Item</th><th>Count</th></tr>
+{{range .Inventory}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+{{if .ImportantItems}}<h3>Important Items</h3>
+<ul>{{range .ImportantItems}}<li>{{.Name}}</li>{{end}}</ul>{{end}}
+
+<h2>Espers</h2>
+{{with .Espers}}<p><span class="bar"><span style="width: {{.Percent}}%"></span></span> {{len .Learned}} / {{.Total}}</p>
+<p>{{if .Learned}}{{join .Learned ", "}}{{else}}none{{end}}</p>{{end}}
+
+<h2>Skills</h2>
+<table><tr><th>Skill</th><th>Completion</th><th>Missing</th></tr>
+{{range .Skills}}<tr><td>{{.Name}}</td><td><span class="bar"><span style="width: {{.Percent}}%"></span></span> {{len .Learned}} / {{.Total}}</td><td class="missing">{{join .Missing ", "}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`,
+	"diff": `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+` + htmlStyle + `
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Generated {{date .Generated}} &mdash; comparing <code>{{.Old}}</code> to <code>{{.New}}</code></p>
+{{with .Statistics}}<p>{{.TotalDiffs}} differences: {{.Added}} added, {{.Removed}} removed, {{.Modified}} modified</p>{{end}}
+{{range .Sections}}
+<h2>{{.Category}}</h2>
+<table><tr><th>Name</th><th>Field</th><th>Old</th><th>New</th></tr>
+{{range .Diffs}}<tr class="{{change .Type}}"><td>{{.Name}}</td><td>{{.Field}}</td><td class="old">{{value .OldValue}}</td><td class="new">{{value .NewValue}}</td></tr>
+{{end}}</table>
+{{else}}<p>No differences found.</p>
+{{end}}
+</body>
+</html>
+`,
+}
+
+var markdownTemplates = map[string]string{
+	"save": `# {{md .Title}}
+
+_Generated {{date .Generated}}_
+
+## Location
+
+| | |
+|---|---|
+{{range .Location}}| {{.Label}} | {{md .Value}} |
+{{end}}
+## Progress
+
+| | |
+|---|---|
+{{range .Progress}}| {{.Label}} | {{md .Value}} |
+{{end}}
+## Characters
+{{range .Characters}}
+### {{md .Name}}{{if ne .Name .RootName}} ({{md .RootName}}){{end}} - Level {{.Level}}
+
+| Experience | HP | MP |{{range .Stats}} {{.Label}} |{{end}}
+|---|---|---|{{range .Stats}}---|{{end}}
+| {{.Exp}} | {{.HP}} / {{.MaxHP}} | {{.MP}} / {{.MaxMP}} |{{range .Stats}} {{md .Value}} |{{end}}
+
+| Slot | Equipment |
+|---|---|
+{{range .Equipment}}| {{.Label}} | {{md .Value}} |
+{{end}}
+**Magic learned ({{len .Learned}}):** {{if .Learned}}{{join .Learned ", "}}{{else}}none{{end}}
+{{if .Learning}}
+**Learning:** {{range $i, $s := .Learning}}{{if $i}}, {{end}}{{$s.Name}} {{$s.Percent}}%{{end}}
+{{end}}{{end}}
+## Inventory
+
+| Item | Count |
+|---|---|
+{{range .Inventory}}| {{md .Name}} | {{.Count}} |
+{{end}}{{if .ImportantItems}}
+### Important Items
+{{range .ImportantItems}}
+- {{md .Name}}{{end}}
+{{end}}
+## Espers
+{{with .Espers}}
+{{len .Learned}} / {{.Total}} ({{.Percent}}%): {{if .Learned}}{{join .Learned ", "}}{{else}}none{{end}}
+{{end}}
+## Skills
+
+| Skill | Learned | Missing |
+|---|---|---|
+{{range .Skills}}| {{.Name}} | {{len .Learned}} / {{.Total}} ({{.Percent}}%) | {{md (join .Missing ", ")}} |
+{{end}}`,
+	"diff": `# {{md .Title}}
+
+_Generated {{date .Generated}}, comparing ` + "`{{.Old}}`" + ` to ` + "`{{.New}}`" + `_
+{{with .Statistics}}
+{{.TotalDiffs}} differences: {{.Added}} added, {{.Removed}} removed, {{.Modified}} modified
+{{end}}{{range .Sections}}
+## {{.Category}}
+
+| | Name | Field | Old | New |
+|---|---|---|---|---|
+{{range .Diffs}}| {{if eq (change .Type) "added"}}+{{else if eq (change .Type) "removed"}}-{{else}}~{{end}} | {{md .Name}} | {{md .Field}} | {{if present .OldValue}}~~{{md .OldValue}}~~{{end}} | {{if present .NewValue}}**{{md .NewValue}}**{{end}} |
+{{end}}{{else}}
+No differences found.
+{{end}}`,
+}
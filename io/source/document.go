@@ -0,0 +1,352 @@
+package source
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Document is a parsed source file. The layout is INI-like:
+//
+//	# Comments run to the end of the line
+//	[section]
+//	key = 12                # integers, floats (1.5), true/false
+//	"quoted key" = "text"   # strings use Go quoting
+//	blob = """
+//	lines of text
+//	"""
+//
+// Comments on their own lines belong to the section or entry below them;
+// comments after a value belong to that entry. Both survive a re-export, see
+// KeepComments.
+type Document struct {
+	Comments []string // Ahead of the first section, up to the first blank line
+	Sections []*Section
+	Trailing []string // After the last entry
+}
+
+// Section is a bracketed group of entries
+type Section struct {
+	Name     string
+	Comments []string
+	Entries  []*Entry
+	Line     int
+}
+
+// Entry is a key and its value: an int, float64, bool or string
+type Entry struct {
+	Key      string
+	Value    interface{}
+	Comments []string
+	Inline   string // Comment after the value, without the "#"
+	Line     int
+}
+
+// SyntaxError is a line of a source file that can't be parsed
+type SyntaxError struct {
+	Line    int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_./~-]+$`)
+
+// Section returns the section with the given name, or nil
+func (d *Document) Section(name string) *Section {
+	for _, s := range d.Sections {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// AddSection appends a section
+func (d *Document) AddSection(name string) *Section {
+	s := &Section{Name: name}
+	d.Sections = append(d.Sections, s)
+	return s
+}
+
+// Get returns the entry with the given key, or nil
+func (s *Section) Get(key string) *Entry {
+	for _, e := range s.Entries {
+		if e.Key == key {
+			return e
+		}
+	}
+	return nil
+}
+
+// Add appends an entry
+func (s *Section) Add(key string, value interface{}) *Entry {
+	e := &Entry{Key: key, Value: value}
+	s.Entries = append(s.Entries, e)
+	return e
+}
+
+// KeepComments copies the comments of old onto the sections and entries of d
+// that old also has
+func (d *Document) KeepComments(old *Document) {
+	if old == nil {
+		return
+	}
+	if len(old.Comments) > 0 {
+		d.Comments = old.Comments
+	}
+	d.Trailing = old.Trailing
+	for _, s := range d.Sections {
+		prev := old.Section(s.Name)
+		if prev == nil {
+			continue
+		}
+		s.Comments = prev.Comments
+		for _, e := range s.Entries {
+			if oe := prev.Get(e.Key); oe != nil {
+				e.Comments, e.Inline = oe.Comments, oe.Inline
+			}
+		}
+	}
+}
+
+// Parse reads a source file
+func Parse(r io.Reader) (*Document, error) {
+	d := &Document{}
+	var (
+		section *Section
+		pending []string
+		line    int
+	)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		switch {
+		case text == "":
+			if section == nil && len(d.Comments) == 0 {
+				d.Comments, pending = pending, nil
+			}
+		case strings.HasPrefix(text, "#"):
+			pending = append(pending, text)
+		case strings.HasPrefix(text, "["):
+			if !strings.HasSuffix(text, "]") || len(text) < 3 {
+				return nil, &SyntaxError{line, fmt.Sprintf("malformed section header %q", text)}
+			}
+			name := strings.TrimSpace(text[1 : len(text)-1])
+			if d.Section(name) != nil {
+				return nil, &SyntaxError{line, fmt.Sprintf("section [%s] appears twice", name)}
+			}
+			section = d.AddSection(name)
+			section.Comments, section.Line, pending = pending, line, nil
+		default:
+			if section == nil {
+				return nil, &SyntaxError{line, "entry outside of a section"}
+			}
+			key, rest, err := parseKey(text)
+			if err != nil {
+				return nil, &SyntaxError{line, err.Error()}
+			}
+			if section.Get(key) != nil {
+				return nil, &SyntaxError{line, fmt.Sprintf("key %q appears twice in [%s]", key, section.Name)}
+			}
+			e := section.Add(key, nil)
+			e.Comments, e.Line, pending = pending, line, nil
+			if rest == `"""` {
+				var lines []string
+				for {
+					if !sc.Scan() {
+						return nil, &SyntaxError{e.Line, `unterminated """ string`}
+					}
+					line++
+					if strings.TrimSpace(sc.Text()) == `"""` {
+						break
+					}
+					lines = append(lines, sc.Text())
+				}
+				e.Value = strings.Join(lines, "\n")
+				continue
+			}
+			if e.Value, e.Inline, err = parseValue(rest); err != nil {
+				return nil, &SyntaxError{line, err.Error()}
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read source: %w", err)
+	}
+	d.Trailing = pending
+	return d, nil
+}
+
+// parseKey splits "key = rest" and unquotes a quoted key
+func parseKey(text string) (key, rest string, err error) {
+	if strings.HasPrefix(text, `"`) {
+		end := quotedEnd(text)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+		if key, err = strconv.Unquote(text[:end]); err != nil {
+			return "", "", fmt.Errorf("invalid quoted key: %v", err)
+		}
+		rest = strings.TrimSpace(text[end:])
+	} else {
+		i := strings.IndexByte(text, '=')
+		if i < 0 {
+			return "", "", fmt.Errorf("expected key = value")
+		}
+		key, rest = strings.TrimSpace(text[:i]), text[i:]
+		if !bareKey.MatchString(key) {
+			return "", "", fmt.Errorf("invalid key %q; quote keys with spaces or symbols", key)
+		}
+	}
+	if !strings.HasPrefix(rest, "=") {
+		return "", "", fmt.Errorf("expected = after key %q", key)
+	}
+	return key, strings.TrimSpace(rest[1:]), nil
+}
+
+// parseValue reads a value and the comment after it
+func parseValue(text string) (value interface{}, comment string, err error) {
+	var token string
+	if strings.HasPrefix(text, `"`) {
+		end := quotedEnd(text)
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated string")
+		}
+		token, text = text[:end], text[end:]
+	} else if i := strings.IndexByte(text, '#'); i >= 0 {
+		token, text = text[:i], text[i:]
+	} else {
+		token, text = text, ""
+	}
+	token, text = strings.TrimSpace(token), strings.TrimSpace(text)
+	if text != "" {
+		if !strings.HasPrefix(text, "#") {
+			return nil, "", fmt.Errorf("unexpected %q after value", text)
+		}
+		comment = strings.TrimPrefix(text, "#")
+	}
+
+	switch {
+	case token == "":
+		return nil, "", fmt.Errorf("missing value")
+	case strings.HasPrefix(token, `"`):
+		s, err := strconv.Unquote(token)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid string %s: %v", token, err)
+		}
+		return s, comment, nil
+	case token == "true" || token == "false":
+		return token == "true", comment, nil
+	case strings.ContainsAny(token, ".eE"):
+		if f, err := strconv.ParseFloat(token, 64); err == nil {
+			return f, comment, nil
+		}
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid value %q; quote strings", token)
+	}
+	return i, comment, nil
+}
+
+// quotedEnd returns the index just past the closing quote of the Go string
+// literal text starts with, or -1
+func quotedEnd(text string) int {
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// WriteTo writes the document in source layout
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	bw := &countingWriter{w: bufio.NewWriter(w)}
+	for _, c := range d.Comments {
+		bw.line(c)
+	}
+	for i, s := range d.Sections {
+		if i > 0 || len(d.Comments) > 0 {
+			bw.line("")
+		}
+		for _, c := range s.Comments {
+			bw.line(c)
+		}
+		bw.line("[" + s.Name + "]")
+		for _, e := range s.Entries {
+			for _, c := range e.Comments {
+				bw.line(c)
+			}
+			bw.line(formatEntry(e))
+		}
+	}
+	if len(d.Trailing) > 0 {
+		bw.line("")
+		for _, c := range d.Trailing {
+			bw.line(c)
+		}
+	}
+	if bw.err == nil {
+		bw.err = bw.w.Flush()
+	}
+	return bw.n, bw.err
+}
+
+// formatEntry renders "key = value  # comment"
+func formatEntry(e *Entry) string {
+	key := e.Key
+	if !bareKey.MatchString(key) {
+		key = strconv.Quote(key)
+	}
+	var value string
+	switch v := e.Value.(type) {
+	case string:
+		if strings.Contains(v, "\n") {
+			return key + ` = """` + "\n" + v + "\n" + `"""`
+		}
+		value = strconv.Quote(v)
+	case bool:
+		value = strconv.FormatBool(v)
+	case int:
+		value = strconv.Itoa(v)
+	case float64:
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.ContainsAny(value, ".eE") {
+			value += ".0"
+		}
+	default:
+		value = strconv.Quote(fmt.Sprint(v))
+	}
+	line := key + " = " + value
+	if e.Inline != "" {
+		line += "  #" + e.Inline
+	}
+	return line
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) line(s string) {
+	if c.err != nil {
+		return
+	}
+	n, err := c.w.WriteString(s + "\n")
+	c.n += int64(n)
+	c.err = err
+}
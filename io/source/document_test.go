@@ -0,0 +1,114 @@
+package source
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const sample = `# header
+
+# about the save
+[save]
+version = 1 # layout
+type = "pc"
+
+[characters/Terra]
+level = 6
+"spells/Fire" = 100
+x = 65.0
+note = """
+two
+lines
+"""
+`
+
+func TestParseAndWrite(t *testing.T) {
+	d, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Sections) != 2 {
+		t.Fatalf("parsed %d sections, want 2", len(d.Sections))
+	}
+	s := d.Section("characters/Terra")
+	if s == nil {
+		t.Fatal("missing characters/Terra")
+	}
+	if v := s.Get("level").Value; v != 6 {
+		t.Errorf("level parsed as %#v", v)
+	}
+	if v := s.Get("spells/Fire").Value; v != 100 {
+		t.Errorf("spells/Fire parsed as %#v", v)
+	}
+	if v := s.Get("x").Value; v != 65.0 {
+		t.Errorf("x parsed as %#v", v)
+	}
+	if v := s.Get("note").Value; v != "two\nlines" {
+		t.Errorf("note parsed as %#v", v)
+	}
+	if e := d.Section("save").Get("version"); e.Inline != " layout" || e.Line != 5 {
+		t.Errorf("version has comment %q on line %d", e.Inline, e.Line)
+	}
+
+	// Writing and parsing again gives the same document
+	var buf bytes.Buffer
+	if _, err = d.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	again, err := Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("can't parse the written document: %v\n%s", err, buf.String())
+	}
+	var buf2 bytes.Buffer
+	again.WriteTo(&buf2)
+	if buf.String() != buf2.String() {
+		t.Errorf("document changed when written twice:\n%s\n---\n%s", buf.String(), buf2.String())
+	}
+	if !strings.Contains(buf.String(), "# about the save\n[save]") {
+		t.Errorf("section comment lost:\n%s", buf.String())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		doc  string
+		line int
+	}{
+		{"[a]\nkey\n", 2},
+		{"[a]\n[a]\n", 2},
+		{"key = 1\n", 1},
+		{"[a]\nkey = \"open\n", 2},
+		{"[a]\nkey = \"\"\"\nnever closed\n", 2},
+		{"[a]\nkey = nope\n", 2},
+	} {
+		_, err := Parse(strings.NewReader(tc.doc))
+		serr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("%q: expected a syntax error, got %v", tc.doc, err)
+			continue
+		}
+		if serr.Line != tc.line {
+			t.Errorf("%q: error on line %d, want %d: %v", tc.doc, serr.Line, tc.line, err)
+		}
+	}
+}
+
+func TestKeepComments(t *testing.T) {
+	old, err := Parse(strings.NewReader("# mine\n[a]\n# why\nkey = 1 # note\n[gone]\nx = 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &Document{}
+	d.AddSection("a").Add("key", 2)
+	d.KeepComments(old)
+
+	s := d.Section("a")
+	if len(s.Comments) != 1 || s.Comments[0] != "# mine" {
+		t.Errorf("section comments %q", s.Comments)
+	}
+	e := s.Get("key")
+	if len(e.Comments) != 1 || e.Comments[0] != "# why" || e.Inline != " note" || e.Value != 2 {
+		t.Errorf("entry kept %q %q with value %v", e.Comments, e.Inline, e.Value)
+	}
+}
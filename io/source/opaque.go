@@ -0,0 +1,415 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	ipr "ffvi_editor/io/pr"
+	"ffvi_editor/models/consts"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+
+	jo "gitlab.com/c0b/go-ordered-json"
+)
+
+// embeddedKey wraps a JSON document the save keeps in a string, so the
+// opaque text can show it expanded
+const embeddedKey = "$json"
+
+// importantItemList is the user data key of the important items
+const importantItemList = "importantOwendItemList"
+
+// encodeOpaque returns the save data as indented JSON. The values the sections
+// write are cleared (0, "", false or []), so the text only carries what the
+// editor doesn't model and stays the same while the sections are edited. The
+// save must be loaded into the models.
+func encodeOpaque(out []byte) (string, error) {
+	base := jo.NewOrderedMap()
+	if err := base.UnmarshalJSON(out); err != nil {
+		return "", fmt.Errorf("failed to read save data: %w", err)
+	}
+	expand(base)
+	clearModelled(base)
+
+	var sb strings.Builder
+	writeJSON(&sb, base, "")
+	return sb.String(), nil
+}
+
+// decodeOpaque turns the opaque text back into save data
+func decodeOpaque(text string) ([]byte, error) {
+	base := jo.NewOrderedMap()
+	if err := base.UnmarshalJSON([]byte(text)); err != nil {
+		return nil, fmt.Errorf("opaque data is damaged: %w", err)
+	}
+	v, err := collapse(base)
+	if err != nil {
+		return nil, fmt.Errorf("opaque data is damaged: %w", err)
+	}
+	return marshalValue(v)
+}
+
+// expand replaces the strings holding JSON documents below v with the
+// documents, wrapped as {"$json": ...}. A string is only expanded when
+// collapse gives it back byte for byte.
+func expand(v interface{}) interface{} {
+	switch t := v.(type) {
+	case *jo.OrderedMap:
+		iter := t.EntriesIter()
+		for kv, ok := iter(); ok; kv, ok = iter() {
+			t.Set(kv.Key, expand(kv.Value))
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = expand(t[i])
+		}
+	case string:
+		if doc, ok := parseEmbedded(t); ok {
+			w := jo.NewOrderedMap()
+			w.Set(embeddedKey, expand(doc))
+			return w
+		}
+	}
+	return v
+}
+
+func parseEmbedded(s string) (interface{}, bool) {
+	if !strings.HasPrefix(s, "{") && !strings.HasPrefix(s, "[") {
+		return nil, false
+	}
+	w := jo.NewOrderedMap()
+	if err := w.UnmarshalJSON([]byte(`{"v":` + s + `}`)); err != nil {
+		return nil, false
+	}
+	doc := w.Get("v")
+	if b, err := marshalValue(doc); err != nil || string(b) != s {
+		return nil, false
+	}
+	return doc, true
+}
+
+// collapse undoes expand
+func collapse(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case *jo.OrderedMap:
+		if doc, ok := embedded(t); ok {
+			doc, err := collapse(doc)
+			if err != nil {
+				return nil, err
+			}
+			b, err := marshalValue(doc)
+			return string(b), err
+		}
+		iter := t.EntriesIter()
+		for kv, ok := iter(); ok; kv, ok = iter() {
+			c, err := collapse(kv.Value)
+			if err != nil {
+				return nil, err
+			}
+			t.Set(kv.Key, c)
+		}
+	case []interface{}:
+		for i := range t {
+			c, err := collapse(t[i])
+			if err != nil {
+				return nil, err
+			}
+			t[i] = c
+		}
+	}
+	return v, nil
+}
+
+// embedded returns the document of a {"$json": ...} wrapper
+func embedded(m *jo.OrderedMap) (interface{}, bool) {
+	doc, ok := m.GetValue(embeddedKey)
+	if !ok {
+		return nil, false
+	}
+	iter := m.EntriesIter()
+	iter()
+	if _, more := iter(); more {
+		return nil, false
+	}
+	return doc, true
+}
+
+// marshalValue encodes v the way the save stores it
+func marshalValue(v interface{}) ([]byte, error) {
+	if m, ok := v.(*jo.OrderedMap); ok {
+		return m.MarshalJSON()
+	}
+	return json.Marshal(v)
+}
+
+// writeJSON indents objects and lists of objects; lists of plain values stay
+// on one line
+func writeJSON(sb *strings.Builder, v interface{}, indent string) {
+	switch t := v.(type) {
+	case *jo.OrderedMap:
+		iter := t.EntriesIter()
+		sep := "{\n"
+		for kv, ok := iter(); ok; kv, ok = iter() {
+			sb.WriteString(sep + indent + "  ")
+			writeScalar(sb, kv.Key)
+			sb.WriteString(": ")
+			writeJSON(sb, kv.Value, indent+"  ")
+			sep = ",\n"
+		}
+		if sep == "{\n" {
+			sb.WriteString("{}")
+		} else {
+			sb.WriteString("\n" + indent + "}")
+		}
+	case []interface{}:
+		if flat(t) {
+			sb.WriteString("[")
+			for i, e := range t {
+				if i > 0 {
+					sb.WriteString(", ")
+				}
+				writeScalar(sb, e)
+			}
+			sb.WriteString("]")
+			return
+		}
+		sb.WriteString("[\n")
+		for i, e := range t {
+			sb.WriteString(indent + "  ")
+			writeJSON(sb, e, indent+"  ")
+			if i < len(t)-1 {
+				sb.WriteString(",")
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString(indent + "]")
+	default:
+		writeScalar(sb, v)
+	}
+}
+
+func flat(l []interface{}) bool {
+	for _, e := range l {
+		switch e.(type) {
+		case *jo.OrderedMap, []interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+func writeScalar(sb *strings.Builder, v interface{}) {
+	if n, ok := v.(json.Number); ok {
+		sb.WriteString(n.String())
+		return
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+	sb.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// clearModelled clears the save values the saver writes from the models, see
+// io/pr/saver.go. Values the sections can't hold, like unknown item IDs, stay.
+func clearModelled(base *jo.OrderedMap) {
+	clearKeys(base, ipr.IsCompleteFlag, ipr.ID)
+	if ds := object(base, ipr.DataStorage); ds != nil {
+		if g, ok := ds.Get("global").([]interface{}); ok && len(g) > 9 {
+			g[9] = zero(g[9])
+		}
+	}
+
+	ud := object(base, ipr.UserData)
+	if ud == nil {
+		return
+	}
+	clearKeys(ud, ipr.OwnedGil, ipr.Steps, ipr.EscapeCount, ipr.BattleCount, ipr.SaveCompleteCount,
+		ipr.MonstersKilledCount, ipr.OpenChestCount, ipr.PlayTime)
+	for _, key := range []string{ipr.OwnedMagicStoneList, ipr.NormalOwnedItemList, importantItemList} {
+		if t := object(ud, key); t != nil && t.Has("target") {
+			t.Set("target", []interface{}{})
+		}
+	}
+	for _, m := range objects(ud, ipr.CorpsList) {
+		clearKeys(m, "characterId")
+	}
+	for _, m := range objects(ud, ipr.OwnedTransportationList) {
+		clearKeys(m, ipr.TransDirection, ipr.TransMapID, ipr.TransEnable)
+		clearKeys(object(m, ipr.TransPosition), "x", "y", "z")
+	}
+	for _, c := range objects(ud, ipr.OwnedCharacterList) {
+		clearCharacter(c)
+	}
+
+	md := object(base, ipr.MapData)
+	if md == nil {
+		return
+	}
+	clearKeys(md, ipr.MapID, ipr.PointIn, ipr.TransportationID, ipr.CarryingHoverShip, ipr.PlayableCharacterCorpsID,
+		ipr.BeastFieldEncountExchangeFlags)
+	if pe := object(md, ipr.PlayerEntity); pe != nil {
+		clearKeys(pe, ipr.PlayerDirection)
+		clearKeys(object(pe, ipr.PlayerPosition), "x", "y", "z")
+	}
+	clearKeys(object(md, ipr.GpsData), ipr.GpsDataMapID, ipr.GpsDataAreaID, ipr.GpsDataID, ipr.GpsDataWidth, ipr.GpsDataHeight)
+}
+
+func clearCharacter(c *jo.OrderedMap) {
+	id, idOK := number(c.Get(ipr.ID))
+	jobID, jobOK := number(c.Get(ipr.JobID))
+	if !idOK || !jobOK {
+		return
+	}
+	o, found := pri.GetCharacterBaseOffset(id, jobID)
+	if !found {
+		return
+	}
+
+	// The name stays: the party is resolved by it
+	clearKeys(c, ipr.IsEnableCorps, ipr.CurrentExp)
+	if esper, ok := number(c.Get(ipr.MagicStoneId)); ok && (esper == 0 || pr.EspersByValue[esper] != nil) {
+		clearKeys(c, ipr.MagicStoneId)
+	}
+	clearKeys(object(c, ipr.Parameter), ipr.AdditionalLevel, ipr.CurrentHP, ipr.AdditionalMaxHp, ipr.CurrentMP,
+		ipr.AdditionalMaxMp, ipr.AdditionalPower, ipr.AdditionalVitality, ipr.AdditionalAgility, ipr.AdditionMagic)
+
+	if t := object(c, ipr.CommandList); t != nil {
+		// The saver writes every command of the model, which may have more
+		commands, ok := t.Get("target").([]interface{})
+		if ok && len(commands) == len(pri.GetCharacter(o.Name).Commands) && knownCommands(commands) {
+			t.Set("target", zero(commands))
+		}
+	}
+
+	if eq := object(c, ipr.EquipmentList); eq != nil {
+		values, _ := eq.Get("values").([]interface{})
+		for _, v := range values {
+			m, _ := unwrap(v).(*jo.OrderedMap)
+			if m == nil {
+				continue
+			}
+			if id, ok := number(m.Get("contentId")); ok && resolvesTo(ipr.ItemName(id), id) {
+				clearKeys(m, "contentId")
+			}
+			clearKeys(m, "count")
+		}
+	}
+
+	var skills map[int]*consts.NameValueChecked
+	switch {
+	case jobID == 3:
+		skills = pr.BushidoLookupByID
+	case jobID == 6:
+		skills = pr.BlitzLookupByID
+	case id == 16:
+		skills = pr.DanceLookupByID
+	case jobID == 8:
+		skills = pr.LoreLookupByID
+	case jobID == 12:
+		skills = pr.RageLookupByID
+	}
+	for _, m := range objects(c, ipr.AbilityList) {
+		id, ok := number(m.Get("abilityId"))
+		if !ok {
+			continue
+		}
+		isSpell := int64(id) >= pr.SpellFrom && int64(id) <= pr.SpellTo && pr.SpellLookupByID[id] != nil
+		if isSpell || skills[id] != nil {
+			clearKeys(m, "skillLevel")
+		}
+	}
+}
+
+// resolvesTo reports whether an equipment name sets the item back, which the
+// empty slots' shared name doesn't
+func resolvesTo(name string, id int) bool {
+	i, err := ipr.ResolveItemID(name, pr.ItemsByName, pr.ItemsByID)
+	return err == nil && i == id
+}
+
+func knownCommands(commands []interface{}) bool {
+	for _, v := range commands {
+		if id, ok := number(v); !ok || pr.CommandLookupByValue[id] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// clearKeys zeroes the keys m has
+func clearKeys(m *jo.OrderedMap, keys ...string) {
+	if m == nil {
+		return
+	}
+	for _, key := range keys {
+		if v, ok := m.GetValue(key); ok {
+			m.Set(key, zero(v))
+		}
+	}
+}
+
+// zero keeps the type of a value and the length of a list
+func zero(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		return json.Number("0")
+	case string:
+		return ""
+	case bool:
+		return false
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i := range t {
+			l[i] = zero(t[i])
+		}
+		return l
+	}
+	return v
+}
+
+// unwrap returns the document of an expanded string, or v
+func unwrap(v interface{}) interface{} {
+	if m, ok := v.(*jo.OrderedMap); ok {
+		if doc, ok := embedded(m); ok {
+			return doc
+		}
+	}
+	return v
+}
+
+// object returns the object under key, looking through an expanded string
+func object(m *jo.OrderedMap, key string) *jo.OrderedMap {
+	if m == nil {
+		return nil
+	}
+	o, _ := unwrap(m.Get(key)).(*jo.OrderedMap)
+	return o
+}
+
+// objects returns the objects of the {"target": [...]} list under key
+func objects(m *jo.OrderedMap, key string) []*jo.OrderedMap {
+	t := object(m, key)
+	if t == nil {
+		return nil
+	}
+	l, _ := unwrap(t.Get("target")).([]interface{})
+	var list []*jo.OrderedMap
+	for _, v := range l {
+		if o, ok := unwrap(v).(*jo.OrderedMap); ok {
+			list = append(list, o)
+		}
+	}
+	return list
+}
+
+func number(v interface{}) (int, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	i, err := n.Int64()
+	return int(i), err == nil
+}
@@ -0,0 +1,424 @@
+// Package source writes a whole save as a text "source" file that can be
+// reviewed, diffed and kept under version control, and rebuilds the binary
+// save from it. Every modelled field is written as a value under the section
+// of its logical save path (see io/pr/paths.go); everything else the save
+// holds is carried through as plain JSON in the [opaque] section.
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/file"
+	ipr "ffvi_editor/io/pr"
+	"ffvi_editor/models/consts"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+// Version is the source layout version written to [save]
+const Version = 1
+
+// Sections that aren't save paths
+const (
+	sectionSave   = "save"
+	sectionOpaque = "opaque"
+)
+
+var header = []string{
+	"# FF6 save source. Edit the values and rebuild the save with:",
+	"#   ffvi_editor source build --input <this file> --output <save file>",
+}
+
+// Built is a save rebuilt from a source document, ready to be saved
+type Built struct {
+	Save *ipr.PR
+	Type global.SaveFileType
+	Slot int
+}
+
+// Error is an entry of a source document that can't be applied
+type Error struct {
+	Line    int
+	Path    string
+	Message string
+}
+
+func (e Error) String() string {
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Path, e.Message)
+}
+
+// BuildError lists every entry that failed to apply; nothing is built
+type BuildError struct {
+	Errors []Error
+}
+
+func (e *BuildError) Error() string {
+	msg := "source doesn't apply: " + e.Errors[0].String()
+	if len(e.Errors) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(e.Errors)-1)
+	}
+	return msg
+}
+
+// checkedList is a learned/owned list and its section
+type checkedList struct {
+	category string
+	list     []*consts.NameValueChecked
+}
+
+func checkedLists() []checkedList {
+	return []checkedList{
+		{ipr.PathEspers, pr.Espers},
+		{ipr.PathRages, pr.Rages},
+		{ipr.PathLores, pr.Lores},
+		{ipr.PathDances, pr.Dances},
+		{ipr.PathBlitzes, pr.Blitzes},
+		{ipr.PathBushido, pr.Bushidos},
+	}
+}
+
+// Export reads a save file and returns it as a source document. The comments
+// of previous, the source it was last exported to, are kept; it may be nil.
+func Export(fromFile string, saveType global.SaveFileType, previous *Document) (*Document, error) {
+	out, trimmed, err := file.LoadFile(fromFile, saveType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load save file: %w", err)
+	}
+	p := ipr.New()
+	if err = p.LoadJSON(out, trimmed); err != nil {
+		return nil, fmt.Errorf("failed to load save file: %w", err)
+	}
+
+	d := &Document{Comments: header}
+	s := d.AddSection(sectionSave)
+	s.Add("version", Version)
+	s.Add("type", saveTypeName(saveType))
+	s.Add("slot", p.SlotID())
+	if len(trimmed) > 0 {
+		s.Add("prefix", string(trimmed))
+	}
+
+	for _, c := range p.LoadedCharacters() {
+		s = d.AddSection(ipr.JoinPath(ipr.PathCharacters, c.RootName))
+		for _, f := range ipr.PathFields(ipr.PathCharacters) {
+			addPath(s, f)
+		}
+		for _, slot := range ipr.PathFields("equipment") {
+			addPath(s, "equipment/"+slot)
+		}
+		for _, spell := range c.SpellsByIndex {
+			addPath(s, "spells/"+ipr.JoinPath(spell.Name))
+		}
+		for i := range c.Commands {
+			addPath(s, "commands/"+strconv.Itoa(i))
+		}
+	}
+
+	addInventory(d.AddSection(ipr.PathInventory), pri.GetInventory(), pr.ItemsByName, pr.ItemsByID)
+	addInventory(d.AddSection(ipr.PathImportantItems), pri.GetImportantInventory(), pr.ImportantItemsByName, pr.ImportantItemsByID)
+
+	for _, l := range checkedLists() {
+		s = d.AddSection(l.category)
+		for _, v := range l.list {
+			s.Add(checkedKey(l.list, v), v.Checked)
+		}
+	}
+
+	s = d.AddSection(ipr.PathVeldt)
+	for i := range pri.GetVeldt().Encounters {
+		addPath(s, strconv.Itoa(i))
+	}
+	s = d.AddSection(ipr.PathParty)
+	for i := range pri.GetParty().Members {
+		addPath(s, strconv.Itoa(i))
+	}
+	s = d.AddSection(ipr.PathMap)
+	for _, f := range ipr.PathFields(ipr.PathMap) {
+		addPath(s, f)
+	}
+	for i, t := range pri.Transportations {
+		if t == nil {
+			continue
+		}
+		s = d.AddSection(ipr.JoinPath(ipr.PathTransportation, strconv.Itoa(i)))
+		for _, f := range ipr.PathFields(ipr.PathTransportation) {
+			addPath(s, f)
+		}
+	}
+	s = d.AddSection(ipr.PathMisc)
+	for _, f := range ipr.PathFields(ipr.PathMisc) {
+		addPath(s, f)
+	}
+
+	opaque, err := encodeOpaque(out)
+	if err != nil {
+		return nil, err
+	}
+	s = d.AddSection(sectionOpaque)
+	s.Comments = []string{
+		"# Save data the editor doesn't model, carried through unchanged. Don't edit.",
+		"# The values of the sections above show as 0, \"\", false or [] here.",
+	}
+	s.Add("data", opaque)
+
+	d.KeepComments(previous)
+	return d, nil
+}
+
+// ExportToFile writes the source of a save file, keeping the comments of the
+// source already at toFile
+func ExportToFile(fromFile string, saveType global.SaveFileType, toFile string) error {
+	var previous *Document
+	if f, err := os.Open(toFile); err == nil {
+		previous, err = Parse(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read the existing source %s: %w", toFile, err)
+		}
+	}
+
+	d, err := Export(fromFile, saveType, previous)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if _, err = d.WriteTo(&buf); err != nil {
+		return err
+	}
+	if err = os.WriteFile(toFile, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write source file: %w", err)
+	}
+	return nil
+}
+
+// Build loads the opaque data of a source document and applies its values.
+// Only values that differ from the opaque data are written, so the saver
+// leaves everything else as the source had it. A value that can't be applied is
+// reported in a *BuildError after the rest were checked.
+func Build(d *Document) (*Built, error) {
+	s := d.Section(sectionSave)
+	if s == nil {
+		return nil, fmt.Errorf("source has no [%s] section", sectionSave)
+	}
+	if v, ok := entryValue(s, "version").(int); !ok || v != Version {
+		return nil, fmt.Errorf("unsupported source version %v (want %d)", entryValue(s, "version"), Version)
+	}
+	b := &Built{Save: ipr.New()}
+	typeName, _ := entryValue(s, "type").(string)
+	var err error
+	if b.Type, err = parseSaveType(typeName); err != nil {
+		return nil, err
+	}
+	slot, ok := entryValue(s, "slot").(int)
+	if !ok {
+		return nil, fmt.Errorf("[%s] slot must be a number", sectionSave)
+	}
+	b.Slot = slot
+	prefix, _ := entryValue(s, "prefix").(string)
+
+	opaque := d.Section(sectionOpaque)
+	var text string
+	if opaque != nil {
+		text, _ = entryValue(opaque, "data").(string)
+	}
+	if text == "" {
+		return nil, fmt.Errorf("source has no [%s] data", sectionOpaque)
+	}
+	out, err := decodeOpaque(text)
+	if err != nil {
+		return nil, err
+	}
+	var trimmed []byte
+	if prefix != "" {
+		trimmed = []byte(prefix)
+	}
+	if err = b.Save.LoadJSON(out, trimmed); err != nil {
+		return nil, fmt.Errorf("failed to load the opaque save data: %w", err)
+	}
+
+	var errs []Error
+	for _, s := range d.Sections {
+		switch s.Name {
+		case sectionSave, sectionOpaque:
+			continue
+		case ipr.PathInventory:
+			errs = append(errs, applyInventory(s, pri.GetInventory(), pr.ItemsByName, pr.ItemsByID)...)
+			continue
+		case ipr.PathImportantItems:
+			errs = append(errs, applyInventory(s, pri.GetImportantInventory(), pr.ImportantItemsByName, pr.ImportantItemsByID)...)
+			continue
+		}
+		for _, e := range s.Entries {
+			path := s.Name + "/" + e.Key
+			current, err := ipr.GetPath(path)
+			if err == nil && sameValue(current, e.Value) {
+				continue
+			}
+			if err == nil {
+				err = ipr.SetPath(path, e.Value)
+			}
+			if err != nil {
+				errs = append(errs, Error{Line: e.Line, Path: path, Message: strings.TrimPrefix(err.Error(), path+": ")})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, &BuildError{Errors: errs}
+	}
+	return b, nil
+}
+
+// BuildFile parses and builds a source file
+func BuildFile(fromFile string) (*Built, error) {
+	f, err := os.Open(fromFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source file: %w", err)
+	}
+	defer f.Close()
+	d, err := Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	return Build(d)
+}
+
+// addPath adds the current value of a path below the section
+func addPath(s *Section, key string) {
+	if v, err := ipr.GetPath(s.Name + "/" + key); err == nil {
+		s.Add(key, v)
+	}
+}
+
+// addInventory adds the owned rows in inventory order. Items are keyed by
+// name unless the name is ambiguous or unknown, then by ID.
+func addInventory(s *Section, inv *pri.Inventory, byName map[string]int, byID map[int]string) {
+	names := make(map[string]int)
+	for name := range byName {
+		names[strings.ToLower(strings.TrimSpace(name))]++
+	}
+	for _, r := range inv.Rows {
+		if r == nil || r.ItemID == 0 || r.Count <= 0 {
+			continue
+		}
+		key := strconv.Itoa(r.ItemID)
+		if name, ok := byID[r.ItemID]; ok {
+			name = strings.TrimSpace(name)
+			if id, found := byName[name]; found && id == r.ItemID && names[strings.ToLower(name)] == 1 {
+				key = ipr.JoinPath(name)
+			}
+		}
+		s.Add(key, r.Count)
+	}
+}
+
+// applyInventory rewrites the inventory rows when the section lists other
+// items, counts or order than the loaded rows
+func applyInventory(s *Section, inv *pri.Inventory, byName map[string]int, byID map[int]string) (errs []Error) {
+	rows := make([]pri.Row, 0, len(s.Entries))
+	for _, e := range s.Entries {
+		path := s.Name + "/" + e.Key
+		id, err := strconv.Atoi(e.Key)
+		if err != nil {
+			if id, err = ipr.ResolveItemID(ipr.SplitPath(e.Key)[0], byName, byID); err != nil {
+				errs = append(errs, Error{Line: e.Line, Path: path, Message: err.Error()})
+				continue
+			}
+		}
+		count, ok := e.Value.(int)
+		if !ok || count < 1 || count > pri.MaxItemCount {
+			errs = append(errs, Error{Line: e.Line, Path: path, Message: fmt.Sprintf("count must be 1-%d", pri.MaxItemCount)})
+			continue
+		}
+		rows = append(rows, pri.Row{ItemID: id, Count: count})
+	}
+	if len(errs) > 0 {
+		return
+	}
+
+	var current []pri.Row
+	for _, r := range inv.Rows {
+		if r != nil && r.ItemID != 0 && r.Count > 0 {
+			current = append(current, *r)
+		}
+	}
+	if sameRows(current, rows) {
+		return nil
+	}
+	if len(rows) > inv.Size {
+		return []Error{{Line: s.Line, Path: s.Name, Message: fmt.Sprintf("%d rows don't fit in %d slots", len(rows), inv.Size)}}
+	}
+	inv.Reset()
+	for n, r := range rows {
+		inv.Set(n, r)
+	}
+	return nil
+}
+
+func sameRows(a, b []pri.Row) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkedKey keys an entry of a list by name unless an earlier entry would
+// take the name, then by value
+func checkedKey(list []*consts.NameValueChecked, v *consts.NameValueChecked) string {
+	for _, other := range list {
+		if other == v {
+			return ipr.JoinPath(v.Name)
+		}
+		if strings.EqualFold(strings.TrimSpace(other.Name), strings.TrimSpace(v.Name)) {
+			break
+		}
+	}
+	return strconv.Itoa(v.Value)
+}
+
+// sameValue compares a path value with a parsed source value
+func sameValue(current, v interface{}) bool {
+	if f, ok := current.(float64); ok {
+		switch t := v.(type) {
+		case float64:
+			return f == t
+		case int:
+			return f == float64(t)
+		}
+		return false
+	}
+	return fmt.Sprint(current) == fmt.Sprint(v)
+}
+
+func entryValue(s *Section, key string) interface{} {
+	if e := s.Get(key); e != nil {
+		return e.Value
+	}
+	return nil
+}
+
+func saveTypeName(t global.SaveFileType) string {
+	if t == global.PS {
+		return "ps"
+	}
+	return "pc"
+}
+
+func parseSaveType(s string) (global.SaveFileType, error) {
+	switch s {
+	case "pc":
+		return global.PC, nil
+	case "ps":
+		return global.PS, nil
+	}
+	return 0, fmt.Errorf("unknown save type %q (want pc or ps)", s)
+}
@@ -0,0 +1,197 @@
+package source
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ffvi_editor/global"
+	ipr "ffvi_editor/io/pr"
+	"ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+const testSave = "../../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y="
+
+func export(t *testing.T) *Document {
+	t.Helper()
+	d, err := Export(testSave, global.PC, nil)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	return d
+}
+
+func write(t *testing.T, d *Document) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// reparse writes and parses the document, as a round trip through a file would
+func reparse(t *testing.T, d *Document) *Document {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	d, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("can't parse the exported source: %v", err)
+	}
+	return d
+}
+
+func TestBuildRoundTrip(t *testing.T) {
+	d := reparse(t, export(t))
+	before := ipr.TakeSnapshot()
+
+	b, err := Build(d)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if b.Type != global.PC || b.Slot != entryValue(d.Section(sectionSave), "slot") {
+		t.Errorf("built type %d slot %d", b.Type, b.Slot)
+	}
+	if report := ipr.NewComparator(before, ipr.TakeSnapshot()).Compare(); report.Statistics.TotalDiffs != 0 {
+		t.Errorf("an unedited source changed %d fields: %+v", report.Statistics.TotalDiffs, report.Diffs)
+	}
+
+	// The written save exports to the same source, byte for byte
+	out := filepath.Join(t.TempDir(), "save")
+	if err = b.Save.Save(b.Slot, out, b.Type); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	again, err := Export(out, global.PC, nil)
+	if err != nil {
+		t.Fatalf("Export of the built save failed: %v", err)
+	}
+	want, got := write(t, d), write(t, again)
+	if got != want {
+		wl, gl := strings.Split(want, "\n"), strings.Split(got, "\n")
+		for i := 0; i < len(wl) && i < len(gl); i++ {
+			if wl[i] != gl[i] {
+				t.Fatalf("re-export differs at line %d: %q, want %q", i+1, gl[i], wl[i])
+			}
+		}
+		t.Fatalf("re-export has %d lines, want %d", len(gl), len(wl))
+	}
+}
+
+func TestOpaqueLeavesOutSectionValues(t *testing.T) {
+	d := export(t)
+	opaque := d.Section(sectionOpaque).Get("data").Value.(string)
+	if !strings.Contains(opaque, `"owendGil": 0,`) {
+		t.Error("opaque data holds the gil")
+	}
+
+	// Edited values don't show up in the opaque data of the built save
+	d.Section(ipr.PathMisc).Get("gil").Value = 1234
+	d.Section("characters/Terra").Get("exp").Value = 4321
+	d.Section("characters/Terra").Get("equipment/weapon").Value = "Mythril Knife"
+	b, err := Build(reparse(t, d))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "save")
+	if err = b.Save.Save(b.Slot, out, b.Type); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	again, err := Export(out, global.PC, nil)
+	if err != nil {
+		t.Fatalf("Export of the built save failed: %v", err)
+	}
+	if again.Section(sectionOpaque).Get("data").Value != opaque {
+		t.Error("editing the sections changed the opaque data")
+	}
+}
+
+func TestBuildAppliesEdits(t *testing.T) {
+	d := export(t)
+	d.Section("characters/Terra").Get("level").Value = 42
+	d.Section("characters/Terra").Get("spells/Fire").Value = 0
+	inv := d.Section(ipr.PathInventory)
+	inv.Get("Potion").Value = 5
+	d.Section(ipr.PathEspers).Get("Ramuh").Value = true
+	d.Section(ipr.PathMisc).Get("gil").Value = 1234
+
+	if _, err := Build(reparse(t, d)); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	for path, want := range map[string]string{
+		"characters/Terra/level":       "42",
+		"characters/Terra/spells/Fire": "0",
+		"espers/Ramuh":                 "true",
+		"misc/gil":                     "1234",
+	} {
+		if v, err := ipr.GetPath(path); err != nil || fmt.Sprint(v) != want {
+			t.Errorf("%s built as %v (%v), want %s", path, v, err, want)
+		}
+	}
+	potion := pr.ItemsByName["Potion"]
+	for _, r := range pri.GetInventory().Rows {
+		if r != nil && r.ItemID == potion && r.Count != 5 {
+			t.Errorf("built %d potions, want 5", r.Count)
+		}
+	}
+}
+
+func TestBuildReportsEveryError(t *testing.T) {
+	d := export(t)
+	d.Section("characters/Terra").Get("level").Value = 500
+	d.Section("characters/Terra").Get("equipment/weapon").Value = "No Such Weapon"
+	d.Section(ipr.PathInventory).Add("No Such Item", 1)
+
+	_, err := Build(reparse(t, d))
+	var berr *BuildError
+	if !errors.As(err, &berr) {
+		t.Fatalf("expected a build error, got %v", err)
+	}
+	if len(berr.Errors) != 3 {
+		t.Errorf("expected 3 errors, got %v", berr.Errors)
+	}
+	for _, e := range berr.Errors {
+		if e.Line == 0 {
+			t.Errorf("error without a line: %v", e)
+		}
+	}
+
+	d = export(t)
+	d.Section(sectionOpaque).Get("data").Value = "{\"userData\": "
+	if _, err = Build(d); err == nil || !strings.Contains(err.Error(), "damaged") {
+		t.Errorf("expected damaged opaque data, got %v", err)
+	}
+}
+
+func TestExportKeepsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.ffsrc")
+	if err := ExportToFile(testSave, global.PC, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := strings.Replace(string(data), "[characters/Terra]\n", "# main character\n[characters/Terra]\n", 1)
+	text = strings.Replace(text, "\nlevel = 6\n", "\nlevel = 6  # grind later\n", 1)
+	if err = os.WriteFile(path, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ExportToFile(testSave, global.PC, path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	for _, want := range []string{"# main character\n[characters/Terra]\n", "\nlevel = 6  # grind later\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("re-export lost %q", want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ffvi_editor/io/pr"
+	"ffvi_editor/models"
+)
+
+// ErrSaveBlocked is returned when validation stops a save from being written
+var ErrSaveBlocked = errors.New("save blocked by validation")
+
+// SaveDecision is what the pre-save check decided about a write
+type SaveDecision string
+
+const (
+	// SaveAllowed lets the write go ahead
+	SaveAllowed SaveDecision = "allowed"
+	// SaveNeedsConfirm writes only when the user accepts the issues
+	SaveNeedsConfirm SaveDecision = "confirm"
+	// SaveBlocked refuses the write
+	SaveBlocked SaveDecision = "blocked"
+)
+
+// SaveCheck is the outcome of the pre-save check
+type SaveCheck struct {
+	Decision SaveDecision
+	Result   models.ValidationResult // After any auto-fixes
+	Fixed    int                     // Issues auto-fixed before the check
+	FixErr   error                   // Fixes that failed
+	// Confirmed is set when the user accepted the issues of SaveNeedsConfirm
+	Confirmed bool
+}
+
+// Issues returns the errors, then the warnings, that stand against the save
+func (c SaveCheck) Issues() []models.ValidationIssue {
+	issues := make([]models.ValidationIssue, 0, len(c.Result.Errors)+len(c.Result.Warnings))
+	issues = append(issues, c.Result.Errors...)
+	return append(issues, c.Result.Warnings...)
+}
+
+// Summary lists at most max issues, one per line, after a count line.
+// Zero or less lists them all.
+func (c SaveCheck) Summary(max int) string {
+	lines := []string{fmt.Sprintf("%d error(s), %d warning(s)", len(c.Result.Errors), len(c.Result.Warnings))}
+	if c.Fixed > 0 {
+		lines[0] += fmt.Sprintf(" after fixing %d issue(s)", c.Fixed)
+	}
+	issues := c.Issues()
+	for i, issue := range issues {
+		if max > 0 && i == max {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(issues)-max))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("[%s] %s: %s", issue.Severity, issue.Target, issue.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Err returns ErrSaveBlocked with the issue counts, or nil when the save was
+// allowed or confirmed
+func (c SaveCheck) Err() error {
+	if c.Decision == SaveAllowed || c.Confirmed {
+		return nil
+	}
+	return fmt.Errorf("%w: %d error(s), %d warning(s)", ErrSaveBlocked, len(c.Result.Errors), len(c.Result.Warnings))
+}
+
+// SaveGate decides whether save data may be written, following the
+// validation settings:
+//   - AutoFix repairs the fixable issues before the data is checked
+//   - Valid data is always written
+//   - Strict mode blocks invalid data
+//   - WarnOnSave asks before writing invalid data; without it the write is
+//     blocked, so permissive mode is the way to save broken data silently
+type SaveGate struct {
+	Validator  *Validator
+	AutoFix    bool
+	WarnOnSave bool
+}
+
+// NewSaveGate creates a gate around a validator
+func NewSaveGate(v *Validator, autoFix, warnOnSave bool) *SaveGate {
+	return &SaveGate{Validator: v, AutoFix: autoFix, WarnOnSave: warnOnSave}
+}
+
+// Check validates the data, fixing it first when AutoFix is set
+func (g *SaveGate) Check(data *pr.PR) (check SaveCheck) {
+	check.Result = g.Validator.Validate(data)
+	if g.AutoFix && len(check.Result.FixableIssues()) > 0 {
+		check.Fixed, check.FixErr = g.Validator.AutoFixIssues(data)
+		check.Result = g.Validator.Validate(data)
+	}
+
+	switch {
+	case check.Result.Valid:
+		check.Decision = SaveAllowed
+	case g.Validator.GetConfig().Mode == models.StrictMode:
+		check.Decision = SaveBlocked
+	case g.WarnOnSave:
+		check.Decision = SaveNeedsConfirm
+	default:
+		check.Decision = SaveBlocked
+	}
+	return
+}
+
+// Save checks the data and calls write when the check allows it. confirm
+// answers SaveNeedsConfirm and may be nil to refuse. A refused or blocked
+// write returns ErrSaveBlocked.
+func (g *SaveGate) Save(data *pr.PR, confirm func(SaveCheck) bool, write func() error) (SaveCheck, error) {
+	check := g.Check(data)
+	if check.Decision == SaveNeedsConfirm && confirm != nil {
+		check.Confirmed = confirm(check)
+	}
+	if err := check.Err(); err != nil {
+		return check, err
+	}
+	return check, write()
+}
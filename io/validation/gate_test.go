@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"ffvi_editor/models"
+	pri "ffvi_editor/models/pr"
+)
+
+func TestSaveGate(t *testing.T) {
+	save := loadSave(t)
+	wrote := 0
+	write := func() error {
+		wrote++
+		return nil
+	}
+
+	gate := NewSaveGate(NewValidator(), false, true)
+	if check, err := gate.Save(save, nil, write); err != nil || check.Decision != SaveAllowed || wrote != 1 {
+		t.Fatalf("expected the fixture to be written, got %s, %v", check.Decision, err)
+	}
+
+	pri.GetCharacter("Terra").Level = 150
+
+	// Prompts are refused without confirm and accepted with it
+	check, err := gate.Save(save, nil, write)
+	if !errors.Is(err, ErrSaveBlocked) || check.Decision != SaveNeedsConfirm || wrote != 1 {
+		t.Fatalf("expected a refused prompt, got %s, %v", check.Decision, err)
+	}
+	if findIssue(check.Issues(), "character_level_range", "characters/Terra/level") == nil {
+		t.Errorf("the prompt should list the level issue: %s", check.Summary(0))
+	}
+	if _, err = gate.Save(save, func(SaveCheck) bool { return true }, write); err != nil || wrote != 2 {
+		t.Fatalf("expected a confirmed write, got %v", err)
+	}
+
+	// Without warnings, or in strict mode, errors block the write
+	gate.WarnOnSave = false
+	if check = gate.Check(save); check.Decision != SaveBlocked {
+		t.Errorf("expected a block without WarnOnSave, got %s", check.Decision)
+	}
+	gate.WarnOnSave = true
+	cfg := gate.Validator.GetConfig()
+	cfg.Mode = models.StrictMode
+	gate.Validator.SetConfig(cfg)
+	if check = gate.Check(save); check.Decision != SaveBlocked {
+		t.Errorf("expected a block in strict mode, got %s", check.Decision)
+	}
+
+	// Lenient mode lets errors through
+	cfg.Mode = models.LenientMode
+	gate.Validator.SetConfig(cfg)
+	if check = gate.Check(save); check.Decision != SaveAllowed {
+		t.Errorf("expected lenient mode to allow the write, got %s", check.Decision)
+	}
+
+	// Auto-fix repairs the level before the check
+	cfg.Mode = models.NormalMode
+	gate.Validator.SetConfig(cfg)
+	gate.AutoFix = true
+	check = gate.Check(save)
+	if check.Decision != SaveAllowed || check.Fixed == 0 || check.FixErr != nil {
+		t.Errorf("expected auto-fix to allow the write, got %s after %d fixes: %v", check.Decision, check.Fixed, check.FixErr)
+	}
+	if level := pri.GetCharacter("Terra").Level; level > 99 {
+		t.Errorf("auto-fix left the level at %d", level)
+	}
+}
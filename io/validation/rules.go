@@ -0,0 +1,354 @@
+package validation
+
+import (
+	"fmt"
+
+	"ffvi_editor/io/pr"
+	"ffvi_editor/models"
+	cpr "ffvi_editor/models/consts/pr"
+	"ffvi_editor/models/game"
+	pri "ffvi_editor/models/pr"
+)
+
+// registerDefaultRules registers all built-in validation rules
+func (v *Validator) registerDefaultRules() {
+	v.registerRule(Rule{
+		Name:        "character_level_range",
+		Description: "Character level must be 1-99",
+		Check: func(data *pr.PR) (findings []Finding) {
+			for _, c := range data.LoadedCharacters() {
+				findings = appendRange(findings, c, "level", "level", &c.Level, 1, int(v.config.MaxCharacterLevel))
+			}
+			return
+		},
+		Severity: models.SeverityError,
+	})
+
+	v.registerRule(Rule{
+		Name:        "character_hp_range",
+		Description: "Character max HP must be 1-9999",
+		Check: func(data *pr.PR) (findings []Finding) {
+			for _, c := range data.LoadedCharacters() {
+				findings = appendRange(findings, c, "maxHp", "max HP", &c.HP.Max, 1, min(int(v.config.MaxCharacterHP), pri.MaxHP))
+			}
+			return
+		},
+		Severity: models.SeverityError,
+	})
+
+	v.registerRule(Rule{
+		Name:        "character_mp_range",
+		Description: "Character max MP must be 0-999",
+		Check: func(data *pr.PR) (findings []Finding) {
+			for _, c := range data.LoadedCharacters() {
+				findings = appendRange(findings, c, "maxMp", "max MP", &c.MP.Max, 0, min(int(v.config.MaxCharacterMP), pri.MaxMP))
+			}
+			return
+		},
+		Severity: models.SeverityError,
+	})
+
+	v.registerRule(Rule{
+		Name:        "character_stat_range",
+		Description: "Character vigor, stamina, speed and magic must be 0-255",
+		Check: func(data *pr.PR) (findings []Finding) {
+			limit := int(v.config.MaxStatValue)
+			for _, c := range data.LoadedCharacters() {
+				findings = appendRange(findings, c, "vigor", "vigor", &c.Vigor, 0, limit)
+				findings = appendRange(findings, c, "stamina", "stamina", &c.Stamina, 0, limit)
+				findings = appendRange(findings, c, "speed", "speed", &c.Speed, 0, limit)
+				findings = appendRange(findings, c, "magic", "magic", &c.Magic, 0, limit)
+			}
+			return
+		},
+		Severity: models.SeverityError,
+	})
+
+	v.registerRule(Rule{
+		Name:        "character_exp_level",
+		Description: "Character level must be the one its EXP reaches",
+		Check: func(data *pr.PR) (findings []Finding) {
+			mode, err := pri.ParseLevelFixMode(v.config.LevelFixMode)
+			if err != nil {
+				mode = pri.ExpFromLevel
+			}
+			for _, c := range data.LoadedCharacters() {
+				if pri.LevelMatchesExp(c) {
+					continue
+				}
+				findings = append(findings, Finding{
+					Target:   pr.JoinPath(pr.PathCharacters, c.RootName, "exp"),
+					TargetID: c.RootName,
+					Message: fmt.Sprintf("%s is level %d but %d EXP is level %d",
+						c.Name, c.Level, c.Exp, pri.LevelForExp(c.Exp)),
+					FixAction: mode.Label(),
+					Fix: func() error {
+						pri.FixLevelExp(c, mode)
+						return nil
+					},
+				})
+			}
+			return
+		},
+		Severity: models.SeverityWarning,
+	})
+
+	v.registerRule(Rule{
+		Name:        "equipment_slot",
+		Description: "Equipment must fit its slot; two weapons need the Genji Glove",
+		Check: func(data *pr.PR) (findings []Finding) {
+			for _, c := range data.LoadedCharacters() {
+				findings = appendEquipment(findings, c, game.IssueWrongSlot, game.IssueDualWield)
+			}
+			return
+		},
+		Severity: models.SeverityError,
+	})
+
+	// The game wears these without complaint, they just can't happen in play
+	v.registerRule(Rule{
+		Name:        "equipment_restriction",
+		Description: "Characters should only wear gear they can equip, without wasted relics",
+		Check: func(data *pr.PR) (findings []Finding) {
+			for _, c := range data.LoadedCharacters() {
+				findings = appendEquipment(findings, c, game.IssueCannotEquip, game.IssueDuplicateRelic, game.IssueWastedRelic)
+			}
+			return
+		},
+		Severity: models.SeverityWarning,
+	})
+
+	// The game caps current HP and MP on load, so these only warn
+	v.registerRule(Rule{
+		Name:        "character_hp_current",
+		Description: "Character current HP must be between 0 and max HP",
+		Check: func(data *pr.PR) (findings []Finding) {
+			for _, c := range data.LoadedCharacters() {
+				findings = appendRange(findings, c, "hp", "HP", &c.HP.Current, 0, c.HP.Max)
+			}
+			return
+		},
+		Severity: models.SeverityWarning,
+	})
+
+	v.registerRule(Rule{
+		Name:        "character_mp_current",
+		Description: "Character current MP must be between 0 and max MP",
+		Check: func(data *pr.PR) (findings []Finding) {
+			for _, c := range data.LoadedCharacters() {
+				findings = appendRange(findings, c, "mp", "MP", &c.MP.Current, 0, c.MP.Max)
+			}
+			return
+		},
+		Severity: models.SeverityWarning,
+	})
+
+	v.registerRule(Rule{
+		Name:        "inventory_count_range",
+		Description: "Item counts must be 1-99; other rows are dropped on save",
+		Check: func(data *pr.PR) []Finding {
+			return checkInventories(pri.ProblemZeroCount, pri.ProblemOverMax)
+		},
+		Severity: models.SeverityError,
+	})
+
+	v.registerRule(Rule{
+		Name:        "inventory_duplicate_row",
+		Description: "Each item should have a single inventory row",
+		Check: func(data *pr.PR) []Finding {
+			return checkInventories(pri.ProblemDuplicateRow)
+		},
+		Severity: models.SeverityWarning,
+	})
+
+	v.registerRule(Rule{
+		Name:        "inventory_unknown_item",
+		Description: "Inventory items should be in the item tables",
+		Check: func(data *pr.PR) []Finding {
+			return checkInventories(pri.ProblemUnknownItem)
+		},
+		Severity: models.SeverityWarning,
+	})
+
+	v.registerRule(Rule{
+		Name:        "inventory_sort_order",
+		Description: "The inventory sort order must list each owned item once",
+		Check: func(data *pr.PR) []Finding {
+			problems, err := data.CheckInventorySortOrder()
+			if err != nil || problems.Empty() {
+				return nil
+			}
+			return []Finding{{
+				Target:    pr.PathInventory,
+				Message:   fmt.Sprintf("The inventory sort order is out of sync (%s)", problems),
+				FixAction: "Rebuild the sort order",
+				Fix:       data.RepairInventorySortOrder,
+			}}
+		},
+		Severity: models.SeverityWarning,
+	})
+
+	v.registerRule(Rule{
+		Name:        "party_not_empty",
+		Description: "The party must have at least one member",
+		Check: func(data *pr.PR) []Finding {
+			for _, m := range pri.GetParty().Members {
+				if m != nil && m.CharacterID != pri.EmptyPartyMember.CharacterID {
+					return nil
+				}
+			}
+			return []Finding{{
+				Target:  pr.JoinPath(pr.PathParty, "0"),
+				Message: "The party has no members",
+			}}
+		},
+		Severity: models.SeverityError,
+	})
+
+	v.registerRule(Rule{
+		Name:        "party_duplicate_member",
+		Description: "A character can only fill one party slot",
+		Check: func(data *pr.PR) (findings []Finding) {
+			party := pri.GetParty()
+			first := make(map[int]int)
+			for slot, m := range party.Members {
+				if m == nil || m.CharacterID == pri.EmptyPartyMember.CharacterID {
+					continue
+				}
+				if prev, found := first[m.CharacterID]; found {
+					findings = append(findings, Finding{
+						Target:    pr.JoinPath(pr.PathParty, fmt.Sprint(slot)),
+						TargetID:  slot,
+						Message:   fmt.Sprintf("%s is in party slots %d and %d", m.Name, prev+1, slot+1),
+						FixAction: fmt.Sprintf("Empty party slot %d", slot+1),
+						Fix: func() error {
+							party.Members[slot] = pri.EmptyPartyMember
+							return nil
+						},
+					})
+					continue
+				}
+				first[m.CharacterID] = slot
+			}
+			return
+		},
+		Severity: models.SeverityError,
+	})
+
+	// Map data validation
+	v.registerRule(Rule{
+		Name:        "map_data_exists",
+		Description: "Map data structure is valid",
+		Check: func(data *pr.PR) []Finding {
+			if data.MapData == nil {
+				return []Finding{{Target: pr.PathMap, Message: "Map data is missing"}}
+			}
+			return nil
+		},
+		Severity: models.SeverityWarning,
+	})
+}
+
+// appendRange adds a finding when a character value is outside [min, max];
+// the fix clamps it into the range
+func appendRange(findings []Finding, c *models.Character, field, label string, value *int, min, max int) []Finding {
+	if *value >= min && *value <= max {
+		return findings
+	}
+	target := min
+	if *value > max {
+		target = max
+	}
+	return append(findings, Finding{
+		Target:    pr.JoinPath(pr.PathCharacters, c.RootName, field),
+		TargetID:  c.RootName,
+		Message:   fmt.Sprintf("%s %s %d is outside %d-%d", c.Name, label, *value, min, max),
+		FixAction: fmt.Sprintf("Set %s %s to %d", c.Name, label, target),
+		Fix: func() error {
+			*value = target
+			return nil
+		},
+	})
+}
+
+// appendEquipment adds a finding for each equipment issue of the given kinds;
+// the fix moves the item back to the inventory
+func appendEquipment(findings []Finding, c *models.Character, kinds ...game.EquipmentIssueKind) []Finding {
+	for _, issue := range game.CheckEquipment(c) {
+		if !hasKind(kinds, issue.Kind) {
+			continue
+		}
+		slot, name := issue.Slot, pr.ItemName(issue.ItemID)
+		findings = append(findings, Finding{
+			Target:    pr.JoinPath(pr.PathCharacters, c.RootName, "equipment", string(slot)),
+			TargetID:  c.RootName,
+			Message:   issue.Message,
+			FixAction: fmt.Sprintf("Move %s to the inventory", name),
+			Fix: func() error {
+				return game.Unequip(c, slot, pri.GetInventory())
+			},
+		})
+	}
+	return findings
+}
+
+func hasKind(kinds []game.EquipmentIssueKind, kind game.EquipmentIssueKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// checkInventories adds a finding for each problem of the given kinds in
+// both inventories
+func checkInventories(kinds ...pri.InventoryProblemKind) []Finding {
+	findings := checkInventory(nil, pr.PathInventory, pri.GetInventory(), cpr.ItemsByID, kinds)
+	return checkInventory(findings, pr.PathImportantItems, pri.GetImportantInventory(), cpr.ImportantItemsByID, kinds)
+}
+
+// checkInventory adds a finding for each row problem of the given kinds. The
+// saver drops rows with bad counts; overfull rows are capped, duplicates are
+// merged into their first row and the rest are cleared.
+func checkInventory(findings []Finding, category string, inv *pri.Inventory, known map[int]string, kinds []pri.InventoryProblemKind) []Finding {
+	for _, p := range inv.Check(known) {
+		if !hasProblemKind(kinds, p.Kind) {
+			continue
+		}
+		problem, name := p, pr.ItemName(p.ItemID)
+		f := Finding{
+			Target:   pr.JoinPath(category, name),
+			TargetID: p.Row,
+			Fix: func() error {
+				inv.Fix(problem)
+				return nil
+			},
+		}
+		switch p.Kind {
+		case pri.ProblemOverMax:
+			f.Message = fmt.Sprintf("%s count %d is over %d", name, p.Count, pri.MaxItemCount)
+			f.FixAction = fmt.Sprintf("Set %s count to %d", name, pri.MaxItemCount)
+		case pri.ProblemZeroCount:
+			f.Message = fmt.Sprintf("%s has a count of %d", name, p.Count)
+			f.FixAction = fmt.Sprintf("Remove %s", name)
+		case pri.ProblemDuplicateRow:
+			f.Message = fmt.Sprintf("%s is in rows %d and %d", name, p.First+1, p.Row+1)
+			f.FixAction = fmt.Sprintf("Merge %s into row %d", name, p.First+1)
+		case pri.ProblemUnknownItem:
+			f.Message = fmt.Sprintf("Item %d isn't a known item", p.ItemID)
+			f.FixAction = fmt.Sprintf("Remove item %d", p.ItemID)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func hasProblemKind(kinds []pri.InventoryProblemKind, kind pri.InventoryProblemKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,357 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"ffvi_editor/io/pr"
+	"ffvi_editor/models"
+)
+
+// RuleSet is a file of user-defined rules, e.g. the rules of a challenge run
+// or a tournament. A set is active in the modes it lists, or in every
+// built-in mode when it lists none, and its name can be selected as a custom
+// profile.
+type RuleSet struct {
+	Name        string     `json:"name" yaml:"name"`
+	Description string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Modes       []string   `json:"modes,omitempty" yaml:"modes,omitempty"`
+	Rules       []UserRule `json:"rules" yaml:"rules"`
+	File        string     `json:"-" yaml:"-"`
+}
+
+// UserRule checks every save value whose path matches Path. A "*" path
+// segment matches any name, e.g. characters/*/level.
+type UserRule struct {
+	Name      string                    `json:"name" yaml:"name"`
+	Path      string                    `json:"path" yaml:"path"`
+	Condition Condition                 `json:"condition" yaml:"condition"`
+	Severity  models.ValidationSeverity `json:"severity" yaml:"severity"`
+	Message   string                    `json:"message,omitempty" yaml:"message,omitempty"` // {path}, {name} and {value} are replaced
+	Fix       interface{}               `json:"fix,omitempty" yaml:"fix,omitempty"`         // Value set by the fix
+	FixRemove bool                      `json:"fixRemove,omitempty" yaml:"fixRemove,omitempty"`
+}
+
+// Condition is what a value must satisfy; every field that is set must hold
+type Condition struct {
+	Min       *float64      `json:"min,omitempty" yaml:"min,omitempty"`
+	Max       *float64      `json:"max,omitempty" yaml:"max,omitempty"`
+	Equals    interface{}   `json:"equals,omitempty" yaml:"equals,omitempty"`
+	NotEquals interface{}   `json:"notEquals,omitempty" yaml:"notEquals,omitempty"`
+	OneOf     []interface{} `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	NoneOf    []interface{} `json:"noneOf,omitempty" yaml:"noneOf,omitempty"`
+	Present   *bool         `json:"present,omitempty" yaml:"present,omitempty"` // Whether the path must exist, e.g. an owned esper
+}
+
+// LoadRuleSet reads a rule set from a .json, .yaml or .yml file. A set
+// without a name is named after its file.
+func LoadRuleSet(file string) (*RuleSet, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &RuleSet{File: file}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		err = json.Unmarshal(data, rs)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, rs)
+	default:
+		return nil, fmt.Errorf("%s: rule files must be .json, .yaml or .yml", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	if rs.Name == "" {
+		rs.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	if err = rs.check(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return rs, nil
+}
+
+// LoadRuleSets reads every rule file in a directory, in name order. A missing
+// directory has no rule sets.
+func LoadRuleSets(dir string) ([]*RuleSet, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var sets []*RuleSet
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".json", ".yaml", ".yml":
+		default:
+			continue
+		}
+		if e.IsDir() {
+			continue
+		}
+		rs, err := LoadRuleSet(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, rs)
+	}
+	return sets, nil
+}
+
+// AppliesTo reports whether the set is active in a mode. Another set's
+// profile only runs the sets that list it.
+func (rs *RuleSet) AppliesTo(mode models.ValidationMode) bool {
+	if strings.EqualFold(rs.Name, string(mode)) {
+		return true
+	}
+	if len(rs.Modes) == 0 {
+		return !mode.IsCustom()
+	}
+	for _, m := range rs.Modes {
+		if models.ValidationModeForLevel(m) == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// check rejects rules that can never be evaluated
+func (rs *RuleSet) check() error {
+	for i, r := range rs.Rules {
+		if r.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if r.Path == "" {
+			return fmt.Errorf("rule %s has no path", r.Name)
+		}
+		switch r.Severity {
+		case models.SeverityError, models.SeverityWarning, models.SeverityInfo:
+		case "":
+			rs.Rules[i].Severity = models.SeverityError
+		default:
+			return fmt.Errorf("rule %s has unknown severity %q", r.Name, r.Severity)
+		}
+		c := r.Condition
+		if c.Min == nil && c.Max == nil && c.Equals == nil && c.NotEquals == nil &&
+			c.OneOf == nil && c.NoneOf == nil && c.Present == nil {
+			return fmt.Errorf("rule %s has no condition", r.Name)
+		}
+	}
+	return nil
+}
+
+// LoadRules loads the rule sets of a directory into the validator
+func (v *Validator) LoadRules(dir string) error {
+	sets, err := LoadRuleSets(dir)
+	if err != nil {
+		return err
+	}
+	for _, rs := range sets {
+		v.AddRuleSet(rs)
+	}
+	return nil
+}
+
+// AddRuleSet registers the rules of a set. They only run while the
+// validator's mode is one the set applies to.
+func (v *Validator) AddRuleSet(rs *RuleSet) {
+	v.ruleSets = append(v.ruleSets, rs)
+	for _, r := range rs.Rules {
+		v.registerRule(Rule{
+			Name:        rs.Name + "/" + r.Name,
+			Description: r.Message,
+			Check: func(data *pr.PR) []Finding {
+				if !rs.AppliesTo(v.config.Mode) {
+					return nil
+				}
+				snapshot := v.snapshot
+				if snapshot == nil {
+					snapshot = pr.TakeSnapshot()
+				}
+				return r.check(data, snapshot)
+			},
+			Severity: r.Severity,
+		})
+	}
+}
+
+// Profiles returns the modes that can be selected: the built-in levels, then
+// the names of the loaded rule sets
+func (v *Validator) Profiles() []string {
+	profiles := []string{string(models.StrictMode), string(models.NormalMode), "permissive"}
+	for _, rs := range v.ruleSets {
+		if models.ValidationModeForLevel(rs.Name).IsCustom() {
+			profiles = append(profiles, rs.Name)
+		}
+	}
+	return profiles
+}
+
+// check evaluates the rule against every matching path of a snapshot of the
+// loaded save
+func (r *UserRule) check(data *pr.PR, snapshot *pr.Snapshot) (findings []Finding) {
+	for _, path := range matchPaths(r.Path, snapshot, data) {
+		value, present := snapshot.Get(path)
+		if r.Condition.holds(value, present) {
+			continue
+		}
+
+		segments := pr.SplitPath(path)
+		f := Finding{
+			Target:   path,
+			TargetID: segments[len(segments)-1],
+			Message:  r.message(path, segments, value, present),
+		}
+		if len(segments) > 1 {
+			f.TargetID = segments[1]
+		}
+		switch {
+		case r.FixRemove:
+			f.FixAction = "Remove " + path
+			f.Fix = func() error { return pr.RemovePath(path) }
+		case r.Fix != nil:
+			fix := r.Fix
+			f.FixAction = fmt.Sprintf("Set %s to %v", path, fix)
+			f.Fix = func() error {
+				if present {
+					return pr.SetPath(path, fix)
+				}
+				return pr.AddPath(path, fix)
+			}
+		}
+		findings = append(findings, f)
+	}
+	return
+}
+
+// message fills in the rule's message, or describes the failed value
+func (r *UserRule) message(path string, segments []string, value interface{}, present bool) string {
+	shown := "missing"
+	if present {
+		shown = fmt.Sprint(value)
+	}
+	if r.Message == "" {
+		return fmt.Sprintf("%s is %s", path, shown)
+	}
+	name := segments[len(segments)-1]
+	if len(segments) > 1 {
+		name = segments[1]
+	}
+	return strings.NewReplacer("{path}", path, "{name}", name, "{value}", shown).Replace(r.Message)
+}
+
+// holds reports whether a value satisfies the condition. Missing values only
+// fail a "present: true" condition.
+func (c Condition) holds(value interface{}, present bool) bool {
+	if c.Present != nil && *c.Present != present {
+		return false
+	}
+	if !present {
+		return true
+	}
+	if c.Min != nil || c.Max != nil {
+		n, ok := toFloat(value)
+		if !ok || (c.Min != nil && n < *c.Min) || (c.Max != nil && n > *c.Max) {
+			return false
+		}
+	}
+	if c.Equals != nil && !valuesEqual(value, c.Equals) {
+		return false
+	}
+	if c.NotEquals != nil && valuesEqual(value, c.NotEquals) {
+		return false
+	}
+	if c.OneOf != nil && !containsValue(c.OneOf, value) {
+		return false
+	}
+	if c.NoneOf != nil && containsValue(c.NoneOf, value) {
+		return false
+	}
+	return true
+}
+
+// matchPaths expands a path expression against the paths of the loaded save.
+// Only characters present in the file are matched; a path without wildcards
+// is returned even when missing so "present" conditions can fail.
+func matchPaths(pattern string, snapshot *pr.Snapshot, data *pr.PR) []string {
+	want := pr.SplitPath(pattern)
+	wildcard := false
+	for _, s := range want {
+		if s == "*" {
+			wildcard = true
+		}
+	}
+
+	loaded := make(map[string]bool)
+	for _, c := range data.LoadedCharacters() {
+		loaded[c.RootName] = true
+	}
+
+	var paths []string
+	for _, path := range snapshot.Paths() {
+		segments := pr.SplitPath(path)
+		if len(segments) != len(want) {
+			continue
+		}
+		if segments[0] == pr.PathCharacters && !loaded[segments[1]] {
+			continue
+		}
+		match := true
+		for i, s := range want {
+			if s != "*" && !strings.EqualFold(s, segments[i]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 && !wildcard {
+		paths = append(paths, pr.JoinPath(want...))
+	}
+	return paths
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return float64(t), true
+	case float64:
+		return t, true
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// valuesEqual compares numbers by value and everything else by its
+// case-insensitive text
+func valuesEqual(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return x == y
+		}
+	}
+	return strings.EqualFold(strings.TrimSpace(fmt.Sprint(a)), strings.TrimSpace(fmt.Sprint(b)))
+}
+
+func containsValue(list []interface{}, v interface{}) bool {
+	for _, l := range list {
+		if valuesEqual(l, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ffvi_editor/models"
+	pri "ffvi_editor/models/pr"
+)
+
+const lowLevelRules = `name: low-level
+description: Low level challenge
+rules:
+  - name: level_cap
+    path: characters/*/level
+    condition: {max: 5}
+    severity: error
+    message: "{name} is level {value}"
+    fix: 5
+  - name: no_ramuh
+    path: espers/Ramuh
+    condition: {present: false}
+    severity: warning
+    fixRemove: true
+`
+
+const tournamentRules = `{
+  "name": "tournament",
+  "modes": ["strict"],
+  "rules": [
+    {"name": "gil_cap", "path": "misc/gil", "condition": {"max": 100}, "fix": 100},
+    {"name": "terra_name", "path": "characters/Terra/name", "condition": {"oneOf": ["Terra", "Tina"]}, "severity": "info"}
+  ]
+}`
+
+func writeRules(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"low-level.yaml":  lowLevelRules,
+		"tournament.json": tournamentRules,
+		"notes.txt":       "not a rule file",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadRuleSets(t *testing.T) {
+	sets, err := LoadRuleSets(writeRules(t))
+	if err != nil {
+		t.Fatalf("LoadRuleSets failed: %v", err)
+	}
+	if len(sets) != 2 || sets[0].Name != "low-level" || sets[1].Name != "tournament" {
+		t.Fatalf("unexpected rule sets: %+v", sets)
+	}
+	if sets[1].Rules[0].Severity != models.SeverityError {
+		t.Errorf("missing severity should default to error, got %q", sets[1].Rules[0].Severity)
+	}
+	if !sets[0].AppliesTo(models.NormalMode) || !sets[1].AppliesTo(models.StrictMode) || sets[1].AppliesTo(models.NormalMode) {
+		t.Error("rule set modes not applied")
+	}
+	if !sets[1].AppliesTo(models.ValidationModeForLevel("Tournament")) {
+		t.Error("a rule set should apply when selected as a profile")
+	}
+	if sets[0].AppliesTo(models.ValidationModeForLevel("tournament")) {
+		t.Error("a set without modes shouldn't run in another set's profile")
+	}
+
+	if sets, err = LoadRuleSets(filepath.Join(t.TempDir(), "missing")); err != nil || sets != nil {
+		t.Errorf("a missing directory should have no rules, got %v, %v", sets, err)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.yaml")
+	_ = os.WriteFile(bad, []byte("rules:\n  - name: x\n    path: misc/gil\n"), 0644)
+	if _, err = LoadRuleSet(bad); err == nil {
+		t.Error("expected an error for a rule without a condition")
+	}
+}
+
+func TestUserRules(t *testing.T) {
+	save := loadSave(t)
+	v := NewValidator()
+	if err := v.LoadRules(writeRules(t)); err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+	if profiles := v.Profiles(); len(profiles) != 5 || profiles[4] != "tournament" {
+		t.Errorf("unexpected profiles: %v", profiles)
+	}
+
+	// Normal mode runs the low-level set only
+	result := v.Validate(save)
+	issue := findIssue(result.Errors, "low-level/level_cap", "characters/Edgar/level")
+	if issue == nil || issue.Message != "Edgar is level 9" || !issue.Fixable {
+		t.Fatalf("expected a fixable level cap error for Edgar, got %+v", result.Errors)
+	}
+	if findIssue(result.Errors, "low-level/level_cap", "characters/Sabin/level") != nil {
+		t.Error("Sabin is level 1 and within the cap")
+	}
+	if findIssue(result.Errors, "tournament/gil_cap", "misc/gil") != nil {
+		t.Error("the tournament set should only run in strict mode")
+	}
+
+	if _, err := v.AutoFixIssues(save); err != nil {
+		t.Fatalf("AutoFixIssues failed: %v", err)
+	}
+	if level := pri.GetCharacter("Edgar").Level; level != 5 {
+		t.Errorf("Edgar level = %d after fixing, want 5", level)
+	}
+
+	cfg := v.GetConfig()
+	cfg.Mode = models.ValidationModeForLevel("tournament")
+	v.SetConfig(cfg)
+	result = v.Validate(save)
+	if findIssue(result.Errors, "tournament/gil_cap", "misc/gil") == nil {
+		t.Errorf("expected the gil cap in the tournament profile, got %+v", result.Errors)
+	}
+	if findIssue(result.Infomsgs, "tournament/terra_name", "characters/Terra/name") != nil {
+		t.Errorf("Terra's name should pass the oneOf condition")
+	}
+}
+
+func TestLenientModeStaysValid(t *testing.T) {
+	save := loadSave(t)
+	pri.GetCharacter("Terra").Level = 120
+
+	v := NewValidator()
+	cfg := v.GetConfig()
+	cfg.Mode = models.ValidationModeForLevel("permissive")
+	v.SetConfig(cfg)
+	result := v.Validate(save)
+	if !result.Valid || len(result.Errors) == 0 {
+		t.Errorf("permissive mode should report errors without failing: %+v", result)
+	}
+}
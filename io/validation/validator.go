@@ -1,26 +1,36 @@
 package validation
 
 import (
+	"errors"
 	"fmt"
 
 	"ffvi_editor/io/pr"
 	"ffvi_editor/models"
 )
 
+// Finding is one place in the save that breaks a rule
+type Finding struct {
+	Target    string      // Save path of the offending value, e.g. characters/Terra/level
+	TargetID  interface{} // Character root name, inventory row or party slot
+	Message   string
+	FixAction string       // Description of what Fix does
+	Fix       func() error // Repairs the value; nil when it can't be fixed automatically
+}
+
 // Rule defines a single validation rule
 type Rule struct {
 	Name        string
 	Description string
-	Check       func(data *pr.PR) (bool, string) // Returns (isValid, errorMessage)
+	Check       func(data *pr.PR) []Finding // Returns every place the rule fails
 	Severity    models.ValidationSeverity
-	Fixable     bool
-	AutoFix     func(data *pr.PR) error
 }
 
 // Validator handles save file validation
 type Validator struct {
-	rules  []Rule
-	config models.ValidationConfig
+	rules    []Rule
+	ruleSets []*RuleSet
+	config   models.ValidationConfig
+	snapshot *pr.Snapshot // Shared by the user rules of one Validate
 }
 
 // NewValidator creates a new validator with default configuration
@@ -45,22 +55,31 @@ func (v *Validator) Validate(data *pr.PR) models.ValidationResult {
 		Infomsgs: make([]models.ValidationIssue, 0),
 	}
 
+	// Nothing is fixed while validating, so user rules can share a snapshot
+	if len(v.ruleSets) > 0 {
+		v.snapshot = pr.TakeSnapshot()
+		defer func() { v.snapshot = nil }()
+	}
+
 	// Run all rules
 	for _, rule := range v.rules {
-		isValid, message := rule.Check(data)
-		if !isValid {
+		for _, f := range rule.Check(data) {
 			issue := models.ValidationIssue{
 				Rule:      rule.Name,
 				Severity:  rule.Severity,
-				Message:   message,
-				Fixable:   rule.Fixable,
-				FixAction: fmt.Sprintf("Auto-fix: %s", rule.Name),
+				Message:   f.Message,
+				Target:    f.Target,
+				TargetID:  f.TargetID,
+				Fixable:   f.Fix != nil,
+				FixAction: f.FixAction,
 			}
 
 			switch rule.Severity {
 			case models.SeverityError:
 				result.Errors = append(result.Errors, issue)
-				result.Valid = false
+				if v.config.Mode != models.LenientMode {
+					result.Valid = false
+				}
 			case models.SeverityWarning:
 				result.Warnings = append(result.Warnings, issue)
 				if v.config.Mode == models.StrictMode {
@@ -85,81 +104,47 @@ func (v *Validator) GetConfig() models.ValidationConfig {
 	return v.config
 }
 
-// AutoFixIssues attempts to fix all fixable issues
+// AutoFixIssues attempts to fix all fixable issues. It returns the number of
+// issues fixed and the errors of the fixes that failed.
 func (v *Validator) AutoFixIssues(data *pr.PR) (int, error) {
 	fixed := 0
+	var errs []error
 
 	for _, rule := range v.rules {
-		isValid, _ := rule.Check(data)
-		if !isValid && rule.Fixable && rule.AutoFix != nil {
-			if err := rule.AutoFix(data); err == nil {
+		for _, f := range rule.Check(data) {
+			if f.Fix == nil {
+				continue
+			}
+			if err := f.Fix(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %w", rule.Name, f.Target, err))
+			} else {
 				fixed++
 			}
 		}
 	}
 
-	return fixed, nil
+	return fixed, errors.Join(errs...)
 }
 
-// registerDefaultRules registers all built-in validation rules
-func (v *Validator) registerDefaultRules() {
-	// Character level validation
-	v.registerRule(Rule{
-		Name:        "character_level_range",
-		Description: "Character level must be 1-99",
-		Check: func(data *pr.PR) (bool, string) {
-			// Simplified: just check structure exists
-			if data.Characters == nil || len(data.Characters) == 0 {
-				return true, ""
-			}
-			// Note: Full validation would need OrderedMap field extraction
-			return true, ""
-		},
-		Severity: models.SeverityError,
-		Fixable:  false,
-	})
-
-	// HP validation
-	v.registerRule(Rule{
-		Name:        "character_hp_range",
-		Description: "Character HP must be 1-9999",
-		Check: func(data *pr.PR) (bool, string) {
-			if data.Characters == nil || len(data.Characters) == 0 {
-				return true, ""
-			}
-			return true, ""
-		},
-		Severity: models.SeverityError,
-		Fixable:  false,
-	})
-
-	// MP validation
-	v.registerRule(Rule{
-		Name:        "character_mp_range",
-		Description: "Character MP must be 0-9999",
-		Check: func(data *pr.PR) (bool, string) {
-			if data.Characters == nil || len(data.Characters) == 0 {
-				return true, ""
+// FixIssue repairs the value a single issue points at
+func (v *Validator) FixIssue(data *pr.PR, issue models.ValidationIssue) error {
+	for _, rule := range v.rules {
+		if rule.Name != issue.Rule {
+			continue
+		}
+		for _, f := range rule.Check(data) {
+			if f.Target != issue.Target {
+				continue
 			}
-			return true, ""
-		},
-		Severity: models.SeverityError,
-		Fixable:  false,
-	})
-
-	// Map data validation
-	v.registerRule(Rule{
-		Name:        "map_data_exists",
-		Description: "Map data structure is valid",
-		Check: func(data *pr.PR) (bool, string) {
-			if data.MapData == nil {
-				return false, "Map data is missing"
+			if f.Fix == nil {
+				return fmt.Errorf("%s: %s can't be fixed automatically", rule.Name, f.Target)
 			}
-			return true, ""
-		},
-		Severity: models.SeverityWarning,
-		Fixable:  false,
-	})
+			return f.Fix()
+		}
+		// The value was fixed in the meantime
+		return nil
+	}
+	return fmt.Errorf("unknown validation rule %q", issue.Rule)
 }
 
 // registerRule adds a validation rule
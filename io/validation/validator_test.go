@@ -0,0 +1,161 @@
+package validation
+
+import (
+	"testing"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+	"ffvi_editor/models"
+	cpr "ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+const testSave = "../../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y="
+
+func loadSave(t *testing.T) *pr.PR {
+	t.Helper()
+	p := pr.New()
+	if err := p.Load(testSave, global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	return p
+}
+
+func findIssue(issues []models.ValidationIssue, rule, target string) *models.ValidationIssue {
+	for i := range issues {
+		if issues[i].Rule == rule && issues[i].Target == target {
+			return &issues[i]
+		}
+	}
+	return nil
+}
+
+func TestValidateUnmodifiedSave(t *testing.T) {
+	result := NewValidator().Validate(loadSave(t))
+	if !result.Valid || len(result.Errors) != 0 {
+		t.Fatalf("expected the fixture to be valid, got errors %+v", result.Errors)
+	}
+	// The game stores 9999 as current HP; only loaded characters are checked
+	if findIssue(result.Warnings, "character_hp_current", "characters/Terra/hp") == nil {
+		t.Errorf("expected a current HP warning for Terra, got %+v", result.Warnings)
+	}
+	if findIssue(result.Warnings, "character_hp_current", "characters/Celes/hp") != nil {
+		t.Error("Celes isn't in the save and shouldn't be checked")
+	}
+}
+
+func TestValidateAndFix(t *testing.T) {
+	save := loadSave(t)
+	terra := pri.GetCharacter("Terra")
+	terra.Level = 150
+	terra.Vigor = 300
+	terra.MP.Max = -5
+	party := pri.GetParty()
+	party.Members[1] = party.Members[0]
+	rows := pri.GetInventory().GetRows()
+	rows[0].Count = 150
+
+	v := NewValidator()
+	result := v.Validate(save)
+	if result.Valid {
+		t.Fatal("expected the edited save to be invalid")
+	}
+	for _, want := range []struct{ rule, target string }{
+		{"character_level_range", "characters/Terra/level"},
+		{"character_stat_range", "characters/Terra/vigor"},
+		{"character_mp_range", "characters/Terra/maxMp"},
+		{"party_duplicate_member", "party/1"},
+		{"inventory_count_range", "inventory/" + pr.ItemName(rows[0].ItemID)},
+	} {
+		issue := findIssue(result.Errors, want.rule, want.target)
+		if issue == nil {
+			t.Errorf("missing %s issue at %s in %+v", want.rule, want.target, result.Errors)
+		} else if !issue.Fixable || issue.FixAction == "" {
+			t.Errorf("%s should be fixable: %+v", want.rule, issue)
+		}
+	}
+
+	if err := v.FixIssue(save, *findIssue(result.Errors, "party_duplicate_member", "party/1")); err != nil {
+		t.Fatalf("FixIssue failed: %v", err)
+	}
+	if party.Members[1] != pri.EmptyPartyMember {
+		t.Errorf("duplicate party member not removed: %+v", party.Members[1])
+	}
+
+	fixed, err := v.AutoFixIssues(save)
+	if err != nil {
+		t.Fatalf("AutoFixIssues failed: %v", err)
+	}
+	if fixed == 0 {
+		t.Fatal("expected issues to be fixed")
+	}
+	if terra.Level != 99 || terra.Vigor != 255 || terra.MP.Max != 0 || rows[0].Count != 99 {
+		t.Errorf("values not clamped: level %d, vigor %d, max MP %d, count %d", terra.Level, terra.Vigor, terra.MP.Max, rows[0].Count)
+	}
+	if terra.HP.Current > terra.HP.Max {
+		t.Errorf("current HP %d still above max %d", terra.HP.Current, terra.HP.Max)
+	}
+	if result = v.Validate(save); len(result.AllIssues()) != 0 {
+		t.Errorf("expected no issues after fixing, got %+v", result.AllIssues())
+	}
+}
+
+func TestEmptyPartyIsNotFixable(t *testing.T) {
+	save := loadSave(t)
+	party := pri.GetParty()
+	for i := range party.Members {
+		party.Members[i] = pri.EmptyPartyMember
+	}
+
+	v := NewValidator()
+	result := v.Validate(save)
+	issue := findIssue(result.Errors, "party_not_empty", "party/0")
+	if issue == nil || issue.Fixable {
+		t.Fatalf("expected an unfixable empty party error, got %+v", result.Errors)
+	}
+	if err := v.FixIssue(save, *issue); err == nil {
+		t.Error("expected FixIssue to fail for an unfixable issue")
+	}
+}
+
+func TestEquipmentLegality(t *testing.T) {
+	save := loadSave(t)
+	terra := pri.GetCharacter("Terra")
+	terra.Equipment.WeaponID = cpr.ItemsByName["Buckler"]
+	terra.Equipment.ShieldID = cpr.ItemsByName["Dagger"]
+	terra.Equipment.Relic1ID = cpr.ItemsByName["Sprint Shoes"]
+	terra.Equipment.Relic2ID = cpr.ItemsByName["Sprint Shoes"]
+
+	v := NewValidator()
+	result := v.Validate(save)
+	for _, want := range []struct {
+		issues       []models.ValidationIssue
+		rule, target string
+	}{
+		{result.Errors, "equipment_slot", "characters/Terra/equipment/weapon"},
+		{result.Errors, "equipment_slot", "characters/Terra/equipment/shield"},
+		{result.Warnings, "equipment_restriction", "characters/Terra/equipment/relic2"},
+	} {
+		if findIssue(want.issues, want.rule, want.target) == nil {
+			t.Errorf("missing %s issue at %s in %+v", want.rule, want.target, result.AllIssues())
+		}
+	}
+
+	before := pri.GetInventory().GetItemLookup()[cpr.ItemsByName["Buckler"]]
+	if err := v.FixIssue(save, *findIssue(result.Errors, "equipment_slot", "characters/Terra/equipment/weapon")); err != nil {
+		t.Fatalf("FixIssue failed: %v", err)
+	}
+	if terra.Equipment.WeaponID != 93 {
+		t.Errorf("Buckler not removed from the weapon slot: %d", terra.Equipment.WeaponID)
+	}
+	if after := pri.GetInventory().GetItemLookup()[cpr.ItemsByName["Buckler"]]; after != before+1 {
+		t.Errorf("Buckler count went from %d to %d, want one more", before, after)
+	}
+
+	// With the Genji Glove the dagger can stay in the shield hand
+	terra.Equipment.Relic2ID = cpr.ItemsByName["Genji Glove"]
+	result = v.Validate(save)
+	if findIssue(result.Errors, "equipment_slot", "characters/Terra/equipment/shield") != nil {
+		t.Errorf("dual wielding with the Genji Glove should be allowed: %+v", result.Errors)
+	}
+}
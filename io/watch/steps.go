@@ -0,0 +1,151 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ffvi_editor/io/backup"
+	"ffvi_editor/io/history"
+	"ffvi_editor/io/pr"
+	"ffvi_editor/io/validation"
+	"ffvi_editor/scripting"
+)
+
+// BackupStep stores a copy of every write with the backup manager
+type BackupStep struct {
+	Manager *backup.Manager
+}
+
+func (s *BackupStep) Name() string { return "backup" }
+
+func (s *BackupStep) Run(e *Event) (string, error) {
+	meta, err := s.Manager.CreateBackup(e.Path, e.Data, "Automatic backup by watch")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("saved %s", meta.ID), nil
+}
+
+// HistoryStep adds every write to the slot's progression timeline
+type HistoryStep struct {
+	Store *history.Store
+}
+
+func (s *HistoryStep) Name() string { return "history" }
+
+func (s *HistoryStep) Run(e *Event) (string, error) {
+	entry, err := s.Store.Record(history.Capture{
+		Path:        e.Path,
+		Data:        e.Data,
+		SaveType:    e.SaveType,
+		Source:      history.SourceWatch,
+		Description: "Recorded by watch",
+		Time:        e.Time,
+	})
+	if err != nil {
+		return "", err
+	}
+	if entry == nil {
+		return "unchanged since the last point", nil
+	}
+	return fmt.Sprintf("recorded %s", entry.ID), nil
+}
+
+// ValidateStep runs the validator over the written save
+type ValidateStep struct {
+	Validator *validation.Validator
+}
+
+func (s *ValidateStep) Name() string { return "validate" }
+
+func (s *ValidateStep) Run(e *Event) (string, error) {
+	save, err := e.Load()
+	if err != nil {
+		return "", err
+	}
+	result := s.Validator.Validate(save)
+	summary := fmt.Sprintf("%d error(s), %d warning(s)", len(result.Errors), len(result.Warnings))
+	if !result.Valid {
+		messages := make([]string, 0, len(result.Errors)+len(result.Warnings))
+		for _, issue := range append(result.Errors, result.Warnings...) {
+			messages = append(messages, issue.Message)
+		}
+		return summary, fmt.Errorf("validation failed: %s", strings.Join(messages, "; "))
+	}
+	return summary, nil
+}
+
+// DiffStep compares the write against the slot's previous contents
+type DiffStep struct{}
+
+func (s *DiffStep) Name() string { return "diff" }
+
+func (s *DiffStep) Run(e *Event) (string, error) {
+	if e.Previous == nil {
+		return "first write seen, nothing to compare", nil
+	}
+
+	// Compare the contents the event saw; the slot may have been written again
+	previous, err := pr.SnapshotData(e.Previous, e.SaveType)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the previous contents: %w", err)
+	}
+	current, err := pr.SnapshotData(e.Data, e.SaveType)
+	if err != nil {
+		return "", err
+	}
+	report := pr.NewComparator(previous, current).Compare()
+	if !report.HasDifferences() {
+		return "no differences", nil
+	}
+
+	lines := []string{report.Statistics.String()}
+	for _, d := range report.GetSortedDiffs() {
+		lines = append(lines, fmt.Sprintf("  %s %s %s: %v -> %v", d.Category, d.Name, d.Field, d.OldValue, d.NewValue))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// LuaRuleStep runs a Lua script with the save bindings after every write.
+// The script may return a table; {ok = false, message = "..."} reports a
+// rule violation.
+type LuaRuleStep struct {
+	Script string
+	code   string
+}
+
+// NewLuaRuleStep reads a rule script from disk
+func NewLuaRuleStep(script string) (*LuaRuleStep, error) {
+	code, err := os.ReadFile(script)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Lua rule: %w", err)
+	}
+	return &LuaRuleStep{Script: script, code: string(code)}, nil
+}
+
+func (s *LuaRuleStep) Name() string { return "lua:" + filepath.Base(s.Script) }
+
+func (s *LuaRuleStep) Run(e *Event) (string, error) {
+	save, err := e.Load()
+	if err != nil {
+		return "", err
+	}
+	result, err := scripting.RunSnippetWithSave(context.Background(), s.code, save)
+	if err != nil {
+		return "", err
+	}
+	message, _ := result["message"].(string)
+	if ok, found := result["ok"].(bool); found && !ok {
+		if message == "" {
+			message = "rule violated"
+		}
+		return "", fmt.Errorf("%s", message)
+	}
+	if message == "" {
+		message = "ok"
+	}
+	return message, nil
+}
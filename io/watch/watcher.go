@@ -0,0 +1,279 @@
+package watch
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/file"
+	"ffvi_editor/io/pr"
+)
+
+// DefaultDebounce is how long a slot must be quiet before the pipeline runs.
+// The game writes a save in several chunks, so reacting to the first event
+// would read a partial file.
+const DefaultDebounce = 500 * time.Millisecond
+
+// Event describes a completed write of a save slot
+type Event struct {
+	Path     string
+	Time     time.Time
+	Data     []byte // Contents after the write
+	Previous []byte // Contents before the write, nil the first time the slot is seen
+	SaveType global.SaveFileType
+}
+
+// Name returns the slot's file name
+func (e *Event) Name() string {
+	return filepath.Base(e.Path)
+}
+
+// Load decodes the written save, the same bytes every step sees, into the
+// editor's models
+func (e *Event) Load() (*pr.PR, error) {
+	out, trimmed, err := file.DecodeFile(e.Data, e.SaveType)
+	if err == nil {
+		save := pr.New()
+		if err = save.LoadJSON(out, trimmed); err == nil {
+			return save, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to load %s: %w", e.Name(), err)
+}
+
+// Step is one stage of the pipeline run after every write
+type Step interface {
+	Name() string
+	// Run processes the event and returns a short summary of what it did
+	Run(e *Event) (string, error)
+}
+
+// Result is the outcome of a single step for a single event
+type Result struct {
+	Event   *Event
+	Step    string
+	Message string
+	Err     error
+}
+
+// Watcher runs a pipeline of steps whenever the game writes a save slot
+type Watcher struct {
+	dir      string
+	saveType global.SaveFileType
+	steps    []Step
+	debounce time.Duration
+	filter   func(path string) bool
+	report   func(Result)
+
+	fsWatcher *fsnotify.Watcher
+	previous  map[string][]byte
+	timers    map[string]*time.Timer
+	queue     chan string
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	isRunning bool
+}
+
+// NewWatcher creates a watcher for the save directory. report receives the
+// result of every step and may be nil.
+func NewWatcher(dir string, saveType global.SaveFileType, steps []Step, report func(Result)) (*Watcher, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open save directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	if report == nil {
+		report = func(Result) {}
+	}
+
+	return &Watcher{
+		dir:      dir,
+		saveType: saveType,
+		steps:    steps,
+		debounce: DefaultDebounce,
+		filter:   IsSlotFile,
+		report:   report,
+		previous: make(map[string][]byte),
+		timers:   make(map[string]*time.Timer),
+		queue:    make(chan string, 16),
+		stopCh:   make(chan struct{}),
+	}, nil
+}
+
+// SetDebounce changes how long a slot must be quiet before the pipeline runs
+func (w *Watcher) SetDebounce(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.debounce = d
+}
+
+// SetFilter replaces the function that decides which files are save slots
+func (w *Watcher) SetFilter(filter func(path string) bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.filter = filter
+}
+
+// IsSlotFile reports whether a file looks like a save slot. Slots are named
+// with base64 text and have no extension; backups, temp files and Steam
+// metadata are ignored.
+func IsSlotFile(path string) bool {
+	name := filepath.Base(path)
+	return !strings.HasPrefix(name, ".") && filepath.Ext(name) == ""
+}
+
+// Start records the current contents of every slot and begins watching
+func (w *Watcher) Start() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.isRunning {
+		return fmt.Errorf("watcher already running")
+	}
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read save directory: %w", err)
+	}
+	for _, entry := range entries {
+		path := filepath.Join(w.dir, entry.Name())
+		if entry.Type().IsRegular() && w.filter(path) {
+			if data, err := os.ReadFile(path); err == nil {
+				w.previous[path] = data
+			}
+		}
+	}
+
+	if w.fsWatcher, err = fsnotify.NewWatcher(); err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	if err = w.fsWatcher.Add(w.dir); err != nil {
+		_ = w.fsWatcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", w.dir, err)
+	}
+
+	w.isRunning = true
+	w.wg.Add(2)
+	go w.watchLoop()
+	go w.processLoop()
+	return nil
+}
+
+// Stop stops watching and waits for a running pipeline to finish
+func (w *Watcher) Stop() error {
+	w.mu.Lock()
+	if !w.isRunning {
+		w.mu.Unlock()
+		return fmt.Errorf("watcher not running")
+	}
+	w.isRunning = false
+	for _, t := range w.timers {
+		t.Stop()
+	}
+	close(w.stopCh)
+	err := w.fsWatcher.Close()
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	return err
+}
+
+// watchLoop turns file system events into debounced slot writes
+func (w *Watcher) watchLoop() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			w.schedule(event.Name)
+		case err, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			w.report(Result{Step: "watch", Err: err})
+		}
+	}
+}
+
+// schedule (re)starts the quiet period timer for a slot
+func (w *Watcher) schedule(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.isRunning || !w.filter(path) {
+		return
+	}
+	if t, ok := w.timers[path]; ok {
+		t.Stop()
+	}
+	w.timers[path] = time.AfterFunc(w.debounce, func() {
+		select {
+		case w.queue <- path:
+		case <-w.stopCh:
+		}
+	})
+}
+
+// processLoop runs the pipeline for one slot at a time, since loading a save
+// replaces the editor's global models
+func (w *Watcher) processLoop() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case path := <-w.queue:
+			w.process(path)
+		}
+	}
+}
+
+func (w *Watcher) process(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// Renamed away or deleted after the write
+		return
+	}
+
+	w.mu.Lock()
+	previous, seen := w.previous[path]
+	delete(w.timers, path)
+	w.mu.Unlock()
+
+	if seen && bytes.Equal(previous, data) {
+		return
+	}
+
+	e := &Event{
+		Path:     path,
+		Time:     time.Now(),
+		Data:     data,
+		Previous: previous,
+		SaveType: w.saveType,
+	}
+	for _, step := range w.steps {
+		msg, err := step.Run(e)
+		w.report(Result{Event: e, Step: step.Name(), Message: msg, Err: err})
+	}
+
+	w.mu.Lock()
+	w.previous[path] = data
+	w.mu.Unlock()
+}
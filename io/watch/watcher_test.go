@@ -0,0 +1,136 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ffvi_editor/global"
+)
+
+// recordStep sends every event it sees on a channel
+type recordStep struct {
+	events chan *Event
+}
+
+func (s *recordStep) Name() string { return "record" }
+
+func (s *recordStep) Run(e *Event) (string, error) {
+	s.events <- e
+	return "", nil
+}
+
+func waitEvent(t *testing.T, events chan *Event) *Event {
+	t.Helper()
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for pipeline")
+		return nil
+	}
+}
+
+func TestWatcherRunsPipelineOnWrite(t *testing.T) {
+	dir := t.TempDir()
+	slot := filepath.Join(dir, "slotA")
+	if err := os.WriteFile(slot, []byte("v1"), 0644); err != nil {
+		t.Fatalf("failed to write slot: %v", err)
+	}
+
+	step := &recordStep{events: make(chan *Event, 10)}
+	w, err := NewWatcher(dir, global.PC, []Step{step}, nil)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	w.SetDebounce(100 * time.Millisecond)
+	if err = w.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	// Two quick writes are one save
+	if err = os.WriteFile(slot, []byte("v2-part"), 0644); err != nil {
+		t.Fatalf("failed to write slot: %v", err)
+	}
+	if err = os.WriteFile(slot, []byte("v2"), 0644); err != nil {
+		t.Fatalf("failed to write slot: %v", err)
+	}
+
+	e := waitEvent(t, step.events)
+	if string(e.Data) != "v2" || string(e.Previous) != "v1" {
+		t.Fatalf("event data=%q previous=%q, want v2 and v1", e.Data, e.Previous)
+	}
+
+	// Rewriting the same contents and writing non-slot files are ignored
+	if err = os.WriteFile(slot, []byte("v2"), 0644); err != nil {
+		t.Fatalf("failed to write slot: %v", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "steam_autocloud.vdf"), []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	select {
+	case e = <-step.events:
+		t.Fatalf("unexpected event for %s", e.Path)
+	case <-time.After(400 * time.Millisecond):
+	}
+}
+
+func TestIsSlotFile(t *testing.T) {
+	tests := map[string]bool{
+		"7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=": true,
+		"steam_autocloud.vdf":                          false,
+		".hidden":                                      false,
+		"slot.bak":                                     false,
+	}
+	for name, want := range tests {
+		if got := IsSlotFile(filepath.Join("dir", name)); got != want {
+			t.Errorf("IsSlotFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestEventLoadUsesData(t *testing.T) {
+	data, err := os.ReadFile("../../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	if err != nil {
+		t.Skipf("test save not available: %v", err)
+	}
+
+	// The slot has changed again since the event; Load must not read it
+	slot := filepath.Join(t.TempDir(), "slotA")
+	if err = os.WriteFile(slot, []byte("partial"), 0644); err != nil {
+		t.Fatalf("failed to write slot: %v", err)
+	}
+	e := &Event{Path: slot, Data: data, SaveType: global.PC}
+	if _, err = e.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+}
+
+func TestDiffStepUsesData(t *testing.T) {
+	dir := "../../save_data/76561198072182150/"
+	previous, err := os.ReadFile(dir + "7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=")
+	if err != nil {
+		t.Skipf("test save not available: %v", err)
+	}
+	data, err := os.ReadFile(dir + "vgU2wnuaPje2Or53Iqs8Mp=Al6sdM+GM04Iymv229Ow=")
+	if err != nil {
+		t.Skipf("test save not available: %v", err)
+	}
+
+	// The slot has changed again since the event; the diff must not read it
+	slot := filepath.Join(t.TempDir(), "slotA")
+	if err = os.WriteFile(slot, []byte("partial"), 0644); err != nil {
+		t.Fatalf("failed to write slot: %v", err)
+	}
+	e := &Event{Path: slot, Data: data, Previous: previous, SaveType: global.PC}
+	summary, err := (&DiffStep{}).Run(e)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(summary, "Misc Saves: 17 -> 19") {
+		t.Errorf("unexpected diff summary:\n%s", summary)
+	}
+}
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"os"
+
+	"ffvi_editor/cli"
 	"ffvi_editor/ui"
 	"ffvi_editor/ui/forms/editors"
 )
 
 func main() {
+	// With arguments the editor runs a command line command, such as diff
+	if len(os.Args) > 1 {
+		os.Exit(cli.Main(os.Args[1:]))
+	}
 	defer func() {
 		_ = recover()
 	}()
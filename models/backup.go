@@ -1,6 +1,7 @@
 package models
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"io"
@@ -59,15 +60,20 @@ func generateID() string {
 
 // generateRandomSuffix creates a random suffix for uniqueness
 func generateRandomSuffix() string {
-	// Simple implementation - in production use crypto/rand
-	return time.Now().Format("9999")
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("050000000")
+	}
+	return hex.EncodeToString(b)
 }
 
 // BackupListEntry represents a backup in the backup list UI
 type BackupListEntry struct {
-	ID          string
-	Timestamp   time.Time
-	Description string
-	FileSize    int64
-	TimeSince   string
+	ID           string
+	Timestamp    time.Time
+	Description  string
+	FileSize     int64
+	Hash         string
+	OriginalPath string
+	TimeSince    string
 }
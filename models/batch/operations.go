@@ -2,7 +2,9 @@ package batch
 
 import (
 	"fmt"
+	"strings"
 
+	ipr "ffvi_editor/io/pr"
 	"ffvi_editor/models"
 	"ffvi_editor/models/pr"
 )
@@ -93,22 +95,27 @@ var Registry = []*Operation{
 		Description: "Set all characters to level 99",
 		Category:    CategoryCharacter,
 		Apply: func(ctx *BatchContext) error {
-			// Note: This is simplified; actual implementation would adjust EXP
 			for _, char := range ctx.Characters {
 				if char == nil {
 					continue
 				}
-				char.Level = 99
-				// Would set appropriate EXP for level 99
+				char.Level = pr.MaxLevel
+				char.Exp = pr.ExpForLevel(pr.MaxLevel)
 				ctx.Changes["level_99"] = fmt.Sprintf("Set %s to Level 99", char.Name)
 			}
 			return nil
 		},
 		Preview: func(ctx *BatchContext) string {
 			count := len(ctx.Characters)
-			return fmt.Sprintf("Will set %d character(s) to Level 99", count)
+			return fmt.Sprintf("Will set %d character(s) to Level 99 with %d EXP", count, pr.ExpForLevel(pr.MaxLevel))
 		},
 	},
+	levelFixOperation("sync_exp_to_level", "Sync EXP to Level",
+		"Set each character's EXP to the minimum for its level", pr.ExpFromLevel),
+	levelFixOperation("sync_level_to_exp", "Sync Level to EXP",
+		"Set each character's level to the one its EXP reaches", pr.LevelFromExp),
+	levelFixOperation("simulate_level_up", "Simulate Level-Ups",
+		"Level characters up to their EXP, adding the HP and MP each level gives", pr.SimulateLevelUp),
 	{
 		ID:          "learn_all_magic",
 		Name:        "Learn All Magic",
@@ -183,6 +190,40 @@ var Registry = []*Operation{
 	},
 }
 
+// levelFixOperation makes the level and EXP of every character agree
+func levelFixOperation(id, name, description string, mode pr.LevelFixMode) *Operation {
+	return &Operation{
+		ID:          id,
+		Name:        name,
+		Description: description,
+		Category:    CategoryCharacter,
+		Apply: func(ctx *BatchContext) error {
+			for _, char := range ctx.Characters {
+				if char == nil || pr.LevelMatchesExp(char) {
+					continue
+				}
+				change := pr.FixLevelExp(char, mode)
+				ctx.Changes[id+"_"+char.RootName] = change.String()
+			}
+			return nil
+		},
+		Preview: func(ctx *BatchContext) string {
+			lines := make([]string, 0, len(ctx.Characters))
+			for _, char := range ctx.Characters {
+				if char == nil || pr.LevelMatchesExp(char) {
+					continue
+				}
+				c := *char
+				lines = append(lines, pr.FixLevelExp(&c, mode).String())
+			}
+			if len(lines) == 0 {
+				return "Every character's level already matches its EXP"
+			}
+			return fmt.Sprintf("Will update %d character(s):\n%s", len(lines), strings.Join(lines, "\n"))
+		},
+	}
+}
+
 // GetOperationByID returns an operation by ID
 func GetOperationByID(id string) *Operation {
 	for _, op := range Registry {
@@ -215,7 +256,8 @@ func GetAllCategories() []Category {
 	return categories
 }
 
-// ExecuteOperation applies an operation to the PR data
+// ExecuteOperation applies an operation to the PR data. Its changes are
+// tracked as one undo step named after the operation.
 func ExecuteOperation(op *Operation, characters []*models.Character, inventory *pr.Inventory) error {
 	if op == nil {
 		return fmt.Errorf("operation cannot be nil")
@@ -227,7 +269,19 @@ func ExecuteOperation(op *Operation, characters []*models.Character, inventory *
 		Changes:    make(map[string]string),
 	}
 
-	return op.Apply(ctx)
+	return ipr.Changes.Edit(op.Name, func() error { return op.Apply(ctx) })
+}
+
+// ExecuteOperations applies operations in order as a single undo step
+func ExecuteOperations(name string, ops []*Operation, characters []*models.Character, inventory *pr.Inventory) error {
+	return ipr.Changes.Edit(name, func() error {
+		for _, op := range ops {
+			if err := ExecuteOperation(op, characters, inventory); err != nil {
+				return fmt.Errorf("%s: %w", op.Name, err)
+			}
+		}
+		return nil
+	})
 }
 
 // PreviewOperation returns what an operation would do
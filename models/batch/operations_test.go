@@ -0,0 +1,37 @@
+package batch
+
+import (
+	"testing"
+
+	"ffvi_editor/global"
+	ipr "ffvi_editor/io/pr"
+	"ffvi_editor/models"
+	"ffvi_editor/models/pr"
+)
+
+const testSave = "../../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y="
+
+func TestExecuteOperationsIsOneUndoStep(t *testing.T) {
+	if err := ipr.New().Load(testSave, global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	ipr.Changes.Sync()
+	before := ipr.TakeSnapshot()
+	var groups []models.ChangeGroup
+	defer ipr.Changes.Subscribe(func(g models.ChangeGroup) { groups = append(groups, g) })()
+
+	ops := []*Operation{GetOperationByID("max_all_stats"), GetOperationByID("set_level_99")}
+	if err := ExecuteOperations("Power up", ops, pr.Characters, pr.GetInventory()); err != nil {
+		t.Fatalf("ExecuteOperations failed: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Name != "Power up" || len(groups[0].Changes) == 0 {
+		t.Fatalf("expected one change group, got %+v", groups)
+	}
+
+	if err := ipr.Changes.Undo(groups[0].Changes); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if report := ipr.NewComparator(before, ipr.TakeSnapshot()).Compare(); report.HasDifferences() {
+		t.Errorf("undo left %d differences: %+v", report.Statistics.TotalDiffs, report.Diffs[0])
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -15,6 +16,11 @@ type Change struct {
 	Batch     bool          `json:"batch"`         // Part of batch operation?
 	BatchID   string        `json:"batchID"`       // Groups multiple changes
 	BatchName string        `json:"batchName"`     // User-friendly batch name
+
+	// Apply makes the change and Revert takes it back. Changes emitted by the
+	// model layer have both; hand-made records may have neither.
+	Apply  func() error `json:"-"`
+	Revert func() error `json:"-"`
 }
 
 // ChangeGroup represents multiple changes that should be undone together
@@ -25,6 +31,42 @@ type ChangeGroup struct {
 	Time    time.Time
 }
 
+// Undo takes the change back
+func (c Change) Undo() error {
+	if c.Revert == nil {
+		return fmt.Errorf("%s: change can't be undone", c.FieldName)
+	}
+	return c.Revert()
+}
+
+// Redo makes the change again
+func (c Change) Redo() error {
+	if c.Apply == nil {
+		return fmt.Errorf("%s: change can't be redone", c.FieldName)
+	}
+	return c.Apply()
+}
+
+// Undo takes the changes of the group back, last first
+func (g ChangeGroup) Undo() error {
+	for i := len(g.Changes) - 1; i >= 0; i-- {
+		if err := g.Changes[i].Undo(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Redo makes the changes of the group again, in order
+func (g ChangeGroup) Redo() error {
+	for _, c := range g.Changes {
+		if err := c.Redo(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // NewChange creates a new change record
 func NewChange(target, fieldName string, oldValue, newValue interface{}) Change {
 	return Change{
@@ -53,11 +95,16 @@ func generateChangeID() string {
 	return time.Now().Format("20060102150405000") // Nanosecond precision
 }
 
-// ValidateChange checks if a change is valid
+// ValidateChange checks if a change is valid. Changes that apply and revert
+// themselves only show their values, which may be nil, such as the old value
+// of a field set for the first time.
 func ValidateChange(c Change) bool {
 	if c.Target == "" || c.FieldName == "" {
 		return false
 	}
+	if c.Apply != nil && c.Revert != nil {
+		return true
+	}
 	if c.OldValue == nil || c.NewValue == nil {
 		return false
 	}
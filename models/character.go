@@ -18,7 +18,7 @@ type Character struct {
 	Magic     int
 	IsEnabled bool
 	IsNPC     bool
-	//Esper   *Esper
+	EsperID   int // Equipped esper (magicStoneId), 0 when none
 
 	SpellsByIndex []*Spell
 	SpellsSorted  []*Spell
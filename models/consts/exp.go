@@ -59,7 +59,7 @@ const LevelText = `Level - Experience
 20 - 22832
 21 - 26360
 22 - 30232
-23 - 24456
+23 - 34456
 24 - 39056
 25 - 44072
 26 - 49464
@@ -67,7 +67,7 @@ const LevelText = `Level - Experience
 28 - 61568
 29 - 68304
 30 - 75496
-31 - 93184
+31 - 83184
 32 - 91384
 33 - 100083
 34 - 108344
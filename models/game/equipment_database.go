@@ -0,0 +1,413 @@
+package game
+
+import "sort"
+
+// EquipType is the kind of slot an item fits in
+type EquipType string
+
+const (
+	TypeWeapon EquipType = "Weapon"
+	TypeShield EquipType = "Shield"
+	TypeHelmet EquipType = "Helmet"
+	TypeArmor  EquipType = "Armor"
+	TypeRelic  EquipType = "Relic"
+)
+
+// Special properties of equipment
+const (
+	PropDualWield   = "Dual wield"       // Lets a weapon be held in the shield hand
+	PropTwoHanded   = "Two-handed"       // Doubles damage with the shield hand empty
+	PropQuadAttack  = "Quadruple attack" // Attacks four times at reduced power
+	PropRanged      = "Ignores row"      // Full damage from the back row
+	PropRunic       = "Runic"            // Can absorb spells with Runic
+	PropMPCritical  = "MP critical"      // Spends MP for critical hits
+	PropBreaks      = "Breaks"           // Casts a spell when used as an item
+	PropStacks      = "Stacks"           // A second copy adds to the first
+	PropCursed      = "Cursed"           // Inflicts statuses on the wearer
+	PropInstantKill = "Instant death"    // May kill outright
+)
+
+// Characters who can change equipment
+const (
+	Terra  = "Terra"
+	Locke  = "Locke"
+	Cyan   = "Cyan"
+	Shadow = "Shadow"
+	Edgar  = "Edgar"
+	Sabin  = "Sabin"
+	Celes  = "Celes"
+	Strago = "Strago"
+	Relm   = "Relm"
+	Setzer = "Setzer"
+	Mog    = "Mog"
+	Gau    = "Gau"
+	Gogo   = "Gogo"
+	Umaro  = "Umaro"
+)
+
+// EquipmentCharacters lists the characters whose gear is checked; guests
+// keep whatever the story gives them
+var EquipmentCharacters = []string{
+	Terra, Locke, Cyan, Shadow, Edgar, Sabin, Celes, Strago, Relm, Setzer, Mog, Gau, Gogo, Umaro,
+}
+
+// EquipmentInfo describes a single piece of equipment
+type EquipmentInfo struct {
+	ID           int
+	Name         string
+	Type         EquipType
+	Category     string   // "Dirk", "Sword", "Heavy Armor", ...
+	EquippableBy []string // Character names
+	Attack       int
+	Defense      int
+	MagicDefense int
+	Vigor        int
+	Speed        int
+	Stamina      int
+	Magic        int
+	Properties   []string
+}
+
+// Copy returns a copy of the item that shares no slices with it
+func (e *EquipmentInfo) Copy() *EquipmentInfo {
+	c := *e
+	c.EquippableBy = append([]string(nil), e.EquippableBy...)
+	c.Properties = append([]string(nil), e.Properties...)
+	return &c
+}
+
+// CanEquip reports whether a character can wear the item
+func (e *EquipmentInfo) CanEquip(character string) bool {
+	for _, c := range e.EquippableBy {
+		if c == character {
+			return true
+		}
+	}
+	return false
+}
+
+// HasProperty reports whether the item has a special property
+func (e *EquipmentInfo) HasProperty(property string) bool {
+	for _, p := range e.Properties {
+		if p == property {
+			return true
+		}
+	}
+	return false
+}
+
+// emptyEquipmentIDs mark an empty slot. The game writes any of them in any
+// slot.
+var emptyEquipmentIDs = map[int]bool{93: true, 198: true, 199: true, 200: true}
+
+// IsEmptyEquipment reports whether an ID marks an empty slot
+func IsEmptyEquipment(id int) bool {
+	return id <= 0 || emptyEquipmentIDs[id]
+}
+
+// Who can equip each category. Items only list users when they differ.
+var (
+	allExceptUmaro = []string{Terra, Locke, Cyan, Shadow, Edgar, Sabin, Celes, Strago, Relm, Setzer, Mog, Gau, Gogo}
+	everyone       = append(append([]string{}, allExceptUmaro...), Umaro)
+	heavyUsers     = []string{Terra, Locke, Cyan, Shadow, Edgar, Sabin, Celes, Setzer, Gogo}
+	ladies         = []string{Terra, Celes, Relm}
+
+	categoryUsers = map[string][]string{
+		"Dirk":         {Terra, Locke, Shadow, Edgar, Celes, Setzer, Gogo},
+		"Sword":        {Terra, Locke, Edgar, Celes},
+		"Spear":        {Edgar, Mog},
+		"Ninja Blade":  {Shadow},
+		"Katana":       {Cyan},
+		"Rod":          {Terra, Celes, Strago, Relm, Gogo},
+		"Brush":        {Relm},
+		"Throwing":     {Shadow},
+		"Special":      {Locke, Shadow, Edgar, Setzer, Gogo},
+		"Club":         {Umaro},
+		"Gambling":     {Setzer},
+		"Claw":         {Sabin},
+		"Light Shield": allExceptUmaro,
+		"Heavy Shield": heavyUsers,
+		"Light Helmet": allExceptUmaro,
+		"Heavy Helmet": heavyUsers,
+		"Light Armor":  allExceptUmaro,
+		"Heavy Armor":  heavyUsers,
+		"Robe":         {Terra, Celes, Strago, Relm, Setzer, Mog, Gogo},
+		"Dress":        ladies,
+		"Costume":      allExceptUmaro,
+		"Scarf":        {Umaro},
+		"Relic":        everyone,
+	}
+)
+
+// equipmentList is the source of EquipmentDatabase
+var equipmentList = []*EquipmentInfo{
+	// Weapons
+	{ID: 94, Name: "Dagger", Type: TypeWeapon, Category: "Dirk", Attack: 26},
+	{ID: 95, Name: "Mythril Knife", Type: TypeWeapon, Category: "Dirk", Attack: 30},
+	{ID: 96, Name: "Main Gauche", Type: TypeWeapon, Category: "Dirk", Attack: 38},
+	{ID: 97, Name: "Air Knife", Type: TypeWeapon, Category: "Dirk", Attack: 57},
+	{ID: 98, Name: "Thief's Knife", Type: TypeWeapon, Category: "Dirk", Attack: 88, EquippableBy: []string{Locke, Shadow, Gogo}},
+	{ID: 99, Name: "Assassin's Dagger", Type: TypeWeapon, Category: "Dirk", Attack: 106, Properties: []string{PropInstantKill}},
+	{ID: 100, Name: "Man-Eater", Type: TypeWeapon, Category: "Dirk", Attack: 146},
+	{ID: 101, Name: "Swordbreaker", Type: TypeWeapon, Category: "Dirk", Attack: 164},
+	{ID: 102, Name: "Gladius", Type: TypeWeapon, Category: "Dirk", Attack: 204},
+	{ID: 103, Name: "Valiant Knife", Type: TypeWeapon, Category: "Dirk", Attack: 145, EquippableBy: []string{Locke}},
+	{ID: 104, Name: "Mythril Sword", Type: TypeWeapon, Category: "Sword", Attack: 38},
+	{ID: 105, Name: "Great Sword", Type: TypeWeapon, Category: "Sword", Attack: 45},
+	{ID: 106, Name: "Rune Blade", Type: TypeWeapon, Category: "Sword", Attack: 55, Properties: []string{PropRunic, PropMPCritical}},
+	{ID: 107, Name: "Flametongue", Type: TypeWeapon, Category: "Sword", Attack: 108, Properties: []string{PropRunic, PropBreaks}},
+	{ID: 108, Name: "Icebrand", Type: TypeWeapon, Category: "Sword", Attack: 108, Properties: []string{PropRunic, PropBreaks}},
+	{ID: 109, Name: "Thunder Blade", Type: TypeWeapon, Category: "Sword", Attack: 108, Properties: []string{PropRunic, PropBreaks}},
+	{ID: 110, Name: "Bastard Sword", Type: TypeWeapon, Category: "Sword", Attack: 115},
+	{ID: 111, Name: "Break Blade", Type: TypeWeapon, Category: "Sword", Attack: 117, Properties: []string{PropRunic, PropBreaks}},
+	{ID: 112, Name: "Blood Sword", Type: TypeWeapon, Category: "Sword", Attack: 122, Properties: []string{PropRunic}},
+	{ID: 113, Name: "Enhancer", Type: TypeWeapon, Category: "Sword", Attack: 135, Magic: 7, Properties: []string{PropRunic, PropMPCritical}},
+	{ID: 114, Name: "Crystal Sword", Type: TypeWeapon, Category: "Sword", Attack: 167, Properties: []string{PropRunic}},
+	{ID: 115, Name: "Falchion", Type: TypeWeapon, Category: "Sword", Attack: 176, Properties: []string{PropRunic}},
+	{ID: 116, Name: "Soul Sabre", Type: TypeWeapon, Category: "Sword", Attack: 125, Properties: []string{PropRunic}},
+	{ID: 117, Name: "Organyx", Type: TypeWeapon, Category: "Sword", Attack: 152, Properties: []string{PropRunic, PropMPCritical}},
+	{ID: 118, Name: "Excalibur", Type: TypeWeapon, Category: "Sword", Attack: 217, Properties: []string{PropRunic}},
+	{ID: 119, Name: "Zantetsuken", Type: TypeWeapon, Category: "Sword", Attack: 160, Properties: []string{PropInstantKill}},
+	{ID: 120, Name: "Lightbringer", Type: TypeWeapon, Category: "Sword", Attack: 199, Properties: []string{PropRunic, PropMPCritical}},
+	{ID: 121, Name: "Ragnarok", Type: TypeWeapon, Category: "Sword", Attack: 255, Vigor: 7, Speed: 3, Stamina: 7, Magic: 7, Properties: []string{PropRunic}},
+	{ID: 122, Name: "Ultima Weapon", Type: TypeWeapon, Category: "Sword", Attack: 255, Properties: []string{PropRunic}},
+	{ID: 123, Name: "Mythril Spear", Type: TypeWeapon, Category: "Spear", Attack: 70},
+	{ID: 124, Name: "Trident", Type: TypeWeapon, Category: "Spear", Attack: 93},
+	{ID: 125, Name: "Heavy Lance", Type: TypeWeapon, Category: "Spear", Attack: 136},
+	{ID: 126, Name: "Partisan", Type: TypeWeapon, Category: "Spear", Attack: 150},
+	{ID: 127, Name: "Holy Lance", Type: TypeWeapon, Category: "Spear", Attack: 194, Properties: []string{PropBreaks}},
+	{ID: 128, Name: "Golden Lance", Type: TypeWeapon, Category: "Spear", Attack: 153},
+	{ID: 129, Name: "Radiant Lance", Type: TypeWeapon, Category: "Spear", Attack: 222},
+	{ID: 130, Name: "Impartisan", Type: TypeWeapon, Category: "Spear", Attack: 172},
+	{ID: 131, Name: "Kunai", Type: TypeWeapon, Category: "Ninja Blade", Attack: 55},
+	{ID: 132, Name: "Kodachi", Type: TypeWeapon, Category: "Ninja Blade", Attack: 93},
+	{ID: 133, Name: "Sakura", Type: TypeWeapon, Category: "Ninja Blade", Attack: 112},
+	{ID: 134, Name: "Sasuke", Type: TypeWeapon, Category: "Ninja Blade", Attack: 121, Properties: []string{PropInstantKill}},
+	{ID: 135, Name: "Ichigeki", Type: TypeWeapon, Category: "Ninja Blade", Attack: 190, Properties: []string{PropInstantKill}},
+	{ID: 136, Name: "Kagenui", Type: TypeWeapon, Category: "Ninja Blade", Attack: 178},
+	{ID: 137, Name: "Ashura", Type: TypeWeapon, Category: "Katana", Attack: 57},
+	{ID: 138, Name: "Kotetsu", Type: TypeWeapon, Category: "Katana", Attack: 66},
+	{ID: 139, Name: "Kikuichimonji", Type: TypeWeapon, Category: "Katana", Attack: 81},
+	{ID: 140, Name: "Kazekiri", Type: TypeWeapon, Category: "Katana", Attack: 101},
+	{ID: 141, Name: "Murasame", Type: TypeWeapon, Category: "Katana", Attack: 110},
+	{ID: 142, Name: "Masamune", Type: TypeWeapon, Category: "Katana", Attack: 123},
+	{ID: 143, Name: "Murakumo", Type: TypeWeapon, Category: "Katana", Attack: 162},
+	{ID: 144, Name: "Mutsunokami", Type: TypeWeapon, Category: "Katana", Attack: 215},
+	{ID: 145, Name: "Heal Rod", Type: TypeWeapon, Category: "Rod", Attack: 200},
+	{ID: 146, Name: "Mythril Rod", Type: TypeWeapon, Category: "Rod", Attack: 60, Properties: []string{PropMPCritical}},
+	{ID: 147, Name: "Flame Rod", Type: TypeWeapon, Category: "Rod", Attack: 79, Properties: []string{PropBreaks}},
+	{ID: 148, Name: "Ice Rod", Type: TypeWeapon, Category: "Rod", Attack: 79, Properties: []string{PropBreaks}},
+	{ID: 149, Name: "Thunder Rod", Type: TypeWeapon, Category: "Rod", Attack: 79, Properties: []string{PropBreaks}},
+	{ID: 150, Name: "Poison Rod", Type: TypeWeapon, Category: "Rod", Attack: 86, Properties: []string{PropBreaks}},
+	{ID: 151, Name: "Holy Rod", Type: TypeWeapon, Category: "Rod", Attack: 124, Properties: []string{PropBreaks}},
+	{ID: 152, Name: "Gravity Rod", Type: TypeWeapon, Category: "Rod", Attack: 120, Properties: []string{PropBreaks}},
+	{ID: 153, Name: "Punisher", Type: TypeWeapon, Category: "Rod", Attack: 111, Properties: []string{PropMPCritical}},
+	{ID: 154, Name: "Magus Rod", Type: TypeWeapon, Category: "Rod", Attack: 168, Magic: 7},
+	{ID: 155, Name: "Chocobo Brush", Type: TypeWeapon, Category: "Brush", Attack: 60},
+	{ID: 156, Name: "Da Vinci Brush", Type: TypeWeapon, Category: "Brush", Attack: 100},
+	{ID: 157, Name: "Magical Brush", Type: TypeWeapon, Category: "Brush", Attack: 130},
+	{ID: 158, Name: "Rainbow Brush", Type: TypeWeapon, Category: "Brush", Attack: 146},
+	{ID: 159, Name: "Shuriken", Type: TypeWeapon, Category: "Throwing", Attack: 86, Properties: []string{PropRanged}},
+	{ID: 160, Name: "Fuma Shuriken", Type: TypeWeapon, Category: "Throwing", Attack: 132, Properties: []string{PropRanged}},
+	{ID: 161, Name: "Pinwheel", Type: TypeWeapon, Category: "Throwing", Attack: 190, Properties: []string{PropRanged}},
+	{ID: 162, Name: "Chain Flail", Type: TypeWeapon, Category: "Special", Attack: 86, Properties: []string{PropRanged}},
+	{ID: 163, Name: "Moonring Blade", Type: TypeWeapon, Category: "Special", Attack: 95, Properties: []string{PropRanged}},
+	{ID: 164, Name: "Morning Star", Type: TypeWeapon, Category: "Special", Attack: 109, Properties: []string{PropRanged}},
+	{ID: 165, Name: "Boomerang", Type: TypeWeapon, Category: "Special", Attack: 102, Properties: []string{PropRanged}},
+	{ID: 166, Name: "Rising Sun", Type: TypeWeapon, Category: "Special", Attack: 111, Properties: []string{PropRanged}},
+	{ID: 167, Name: "Hawkeye", Type: TypeWeapon, Category: "Special", Attack: 111, Properties: []string{PropRanged}},
+	{ID: 168, Name: "Bone Club", Type: TypeWeapon, Category: "Club", Attack: 151},
+	{ID: 169, Name: "Sniper", Type: TypeWeapon, Category: "Special", Attack: 140, Properties: []string{PropRanged}},
+	{ID: 170, Name: "Wing Edge", Type: TypeWeapon, Category: "Special", Attack: 198, Properties: []string{PropRanged, PropInstantKill}},
+	{ID: 171, Name: "Cards", Type: TypeWeapon, Category: "Gambling", Attack: 104, Properties: []string{PropRanged}},
+	{ID: 172, Name: "Darts", Type: TypeWeapon, Category: "Gambling", Attack: 104, Properties: []string{PropRanged}},
+	{ID: 173, Name: "Death Tarot", Type: TypeWeapon, Category: "Gambling", Attack: 128, Properties: []string{PropRanged, PropInstantKill}},
+	{ID: 174, Name: "Viper Darts", Type: TypeWeapon, Category: "Gambling", Attack: 115, Properties: []string{PropRanged, PropInstantKill}},
+	{ID: 175, Name: "Dice", Type: TypeWeapon, Category: "Gambling", Attack: 1, Properties: []string{PropRanged}},
+	{ID: 176, Name: "Fixed Dice", Type: TypeWeapon, Category: "Gambling", Attack: 1, Properties: []string{PropRanged}},
+	{ID: 177, Name: "Metal Knuckles", Type: TypeWeapon, Category: "Claw", Attack: 26},
+	{ID: 178, Name: "Mythril Claws", Type: TypeWeapon, Category: "Claw", Attack: 55},
+	{ID: 179, Name: "Kaiser Knuckles", Type: TypeWeapon, Category: "Claw", Attack: 83},
+	{ID: 180, Name: "Venom Claws", Type: TypeWeapon, Category: "Claw", Attack: 95},
+	{ID: 181, Name: "Burning Fist", Type: TypeWeapon, Category: "Claw", Attack: 122},
+	{ID: 182, Name: "Dragon Claws", Type: TypeWeapon, Category: "Claw", Attack: 188},
+	{ID: 183, Name: "Tigerfangs", Type: TypeWeapon, Category: "Claw", Attack: 215},
+
+	// Shields
+	{ID: 201, Name: "Buckler", Type: TypeShield, Category: "Light Shield", Defense: 16, MagicDefense: 10},
+	{ID: 202, Name: "Heavy Shield", Type: TypeShield, Category: "Heavy Shield", Defense: 22, MagicDefense: 14},
+	{ID: 203, Name: "Mythril Shield", Type: TypeShield, Category: "Light Shield", Defense: 27, MagicDefense: 19},
+	{ID: 204, Name: "Gold Shield", Type: TypeShield, Category: "Heavy Shield", Defense: 34, MagicDefense: 23},
+	{ID: 205, Name: "Aegis Shield", Type: TypeShield, Category: "Light Shield", Defense: 46, MagicDefense: 52},
+	{ID: 206, Name: "Diamond Shield", Type: TypeShield, Category: "Heavy Shield", Defense: 40, MagicDefense: 27},
+	{ID: 207, Name: "Flame Shield", Type: TypeShield, Category: "Heavy Shield", Defense: 41, MagicDefense: 28},
+	{ID: 208, Name: "Ice Shield", Type: TypeShield, Category: "Heavy Shield", Defense: 42, MagicDefense: 28},
+	{ID: 209, Name: "Thunder Shield", Type: TypeShield, Category: "Heavy Shield", Defense: 42, MagicDefense: 28},
+	{ID: 210, Name: "Crystal Shield", Type: TypeShield, Category: "Heavy Shield", Defense: 50, MagicDefense: 34},
+	{ID: 211, Name: "Genji Shield", Type: TypeShield, Category: "Heavy Shield", Defense: 54, MagicDefense: 36},
+	{ID: 212, Name: "Tortoise Shield", Type: TypeShield, Category: "Light Shield", Defense: 66, MagicDefense: 66},
+	{ID: 213, Name: "Cursed Shield", Type: TypeShield, Category: "Heavy Shield", Defense: 13, MagicDefense: 13, Properties: []string{PropCursed}},
+	{ID: 214, Name: "Paladin Shield", Type: TypeShield, Category: "Heavy Shield", Defense: 59, MagicDefense: 59},
+	{ID: 215, Name: "Force Shield", Type: TypeShield, Category: "Light Shield", Defense: 70, MagicDefense: 70},
+
+	// Helmets
+	{ID: 216, Name: "Leather Hat", Type: TypeHelmet, Category: "Light Helmet", Defense: 11, MagicDefense: 7},
+	{ID: 217, Name: "Hairband", Type: TypeHelmet, Category: "Light Helmet", Defense: 12, MagicDefense: 9},
+	{ID: 218, Name: "Plumed Hat", Type: TypeHelmet, Category: "Light Helmet", Defense: 14, MagicDefense: 10, Magic: 1},
+	{ID: 219, Name: "Beret", Type: TypeHelmet, Category: "Light Helmet", Defense: 16, MagicDefense: 12},
+	{ID: 220, Name: "Magus Hat", Type: TypeHelmet, Category: "Light Helmet", Defense: 16, MagicDefense: 15, Magic: 2},
+	{ID: 221, Name: "Bandana", Type: TypeHelmet, Category: "Light Helmet", Defense: 16, MagicDefense: 14, Vigor: 1},
+	{ID: 222, Name: "Iron Helmet", Type: TypeHelmet, Category: "Heavy Helmet", Defense: 18, MagicDefense: 13},
+	{ID: 223, Name: "Priest's Miter", Type: TypeHelmet, Category: "Light Helmet", Defense: 22, MagicDefense: 16, EquippableBy: []string{Strago}},
+	{ID: 224, Name: "Bard's Hat", Type: TypeHelmet, Category: "Light Helmet", Defense: 19, MagicDefense: 21, Speed: 2},
+	{ID: 225, Name: "Green Beret", Type: TypeHelmet, Category: "Light Helmet", Defense: 19, MagicDefense: 16},
+	{ID: 226, Name: "Head Band", Type: TypeHelmet, Category: "Light Helmet", Defense: 22, MagicDefense: 14, Vigor: 2},
+	{ID: 227, Name: "Mythril Helm", Type: TypeHelmet, Category: "Heavy Helmet", Defense: 20, MagicDefense: 14},
+	{ID: 228, Name: "Tiara", Type: TypeHelmet, Category: "Dress", Defense: 22, MagicDefense: 20},
+	{ID: 229, Name: "Gold Helmet", Type: TypeHelmet, Category: "Heavy Helmet", Defense: 22, MagicDefense: 15},
+	{ID: 230, Name: "Tiger Mask", Type: TypeHelmet, Category: "Light Helmet", Defense: 23, MagicDefense: 15, Vigor: 3, EquippableBy: []string{Sabin, Gau, Umaro}},
+	{ID: 231, Name: "Red Hat", Type: TypeHelmet, Category: "Light Helmet", Defense: 22, MagicDefense: 20},
+	{ID: 232, Name: "Mystery Veil", Type: TypeHelmet, Category: "Dress", Defense: 24, MagicDefense: 21},
+	{ID: 233, Name: "Circlet", Type: TypeHelmet, Category: "Light Helmet", Defense: 24, MagicDefense: 21, Magic: 3},
+	{ID: 234, Name: "Royal Crown", Type: TypeHelmet, Category: "Light Helmet", Defense: 25, MagicDefense: 20, EquippableBy: []string{Edgar, Gogo}},
+	{ID: 235, Name: "Diamond Helm", Type: TypeHelmet, Category: "Heavy Helmet", Defense: 26, MagicDefense: 17},
+	{ID: 236, Name: "Black Hood", Type: TypeHelmet, Category: "Light Helmet", Defense: 27, MagicDefense: 18, EquippableBy: []string{Locke, Shadow}},
+	{ID: 237, Name: "Crystal Helm", Type: TypeHelmet, Category: "Heavy Helmet", Defense: 29, MagicDefense: 19},
+	{ID: 238, Name: "Oath Veil", Type: TypeHelmet, Category: "Dress", Defense: 32, MagicDefense: 31},
+	{ID: 239, Name: "Cat-Ear Hood", Type: TypeHelmet, Category: "Light Helmet", Defense: 33, MagicDefense: 33, EquippableBy: []string{Relm}},
+	{ID: 240, Name: "Genji Helmet", Type: TypeHelmet, Category: "Heavy Helmet", Defense: 36, MagicDefense: 20},
+	{ID: 241, Name: "Thornlet", Type: TypeHelmet, Category: "Light Helmet", Defense: 38, MagicDefense: 0, Properties: []string{PropCursed}},
+	{ID: 242, Name: "Saucer", Type: TypeHelmet, Category: "Light Helmet", Defense: 0, MagicDefense: 0},
+
+	// Armor
+	{ID: 244, Name: "Leather Armor", Type: TypeArmor, Category: "Light Armor", Defense: 28, MagicDefense: 19},
+	{ID: 245, Name: "Cotton Robe", Type: TypeArmor, Category: "Robe", Defense: 32, MagicDefense: 21},
+	{ID: 246, Name: "Kenpo Gi", Type: TypeArmor, Category: "Light Armor", Defense: 34, MagicDefense: 23, EquippableBy: []string{Sabin, Gau, Cyan}},
+	{ID: 247, Name: "Iron Armor", Type: TypeArmor, Category: "Heavy Armor", Defense: 40, MagicDefense: 27},
+	{ID: 248, Name: "Silk Robe", Type: TypeArmor, Category: "Robe", Defense: 39, MagicDefense: 29},
+	{ID: 249, Name: "Mythril Vest", Type: TypeArmor, Category: "Light Armor", Defense: 45, MagicDefense: 30},
+	{ID: 250, Name: "Ninja Gear", Type: TypeArmor, Category: "Light Armor", Defense: 47, MagicDefense: 33, EquippableBy: []string{Locke, Shadow}},
+	{ID: 251, Name: "White Dress", Type: TypeArmor, Category: "Dress", Defense: 47, MagicDefense: 35},
+	{ID: 252, Name: "Mythril Mail", Type: TypeArmor, Category: "Heavy Armor", Defense: 51, MagicDefense: 35},
+	{ID: 253, Name: "Gaia Gear", Type: TypeArmor, Category: "Robe", Defense: 53, MagicDefense: 43},
+	{ID: 254, Name: "Mirage Dress", Type: TypeArmor, Category: "Dress", Defense: 48, MagicDefense: 48},
+	{ID: 255, Name: "Golden Armor", Type: TypeArmor, Category: "Heavy Armor", Defense: 55, MagicDefense: 37},
+	{ID: 256, Name: "Power Sash", Type: TypeArmor, Category: "Light Armor", Defense: 58, MagicDefense: 38, Vigor: 5, EquippableBy: []string{Sabin, Gau, Cyan}},
+	{ID: 257, Name: "Luminous Robe", Type: TypeArmor, Category: "Robe", Defense: 60, MagicDefense: 41},
+	{ID: 258, Name: "Diamond Vest", Type: TypeArmor, Category: "Light Armor", Defense: 65, MagicDefense: 45},
+	{ID: 259, Name: "Red Jacket", Type: TypeArmor, Category: "Light Armor", Defense: 78, MagicDefense: 54, EquippableBy: []string{Sabin, Gau, Umaro}},
+	{ID: 260, Name: "Force Armor", Type: TypeArmor, Category: "Heavy Armor", Defense: 69, MagicDefense: 68},
+	{ID: 261, Name: "Diamond Armor", Type: TypeArmor, Category: "Heavy Armor", Defense: 70, MagicDefense: 45},
+	{ID: 262, Name: "Black Garb", Type: TypeArmor, Category: "Light Armor", Defense: 71, MagicDefense: 47, EquippableBy: []string{Locke, Shadow}},
+	{ID: 263, Name: "Magus Rove", Type: TypeArmor, Category: "Robe", Defense: 70, MagicDefense: 49, Magic: 4},
+	{ID: 264, Name: "Crystal Mail", Type: TypeArmor, Category: "Heavy Armor", Defense: 72, MagicDefense: 49},
+	{ID: 265, Name: "Regal Gown", Type: TypeArmor, Category: "Dress", Defense: 73, MagicDefense: 50},
+	{ID: 266, Name: "Genji Armor", Type: TypeArmor, Category: "Heavy Armor", Defense: 90, MagicDefense: 50},
+	{ID: 267, Name: "Reed Cloak", Type: TypeArmor, Category: "Robe", Defense: 71, MagicDefense: 50},
+	{ID: 268, Name: "Minerva Bustier", Type: TypeArmor, Category: "Dress", Defense: 88, MagicDefense: 57},
+	{ID: 269, Name: "Tabby Suit", Type: TypeArmor, Category: "Costume", Defense: 80, MagicDefense: 50},
+	{ID: 270, Name: "Chocobo Suit", Type: TypeArmor, Category: "Costume", Defense: 86, MagicDefense: 49},
+	{ID: 271, Name: "Moogle Suit", Type: TypeArmor, Category: "Costume", Defense: 79, MagicDefense: 70},
+	{ID: 272, Name: "Nutkin Suit", Type: TypeArmor, Category: "Costume", Defense: 90, MagicDefense: 55},
+	{ID: 273, Name: "Behemeth Suit", Type: TypeArmor, Category: "Costume", Defense: 94, MagicDefense: 73},
+	{ID: 274, Name: "Snow Scarf", Type: TypeArmor, Category: "Scarf", Defense: 86, MagicDefense: 50},
+
+	// Relics
+	{ID: 275, Name: "Silver Spectacles", Type: TypeRelic, Category: "Relic"},
+	{ID: 276, Name: "Star Pendant", Type: TypeRelic, Category: "Relic"},
+	{ID: 277, Name: "Peace Ring", Type: TypeRelic, Category: "Relic"},
+	{ID: 278, Name: "Amulet", Type: TypeRelic, Category: "Relic"},
+	{ID: 279, Name: "White Cape", Type: TypeRelic, Category: "Relic"},
+	{ID: 280, Name: "Jewel Ring", Type: TypeRelic, Category: "Relic"},
+	{ID: 281, Name: "Fairy Ring", Type: TypeRelic, Category: "Relic"},
+	{ID: 282, Name: "Barrier Ring", Type: TypeRelic, Category: "Relic"},
+	{ID: 283, Name: "Mythril Glove", Type: TypeRelic, Category: "Relic"},
+	{ID: 284, Name: "Protect Ring", Type: TypeRelic, Category: "Relic"},
+	{ID: 285, Name: "Hermes Sandals", Type: TypeRelic, Category: "Relic"},
+	{ID: 286, Name: "Reflect Ring", Type: TypeRelic, Category: "Relic"},
+	{ID: 287, Name: "Angel Wings", Type: TypeRelic, Category: "Relic"},
+	{ID: 288, Name: "Angel Ring", Type: TypeRelic, Category: "Relic"},
+	{ID: 289, Name: "Knight's Code", Type: TypeRelic, Category: "Relic"},
+	{ID: 290, Name: "Dragoon Boots", Type: TypeRelic, Category: "Relic"},
+	{ID: 291, Name: "Zephyr Cloak", Type: TypeRelic, Category: "Relic"},
+	{ID: 292, Name: "Princess Ring", Type: TypeRelic, Category: "Relic"},
+	{ID: 293, Name: "Cursed Ring", Type: TypeRelic, Category: "Relic", Properties: []string{PropCursed}},
+	{ID: 294, Name: "Earring", Type: TypeRelic, Category: "Relic", Properties: []string{PropStacks}},
+	{ID: 295, Name: "Gigas Glove", Type: TypeRelic, Category: "Relic"},
+	{ID: 296, Name: "Blizzard Orb", Type: TypeRelic, Category: "Relic", EquippableBy: []string{Umaro}},
+	{ID: 297, Name: "Berserker Ring", Type: TypeRelic, Category: "Relic", EquippableBy: []string{Umaro}},
+	{ID: 298, Name: "Thief's Bracer", Type: TypeRelic, Category: "Relic"},
+	{ID: 299, Name: "Guard Bracelet", Type: TypeRelic, Category: "Relic"},
+	{ID: 300, Name: "Hero Ring", Type: TypeRelic, Category: "Relic", Properties: []string{PropStacks}},
+	{ID: 301, Name: "Ribbon", Type: TypeRelic, Category: "Relic"},
+	{ID: 302, Name: "Muscle Belt", Type: TypeRelic, Category: "Relic"},
+	{ID: 303, Name: "Crystal Orb", Type: TypeRelic, Category: "Relic"},
+	{ID: 304, Name: "Gold Hairpin", Type: TypeRelic, Category: "Relic"},
+	{ID: 305, Name: "Celestriad", Type: TypeRelic, Category: "Relic"},
+	{ID: 306, Name: "Brigand's Glove", Type: TypeRelic, Category: "Relic"},
+	{ID: 307, Name: "Gauntlet", Type: TypeRelic, Category: "Relic", Properties: []string{PropTwoHanded}},
+	{ID: 308, Name: "Genji Glove", Type: TypeRelic, Category: "Relic", Properties: []string{PropDualWield}},
+	{ID: 309, Name: "Hyper Wrist", Type: TypeRelic, Category: "Relic"},
+	{ID: 310, Name: "Master's Scroll", Type: TypeRelic, Category: "Relic", Properties: []string{PropQuadAttack}},
+	{ID: 311, Name: "Prayer Beads", Type: TypeRelic, Category: "Relic"},
+	{ID: 312, Name: "Black Belt", Type: TypeRelic, Category: "Relic"},
+	{ID: 313, Name: "Heiki's Jitte", Type: TypeRelic, Category: "Relic"},
+	{ID: 314, Name: "Fake Mustache", Type: TypeRelic, Category: "Relic", EquippableBy: []string{Relm}},
+	{ID: 315, Name: "Soul of Thamasa", Type: TypeRelic, Category: "Relic"},
+	{ID: 316, Name: "Dragon Horn", Type: TypeRelic, Category: "Relic"},
+	{ID: 317, Name: "Merit Award", Type: TypeRelic, Category: "Relic"},
+	{ID: 318, Name: "Momento Ring", Type: TypeRelic, Category: "Relic"},
+	{ID: 319, Name: "Safety Bit", Type: TypeRelic, Category: "Relic"},
+	{ID: 320, Name: "Lich Ring", Type: TypeRelic, Category: "Relic"},
+	{ID: 321, Name: "Molulu's Charm", Type: TypeRelic, Category: "Relic"},
+	{ID: 322, Name: "Ward Bangle", Type: TypeRelic, Category: "Relic"},
+	{ID: 323, Name: "Miracle Shoes", Type: TypeRelic, Category: "Relic"},
+	{ID: 324, Name: "Alarm Gaurd", Type: TypeRelic, Category: "Relic"},
+	{ID: 325, Name: "Gale Hairpin", Type: TypeRelic, Category: "Relic"},
+	{ID: 326, Name: "Sniper Eye", Type: TypeRelic, Category: "Relic"},
+	{ID: 327, Name: "Growth Egg", Type: TypeRelic, Category: "Relic"},
+	{ID: 328, Name: "Tintinnabulum", Type: TypeRelic, Category: "Relic"},
+	{ID: 329, Name: "Sprint Shoes", Type: TypeRelic, Category: "Relic"},
+}
+
+// EquipmentDatabase maps item IDs to their equipment data
+var EquipmentDatabase = make(map[int]*EquipmentInfo)
+
+func init() {
+	for _, e := range equipmentList {
+		if e.EquippableBy == nil {
+			e.EquippableBy = categoryUsers[e.Category]
+		}
+		EquipmentDatabase[e.ID] = e
+	}
+}
+
+// GetEquipmentInfo returns the data for an item; false when the item isn't
+// equipment
+func GetEquipmentInfo(id int) (*EquipmentInfo, bool) {
+	e, found := EquipmentDatabase[id]
+	return e, found
+}
+
+// EquipmentOfType returns every item of a type in ID order
+func EquipmentOfType(t EquipType) []*EquipmentInfo {
+	var items []*EquipmentInfo
+	for _, e := range EquipmentDatabase {
+		if e.Type == t {
+			items = append(items, e)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
+
+// EquippableItems returns the items of a type a character can wear in ID order
+func EquippableItems(character string, t EquipType) []*EquipmentInfo {
+	var items []*EquipmentInfo
+	for _, e := range EquipmentOfType(t) {
+		if e.CanEquip(character) {
+			items = append(items, e)
+		}
+	}
+	return items
+}
@@ -0,0 +1,187 @@
+package game
+
+import (
+	"fmt"
+
+	"ffvi_editor/models"
+	"ffvi_editor/models/pr"
+)
+
+// EquipSlot is a position on the equipment screen; the names match the
+// equipment paths used by patches and rules
+type EquipSlot string
+
+const (
+	SlotWeapon EquipSlot = "weapon"
+	SlotShield EquipSlot = "shield"
+	SlotHelmet EquipSlot = "helmet"
+	SlotArmor  EquipSlot = "armor"
+	SlotRelic1 EquipSlot = "relic1"
+	SlotRelic2 EquipSlot = "relic2"
+)
+
+// EquipSlots lists the slots in screen order
+var EquipSlots = []EquipSlot{SlotWeapon, SlotShield, SlotHelmet, SlotArmor, SlotRelic1, SlotRelic2}
+
+// EquipmentIssueKind is what makes equipment illegal
+type EquipmentIssueKind string
+
+const (
+	// IssueWrongSlot is an item in a slot of another type
+	IssueWrongSlot EquipmentIssueKind = "wrong_slot"
+	// IssueDualWield is a weapon in the shield hand without the Genji Glove
+	IssueDualWield EquipmentIssueKind = "dual_wield"
+	// IssueCannotEquip is an item the character can't wear
+	IssueCannotEquip EquipmentIssueKind = "cannot_equip"
+	// IssueDuplicateRelic is the same relic in both slots when a second copy
+	// does nothing
+	IssueDuplicateRelic EquipmentIssueKind = "duplicate_relic"
+	// IssueWastedRelic is a relic that can't work with the rest of the gear
+	IssueWastedRelic EquipmentIssueKind = "wasted_relic"
+)
+
+// EquipmentIssue is a problem with one slot of a character's equipment
+type EquipmentIssue struct {
+	Kind    EquipmentIssueKind
+	Slot    EquipSlot
+	ItemID  int
+	Message string
+}
+
+// slotType is the type each slot holds; the shield hand also takes a weapon
+// when the Genji Glove is worn
+var slotType = map[EquipSlot]EquipType{
+	SlotWeapon: TypeWeapon,
+	SlotShield: TypeShield,
+	SlotHelmet: TypeHelmet,
+	SlotArmor:  TypeArmor,
+	SlotRelic1: TypeRelic,
+	SlotRelic2: TypeRelic,
+}
+
+// SlotID returns the item ID field for a slot and the ID the loader uses
+// for it when empty
+func SlotID(eq *models.Equipment, slot EquipSlot) (id *int, emptyID int) {
+	switch slot {
+	case SlotWeapon:
+		return &eq.WeaponID, 93
+	case SlotShield:
+		return &eq.ShieldID, 93
+	case SlotHelmet:
+		return &eq.HelmetID, 198
+	case SlotArmor:
+		return &eq.ArmorID, 199
+	case SlotRelic1:
+		return &eq.Relic1ID, 200
+	case SlotRelic2:
+		return &eq.Relic2ID, 200
+	}
+	return nil, 0
+}
+
+// CheckEquipment returns every problem with a character's equipment. Items
+// missing from the database are left to the other checks.
+func CheckEquipment(c *models.Character) (issues []EquipmentIssue) {
+	eq := &c.Equipment
+	genji := wears(eq, PropDualWield)
+	restricted := isEquipmentCharacter(c.RootName)
+
+	for _, slot := range EquipSlots {
+		id, _ := SlotID(eq, slot)
+		item, found := GetEquipmentInfo(*id)
+		if IsEmptyEquipment(*id) || !found {
+			continue
+		}
+		switch {
+		case slot == SlotShield && item.Type == TypeWeapon:
+			if !genji {
+				issues = append(issues, EquipmentIssue{
+					Kind:    IssueDualWield,
+					Slot:    slot,
+					ItemID:  item.ID,
+					Message: fmt.Sprintf("%s holds %s in the shield hand without the Genji Glove", c.Name, item.Name),
+				})
+				continue
+			}
+		case item.Type != slotType[slot]:
+			issues = append(issues, EquipmentIssue{
+				Kind:    IssueWrongSlot,
+				Slot:    slot,
+				ItemID:  item.ID,
+				Message: fmt.Sprintf("%s has %s (%s) in the %s slot", c.Name, item.Name, item.Type, slot),
+			})
+			continue
+		}
+		if restricted && !item.CanEquip(c.RootName) {
+			issues = append(issues, EquipmentIssue{
+				Kind:    IssueCannotEquip,
+				Slot:    slot,
+				ItemID:  item.ID,
+				Message: fmt.Sprintf("%s can't equip %s", c.Name, item.Name),
+			})
+		}
+	}
+
+	if eq.Relic1ID == eq.Relic2ID && !IsEmptyEquipment(eq.Relic1ID) {
+		if item, found := GetEquipmentInfo(eq.Relic1ID); found && item.Type == TypeRelic && !item.HasProperty(PropStacks) {
+			issues = append(issues, EquipmentIssue{
+				Kind:    IssueDuplicateRelic,
+				Slot:    SlotRelic2,
+				ItemID:  item.ID,
+				Message: fmt.Sprintf("%s wears two %ss; the second does nothing", c.Name, item.Name),
+			})
+		}
+	}
+
+	// The Gauntlet needs the shield hand empty
+	if shield, found := GetEquipmentInfo(eq.ShieldID); found && !IsEmptyEquipment(eq.ShieldID) {
+		for _, slot := range []EquipSlot{SlotRelic1, SlotRelic2} {
+			id, _ := SlotID(eq, slot)
+			if item, ok := GetEquipmentInfo(*id); ok && item.HasProperty(PropTwoHanded) {
+				issues = append(issues, EquipmentIssue{
+					Kind:    IssueWastedRelic,
+					Slot:    slot,
+					ItemID:  item.ID,
+					Message: fmt.Sprintf("%s's %s does nothing while holding %s", c.Name, item.Name, shield.Name),
+				})
+				break
+			}
+		}
+	}
+	return
+}
+
+// Unequip empties a slot and puts its item back in the inventory
+func Unequip(c *models.Character, slot EquipSlot, inv *pr.Inventory) error {
+	id, emptyID := SlotID(&c.Equipment, slot)
+	if id == nil {
+		return fmt.Errorf("unknown equipment slot %q", slot)
+	}
+	if IsEmptyEquipment(*id) {
+		return nil
+	}
+	if err := inv.Add(*id, 1); err != nil {
+		return err
+	}
+	*id = emptyID
+	return nil
+}
+
+// wears reports whether either relic has a property
+func wears(eq *models.Equipment, property string) bool {
+	for _, id := range []int{eq.Relic1ID, eq.Relic2ID} {
+		if item, found := GetEquipmentInfo(id); found && item.Type == TypeRelic && item.HasProperty(property) {
+			return true
+		}
+	}
+	return false
+}
+
+func isEquipmentCharacter(name string) bool {
+	for _, c := range EquipmentCharacters {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
@@ -1,5 +1,7 @@
 package pr
 
+import "fmt"
+
 type Inventory struct {
 	Size             int
 	Rows             []*Row
@@ -62,6 +64,31 @@ func (i *Inventory) Set(index int, row Row) {
 	}
 }
 
+// Add puts count more of an item in the inventory, on the item's row if it
+// has one and otherwise in the first empty row. Stacks stop at MaxItemCount.
+func (i *Inventory) Add(itemID, count int) error {
+	for _, r := range i.Rows {
+		if r != nil && r.ItemID == itemID && r.Count > 0 {
+			r.Count += count
+			if r.Count > MaxItemCount {
+				r.Count = MaxItemCount
+			}
+			return nil
+		}
+	}
+	for _, r := range i.Rows {
+		if r != nil && (r.ItemID == 0 || r.Count == 0) {
+			r.ItemID, r.Count = itemID, count
+			return nil
+		}
+	}
+	if len(i.Rows) >= i.Size {
+		return fmt.Errorf("inventory is full")
+	}
+	i.Set(len(i.Rows), Row{ItemID: itemID, Count: count})
+	return nil
+}
+
 func (i *Inventory) GetRowsForPrSave() []Row {
 	rows := make([]Row, 0, len(i.Rows))
 	for _, r := range i.Rows {
@@ -0,0 +1,111 @@
+package pr
+
+import "fmt"
+
+// MaxItemCount is the largest stack the game holds
+const MaxItemCount = 99
+
+// InventoryProblemKind is what is wrong with an inventory row
+type InventoryProblemKind string
+
+const (
+	// ProblemDuplicateRow is a second row for an item already in the inventory
+	ProblemDuplicateRow InventoryProblemKind = "duplicate_row"
+	// ProblemZeroCount is an item row without any of the item
+	ProblemZeroCount InventoryProblemKind = "zero_count"
+	// ProblemOverMax is a row holding more than MaxItemCount
+	ProblemOverMax InventoryProblemKind = "over_max"
+	// ProblemUnknownItem is an item ID missing from the item tables
+	ProblemUnknownItem InventoryProblemKind = "unknown_item"
+)
+
+// InventoryProblem is a problem with one inventory row
+type InventoryProblem struct {
+	Kind   InventoryProblemKind
+	Row    int
+	ItemID int
+	Count  int
+	// First is the row a duplicate merges into
+	First int
+}
+
+func (p InventoryProblem) String() string {
+	switch p.Kind {
+	case ProblemDuplicateRow:
+		return fmt.Sprintf("row %d repeats item %d from row %d", p.Row+1, p.ItemID, p.First+1)
+	case ProblemZeroCount:
+		return fmt.Sprintf("row %d has a count of %d for item %d", p.Row+1, p.Count, p.ItemID)
+	case ProblemOverMax:
+		return fmt.Sprintf("row %d has %d of item %d, over %d", p.Row+1, p.Count, p.ItemID, MaxItemCount)
+	case ProblemUnknownItem:
+		return fmt.Sprintf("row %d holds unknown item %d", p.Row+1, p.ItemID)
+	}
+	return string(p.Kind)
+}
+
+// Check returns the problems of every row. Items missing from known are
+// reported as unknown; empty rows are skipped.
+func (i *Inventory) Check(known map[int]string) (problems []InventoryProblem) {
+	first := make(map[int]int)
+	for j, r := range i.Rows {
+		if r == nil || r.ItemID == 0 {
+			continue
+		}
+		p := InventoryProblem{Row: j, ItemID: r.ItemID, Count: r.Count}
+		if _, found := known[r.ItemID]; !found {
+			p.Kind = ProblemUnknownItem
+			problems = append(problems, p)
+			continue
+		}
+		if r.Count <= 0 {
+			p.Kind = ProblemZeroCount
+			problems = append(problems, p)
+			continue
+		}
+		if f, found := first[r.ItemID]; found {
+			p.Kind, p.First = ProblemDuplicateRow, f
+			problems = append(problems, p)
+			continue
+		}
+		first[r.ItemID] = j
+		if r.Count > MaxItemCount {
+			p.Kind = ProblemOverMax
+			problems = append(problems, p)
+		}
+	}
+	return
+}
+
+// Fix repairs a problem returned by Check: duplicates are merged into their
+// first row, overfull rows are capped and the other rows are cleared. It does
+// nothing when the row changed since the check.
+func (i *Inventory) Fix(p InventoryProblem) {
+	if p.Row >= len(i.Rows) || i.Rows[p.Row] == nil || i.Rows[p.Row].ItemID != p.ItemID {
+		return
+	}
+	r := i.Rows[p.Row]
+	switch p.Kind {
+	case ProblemDuplicateRow:
+		if p.First < len(i.Rows) && i.Rows[p.First] != nil && i.Rows[p.First].ItemID == p.ItemID {
+			f := i.Rows[p.First]
+			f.Count += r.Count
+			if f.Count > MaxItemCount {
+				f.Count = MaxItemCount
+			}
+		}
+		r.ItemID, r.Count = 0, 0
+	case ProblemOverMax:
+		r.Count = MaxItemCount
+	case ProblemZeroCount, ProblemUnknownItem:
+		r.ItemID, r.Count = 0, 0
+	}
+}
+
+// Repair fixes every problem Check finds and returns them
+func (i *Inventory) Repair(known map[int]string) []InventoryProblem {
+	problems := i.Check(known)
+	for _, p := range problems {
+		i.Fix(p)
+	}
+	return problems
+}
@@ -0,0 +1,46 @@
+package pr
+
+import "testing"
+
+func TestInventoryRepair(t *testing.T) {
+	known := map[int]string{1: "Potion", 2: "Hi-Potion", 3: "Ether"}
+	inv := &Inventory{Size: 10}
+	inv.Clear()
+	inv.Set(0, Row{ItemID: 1, Count: 60})
+	inv.Set(1, Row{ItemID: 2, Count: 0})
+	inv.Set(2, Row{ItemID: 1, Count: 60})
+	inv.Set(3, Row{ItemID: 3, Count: 120})
+	inv.Set(4, Row{ItemID: 42, Count: 1})
+
+	problems := inv.Check(known)
+	want := map[int]InventoryProblemKind{
+		1: ProblemZeroCount,
+		2: ProblemDuplicateRow,
+		3: ProblemOverMax,
+		4: ProblemUnknownItem,
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("got %d problems, want %d: %+v", len(problems), len(want), problems)
+	}
+	for _, p := range problems {
+		if want[p.Row] != p.Kind {
+			t.Errorf("row %d: got %s, want %s", p.Row, p.Kind, want[p.Row])
+		}
+	}
+
+	inv.Repair(known)
+	if r := inv.Rows[0]; r.ItemID != 1 || r.Count != MaxItemCount {
+		t.Errorf("duplicate not merged and capped: %+v", r)
+	}
+	if r := inv.Rows[3]; r.Count != MaxItemCount {
+		t.Errorf("overfull row not capped: %+v", r)
+	}
+	for _, i := range []int{1, 2, 4} {
+		if r := inv.Rows[i]; r.ItemID != 0 || r.Count != 0 {
+			t.Errorf("row %d not cleared: %+v", i, r)
+		}
+	}
+	if problems = inv.Check(known); len(problems) != 0 {
+		t.Errorf("problems left after repair: %+v", problems)
+	}
+}
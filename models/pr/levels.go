@@ -0,0 +1,199 @@
+package pr
+
+import (
+	"fmt"
+
+	"ffvi_editor/models"
+	"ffvi_editor/models/consts"
+)
+
+const (
+	MinLevel = 1
+	MaxLevel = 99
+	MaxHP    = 9999
+	MaxMP    = 999
+)
+
+// LevelFixMode is how a level that doesn't match the experience is repaired
+type LevelFixMode string
+
+const (
+	// ExpFromLevel sets the experience to the minimum for the level
+	ExpFromLevel LevelFixMode = "exp"
+	// LevelFromExp sets the level the experience reaches
+	LevelFromExp LevelFixMode = "level"
+	// SimulateLevelUp raises the level to the one the experience reaches and
+	// adds the HP and MP the level-ups would have given. Experience below the
+	// level is raised to the level's minimum, since levels are never lost.
+	SimulateLevelUp LevelFixMode = "simulate"
+)
+
+// LevelFixModes lists the modes in menu order
+var LevelFixModes = []LevelFixMode{ExpFromLevel, LevelFromExp, SimulateLevelUp}
+
+// Label is the display name of a fix mode
+func (m LevelFixMode) Label() string {
+	switch m {
+	case ExpFromLevel:
+		return "Set EXP to the minimum for the level"
+	case LevelFromExp:
+		return "Set level from EXP"
+	case SimulateLevelUp:
+		return "Simulate level-ups"
+	}
+	return string(m)
+}
+
+// ParseLevelFixMode accepts a mode name
+func ParseLevelFixMode(s string) (LevelFixMode, error) {
+	for _, m := range LevelFixModes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown level fix mode %q (use exp, level or simulate)", s)
+}
+
+// LevelChange is the result of fixing a character's level and experience
+type LevelChange struct {
+	Character string
+	OldLevel  int
+	NewLevel  int
+	OldExp    int
+	NewExp    int
+	HPGain    int
+	MPGain    int
+}
+
+// Changed reports whether the fix changed anything
+func (c LevelChange) Changed() bool {
+	return c.OldLevel != c.NewLevel || c.OldExp != c.NewExp || c.HPGain != 0 || c.MPGain != 0
+}
+
+func (c LevelChange) String() string {
+	s := fmt.Sprintf("%s: level %d -> %d, EXP %d -> %d", c.Character, c.OldLevel, c.NewLevel, c.OldExp, c.NewExp)
+	if c.HPGain != 0 || c.MPGain != 0 {
+		s += fmt.Sprintf(", HP %+d, MP %+d", c.HPGain, c.MPGain)
+	}
+	return s
+}
+
+// ExpForLevel returns the experience needed to reach a level
+func ExpForLevel(level int) int {
+	level = clampLevel(level)
+	return int(consts.LevelToExp[level])
+}
+
+// LevelForExp returns the level an amount of experience reaches
+func LevelForExp(exp int) int {
+	level := MinLevel
+	for level < MaxLevel && exp >= int(consts.LevelToExp[level+1]) {
+		level++
+	}
+	return level
+}
+
+// LevelMatchesExp reports whether a character's level is the one its
+// experience reaches
+func LevelMatchesExp(c *models.Character) bool {
+	return LevelForExp(c.Exp) == c.Level
+}
+
+// EstimateHPMP returns the max HP and MP a character has at a level without
+// equipment or esper bonuses: its base plus the level-up gains
+func EstimateHPMP(c *models.Character, level int) (hp, mp int) {
+	level = clampLevel(level)
+	if b, ok := CharacterOffsetByName[c.RootName]; ok {
+		hp, mp = b.HPBase, b.MPBase
+	}
+	return hp + int(HpMpCounts[level].HP), mp + int(HpMpCounts[level].MP)
+}
+
+// LevelUpGains returns the HP and MP gained going from one level to another;
+// they are negative when going down
+func LevelUpGains(from, to int) (hp, mp int) {
+	from, to = clampLevel(from), clampLevel(to)
+	return int(HpMpCounts[to].HP) - int(HpMpCounts[from].HP), int(HpMpCounts[to].MP) - int(HpMpCounts[from].MP)
+}
+
+// FixLevelExp makes a character's level and experience agree
+func FixLevelExp(c *models.Character, mode LevelFixMode) LevelChange {
+	change := LevelChange{Character: c.Name, OldLevel: c.Level, OldExp: c.Exp}
+	switch mode {
+	case ExpFromLevel:
+		c.Level = clampLevel(c.Level)
+		if !LevelMatchesExp(c) {
+			c.Exp = ExpForLevel(c.Level)
+		}
+	case LevelFromExp:
+		c.Level = LevelForExp(c.Exp)
+	case SimulateLevelUp:
+		if target := LevelForExp(c.Exp); target > c.Level {
+			change.HPGain, change.MPGain = SetLevel(c, target)
+			change.NewLevel, change.NewExp = c.Level, c.Exp
+			return change
+		}
+		c.Level = clampLevel(c.Level)
+		if !LevelMatchesExp(c) {
+			c.Exp = ExpForLevel(c.Level)
+		}
+	}
+	change.NewLevel, change.NewExp = c.Level, c.Exp
+	return change
+}
+
+// SetLevel moves a character to a level as if it had leveled up: max HP and
+// MP change by the level-up gains, current HP and MP follow, and the
+// experience is set to the level's minimum unless it already falls within it.
+// It returns the HP and MP gained.
+func SetLevel(c *models.Character, level int) (hpGain, mpGain int) {
+	level = clampLevel(level)
+	from := c.Level
+	if from < MinLevel {
+		from = MinLevel
+	}
+	hpGain, mpGain = LevelUpGains(from, level)
+
+	// A max below the base was never filled in; start from the estimate
+	if b, ok := CharacterOffsetByName[c.RootName]; ok && c.HP.Max < b.HPBase {
+		c.HP.Max, c.MP.Max = EstimateHPMP(c, from)
+	}
+
+	hpGain = adjust(&c.HP, hpGain, MaxHP)
+	mpGain = adjust(&c.MP, mpGain, MaxMP)
+	c.Level = level
+	if !LevelMatchesExp(c) {
+		c.Exp = ExpForLevel(level)
+	}
+	return
+}
+
+// adjust changes a max by a gain within [0, limit], moves the current value
+// along with it and returns the change actually made
+func adjust(v *models.CurrentMax, gain, limit int) int {
+	old := v.Max
+	v.Max += gain
+	if v.Max > limit {
+		v.Max = limit
+	} else if v.Max < 0 {
+		v.Max = 0
+	}
+	gain = v.Max - old
+	v.Current += gain
+	if v.Current > v.Max {
+		v.Current = v.Max
+	} else if v.Current < 0 {
+		v.Current = 0
+	}
+	return gain
+}
+
+func clampLevel(level int) int {
+	if level < MinLevel {
+		return MinLevel
+	}
+	if level > MaxLevel {
+		return MaxLevel
+	}
+	return level
+}
@@ -0,0 +1,95 @@
+package pr
+
+import (
+	"testing"
+
+	"ffvi_editor/models"
+)
+
+func TestLevelForExp(t *testing.T) {
+	tests := []struct {
+		exp   int
+		level int
+	}{
+		{0, 1},
+		{31, 1},
+		{32, 2},
+		{34456, 23},
+		{83184, 31},
+		{ExpForLevel(MaxLevel) + 1000, MaxLevel},
+	}
+	for _, tt := range tests {
+		if got := LevelForExp(tt.exp); got != tt.level {
+			t.Errorf("LevelForExp(%d) = %d, want %d", tt.exp, got, tt.level)
+		}
+	}
+}
+
+func TestExpTableIncreases(t *testing.T) {
+	for l := MinLevel + 1; l <= MaxLevel; l++ {
+		if ExpForLevel(l) <= ExpForLevel(l-1) {
+			t.Fatalf("EXP for level %d (%d) is not above level %d (%d)", l, ExpForLevel(l), l-1, ExpForLevel(l-1))
+		}
+		if LevelForExp(ExpForLevel(l)) != l {
+			t.Fatalf("EXP for level %d reaches level %d", l, LevelForExp(ExpForLevel(l)))
+		}
+	}
+}
+
+func TestFixLevelExp(t *testing.T) {
+	newChar := func() *models.Character {
+		return &models.Character{
+			RootName: "Terra",
+			Name:     "Terra",
+			Level:    3,
+			Exp:      ExpForLevel(10),
+			HP:       models.CurrentMax{Current: 50, Max: 60},
+			MP:       models.CurrentMax{Current: 20, Max: 30},
+		}
+	}
+
+	c := newChar()
+	FixLevelExp(c, ExpFromLevel)
+	if c.Level != 3 || c.Exp != ExpForLevel(3) {
+		t.Errorf("ExpFromLevel gave level %d, EXP %d", c.Level, c.Exp)
+	}
+
+	c = newChar()
+	FixLevelExp(c, LevelFromExp)
+	if c.Level != 10 || c.Exp != ExpForLevel(10) || c.HP.Max != 60 {
+		t.Errorf("LevelFromExp gave level %d, EXP %d, max HP %d", c.Level, c.Exp, c.HP.Max)
+	}
+
+	c = newChar()
+	change := FixLevelExp(c, SimulateLevelUp)
+	hp, mp := LevelUpGains(3, 10)
+	if c.Level != 10 || change.HPGain != hp || change.MPGain != mp {
+		t.Errorf("SimulateLevelUp gave level %d, HP %+d, MP %+d; want 10, %+d, %+d",
+			c.Level, change.HPGain, change.MPGain, hp, mp)
+	}
+	if c.HP.Max != 60+hp || c.HP.Current != 50+hp {
+		t.Errorf("SimulateLevelUp gave HP %d/%d", c.HP.Current, c.HP.Max)
+	}
+	if !LevelMatchesExp(c) {
+		t.Error("level doesn't match EXP after SimulateLevelUp")
+	}
+
+	// Levels are never lost, so EXP below the level is raised instead
+	c = newChar()
+	c.Level, c.Exp = 10, ExpForLevel(3)
+	FixLevelExp(c, SimulateLevelUp)
+	if c.Level != 10 || c.Exp != ExpForLevel(10) {
+		t.Errorf("SimulateLevelUp with low EXP gave level %d, EXP %d", c.Level, c.Exp)
+	}
+}
+
+func TestParseLevelFixMode(t *testing.T) {
+	for _, m := range LevelFixModes {
+		if got, err := ParseLevelFixMode(string(m)); err != nil || got != m {
+			t.Errorf("ParseLevelFixMode(%q) = %q, %v", m, got, err)
+		}
+	}
+	if _, err := ParseLevelFixMode("bogus"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
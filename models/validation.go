@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 // ValidationSeverity represents how serious a validation issue is
 type ValidationSeverity string
 
@@ -43,6 +45,27 @@ const (
 	LenientMode ValidationMode = "lenient"
 )
 
+// ValidationModeForLevel maps a settings validation level to a mode.
+// "permissive" is the settings name of LenientMode; any other unknown level
+// names a custom profile defined by a rule set.
+func ValidationModeForLevel(level string) ValidationMode {
+	switch l := strings.ToLower(strings.TrimSpace(level)); l {
+	case "", string(NormalMode):
+		return NormalMode
+	case string(StrictMode):
+		return StrictMode
+	case "permissive", string(LenientMode):
+		return LenientMode
+	default:
+		return ValidationMode(l)
+	}
+}
+
+// IsCustom reports whether the mode is a profile from a rule set
+func (m ValidationMode) IsCustom() bool {
+	return m != StrictMode && m != NormalMode && m != LenientMode
+}
+
 // ValidationConfig holds validation settings
 type ValidationConfig struct {
 	Mode                ValidationMode
@@ -53,6 +76,7 @@ type ValidationConfig struct {
 	MaxCharacterMP      uint16
 	MaxStatValue        uint16
 	AutoFixSimpleIssues bool
+	LevelFixMode        string // How a level that doesn't match EXP is fixed: "exp", "level" or "simulate"
 }
 
 // DefaultValidationConfig returns safe defaults
@@ -62,10 +86,11 @@ func DefaultValidationConfig() ValidationConfig {
 		RealTimeValidation: true,
 		PreSaveValidation:  true,
 		MaxCharacterLevel:  99,
-		MaxCharacterHP:     9999,
-		MaxCharacterMP:     9999,
+		MaxCharacterHP:     9999, // The game's caps, as pr.MaxHP and pr.MaxMP
+		MaxCharacterMP:     999,
 		MaxStatValue:       255,
 		AutoFixSimpleIssues: false,
+		LevelFixMode:        "exp",
 	}
 }
 
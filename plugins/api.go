@@ -2,13 +2,15 @@ package plugins
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"time"
+	"ffvi_editor/global"
 	"ffvi_editor/models"
+	"ffvi_editor/models/game"
 	constsPR "ffvi_editor/models/consts/pr"
 	modelsPR "ffvi_editor/models/pr"
 	ioPR "ffvi_editor/io/pr"
-	jo "gitlab.com/c0b/go-ordered-json"
+	"ffvi_editor/io/validation"
 )
 
 // PluginAPI provides safe access to save editor functionality for plugins
@@ -22,6 +24,11 @@ type PluginAPI interface {
 	SetParty(ctx context.Context, party *modelsPR.Party) error
 	GetEquipment(ctx context.Context) (*models.Equipment, error)
 	SetEquipment(ctx context.Context, eq *models.Equipment) error
+	SaveFile(ctx context.Context, path string) error
+
+	// Game Data
+	GetEquipmentInfo(ctx context.Context, itemID int) (*game.EquipmentInfo, error)
+	ListEquipment(ctx context.Context, equipType game.EquipType) ([]*game.EquipmentInfo, error)
 
 	// Batch Operations
 	ApplyBatchOperation(ctx context.Context, op string, params map[string]interface{}) (int, error)
@@ -31,7 +38,7 @@ type PluginAPI interface {
 	FindItems(ctx context.Context, predicate func(*modelsPR.Row) bool) []*modelsPR.Row
 
 	// Events
-	RegisterHook(event string, callback func(interface{}) error) error
+	RegisterHook(event string, callback func(ctx context.Context, data interface{}) error) error
 	FireEvent(ctx context.Context, event string, data interface{}) error
 
 	// UI
@@ -53,22 +60,45 @@ type PluginAPI interface {
 // APIImpl provides a default implementation of PluginAPI
 type APIImpl struct {
 	prData        *ioPR.PR
-	hooks         map[string][]func(interface{}) error
+	hooks         map[string][]func(context.Context, interface{}) error
 	settings      map[string]interface{}
 	permissions   map[string]bool
 	logger        func(level, msg string)
 	showDialogFn  func(title, message string) error
 	showConfirmFn func(title, message string) bool
 	showInputFn   func(prompt string) (string, error)
+	newSaveGate   func() *validation.SaveGate
+	saveType      global.SaveFileType
+	auditLogger   *AuditLogger
+}
+
+// maxConfirmIssues is how many issues the save confirmation lists
+const maxConfirmIssues = 10
+
+type pluginIDKey struct{}
+
+// WithPluginID marks a context with the plugin whose API calls it carries
+func WithPluginID(ctx context.Context, pluginID string) context.Context {
+	return context.WithValue(ctx, pluginIDKey{}, pluginID)
+}
+
+// PluginIDFromContext returns the plugin set by WithPluginID
+func PluginIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(pluginIDKey{}).(string)
+	return id
 }
 
 // NewAPIImpl creates a new API implementation
 func NewAPIImpl(prData *ioPR.PR, permissions []string) *APIImpl {
 	api := &APIImpl{
 		prData:      prData,
-		hooks:       make(map[string][]func(interface{}) error),
+		hooks:       make(map[string][]func(context.Context, interface{}) error),
 		settings:    make(map[string]interface{}),
 		permissions: make(map[string]bool),
+		saveType:    global.PC,
+		newSaveGate: func() *validation.SaveGate {
+			return validation.NewSaveGate(validation.NewValidator(), false, true)
+		},
 	}
 
 	// Set permissions
@@ -119,7 +149,27 @@ func (a *APIImpl) SetDialogFunctions(
 	}
 }
 
-// GetCharacter retrieves a character by name
+// SetSaveData points the API at another save and the file format SaveFile
+// writes it in, e.g. when the editor opens a file
+func (a *APIImpl) SetSaveData(prData *ioPR.PR, saveType global.SaveFileType) {
+	a.prData = prData
+	a.saveType = saveType
+}
+
+// SetSaveGate sets how SaveFile creates its pre-save check. It is called for
+// every save, so the check follows the current validation settings.
+func (a *APIImpl) SetSaveGate(newGate func() *validation.SaveGate) {
+	if newGate != nil {
+		a.newSaveGate = newGate
+	}
+}
+
+// SetAuditLogger sets the log that records the outcome of plugin saves
+func (a *APIImpl) SetAuditLogger(logger *AuditLogger) {
+	a.auditLogger = logger
+}
+
+// GetCharacter returns a copy of a loaded character, found by name
 func (a *APIImpl) GetCharacter(ctx context.Context, name string) (*models.Character, error) {
 	if !a.HasPermission(CommonPermissions.ReadSave) {
 		return nil, ErrInsufficientPermissions
@@ -129,173 +179,15 @@ func (a *APIImpl) GetCharacter(ctx context.Context, name string) (*models.Charac
 		return nil, ErrNilPRData
 	}
 
-	// Extract character from PR Characters OrderedMaps
-	for _, charMap := range a.prData.Characters {
-		if charMap == nil {
-			continue
-		}
-		
-		// Get character name from OrderedMap
-		charNameVal := charMap.Get("name")
-		if charNameVal == nil {
-			continue
-		}
-		charName, ok := charNameVal.(string)
-		if !ok || charName != name {
-			continue
-		}
-
-		// Get base character model
-		char := modelsPR.GetCharacter(name)
-		if char == nil {
-			continue
-		}
-
-		// Extract enabled status
-		if enabledVal := charMap.Get("isEnableCorps"); enabledVal != nil {
-			if enabled, ok := enabledVal.(bool); ok {
-				char.IsEnabled = enabled
-			}
-		}
-
-		// Extract experience
-		if expVal := charMap.Get("currentExp"); expVal != nil {
-			if expNum, ok := expVal.(json.Number); ok {
-				if exp, err := expNum.Int64(); err == nil {
-					char.Exp = int(exp)
-				}
-			}
-		}
-
-		// Extract character ID and job ID for base stats lookup
-		var charID, jobID int
-		if idVal := charMap.Get("id"); idVal != nil {
-			if idNum, ok := idVal.(json.Number); ok {
-				if id, err := idNum.Int64(); err == nil {
-					charID = int(id)
-					char.ID = charID
-				}
-			}
-		}
-		if jobIDVal := charMap.Get("jobId"); jobIDVal != nil {
-			if jobIDNum, ok := jobIDVal.(json.Number); ok {
-				if id, err := jobIDNum.Int64(); err == nil {
-					jobID = int(id)
-				}
-			}
-		}
-
-		// Get base stats for HP/MP calculation
-		baseOffset, _ := modelsPR.GetCharacterBaseOffset(charID, jobID)
-
-		// Extract parameter nested object for stats
-		if paramVal := charMap.Get("parameter"); paramVal != nil {
-			if paramStr, ok := paramVal.(string); ok {
-				// Parameter is stored as JSON string, need to unmarshal
-				paramMap := jo.NewOrderedMap()
-				if err := paramMap.UnmarshalJSON([]byte(paramStr)); err == nil {
-					// Extract HP
-					if hpVal := paramMap.Get("currentHP"); hpVal != nil {
-						if hpNum, ok := hpVal.(json.Number); ok {
-							if hp, err := hpNum.Int64(); err == nil {
-								char.HP.Current = int(hp)
-							}
-						}
-					}
-					if maxHPVal := paramMap.Get("addtionalMaxHp"); maxHPVal != nil {
-						if maxHPNum, ok := maxHPVal.(json.Number); ok {
-							if maxHP, err := maxHPNum.Int64(); err == nil {
-								char.HP.Max = int(maxHP)
-								if baseOffset != nil {
-									char.HP.Max += baseOffset.HPBase
-								}
-							}
-						}
-					}
-
-					// Extract MP
-					if mpVal := paramMap.Get("currentMP"); mpVal != nil {
-						if mpNum, ok := mpVal.(json.Number); ok {
-							if mp, err := mpNum.Int64(); err == nil {
-								char.MP.Current = int(mp)
-							}
-						}
-					}
-					if maxMPVal := paramMap.Get("addtionalMaxMp"); maxMPVal != nil {
-						if maxMPNum, ok := maxMPVal.(json.Number); ok {
-							if maxMP, err := maxMPNum.Int64(); err == nil {
-								char.MP.Max = int(maxMP)
-								if baseOffset != nil {
-									char.MP.Max += baseOffset.MPBase
-								}
-							}
-						}
-					}
-
-					// Extract Level
-					if levelVal := paramMap.Get("addtionalLevel"); levelVal != nil {
-						if levelNum, ok := levelVal.(json.Number); ok {
-							if level, err := levelNum.Int64(); err == nil {
-								char.Level = int(level)
-							}
-						}
-					}
-
-					// Extract Vigor (Power)
-					if vigorVal := paramMap.Get("addtionalPower"); vigorVal != nil {
-						if vigorNum, ok := vigorVal.(json.Number); ok {
-							if vigor, err := vigorNum.Int64(); err == nil {
-								char.Vigor = int(vigor)
-							}
-						}
-					}
-
-					// Extract Stamina (Vitality)
-					if staminaVal := paramMap.Get("addtionalVitality"); staminaVal != nil {
-						if staminaNum, ok := staminaVal.(json.Number); ok {
-							if stamina, err := staminaNum.Int64(); err == nil {
-								char.Stamina = int(stamina)
-							}
-						}
-					}
-
-					// Extract Speed (Agility)
-					if speedVal := paramMap.Get("addtionalAgility"); speedVal != nil {
-						if speedNum, ok := speedVal.(json.Number); ok {
-							if speed, err := speedNum.Int64(); err == nil {
-								char.Speed = int(speed)
-							}
-						}
-					}
-
-					// Extract Magic
-					if magicVal := paramMap.Get("addtionMagic"); magicVal != nil {
-						if magicNum, ok := magicVal.(json.Number); ok {
-							if magic, err := magicNum.Int64(); err == nil {
-								char.Magic = int(magic)
-							}
-						}
-					}
-				}
-			}
-		}
-
-		// Extract equipment
-		a.extractEquipment(charMap, char)
-
-		// Extract spells/magic
-		a.extractSpells(charMap, char)
-
-		// Extract commands
-		a.extractCommands(charMap, char)
-
-		return char, nil
+	c := a.findCharacter(name)
+	if c == nil {
+		return nil, ErrCharacterNotFound
 	}
-
-	return nil, ErrCharacterNotFound
+	return copyCharacter(c), nil
 }
 
-// SetCharacter updates a character with full data modification
+// SetCharacter writes the fields of ch that differ from the named character
+// to the character model as one edit, which the editor can undo
 func (a *APIImpl) SetCharacter(ctx context.Context, name string, ch *models.Character) error {
 	if !a.HasPermission(CommonPermissions.WriteSave) {
 		return ErrInsufficientPermissions
@@ -305,74 +197,16 @@ func (a *APIImpl) SetCharacter(ctx context.Context, name string, ch *models.Char
 		return ErrNilPRData
 	}
 
-	// Find and update character in PR Characters OrderedMaps
-	for _, charMap := range a.prData.Characters {
-		if charMap == nil {
-			continue
-		}
-
-		// Check if this is the target character
-		charNameVal := charMap.Get("name")
-		if charNameVal == nil {
-			continue
-		}
-		charName, ok := charNameVal.(string)
-		if !ok || charName != name {
-			continue
-		}
-
-		// Update basic character fields
-		charMap.Set("name", ch.Name)
-		charMap.Set("isEnableCorps", ch.IsEnabled)
-		
-		// Update experience
-		charMap.Set("currentExp", json.Number(fmt.Sprintf("%d", ch.Exp)))
-
-		// Get base stats for HP/MP calculation
-		var charID, jobID int
-		if idVal := charMap.Get("id"); idVal != nil {
-			if idNum, ok := idVal.(json.Number); ok {
-				if id, err := idNum.Int64(); err == nil {
-					charID = int(id)
-				}
-			}
-		}
-		if jobIDVal := charMap.Get("jobId"); jobIDVal != nil {
-			if jobIDNum, ok := jobIDVal.(json.Number); ok {
-				if id, err := jobIDNum.Int64(); err == nil {
-					jobID = int(id)
-				}
-			}
-		}
-		baseOffset, _ := modelsPR.GetCharacterBaseOffset(charID, jobID)
-
-		// Update parameter nested object for stats
-		if err := a.updateParameter(charMap, ch, baseOffset); err != nil {
-			return fmt.Errorf("failed to update parameter: %w", err)
-		}
-
-		// Update equipment
-		if err := a.updateEquipment(charMap, ch); err != nil {
-			return fmt.Errorf("failed to update equipment: %w", err)
-		}
-
-		// Update spells
-		if err := a.updateSpells(charMap, ch); err != nil {
-			return fmt.Errorf("failed to update spells: %w", err)
-		}
-
-		// Update commands
-		if err := a.updateCommands(charMap, ch); err != nil {
-			return fmt.Errorf("failed to update commands: %w", err)
-		}
-
-		return nil
+	c := a.findCharacter(name)
+	if c == nil {
+		return ErrCharacterNotFound
 	}
-
-	return ErrCharacterNotFound
+	return ioPR.Changes.Edit("Set "+c.Name, func() error {
+		return setPaths(characterEdits(c, ch))
+	})
 }
 
-// GetInventory retrieves the inventory
+// GetInventory returns a copy of the inventory
 func (a *APIImpl) GetInventory(ctx context.Context) (*modelsPR.Inventory, error) {
 	if !a.HasPermission(CommonPermissions.ReadSave) {
 		return nil, ErrInsufficientPermissions
@@ -382,13 +216,11 @@ func (a *APIImpl) GetInventory(ctx context.Context) (*modelsPR.Inventory, error)
 		return nil, ErrNilPRData
 	}
 
-	// Return the cached inventory instance
-	// In production, this would extract from UserData OrderedMap
-	inventory := modelsPR.GetInventory()
-	return inventory, nil
+	return copyInventory(modelsPR.GetInventory()), nil
 }
 
-// SetInventory updates the inventory
+// SetInventory replaces the inventory rows with the owned rows of inv, in
+// their order, as one edit
 func (a *APIImpl) SetInventory(ctx context.Context, inv *modelsPR.Inventory) error {
 	if !a.HasPermission(CommonPermissions.WriteSave) {
 		return ErrInsufficientPermissions
@@ -398,38 +230,42 @@ func (a *APIImpl) SetInventory(ctx context.Context, inv *modelsPR.Inventory) err
 		return ErrNilPRData
 	}
 
-	// Serialize inventory rows to JSON strings
-	rows := inv.GetRows()
-	itemStrings := make([]interface{}, 0, len(rows))
-	
-	for _, r := range rows {
+	current := modelsPR.GetInventory()
+	owned := make(map[int]bool)
+	for _, r := range current.Rows {
+		if r != nil && r.Count > 0 {
+			owned[r.ItemID] = true
+		}
+	}
+
+	rows := make([]modelsPR.Row, 0, len(inv.Rows))
+	for n, r := range inv.Rows {
 		// Skip empty rows
-		if r.ItemID == 0 || r.Count == 0 {
+		if r == nil || r.ItemID == 0 || r.Count == 0 {
 			continue
 		}
-		
-		// Create item object
-		itemJSON, err := json.Marshal(r)
-		if err != nil {
-			return fmt.Errorf("failed to marshal inventory item: %w", err)
+		if _, known := constsPR.ItemsByID[r.ItemID]; !known && !owned[r.ItemID] {
+			return fmt.Errorf("inventory row %d: unknown item %d", n, r.ItemID)
 		}
-		itemStrings = append(itemStrings, string(itemJSON))
-	}
-	
-	// Update normalOwnedItemList in UserData
-	itemListObj := jo.NewOrderedMap()
-	itemListObj.Set("target", itemStrings)
-	
-	itemListJSON, err := itemListObj.MarshalJSON()
-	if err != nil {
-		return fmt.Errorf("failed to marshal item list: %w", err)
-	}
-	
-	a.prData.UserData.Set("normalOwnedItemList", string(itemListJSON))
-	return nil
+		if r.Count < 0 || r.Count > modelsPR.MaxItemCount {
+			return fmt.Errorf("inventory row %d: count %d out of range 1-%d", n, r.Count, modelsPR.MaxItemCount)
+		}
+		rows = append(rows, *r)
+	}
+	if len(rows) > current.Size {
+		return fmt.Errorf("%d inventory rows don't fit in %d slots", len(rows), current.Size)
+	}
+
+	return ioPR.Changes.Edit("Set inventory", func() error {
+		current.Reset()
+		for n, r := range rows {
+			current.Set(n, r)
+		}
+		return nil
+	})
 }
 
-// GetParty retrieves the party
+// GetParty returns a copy of the party
 func (a *APIImpl) GetParty(ctx context.Context) (*modelsPR.Party, error) {
 	if !a.HasPermission(CommonPermissions.ReadSave) {
 		return nil, ErrInsufficientPermissions
@@ -439,13 +275,11 @@ func (a *APIImpl) GetParty(ctx context.Context) (*modelsPR.Party, error) {
 		return nil, ErrNilPRData
 	}
 
-	// Return the cached party instance
-	// In production, this would extract from UserData OrderedMap
-	party := modelsPR.GetParty()
-	return party, nil
+	return copyParty(modelsPR.GetParty()), nil
 }
 
-// SetParty updates the party
+// SetParty puts the characters of party's members, by character ID, in the
+// party as one edit. A nil member or ID 0 empties the slot.
 func (a *APIImpl) SetParty(ctx context.Context, party *modelsPR.Party) error {
 	if !a.HasPermission(CommonPermissions.WriteSave) {
 		return ErrInsufficientPermissions
@@ -455,62 +289,26 @@ func (a *APIImpl) SetParty(ctx context.Context, party *modelsPR.Party) error {
 		return ErrNilPRData
 	}
 
-	// Get existing party ID (or default to 1)
-	partyID := 1
-	corpsListValue := a.prData.UserData.Get("corpsList")
-	if corpsListValue != nil {
-		corpsListStr, ok := corpsListValue.(string)
-		if ok {
-			corpsListObj := jo.NewOrderedMap()
-			if err := corpsListObj.UnmarshalJSON([]byte(corpsListStr)); err == nil {
-				targetValue := corpsListObj.Get("target")
-				if targetArray, ok := targetValue.([]interface{}); ok && len(targetArray) > 0 {
-					if firstMemberStr, ok := targetArray[0].(string); ok {
-						var pm struct {
-							ID          int `json:"id"`
-							CharacterID int `json:"characterId"`
-						}
-						if err := json.Unmarshal([]byte(firstMemberStr), &pm); err == nil {
-							partyID = pm.ID
-						}
-					}
-				}
+	current := modelsPR.GetParty()
+	return ioPR.Changes.Edit("Set party", func() error {
+		for slot, m := range party.Members {
+			id := modelsPR.EmptyPartyMember.CharacterID
+			if m != nil {
+				id = m.CharacterID
 			}
+			if cm := current.Members[slot]; cm != nil && cm.CharacterID == id {
+				continue
+			}
+			if err := current.SetMemberByID(slot, id); err != nil {
+				return fmt.Errorf("party slot %d: %w", slot, err)
+			}
+			current.Enabled = true
 		}
-	}
-	
-	// Serialize party members to JSON strings
-	memberStrings := make([]interface{}, 4)
-	for i, member := range party.Members {
-		pm := struct {
-			ID          int `json:"id"`
-			CharacterID int `json:"characterId"`
-		}{
-			ID:          partyID,
-			CharacterID: member.CharacterID,
-		}
-		
-		memberJSON, err := json.Marshal(pm)
-		if err != nil {
-			return fmt.Errorf("failed to marshal party member %d: %w", i, err)
-		}
-		memberStrings[i] = string(memberJSON)
-	}
-	
-	// Update corpsList in UserData
-	corpsListObj := jo.NewOrderedMap()
-	corpsListObj.Set("target", memberStrings)
-	
-	corpsListJSON, err := corpsListObj.MarshalJSON()
-	if err != nil {
-		return fmt.Errorf("failed to marshal corps list: %w", err)
-	}
-	
-	a.prData.UserData.Set("corpsList", string(corpsListJSON))
-	return nil
+		return nil
+	})
 }
 
-// GetEquipment retrieves equipment
+// GetEquipment returns a copy of the equipment of the first character
 func (a *APIImpl) GetEquipment(ctx context.Context) (*models.Equipment, error) {
 	if !a.HasPermission(CommonPermissions.ReadSave) {
 		return nil, ErrInsufficientPermissions
@@ -520,15 +318,16 @@ func (a *APIImpl) GetEquipment(ctx context.Context) (*models.Equipment, error) {
 		return nil, ErrNilPRData
 	}
 
-	// Extract equipment from UserData OrderedMap
-	equipment := &models.Equipment{}
-	// In production, this would extract equipment data from UserData
-	// For now, return empty equipment structure
-	return equipment, nil
+	characters := a.prData.LoadedCharacters()
+	if len(characters) == 0 {
+		return nil, ErrCharacterNotFound
+	}
+	eq := characters[0].Equipment
+	return &eq, nil
 }
 
-// SetEquipment updates equipment for the first character (standalone method)
-// Note: For character-specific equipment updates, use SetCharacter() instead
+// SetEquipment writes the equipment of the first character as one edit. Use
+// SetCharacter for the other characters.
 func (a *APIImpl) SetEquipment(ctx context.Context, eq *models.Equipment) error {
 	if !a.HasPermission(CommonPermissions.WriteSave) {
 		return ErrInsufficientPermissions
@@ -538,54 +337,61 @@ func (a *APIImpl) SetEquipment(ctx context.Context, eq *models.Equipment) error
 		return ErrNilPRData
 	}
 
-	// Find first character in the party to update equipment
-	// This is a convenience method - use SetCharacter for specific character updates
-	if len(a.prData.Characters) == 0 {
-		return fmt.Errorf("no characters found")
-	}
-	
-	firstChar := a.prData.Characters[0]
-	if firstChar == nil {
-		return fmt.Errorf("first character is nil")
-	}
-	
-	// Create equipment OrderedMap
-	equipmentMap := jo.NewOrderedMap()
-	values := make([]interface{}, 6)
-	
-	// Build equipment array in order: Weapon, Shield, Armor, Helmet, Relic1, Relic2
-	equipIDs := []int{
-		eq.WeaponID,
-		eq.ShieldID,
-		eq.ArmorID,
-		eq.HelmetID,
-		eq.Relic1ID,
-		eq.Relic2ID,
-	}
-	
-	for i, equipID := range equipIDs {
-		itemMap := make(map[string]interface{})
-		itemMap["contentId"] = json.Number(fmt.Sprintf("%d", equipID))
-		itemMap["count"] = json.Number("1")
-		
-		itemJSON, err := json.Marshal(itemMap)
-		if err != nil {
-			return fmt.Errorf("failed to marshal equipment slot %d: %w", i, err)
-		}
-		values[i] = string(itemJSON)
-	}
-	
-	equipmentMap.Set("values", values)
-	
-	// Marshal to JSON string
-	equipmentJSON, err := equipmentMap.MarshalJSON()
-	if err != nil {
-		return fmt.Errorf("failed to marshal equipment: %w", err)
-	}
-	
-	// Update equipmentList field in character
-	firstChar.Set("equipmentList", string(equipmentJSON))
-	return nil
+	characters := a.prData.LoadedCharacters()
+	if len(characters) == 0 {
+		return ErrCharacterNotFound
+	}
+	c := characters[0]
+	return ioPR.Changes.Edit("Set equipment", func() error {
+		return setPaths(equipmentEdits(c, *eq))
+	})
+}
+
+// SaveFile writes the save data to path once it passes the pre-save check.
+// Issues the check would ask about go to the confirm dialog with the issue
+// list; the outcome is recorded in the audit log under the plugin of ctx.
+func (a *APIImpl) SaveFile(ctx context.Context, path string) error {
+	if !a.HasPermission(CommonPermissions.WriteSave) {
+		return ErrInsufficientPermissions
+	}
+	if a.prData == nil {
+		return ErrNilPRData
+	}
+
+	start := time.Now()
+	confirm := func(check validation.SaveCheck) bool {
+		return a.showConfirmFn("Validation Issues", check.Summary(maxConfirmIssues)+"\n\nSave anyway?")
+	}
+	check, err := a.newSaveGate().Save(a.prData, confirm, func() error {
+		return a.prData.Save(a.prData.SlotID(), path, a.saveType)
+	})
+
+	if a.auditLogger != nil {
+		a.auditLogger.LogSaveValidation(PluginIDFromContext(ctx), path, string(check.Decision),
+			len(check.Result.Errors), len(check.Result.Warnings), check.Fixed, time.Since(start).Microseconds(), err)
+	}
+	return err
+}
+
+// GetEquipmentInfo returns a copy of the slot, users, stats and properties of
+// an item. Game data needs no permission.
+func (a *APIImpl) GetEquipmentInfo(ctx context.Context, itemID int) (*game.EquipmentInfo, error) {
+	if info, found := game.GetEquipmentInfo(itemID); found {
+		return info.Copy(), nil
+	}
+	return nil, ErrEquipmentNotFound
+}
+
+// ListEquipment returns copies of every item of a type in ID order
+func (a *APIImpl) ListEquipment(ctx context.Context, equipType game.EquipType) ([]*game.EquipmentInfo, error) {
+	items := game.EquipmentOfType(equipType)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("unknown equipment type %q", equipType)
+	}
+	for i, item := range items {
+		items[i] = item.Copy()
+	}
+	return items, nil
 }
 
 // ApplyBatchOperation applies a batch operation
@@ -605,36 +411,8 @@ func (a *APIImpl) FindCharacter(ctx context.Context, predicate func(*models.Char
 		return nil
 	}
 
-	// Extract and search characters from PR data structure
-	for _, charMap := range a.prData.Characters {
-		if charMap == nil {
-			continue
-		}
-
-		// Get character name
-		charNameVal := charMap.Get("name")
-		if charNameVal == nil {
-			continue
-		}
-		charName, ok := charNameVal.(string)
-		if !ok {
-			continue
-		}
-
-		// Get base character model
-		char := modelsPR.GetCharacter(charName)
-		if char == nil {
-			continue
-		}
-
-		// Extract full character data (reuse GetCharacter logic)
-		// This ensures consistent data extraction
-		if fullChar, err := a.GetCharacter(ctx, charName); err == nil {
-			char = fullChar
-		}
-
-		// Test predicate
-		if predicate(char) {
+	for _, c := range a.prData.LoadedCharacters() {
+		if char := copyCharacter(c); predicate(char) {
 			return char
 		}
 	}
@@ -663,8 +441,9 @@ func (a *APIImpl) FindItems(ctx context.Context, predicate func(*modelsPR.Row) b
 	return items
 }
 
-// RegisterHook registers a hook callback
-func (a *APIImpl) RegisterHook(event string, callback func(interface{}) error) error {
+// RegisterHook registers a hook callback; it gets the context the event was
+// fired with
+func (a *APIImpl) RegisterHook(event string, callback func(ctx context.Context, data interface{}) error) error {
 	if callback == nil {
 		return ErrNilCallback
 	}
@@ -681,7 +460,7 @@ func (a *APIImpl) FireEvent(ctx context.Context, event string, data interface{})
 	}
 
 	for _, callback := range callbacks {
-		if err := callback(data); err != nil {
+		if err := callback(ctx, data); err != nil {
 			a.logger("error", "hook callback error: "+err.Error())
 		}
 	}
@@ -743,108 +522,3 @@ func (a *APIImpl) SetCharacterStat(charID int, stat string, value int) error {
 	// TODO: Set stat in PR save
 	return nil
 }
-
-// extractEquipment extracts equipment slots from character data
-func (a *APIImpl) extractEquipment(charMap *jo.OrderedMap, char *models.Character) {
-	// Equipment is stored as JSON string in equipmentList field
-	if eqListVal := charMap.Get("equipmentList"); eqListVal != nil {
-		if eqListStr, ok := eqListVal.(string); ok {
-			eqMap := jo.NewOrderedMap()
-			if err := eqMap.UnmarshalJSON([]byte(eqListStr)); err == nil {
-				// Equipment is stored as array of {contentId, count} objects
-				if valuesVal := eqMap.Get("values"); valuesVal != nil {
-					if valuesArr, ok := valuesVal.([]interface{}); ok {
-						// Slots: 0=Weapon, 1=Shield, 2=Armor, 3=Helmet, 4=Relic1, 5=Relic2
-						for i, itemVal := range valuesArr {
-							if itemMap, ok := itemVal.(map[string]interface{}); ok {
-								if contentIDVal, ok := itemMap["contentId"]; ok {
-									if contentIDNum, ok := contentIDVal.(json.Number); ok {
-										if contentID, err := contentIDNum.Int64(); err == nil {
-											switch i {
-											case 0:
-												char.Equipment.WeaponID = int(contentID)
-											case 1:
-												char.Equipment.ShieldID = int(contentID)
-											case 2:
-												char.Equipment.ArmorID = int(contentID)
-											case 3:
-												char.Equipment.HelmetID = int(contentID)
-											case 4:
-												char.Equipment.Relic1ID = int(contentID)
-											case 5:
-												char.Equipment.Relic2ID = int(contentID)
-											}
-										}
-									}
-								}
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-}
-
-// extractSpells extracts learned spells/magic from character data
-func (a *APIImpl) extractSpells(charMap *jo.OrderedMap, char *models.Character) {
-	// Spells are stored in abilityList as JSON string
-	if abilityListVal := charMap.Get("abilityList"); abilityListVal != nil {
-		if abilityListStr, ok := abilityListVal.(string); ok {
-			abilityMap := jo.NewOrderedMap()
-			if err := abilityMap.UnmarshalJSON([]byte(abilityListStr)); err == nil {
-				if valuesVal := abilityMap.Get("values"); valuesVal != nil {
-					if valuesArr, ok := valuesVal.([]interface{}); ok {
-						for _, abilityVal := range valuesArr {
-							if abilityStr, ok := abilityVal.(string); ok {
-								abilityObj := jo.NewOrderedMap()
-								if err := abilityObj.UnmarshalJSON([]byte(abilityStr)); err == nil {
-									// Get ability ID
-									if abilityIDVal := abilityObj.Get("abilityId"); abilityIDVal != nil {
-										if abilityIDNum, ok := abilityIDVal.(json.Number); ok {
-											if abilityID, err := abilityIDNum.Int64(); err == nil {
-												// Check if it's a spell (spells are in specific ID range)
-												if abilityID >= 1 && abilityID <= 54 {
-													if spell, ok := char.SpellsByID[int(abilityID)]; ok {
-														// Get skill level (spell proficiency)
-														if skillLevelVal := abilityObj.Get("skillLevel"); skillLevelVal != nil {
-															if skillLevelNum, ok := skillLevelVal.(json.Number); ok {
-																if skillLevel, err := skillLevelNum.Int64(); err == nil {
-																	spell.Value = int(skillLevel)
-																}
-															}
-														}
-													}
-												}
-											}
-										}
-									}
-								}
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-}
-
-// extractCommands extracts command list from character data
-func (a *APIImpl) extractCommands(charMap *jo.OrderedMap, char *models.Character) {
-	// Commands are stored as array of command IDs
-	if commandListVal := charMap.Get("commandList"); commandListVal != nil {
-		if commandArr, ok := commandListVal.([]interface{}); ok {
-			char.Commands = make([]*models.Command, 0, len(commandArr))
-			for _, cmdVal := range commandArr {
-				if cmdNum, ok := cmdVal.(json.Number); ok {
-					if cmdID, err := cmdNum.Int64(); err == nil {
-						// Look up command by ID from constants
-						if cmd := constsPR.CommandLookupByValue[int(cmdID)]; cmd != nil {
-							char.Commands = append(char.Commands, cmd)
-						}
-					}
-				}
-			}
-		}
-	}
-}
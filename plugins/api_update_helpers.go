@@ -1,167 +1,194 @@
 package plugins
 
 import (
-	"encoding/json"
-	"fmt"
+	"strconv"
+
+	ioPR "ffvi_editor/io/pr"
 	"ffvi_editor/models"
 	modelsPR "ffvi_editor/models/pr"
-	jo "gitlab.com/c0b/go-ordered-json"
 )
 
-// Update helper methods for SetCharacter
+// Helpers for the API setters, which write the models through the logical
+// save paths of io/pr so values are checked and edits can be undone
+
+// pathValue is a value to write at a logical save path
+type pathValue struct {
+	path  string
+	value interface{}
+}
 
-// updateParameter updates the parameter nested object with character stats
-func (a *APIImpl) updateParameter(charMap *jo.OrderedMap, char *models.Character, baseOffset *modelsPR.CharacterBase) error {
-	// Get existing parameter or create new one
-	paramMap := jo.NewOrderedMap()
-	if paramVal := charMap.Get("parameter"); paramVal != nil {
-		if paramStr, ok := paramVal.(string); ok {
-			// Unmarshal existing parameter
-			if err := paramMap.UnmarshalJSON([]byte(paramStr)); err != nil {
-				return fmt.Errorf("failed to unmarshal parameter: %w", err)
-			}
+// setPaths writes the values in order and stops at the first that fails
+func setPaths(values []pathValue) error {
+	for _, v := range values {
+		if err := ioPR.SetPath(v.path, v.value); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	// Update HP
-	paramMap.Set("currentHP", json.Number(fmt.Sprintf("%d", char.HP.Current)))
-	
-	// Calculate additional max HP (subtract base from max)
-	additionalMaxHP := char.HP.Max
-	if baseOffset != nil {
-		additionalMaxHP -= baseOffset.HPBase
-	}
-	paramMap.Set("addtionalMaxHp", json.Number(fmt.Sprintf("%d", additionalMaxHP)))
-
-	// Update MP
-	paramMap.Set("currentMP", json.Number(fmt.Sprintf("%d", char.MP.Current)))
-	
-	// Calculate additional max MP (subtract base from max)
-	additionalMaxMP := char.MP.Max
-	if baseOffset != nil {
-		additionalMaxMP -= baseOffset.MPBase
-	}
-	paramMap.Set("addtionalMaxMp", json.Number(fmt.Sprintf("%d", additionalMaxMP)))
-
-	// Update Level
-	paramMap.Set("addtionalLevel", json.Number(fmt.Sprintf("%d", char.Level)))
-
-	// Update Vigor (Power)
-	paramMap.Set("addtionalPower", json.Number(fmt.Sprintf("%d", char.Vigor)))
-
-	// Update Stamina (Vitality)
-	paramMap.Set("addtionalVitality", json.Number(fmt.Sprintf("%d", char.Stamina)))
-
-	// Update Speed (Agility)
-	paramMap.Set("addtionalAgility", json.Number(fmt.Sprintf("%d", char.Speed)))
-
-	// Update Magic
-	paramMap.Set("addtionMagic", json.Number(fmt.Sprintf("%d", char.Magic)))
-
-	// Marshal parameter back to JSON string
-	paramJSON, err := paramMap.MarshalJSON()
-	if err != nil {
-		return fmt.Errorf("failed to marshal parameter: %w", err)
+// findCharacter returns the loaded character with a name, or root name
+func (a *APIImpl) findCharacter(name string) *models.Character {
+	characters := a.prData.LoadedCharacters()
+	for _, c := range characters {
+		if c.Name == name {
+			return c
+		}
+	}
+	for _, c := range characters {
+		if c.RootName == name {
+			return c
+		}
 	}
-
-	// Set parameter as JSON string
-	charMap.Set("parameter", string(paramJSON))
-
 	return nil
 }
 
-// updateEquipment updates equipment slots in character data
-func (a *APIImpl) updateEquipment(charMap *jo.OrderedMap, char *models.Character) error {
-	// Create equipment structure
-	eqMap := jo.NewOrderedMap()
-	
-	// Create values array with equipment slots
-	values := make([]interface{}, 6)
-	
-	// Equipment slots: 0=Weapon, 1=Shield, 2=Armor, 3=Helmet, 4=Relic1, 5=Relic2
-	equipmentIDs := []int{
-		char.Equipment.WeaponID,
-		char.Equipment.ShieldID,
-		char.Equipment.ArmorID,
-		char.Equipment.HelmetID,
-		char.Equipment.Relic1ID,
-		char.Equipment.Relic2ID,
+// characterEdits lists the fields of ch that differ from c
+func characterEdits(c, ch *models.Character) []pathValue {
+	root := ioPR.JoinPath(ioPR.PathCharacters, c.RootName) + "/"
+	var values []pathValue
+	add := func(field string, old, value interface{}) {
+		if old != value {
+			values = append(values, pathValue{root + field, value})
+		}
 	}
 
-	// Build equipment array
-	for i, equipID := range equipmentIDs {
-		itemMap := make(map[string]interface{})
-		itemMap["contentId"] = json.Number(fmt.Sprintf("%d", equipID))
-		itemMap["count"] = json.Number("1")
-		values[i] = itemMap
+	add("name", c.Name, ch.Name)
+	add("enabled", c.IsEnabled, ch.IsEnabled)
+	add("level", c.Level, ch.Level)
+	add("exp", c.Exp, ch.Exp)
+	add("maxHp", c.HP.Max, ch.HP.Max)
+	add("hp", c.HP.Current, ch.HP.Current)
+	add("maxMp", c.MP.Max, ch.MP.Max)
+	add("mp", c.MP.Current, ch.MP.Current)
+	add("vigor", c.Vigor, ch.Vigor)
+	add("stamina", c.Stamina, ch.Stamina)
+	add("speed", c.Speed, ch.Speed)
+	add("magic", c.Magic, ch.Magic)
+	add("esper", c.EsperID, ch.EsperID)
+	values = append(values, equipmentEdits(c, ch.Equipment)...)
+
+	spells := make(map[int]*models.Spell, len(c.SpellsByIndex))
+	for _, s := range c.SpellsByIndex {
+		spells[s.Index] = s
 	}
-
-	eqMap.Set("values", values)
-
-	// Marshal equipment to JSON string
-	eqJSON, err := eqMap.MarshalJSON()
-	if err != nil {
-		return fmt.Errorf("failed to marshal equipment: %w", err)
+	for _, s := range ch.SpellsByIndex {
+		if s == nil {
+			continue
+		}
+		old := 0
+		if cs, ok := spells[s.Index]; ok {
+			old = cs.Value
+		}
+		add("spells/"+strconv.Itoa(s.Index), old, s.Value)
 	}
 
-	// Set equipmentList as JSON string
-	charMap.Set("equipmentList", string(eqJSON))
-
-	return nil
+	for i, cmd := range ch.Commands {
+		if cmd == nil || i >= len(c.Commands) {
+			continue
+		}
+		old := -1
+		if c.Commands[i] != nil {
+			old = c.Commands[i].Value
+		}
+		add("commands/"+strconv.Itoa(i), old, cmd.Value)
+	}
+	return values
 }
 
-// updateSpells updates spell list in character data
-func (a *APIImpl) updateSpells(charMap *jo.OrderedMap, char *models.Character) error {
-	// Create ability list structure
-	abilityMap := jo.NewOrderedMap()
-	
-	// Build array of spell JSONs
-	var values []interface{}
-
-	// Iterate through learned spells (filter by spell ID range 1-54)
-	for spellID := 1; spellID <= 54; spellID++ {
-		if spell, ok := char.SpellsByID[spellID]; ok && spell.Value > 0 {
-			// Create ability object for this spell
-			abilityObj := jo.NewOrderedMap()
-			abilityObj.Set("abilityId", json.Number(fmt.Sprintf("%d", spellID)))
-			abilityObj.Set("skillLevel", json.Number(fmt.Sprintf("%d", spell.Value)))
-
-			// Marshal to JSON string
-			abilityJSON, err := abilityObj.MarshalJSON()
-			if err != nil {
-				return fmt.Errorf("failed to marshal ability: %w", err)
-			}
-
-			values = append(values, string(abilityJSON))
+// equipmentEdits lists the slots of eq that differ from c's equipment
+func equipmentEdits(c *models.Character, eq models.Equipment) []pathValue {
+	root := ioPR.JoinPath(ioPR.PathCharacters, c.RootName, "equipment") + "/"
+	var values []pathValue
+	for _, slot := range []struct {
+		name       string
+		old, value int
+	}{
+		{"weapon", c.Equipment.WeaponID, eq.WeaponID},
+		{"shield", c.Equipment.ShieldID, eq.ShieldID},
+		{"helmet", c.Equipment.HelmetID, eq.HelmetID},
+		{"armor", c.Equipment.ArmorID, eq.ArmorID},
+		{"relic1", c.Equipment.Relic1ID, eq.Relic1ID},
+		{"relic2", c.Equipment.Relic2ID, eq.Relic2ID},
+	} {
+		if slot.old != slot.value {
+			values = append(values, pathValue{root + slot.name, slot.value})
 		}
 	}
+	return values
+}
 
-	abilityMap.Set("values", values)
-
-	// Marshal ability list to JSON string
-	abilityListJSON, err := abilityMap.MarshalJSON()
-	if err != nil {
-		return fmt.Errorf("failed to marshal ability list: %w", err)
+// copyCharacter copies a character with its spells and commands, so changes
+// to the copy only reach the save through SetCharacter
+func copyCharacter(c *models.Character) *models.Character {
+	cp := *c
+	cp.SpellsByIndex = make([]*models.Spell, len(c.SpellsByIndex))
+	cp.SpellsByID = make(map[int]*models.Spell, len(c.SpellsByID))
+	spells := make(map[*models.Spell]*models.Spell, len(c.SpellsByIndex))
+	copySpell := func(s *models.Spell) *models.Spell {
+		if s == nil {
+			return nil
+		}
+		if sc, ok := spells[s]; ok {
+			return sc
+		}
+		sc := *s
+		spells[s] = &sc
+		return &sc
+	}
+	for i, s := range c.SpellsByIndex {
+		cp.SpellsByIndex[i] = copySpell(s)
+	}
+	if c.SpellsSorted != nil {
+		cp.SpellsSorted = make([]*models.Spell, len(c.SpellsSorted))
+		for i, s := range c.SpellsSorted {
+			cp.SpellsSorted[i] = copySpell(s)
+		}
+	}
+	for id, s := range c.SpellsByID {
+		cp.SpellsByID[id] = copySpell(s)
 	}
 
-	// Set abilityList as JSON string
-	charMap.Set("abilityList", string(abilityListJSON))
-
-	return nil
-}
-
-// updateCommands updates command list in character data
-func (a *APIImpl) updateCommands(charMap *jo.OrderedMap, char *models.Character) error {
-	// Build command ID array
-	commandIDs := make([]interface{}, len(char.Commands))
-	for i, cmd := range char.Commands {
+	cp.Commands = make([]*models.Command, len(c.Commands))
+	for i, cmd := range c.Commands {
 		if cmd != nil {
-			commandIDs[i] = json.Number(fmt.Sprintf("%d", cmd.Value))
+			cc := *cmd
+			cp.Commands[i] = &cc
 		}
 	}
+	cp.StatusEffects = append(cp.StatusEffects[:0:0], c.StatusEffects...)
+	return &cp
+}
 
-	// Set commandList as array
-	charMap.Set("commandList", commandIDs)
+// copyInventory copies an inventory and its rows
+func copyInventory(inv *modelsPR.Inventory) *modelsPR.Inventory {
+	cp := *inv
+	cp.Rows = make([]*modelsPR.Row, len(inv.Rows))
+	for i, r := range inv.Rows {
+		if r != nil {
+			rc := *r
+			cp.Rows[i] = &rc
+		}
+	}
+	return &cp
+}
 
-	return nil
+// copyParty copies a party and its members
+func copyParty(p *modelsPR.Party) *modelsPR.Party {
+	cp := &modelsPR.Party{
+		Possible:      make(map[string]*modelsPR.Member, len(p.Possible)),
+		PossibleNames: append([]string(nil), p.PossibleNames...),
+		Enabled:       p.Enabled,
+	}
+	for name, m := range p.Possible {
+		mc := *m
+		cp.Possible[name] = &mc
+	}
+	for i, m := range p.Members {
+		if m != nil {
+			mc := *m
+			cp.Members[i] = &mc
+		}
+	}
+	return cp
 }
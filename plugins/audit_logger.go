@@ -125,6 +125,26 @@ func (al *AuditLogger) LogPluginExecution(pluginID string, duration int64, succe
 	})
 }
 
+// LogSaveValidation logs the pre-save check of a plugin write. Decision is
+// allowed, confirm or blocked; a blocked or declined write is denied.
+func (al *AuditLogger) LogSaveValidation(pluginID, path, decision string, errors, warnings, fixed int, duration int64, err error) {
+	status, errMsg := "success", ""
+	if err != nil {
+		status, errMsg = "error", err.Error()
+		if decision != "allowed" {
+			status = "denied"
+		}
+	}
+
+	al.LogEvent(pluginID, "save_validation", "WRITE", CommonPermissions.WriteSave, status, errMsg, duration, map[string]interface{}{
+		"path":     path,
+		"decision": decision,
+		"errors":   errors,
+		"warnings": warnings,
+		"fixed":    fixed,
+	})
+}
+
 // LogSecurityViolation logs security violations
 func (al *AuditLogger) LogSecurityViolation(pluginID, violationType, description string) {
 	al.LogEvent(pluginID, "security_violation", "SECURITY", "", "denied", description, 0, map[string]interface{}{
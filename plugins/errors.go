@@ -11,6 +11,7 @@ var (
 	ErrInvalidPluginAuthor       = fmt.Errorf("plugin author is invalid")
 	ErrNilPRData                 = fmt.Errorf("PR data is nil")
 	ErrCharacterNotFound         = fmt.Errorf("character not found")
+	ErrEquipmentNotFound         = fmt.Errorf("equipment not found")
 	ErrInsufficientPermissions   = fmt.Errorf("insufficient permissions for this operation")
 	ErrNilCallback               = fmt.Errorf("callback function is nil")
 	ErrBatchOpNotSupported       = fmt.Errorf("batch operation is not supported")
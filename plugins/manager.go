@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	ioPR "ffvi_editor/io/pr"
 )
 
 // Manager manages plugin lifecycle and execution
@@ -30,8 +32,14 @@ type Manager struct {
 	securityMgr        *SecurityManager
 	auditLogger        *AuditLogger
 	sandboxMgr         *SandboxManager
+	runner             Runner
 }
 
+// Runner runs a plugin's script when the plugin is executed. The sandbox is
+// nil when sandboxing is off; otherwise the script runs under the plugin's
+// policy and reports the limits it exceeds there.
+type Runner func(ctx context.Context, plugin *Plugin, sandbox *SandboxManager) error
+
 // NewManager creates a new plugin manager
 func NewManager(pluginDir string, api PluginAPI) *Manager {
 	m := &Manager{
@@ -58,6 +66,11 @@ func NewManager(pluginDir string, api PluginAPI) *Manager {
 		sandboxMgr:         NewSandboxManager(),
 	}
 
+	// Plugin saves are audited with the rest of the plugin activity
+	if impl, ok := api.(*APIImpl); ok {
+		impl.SetAuditLogger(m.auditLogger)
+	}
+
 	// Initialize hot-reload manager
 	hotReload, err := NewHotReloadManager(m)
 	if err != nil {
@@ -298,7 +311,7 @@ func (m *Manager) ExecutePlugin(ctx context.Context, pluginID string) error {
 		// Check sandbox resource limits
 		if m.sandboxMgr != nil && m.sandbox {
 			// Check execution time
-			if violated, reason := m.sandboxMgr.VerifyExecutionTime(pluginID, record.Duration); violated {
+			if ok, reason := m.sandboxMgr.VerifyExecutionTime(pluginID, record.Duration); !ok {
 				if m.auditLogger != nil {
 					m.auditLogger.LogSecurityViolation(pluginID, "timeout", reason)
 				}
@@ -344,13 +357,25 @@ func (m *Manager) ExecutePlugin(ctx context.Context, pluginID string) error {
 	}()
 
 	// Call hook with context timeout
-	execCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	execCtx, cancel := context.WithTimeout(WithPluginID(ctx, pluginID), 30*time.Second)
 	defer cancel()
 
 	// Execute plugin with hook - timeout enforced via execCtx
 	// Note: CallHook signature could be updated to accept context parameter for cleaner async support
 	// Currently context enforced at callsite level via context.WithTimeout
-	if err := plugin.CallHook(HookLoad); err != nil {
+	// The plugin's edits of the save become one undo step
+	m.mu.RLock()
+	run, sandbox := m.runner, m.sandboxMgr
+	if !m.sandbox {
+		sandbox = nil
+	}
+	m.mu.RUnlock()
+	if err := ioPR.Changes.Edit("Plugin: "+plugin.Name, func() error {
+		if err := plugin.CallHook(HookLoad); err != nil || run == nil {
+			return err
+		}
+		return run(execCtx, plugin, sandbox)
+	}); err != nil {
 		// Track error if context was cancelled due to timeout
 		if execCtx.Err() == context.DeadlineExceeded {
 			record.Error = "plugin execution timeout"
@@ -532,6 +557,14 @@ func (m *Manager) SetMaxPlugins(max int) {
 	m.mu.Unlock()
 }
 
+// SetRunner sets what runs a plugin's script when it's executed; without one
+// only the plugin's load hook is called
+func (m *Manager) SetRunner(run Runner) {
+	m.mu.Lock()
+	m.runner = run
+	m.mu.Unlock()
+}
+
 // SetSandboxMode enables/disables sandboxing
 func (m *Manager) SetSandboxMode(enabled bool) {
 	m.mu.Lock()
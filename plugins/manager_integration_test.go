@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"ffvi_editor/models"
+	"ffvi_editor/models/game"
 	modelsPR "ffvi_editor/models/pr"
 )
 
@@ -387,6 +388,18 @@ func (api *testPluginAPI) SetEquipment(ctx context.Context, eq *models.Equipment
 	return nil
 }
 
+func (api *testPluginAPI) SaveFile(ctx context.Context, path string) error {
+	return nil
+}
+
+func (api *testPluginAPI) GetEquipmentInfo(ctx context.Context, itemID int) (*game.EquipmentInfo, error) {
+	return nil, nil
+}
+
+func (api *testPluginAPI) ListEquipment(ctx context.Context, equipType game.EquipType) ([]*game.EquipmentInfo, error) {
+	return nil, nil
+}
+
 func (api *testPluginAPI) ApplyBatchOperation(ctx context.Context, op string, params map[string]interface{}) (int, error) {
 	return 0, nil
 }
@@ -399,7 +412,7 @@ func (api *testPluginAPI) FindItems(ctx context.Context, predicate func(*modelsP
 	return nil
 }
 
-func (api *testPluginAPI) RegisterHook(event string, callback func(interface{}) error) error {
+func (api *testPluginAPI) RegisterHook(event string, callback func(ctx context.Context, data interface{}) error) error {
 	return nil
 }
 
@@ -2,8 +2,19 @@ package plugins
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"ffvi_editor/global"
+	ioPR "ffvi_editor/io/pr"
+	"ffvi_editor/io/validation"
+	"ffvi_editor/models"
+	"ffvi_editor/models/game"
+	modelsPR "ffvi_editor/models/pr"
 )
 
 // TestPluginCreation tests plugin creation and metadata
@@ -161,6 +172,79 @@ func TestAPIPermissions(t *testing.T) {
 	}
 }
 
+// TestEquipmentInfoIsCopied tests that plugins can't change the equipment database
+func TestEquipmentInfoIsCopied(t *testing.T) {
+	api := NewAPIImpl(nil, nil)
+	items, err := api.ListEquipment(context.Background(), game.TypeWeapon)
+	if err != nil || len(items) == 0 {
+		t.Fatalf("ListEquipment failed: %v", err)
+	}
+	id, name := items[0].ID, items[0].Name
+	items[0].Name = "Changed"
+	items[0].EquippableBy = append(items[0].EquippableBy[:0], "Nobody")
+
+	info, err := api.GetEquipmentInfo(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetEquipmentInfo failed: %v", err)
+	}
+	if info.Name != name || info.CanEquip("Nobody") {
+		t.Errorf("the database was changed through a listed item: %+v", info)
+	}
+	info.Attack = -1
+	if db, _ := game.GetEquipmentInfo(id); db.Attack == -1 {
+		t.Error("the database was changed through GetEquipmentInfo")
+	}
+}
+
+// TestAPIEditsAreUndoSteps tests that plugin writes reach the models as one
+// change group each, and only through the setters
+func TestAPIEditsAreUndoSteps(t *testing.T) {
+	save := ioPR.New()
+	if err := save.Load("../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=", global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	ioPR.Changes.Sync()
+	var groups []models.ChangeGroup
+	defer ioPR.Changes.Subscribe(func(g models.ChangeGroup) { groups = append(groups, g) })()
+
+	api := NewAPIImpl(save, []string{CommonPermissions.ReadSave, CommonPermissions.WriteSave})
+	ctx := context.Background()
+	terra, err := api.GetCharacter(ctx, "Terra")
+	if err != nil {
+		t.Fatalf("GetCharacter failed: %v", err)
+	}
+	exp := terra.Exp
+	terra.Exp = exp + 100
+	terra.Vigor = 99
+	if c := modelsPR.GetCharacter("Terra"); c.Exp != exp {
+		t.Fatal("GetCharacter returned the live character")
+	}
+	if err = api.SetCharacter(ctx, "Terra", terra); err != nil {
+		t.Fatalf("SetCharacter failed: %v", err)
+	}
+	if c := modelsPR.GetCharacter("Terra"); c.Exp != exp+100 || c.Vigor != 99 {
+		t.Errorf("SetCharacter didn't write the model: %+v", c)
+	}
+	if len(groups) != 1 || len(groups[0].Changes) != 2 {
+		t.Fatalf("expected one change group with two changes, got %+v", groups)
+	}
+
+	if err = ioPR.Changes.Undo(groups[0].Changes); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if c := modelsPR.GetCharacter("Terra"); c.Exp != exp {
+		t.Errorf("undo left exp at %d, want %d", c.Exp, exp)
+	}
+
+	terra.Level = 150
+	if err = api.SetCharacter(ctx, "Terra", terra); err == nil {
+		t.Error("expected an out of range level to fail")
+	}
+	if c := modelsPR.GetCharacter("Terra"); c.Exp != exp || c.Level == 150 {
+		t.Errorf("a failed SetCharacter should change nothing: %+v", c)
+	}
+}
+
 // TestAPILogging tests API logging
 func TestAPILogging(t *testing.T) {
 	api := NewAPIImpl(nil, []string{})
@@ -189,6 +273,66 @@ func TestAPILogging(t *testing.T) {
 	}
 }
 
+// TestAPISaveFileValidation tests the pre-save check of plugin writes
+func TestAPISaveFileValidation(t *testing.T) {
+	save := ioPR.New()
+	if err := save.Load("../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y=", global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	modelsPR.GetCharacter("Terra").Level = 150
+
+	api := NewAPIImpl(save, []string{CommonPermissions.WriteSave})
+	audit := NewAuditLogger(100)
+	api.SetAuditLogger(audit)
+	ctx := WithPluginID(context.Background(), "test-plugin")
+	file := filepath.Join(t.TempDir(), "slot.sav")
+
+	var prompt string
+	confirm := false
+	api.SetDialogFunctions(nil, func(title, message string) bool {
+		prompt = message
+		return confirm
+	}, nil)
+
+	if err := api.SaveFile(ctx, file); !errors.Is(err, validation.ErrSaveBlocked) {
+		t.Fatalf("SaveFile() error = %v, want ErrSaveBlocked", err)
+	}
+	if !strings.Contains(prompt, "characters/Terra/level") {
+		t.Errorf("prompt should list the level issue:\n%s", prompt)
+	}
+	if _, err := os.Stat(file); err == nil {
+		t.Error("a declined save should not be written")
+	}
+
+	confirm = true
+	if err := api.SaveFile(ctx, file); err != nil {
+		t.Fatalf("SaveFile() error = %v after confirming", err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("confirmed save not written: %v", err)
+	}
+
+	trail := audit.GetPluginAuditTrail("test-plugin")
+	if len(trail) != 2 || trail[0].Status != "denied" || trail[1].Status != "success" {
+		t.Fatalf("unexpected audit trail: %+v", trail)
+	}
+	if trail[1].EventType != "save_validation" || trail[1].Details["decision"] != "confirm" {
+		t.Errorf("unexpected audit event: %+v", trail[1])
+	}
+
+	// The host's settings decide the check, e.g. auto-fixing first
+	prompt = ""
+	api.SetSaveGate(func() *validation.SaveGate {
+		return validation.NewSaveGate(validation.NewValidator(), true, true)
+	})
+	if err := api.SaveFile(ctx, file); err != nil {
+		t.Fatalf("SaveFile() error = %v with auto-fix", err)
+	}
+	if prompt != "" || modelsPR.GetCharacter("Terra").Level == 150 {
+		t.Errorf("auto-fix should repair the level without asking, prompt %q", prompt)
+	}
+}
+
 // TestCommonPermissions tests common permissions constants
 func TestCommonPermissions(t *testing.T) {
 	if CommonPermissions.ReadSave != "read_save" {
@@ -14,6 +14,7 @@ type SandboxPolicy struct {
 	DeniedPermissions  []string // Blacklist of forbidden actions
 	MaxMemoryMB        int      // Memory limit in MB (0 = unlimited)
 	MaxCPUPercent      int      // CPU usage limit as percentage (0 = unlimited)
+	MaxInstructions    int64    // Lua instructions per run (0 = the VM's default)
 	TimeoutSeconds     int      // Execution timeout in seconds (0 = unlimited)
 	IsolationLevel     string   // "none", "basic", "strict"
 	CreatedAt          time.Time
@@ -344,6 +345,12 @@ func (sm *SandboxManager) GetViolationStats() map[string]interface{} {
 	return stats
 }
 
+// ReportViolation records a limit enforced while the plugin ran, such as a
+// Lua script stopped for running too many instructions
+func (sm *SandboxManager) ReportViolation(pluginID, violationType, message string) {
+	sm.logViolation(pluginID, violationType, message, "CRITICAL", true)
+}
+
 // logViolation adds a security violation record
 func (sm *SandboxManager) logViolation(pluginID, violationType, message, severity string, enforced bool) {
 	sm.mu.Lock()
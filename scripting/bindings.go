@@ -2,11 +2,27 @@ package scripting
 
 import (
 	"context"
-	"ffvi_editor/plugins"
 	"fmt"
+	"sort"
+
+	"ffvi_editor/models"
+	"ffvi_editor/models/game"
+	modelsPR "ffvi_editor/models/pr"
+	"ffvi_editor/plugins"
+
+	lua "github.com/yuin/gopher-lua"
 )
 
-// Bindings provides Go-to-Lua function bindings for the plugin API
+// Bindings publishes the plugin API to Lua as the "editor" module. Models
+// reach Lua as tables built by ToLua and come back through DecodeLua, so a
+// script can change a field of what it got and hand it back:
+//
+//	local terra = editor.getCharacter("Terra")
+//	terra.level = 50
+//	editor.setCharacter("Terra", terra)
+//
+// Functions that fail return nil, or false for those that only act, and the
+// error message.
 type Bindings struct {
 	api plugins.PluginAPI
 	vm  *VM
@@ -20,7 +36,8 @@ func NewBindings(api plugins.PluginAPI, vm *VM) *Bindings {
 	}
 }
 
-// Register registers all API functions as Lua globals
+// Register registers every API function in the global "editor" table, which
+// require("editor") returns too
 func (b *Bindings) Register(ctx context.Context) error {
 	if b.api == nil {
 		return fmt.Errorf("API is nil")
@@ -29,226 +46,291 @@ func (b *Bindings) Register(ctx context.Context) error {
 		return fmt.Errorf("VM is nil")
 	}
 
-	// Register editor API functions
-	// Note: Real implementation would use gopher-lua to register these functions
-
-	// Character functions
-	// b.vm.RegisterFunction("editor.getCharacter", func(name string) ...)
-	// b.vm.RegisterFunction("editor.setCharacter", func(name string, ch ...) ...)
-
-	// Inventory functions
-	// b.vm.RegisterFunction("editor.getInventory", func() ...)
-	// b.vm.RegisterFunction("editor.setInventory", func(inv ...) ...)
-
-	// Party functions
-	// b.vm.RegisterFunction("editor.getParty", func() ...)
-	// b.vm.RegisterFunction("editor.setParty", func(party ...) ...)
-
-	// Batch operations
-	// b.vm.RegisterFunction("editor.applyBatch", func(op string, params ...) ...)
-
-	// Query functions
-	// b.vm.RegisterFunction("editor.findCharacter", func(predicate ...) ...)
-	// b.vm.RegisterFunction("editor.findItems", func(predicate ...) ...)
-
-	// Event functions
-	// b.vm.RegisterFunction("editor.registerHook", func(event string, callback ...) ...)
-	// b.vm.RegisterFunction("editor.fireEvent", func(event string, data ...) ...)
-
-	// UI functions
-	// b.vm.RegisterFunction("editor.showDialog", func(title, message string) ...)
-	// b.vm.RegisterFunction("editor.showConfirm", func(title, message string) bool ...)
-	// b.vm.RegisterFunction("editor.showInput", func(prompt string) string ...)
-
-	// Logging
-	// b.vm.RegisterFunction("editor.log", func(level, message string) ...)
-
-	// Settings
-	// b.vm.RegisterFunction("editor.getSetting", func(key string) any ...)
-	// b.vm.RegisterFunction("editor.setSetting", func(key string, value any) ...)
-
-	// Permissions
-	// b.vm.RegisterFunction("editor.hasPermission", func(perm string) bool ...)
+	functions := b.functions(ctx)
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := b.bind(ctx, name); err != nil {
+			return err
+		}
+	}
 
+	L, err := b.vm.idleState()
+	if err != nil {
+		return err
+	}
+	L.PreloadModule("editor", func(L *lua.LState) int {
+		L.Push(L.GetGlobal("editor"))
+		return 1
+	})
 	return nil
 }
 
 // BindGetCharacter binds the GetCharacter API function
 func (b *Bindings) BindGetCharacter(ctx context.Context) error {
-	// Real implementation would create a Lua function that calls:
-	// return b.api.GetCharacter(ctx, name)
-	return b.vm.RegisterFunction("editor.getCharacter", func(name string) interface{} {
-		ch, err := b.api.GetCharacter(ctx, name)
-		if err != nil {
-			return nil
-		}
-		return ch
-	})
+	return b.bind(ctx, "getCharacter")
 }
 
 // BindSetCharacter binds the SetCharacter API function
 func (b *Bindings) BindSetCharacter(ctx context.Context) error {
-	// Real implementation would create a Lua function that calls:
-	// return b.api.SetCharacter(ctx, name, ch)
-	return b.vm.RegisterFunction("editor.setCharacter", func(name string, ch interface{}) interface{} {
-		// Would deserialize ch and call b.api.SetCharacter
-		return nil
-	})
+	return b.bind(ctx, "setCharacter")
 }
 
 // BindGetInventory binds the GetInventory API function
 func (b *Bindings) BindGetInventory(ctx context.Context) error {
-	return b.vm.RegisterFunction("editor.getInventory", func() interface{} {
-		inv, err := b.api.GetInventory(ctx)
-		if err != nil {
-			return nil
-		}
-		return inv
-	})
+	return b.bind(ctx, "getInventory")
 }
 
 // BindSetInventory binds the SetInventory API function
 func (b *Bindings) BindSetInventory(ctx context.Context) error {
-	return b.vm.RegisterFunction("editor.setInventory", func(inv interface{}) interface{} {
-		// Would deserialize inv and call b.api.SetInventory
-		return nil
-	})
+	return b.bind(ctx, "setInventory")
 }
 
 // BindLog binds the Log function
 func (b *Bindings) BindLog(ctx context.Context) error {
-	return b.vm.RegisterFunction("editor.log", func(level, message string) error {
-		return b.api.Log(ctx, level, message)
-	})
+	return b.bind(ctx, "log")
 }
 
 // BindShowDialog binds the ShowDialog function
 func (b *Bindings) BindShowDialog(ctx context.Context) error {
-	return b.vm.RegisterFunction("editor.showDialog", func(title, message string) error {
-		return b.api.ShowDialog(ctx, title, message)
-	})
+	return b.bind(ctx, "showDialog")
 }
 
 // BindShowConfirm binds the ShowConfirm function
 func (b *Bindings) BindShowConfirm(ctx context.Context) error {
-	return b.vm.RegisterFunction("editor.showConfirm", func(title, message string) bool {
-		result, err := b.api.ShowConfirm(ctx, title, message)
-		if err != nil {
-			return false
-		}
-		return result
-	})
+	return b.bind(ctx, "showConfirm")
 }
 
 // BindShowInput binds the ShowInput function
 func (b *Bindings) BindShowInput(ctx context.Context) error {
-	return b.vm.RegisterFunction("editor.showInput", func(prompt string) string {
-		result, err := b.api.ShowInput(ctx, prompt)
-		if err != nil {
-			return ""
-		}
-		return result
-	})
+	return b.bind(ctx, "showInput")
 }
 
 // BindHasPermission binds the HasPermission function
 func (b *Bindings) BindHasPermission() error {
-	return b.vm.RegisterFunction("editor.hasPermission", func(perm string) bool {
-		return b.api.HasPermission(perm)
-	})
+	return b.bind(context.Background(), "hasPermission")
 }
 
 // BindGetSetting binds the GetSetting function
 func (b *Bindings) BindGetSetting() error {
-	return b.vm.RegisterFunction("editor.getSetting", func(key string) interface{} {
-		return b.api.GetSetting(key)
-	})
+	return b.bind(context.Background(), "getSetting")
 }
 
 // BindSetSetting binds the SetSetting function
 func (b *Bindings) BindSetSetting() error {
-	return b.vm.RegisterFunction("editor.setSetting", func(key string, value interface{}) error {
-		return b.api.SetSetting(key, value)
-	})
-}
-
-// Character functions
-
-func (b *Bindings) getCharacter(charID int) (interface{}, error) {
-	// TODO: Extract character from PR save
-	return nil, nil
-}
-
-func (b *Bindings) setCharacterLevel(charID, level int) error {
-	// TODO: Set character level in PR save
-	return nil
-}
-
-func (b *Bindings) setCharacterHP(charID, hp int) error {
-	// TODO: Set character HP in PR save
-	return nil
-}
-
-func (b *Bindings) setCharacterMP(charID, mp int) error {
-	// TODO: Set character MP in PR save
-	return nil
-}
-
-func (b *Bindings) setCharacterStat(charID int, stat string, value int) error {
-	// TODO: Set character stat in PR save
-	// stat can be: "vigor", "speed", "stamina", "magic", "defense", "magicdef"
-	return nil
-}
-
-// Inventory functions
-
-func (b *Bindings) getItemCount(itemID int) (int, error) {
-	// TODO: Get item count from PR save
-	return 0, nil
-}
-
-func (b *Bindings) setItemCount(itemID, count int) error {
-	// TODO: Set item count in PR save
-	return nil
+	return b.bind(context.Background(), "setSetting")
 }
 
-func (b *Bindings) addItem(itemID, count int) error {
-	// TODO: Add items to inventory
-	return nil
-}
-
-// Party functions
-
-func (b *Bindings) getPartyMembers() ([]int, error) {
-	// TODO: Get party members from PR save
-	return []int{}, nil
-}
-
-func (b *Bindings) setPartyMembers(members []int) error {
-	// TODO: Set party members in PR save
-	return nil
+// bind registers one function of the editor module
+func (b *Bindings) bind(ctx context.Context, name string) error {
+	fn, ok := b.functions(ctx)[name]
+	if !ok {
+		return fmt.Errorf("unknown editor function %q", name)
+	}
+	return b.vm.RegisterFunction("editor."+name, fn)
 }
 
-// Magic functions
-
-func (b *Bindings) hasMagic(charID, spellID int) (bool, error) {
-	// TODO: Check if character has magic
-	return false, nil
-}
+// functions returns the editor module by function name. API calls get the
+// running script's context, or ctx outside of a script.
+func (b *Bindings) functions(ctx context.Context) map[string]lua.LGFunction {
+	apiContext := func(L *lua.LState) context.Context {
+		if L.Context() != nil {
+			return scriptContext(L)
+		}
+		return ctx
+	}
 
-func (b *Bindings) learnMagic(charID, spellID int) error {
-	// TODO: Learn magic for character
-	return nil
+	return map[string]lua.LGFunction{
+		// Save data
+		"getCharacter": func(L *lua.LState) int {
+			ch, err := b.api.GetCharacter(apiContext(L), L.CheckString(1))
+			return pushValue(L, ch, err)
+		},
+		"setCharacter": func(L *lua.LState) int {
+			name := L.CheckString(1)
+			ch := &models.Character{}
+			if current, err := b.api.GetCharacter(apiContext(L), name); err == nil {
+				ch = current
+			}
+			if err := DecodeLua(L.CheckTable(2), ch); err != nil {
+				return pushResult(L, err)
+			}
+			return pushResult(L, b.api.SetCharacter(apiContext(L), name, ch))
+		},
+		"getInventory": func(L *lua.LState) int {
+			inv, err := b.api.GetInventory(apiContext(L))
+			return pushValue(L, inv, err)
+		},
+		"setInventory": func(L *lua.LState) int {
+			inv := &modelsPR.Inventory{}
+			if current, err := b.api.GetInventory(apiContext(L)); err == nil && current != nil {
+				*inv = *current
+			}
+			if err := DecodeLua(L.CheckTable(1), inv); err != nil {
+				return pushResult(L, err)
+			}
+			return pushResult(L, b.api.SetInventory(apiContext(L), inv))
+		},
+		"getParty": func(L *lua.LState) int {
+			party, err := b.api.GetParty(apiContext(L))
+			return pushValue(L, party, err)
+		},
+		"setParty": func(L *lua.LState) int {
+			party := &modelsPR.Party{}
+			if current, err := b.api.GetParty(apiContext(L)); err == nil && current != nil {
+				*party = *current
+			}
+			if err := DecodeLua(L.CheckTable(1), party); err != nil {
+				return pushResult(L, err)
+			}
+			return pushResult(L, b.api.SetParty(apiContext(L), party))
+		},
+		"getEquipment": func(L *lua.LState) int {
+			eq, err := b.api.GetEquipment(apiContext(L))
+			return pushValue(L, eq, err)
+		},
+		"setEquipment": func(L *lua.LState) int {
+			eq := &models.Equipment{}
+			if current, err := b.api.GetEquipment(apiContext(L)); err == nil && current != nil {
+				*eq = *current
+			}
+			if err := DecodeLua(L.CheckTable(1), eq); err != nil {
+				return pushResult(L, err)
+			}
+			return pushResult(L, b.api.SetEquipment(apiContext(L), eq))
+		},
+		"saveFile": func(L *lua.LState) int {
+			return pushResult(L, b.api.SaveFile(apiContext(L), L.CheckString(1)))
+		},
+
+		// Game data
+		"getEquipmentInfo": func(L *lua.LState) int {
+			info, err := b.api.GetEquipmentInfo(apiContext(L), L.CheckInt(1))
+			return pushValue(L, info, err)
+		},
+		"listEquipment": func(L *lua.LState) int {
+			list, err := b.api.ListEquipment(apiContext(L), game.EquipType(L.CheckString(1)))
+			return pushValue(L, list, err)
+		},
+
+		// Batch operations
+		"applyBatchOperation": func(L *lua.LState) int {
+			params := make(map[string]interface{})
+			if L.GetTop() >= 2 {
+				if err := DecodeLua(L.CheckTable(2), &params); err != nil {
+					return pushResult(L, err)
+				}
+			}
+			n, err := b.api.ApplyBatchOperation(apiContext(L), L.CheckString(1), params)
+			return pushValue(L, n, err)
+		},
+
+		// Queries take Lua predicates, which get each candidate as a table
+		"findCharacter": func(L *lua.LState) int {
+			predicate := L.CheckFunction(1)
+			var err error
+			ch := b.api.FindCharacter(apiContext(L), func(ch *models.Character) bool {
+				ok, e := callPredicate(L, predicate, ch)
+				if e != nil && err == nil {
+					err = e
+				}
+				return e == nil && ok
+			})
+			if err != nil {
+				L.RaiseError("%s", err.Error())
+			}
+			return pushValue(L, ch, nil)
+		},
+		"findItems": func(L *lua.LState) int {
+			predicate := L.CheckFunction(1)
+			var err error
+			rows := b.api.FindItems(apiContext(L), func(r *modelsPR.Row) bool {
+				ok, e := callPredicate(L, predicate, r)
+				if e != nil && err == nil {
+					err = e
+				}
+				return e == nil && ok
+			})
+			if err != nil {
+				L.RaiseError("%s", err.Error())
+			}
+			if rows == nil {
+				rows = []*modelsPR.Row{}
+			}
+			return pushValue(L, rows, nil)
+		},
+
+		// Events
+		"registerHook": func(L *lua.LState) int {
+			event, callback := L.CheckString(1), L.CheckFunction(2)
+			return pushResult(L, b.api.RegisterHook(event, func(ctx context.Context, data interface{}) error {
+				return b.vm.callHook(ctx, callback, data)
+			}))
+		},
+		"fireEvent": func(L *lua.LState) int {
+			return pushResult(L, b.api.FireEvent(apiContext(L), L.CheckString(1), FromLua(L.Get(2))))
+		},
+
+		// UI
+		"showDialog": func(L *lua.LState) int {
+			return pushResult(L, b.api.ShowDialog(apiContext(L), L.CheckString(1), L.CheckString(2)))
+		},
+		"showConfirm": func(L *lua.LState) int {
+			ok, err := b.api.ShowConfirm(apiContext(L), L.CheckString(1), L.CheckString(2))
+			return pushValue(L, ok, err)
+		},
+		"showInput": func(L *lua.LState) int {
+			text, err := b.api.ShowInput(apiContext(L), L.CheckString(1))
+			return pushValue(L, text, err)
+		},
+
+		// Logging takes the level first; editor.log(message) logs at info
+		"log": func(L *lua.LState) int {
+			level, message := "info", L.CheckString(1)
+			if L.GetTop() >= 2 {
+				level, message = message, L.CheckString(2)
+			}
+			return pushResult(L, b.api.Log(apiContext(L), level, message))
+		},
+
+		// Settings
+		"getSetting": func(L *lua.LState) int {
+			L.Push(ToLua(L, b.api.GetSetting(L.CheckString(1))))
+			return 1
+		},
+		"setSetting": func(L *lua.LState) int {
+			return pushResult(L, b.api.SetSetting(L.CheckString(1), FromLua(L.Get(2))))
+		},
+
+		// Permissions
+		"hasPermission": func(L *lua.LState) int {
+			L.Push(lua.LBool(b.api.HasPermission(L.CheckString(1))))
+			return 1
+		},
+	}
 }
 
-// Utility functions
-
-func (b *Bindings) print(message string) {
-	// Print to console/log
-	println(message)
+// callPredicate calls a Lua predicate with a model and reports whether it
+// returned a true value
+func callPredicate(L *lua.LState, predicate *lua.LFunction, v interface{}) (bool, error) {
+	if err := L.CallByParam(lua.P{Fn: predicate, NRet: 1, Protect: true}, ToLua(L, v)); err != nil {
+		return false, err
+	}
+	result := L.Get(-1)
+	L.Pop(1)
+	return lua.LVAsBool(result), nil
 }
 
-func (b *Bindings) log(message string) {
-	// Log message
-	println("[Script]", message)
+// pushValue pushes the value converted by ToLua, or nil and the error message
+func pushValue(L *lua.LState, v interface{}, err error) int {
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	L.Push(ToLua(L, v))
+	return 1
 }
@@ -0,0 +1,408 @@
+package scripting
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// maxConvertDepth stops the conversion of values that refer to themselves
+const maxConvertDepth = 32
+
+var (
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	luaValue    = reflect.TypeOf((*lua.LValue)(nil)).Elem()
+)
+
+// ToLua converts a Go value to a Lua value. Structs become tables keyed by
+// their exported field names in lower camel case ("ItemID" is "itemID",
+// "HP" is "hp"), slices and arrays become sequences, byte slices strings and
+// nil pointers nil. Functions and channels are left out.
+func ToLua(L *lua.LState, v interface{}) lua.LValue {
+	if lv, ok := v.(lua.LValue); ok {
+		return lv
+	}
+	return toLua(L, reflect.ValueOf(v), 0)
+}
+
+func toLua(L *lua.LState, rv reflect.Value, depth int) lua.LValue {
+	if !rv.IsValid() || depth > maxConvertDepth {
+		return lua.LNil
+	}
+	if rv.Type().Implements(luaValue) && rv.CanInterface() {
+		if lv, ok := rv.Interface().(lua.LValue); ok && lv != nil {
+			return lv
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return lua.LNil
+		}
+		return toLua(L, rv.Elem(), depth+1)
+	case reflect.Bool:
+		return lua.LBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return lua.LNumber(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return lua.LNumber(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return lua.LNumber(rv.Float())
+	case reflect.String:
+		return lua.LString(rv.String())
+	case reflect.Slice:
+		if rv.IsNil() {
+			return lua.LNil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return lua.LString(rv.Bytes())
+		}
+		fallthrough
+	case reflect.Array:
+		tbl := L.CreateTable(rv.Len(), 0)
+		for i := 0; i < rv.Len(); i++ {
+			tbl.RawSetInt(i+1, toLua(L, rv.Index(i), depth+1))
+		}
+		return tbl
+	case reflect.Map:
+		if rv.IsNil() {
+			return lua.LNil
+		}
+		tbl := L.CreateTable(0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			tbl.RawSet(toLua(L, iter.Key(), depth+1), toLua(L, iter.Value(), depth+1))
+		}
+		return tbl
+	case reflect.Struct:
+		tbl := L.CreateTable(0, rv.NumField())
+		structToTable(L, tbl, rv, depth)
+		return tbl
+	}
+	return lua.LNil
+}
+
+// structToTable sets the fields of a struct in tbl. Fields of embedded
+// structs are set as if they were the struct's own.
+func structToTable(L *lua.LState, tbl *lua.LTable, rv reflect.Value, depth int) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("lua") == "-" {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			structToTable(L, tbl, rv.Field(i), depth)
+			continue
+		}
+		tbl.RawSetString(luaName(f.Name), toLua(L, rv.Field(i), depth+1))
+	}
+}
+
+// FromLua converts a Lua value to plain Go values: nil, bool, float64 or
+// string, and for tables []interface{} when they are sequences and
+// map[string]interface{} otherwise. Functions come back as they are.
+func FromLua(v lua.LValue) interface{} {
+	return fromLua(v, 0)
+}
+
+func fromLua(v lua.LValue, depth int) interface{} {
+	if depth > maxConvertDepth {
+		return nil
+	}
+	switch t := v.(type) {
+	case lua.LBool:
+		return bool(t)
+	case lua.LNumber:
+		return float64(t)
+	case lua.LString:
+		return string(t)
+	case *lua.LTable:
+		if n := t.Len(); n > 0 && tableSize(t) == n {
+			list := make([]interface{}, n)
+			for i := 1; i <= n; i++ {
+				list[i-1] = fromLua(t.RawGetInt(i), depth+1)
+			}
+			return list
+		}
+		m := make(map[string]interface{})
+		t.ForEach(func(key, value lua.LValue) {
+			m[key.String()] = fromLua(value, depth+1)
+		})
+		return m
+	case *lua.LFunction:
+		return t
+	}
+	return nil
+}
+
+// DecodeLua writes a Lua value into the Go value target points to. Table
+// keys set the struct field of the same name, in any case, and the other
+// fields keep their values, so {level = 50} only changes the level. Slices,
+// arrays and maps are replaced by the table's contents.
+func DecodeLua(v lua.LValue, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", target)
+	}
+	return decode(v, rv.Elem(), "")
+}
+
+func decode(v lua.LValue, rv reflect.Value, path string) error {
+	if v == lua.LNil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	if rv.Type() == luaValue || rv.Type().Implements(luaValue) && reflect.TypeOf(v).AssignableTo(rv.Type()) {
+		rv.Set(reflect.ValueOf(v))
+		return nil
+	}
+
+	fail := func(want string) error {
+		return decodeError(path, fmt.Errorf("expected %s, got %s", want, v.Type()))
+	}
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decode(v, rv.Elem(), path)
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return fail(rv.Type().String())
+		}
+		if value := FromLua(v); value != nil {
+			rv.Set(reflect.ValueOf(value))
+		}
+		return nil
+	case reflect.Bool:
+		b, ok := v.(lua.LBool)
+		if !ok {
+			return fail("boolean")
+		}
+		rv.SetBool(bool(b))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := v.(lua.LNumber)
+		if !ok {
+			return fail("number")
+		}
+		rv.SetInt(int64(n))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := v.(lua.LNumber)
+		if !ok || n < 0 {
+			return fail("positive number")
+		}
+		rv.SetUint(uint64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		n, ok := v.(lua.LNumber)
+		if !ok {
+			return fail("number")
+		}
+		rv.SetFloat(float64(n))
+		return nil
+	case reflect.String:
+		s, ok := v.(lua.LString)
+		if !ok {
+			return fail("string")
+		}
+		rv.SetString(string(s))
+		return nil
+	}
+
+	tbl, ok := v.(*lua.LTable)
+	if !ok {
+		if s, isString := v.(lua.LString); isString && rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			rv.SetBytes([]byte(s))
+			return nil
+		}
+		return fail("table")
+	}
+	switch rv.Kind() {
+	case reflect.Slice:
+		n := tbl.Len()
+		list := reflect.MakeSlice(rv.Type(), n, n)
+		for i := 0; i < n; i++ {
+			if err := decode(tbl.RawGetInt(i+1), list.Index(i), fmt.Sprintf("%s[%d]", path, i+1)); err != nil {
+				return err
+			}
+		}
+		rv.Set(list)
+		return nil
+	case reflect.Array:
+		if n := tbl.Len(); n > rv.Len() {
+			return decodeError(path, fmt.Errorf("expected at most %d values, got %d", rv.Len(), n))
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := decode(tbl.RawGetInt(i+1), rv.Index(i), fmt.Sprintf("%s[%d]", path, i+1)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		m := reflect.MakeMap(rv.Type())
+		var err error
+		tbl.ForEach(func(key, value lua.LValue) {
+			if err != nil {
+				return
+			}
+			k := reflect.New(rv.Type().Key()).Elem()
+			if err = decode(key, k, path); err != nil {
+				return
+			}
+			e := reflect.New(rv.Type().Elem()).Elem()
+			if err = decode(value, e, joinField(path, key.String())); err == nil {
+				m.SetMapIndex(k, e)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		rv.Set(m)
+		return nil
+	case reflect.Struct:
+		return decodeStruct(tbl, rv, path)
+	}
+	return fail(rv.Type().String())
+}
+
+// decodeStruct sets the fields named by the table's keys. Keys that name no
+// field are an error, so typos don't go unnoticed.
+func decodeStruct(tbl *lua.LTable, rv reflect.Value, path string) error {
+	keys := make([]string, 0)
+	tbl.ForEach(func(key, _ lua.LValue) {
+		keys = append(keys, key.String())
+	})
+	sort.Strings(keys)
+	for _, key := range keys {
+		field, ok := findField(rv, key)
+		if !ok {
+			return decodeError(path, fmt.Errorf("unknown field %q", key))
+		}
+		if err := decode(tbl.RawGetString(key), field, joinField(path, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findField returns the exported field, possibly of an embedded struct,
+// whose name matches key in any case
+func findField(rv reflect.Value, key string) (reflect.Value, bool) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("lua") == "-" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if field, ok := findField(rv.Field(i), key); ok {
+				return field, true
+			}
+			continue
+		}
+		if strings.EqualFold(f.Name, key) {
+			return rv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// wrapFunction turns a Go function into a Lua function. Arguments are
+// decoded into the parameter types, a context.Context parameter receives the
+// running script's context and a non-nil error result raises a Lua error;
+// other results are returned through ToLua.
+func wrapFunction(fn interface{}) (lua.LGFunction, error) {
+	switch f := fn.(type) {
+	case lua.LGFunction:
+		return f, nil
+	case func(*lua.LState) int:
+		return f, nil
+	}
+
+	rv := reflect.ValueOf(fn)
+	if rv.Kind() != reflect.Func {
+		return nil, fmt.Errorf("%T is not a function", fn)
+	}
+	t := rv.Type()
+	if t.IsVariadic() {
+		return nil, fmt.Errorf("variadic function %T is not supported", fn)
+	}
+	return func(L *lua.LState) int {
+		args := make([]reflect.Value, t.NumIn())
+		arg := 1
+		for i := range args {
+			if t.In(i) == contextType {
+				args[i] = reflect.ValueOf(scriptContext(L))
+				continue
+			}
+			args[i] = reflect.New(t.In(i)).Elem()
+			if err := decode(L.Get(arg), args[i], ""); err != nil {
+				L.ArgError(arg, err.Error())
+			}
+			arg++
+		}
+
+		out := rv.Call(args)
+		if n := len(out); n > 0 && t.Out(n-1) == errorType {
+			if err, _ := out[n-1].Interface().(error); err != nil {
+				L.RaiseError("%s", err.Error())
+			}
+			out = out[:n-1]
+		}
+		for _, o := range out {
+			L.Push(toLua(L, o, 0))
+		}
+		return len(out)
+	}, nil
+}
+
+// luaName lowers the leading capitals of a Go name, keeping the capital
+// that starts the next word: "ItemID" is "itemID", "HPMax" is "hpMax"
+func luaName(name string) string {
+	r := []rune(name)
+	n := 0
+	for n < len(r) && unicode.IsUpper(r[n]) {
+		n++
+	}
+	if n > 1 && n < len(r) {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
+
+func tableSize(tbl *lua.LTable) int {
+	n := 0
+	tbl.ForEach(func(_, _ lua.LValue) { n++ })
+	return n
+}
+
+func joinField(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func decodeError(path string, err error) error {
+	if path == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
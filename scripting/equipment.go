@@ -0,0 +1,78 @@
+package scripting
+
+import (
+	"ffvi_editor/models/game"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// registerEquipmentBindings exposes the read-only equipment database to Lua
+// as the global "equipment" table for optimizer plugins
+func registerEquipmentBindings(L *lua.LState) {
+	equipTable := L.NewTable()
+
+	L.SetField(equipTable, "get", L.NewFunction(func(L *lua.LState) int {
+		info, found := game.GetEquipmentInfo(int(L.CheckNumber(1)))
+		if !found {
+			L.Push(lua.LNil)
+			L.Push(lua.LString("not equipment"))
+			return 2
+		}
+		L.Push(equipmentToTable(L, info))
+		return 1
+	}))
+
+	L.SetField(equipTable, "list", L.NewFunction(func(L *lua.LState) int {
+		L.Push(equipmentListToTable(L, game.EquipmentOfType(game.EquipType(L.CheckString(1)))))
+		return 1
+	}))
+
+	L.SetField(equipTable, "equippable", L.NewFunction(func(L *lua.LState) int {
+		items := game.EquippableItems(L.CheckString(1), game.EquipType(L.CheckString(2)))
+		L.Push(equipmentListToTable(L, items))
+		return 1
+	}))
+
+	L.SetField(equipTable, "canEquip", L.NewFunction(func(L *lua.LState) int {
+		character := L.CheckString(1)
+		info, found := game.GetEquipmentInfo(int(L.CheckNumber(2)))
+		L.Push(lua.LBool(found && info.CanEquip(character)))
+		return 1
+	}))
+
+	L.SetGlobal("equipment", equipTable)
+}
+
+func equipmentListToTable(L *lua.LState, items []*game.EquipmentInfo) *lua.LTable {
+	tbl := L.NewTable()
+	for _, info := range items {
+		tbl.Append(equipmentToTable(L, info))
+	}
+	return tbl
+}
+
+func equipmentToTable(L *lua.LState, info *game.EquipmentInfo) *lua.LTable {
+	tbl := L.NewTable()
+	L.SetField(tbl, "id", lua.LNumber(info.ID))
+	L.SetField(tbl, "name", lua.LString(info.Name))
+	L.SetField(tbl, "type", lua.LString(info.Type))
+	L.SetField(tbl, "category", lua.LString(info.Category))
+	L.SetField(tbl, "attack", lua.LNumber(info.Attack))
+	L.SetField(tbl, "defense", lua.LNumber(info.Defense))
+	L.SetField(tbl, "magicDefense", lua.LNumber(info.MagicDefense))
+	L.SetField(tbl, "vigor", lua.LNumber(info.Vigor))
+	L.SetField(tbl, "speed", lua.LNumber(info.Speed))
+	L.SetField(tbl, "stamina", lua.LNumber(info.Stamina))
+	L.SetField(tbl, "magic", lua.LNumber(info.Magic))
+	L.SetField(tbl, "equippableBy", stringsToTable(L, info.EquippableBy))
+	L.SetField(tbl, "properties", stringsToTable(L, info.Properties))
+	return tbl
+}
+
+func stringsToTable(L *lua.LState, values []string) *lua.LTable {
+	tbl := L.NewTable()
+	for _, v := range values {
+		tbl.Append(lua.LString(v))
+	}
+	return tbl
+}
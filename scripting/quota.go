@@ -0,0 +1,359 @@
+package scripting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Default limits of a script run
+const (
+	DefaultMaxInstructions = 100000000
+	DefaultMaxMemory       = 50 * 1024 * 1024
+)
+
+var (
+	// ErrInstructionLimit is returned when a script runs too many instructions
+	ErrInstructionLimit = errors.New("instruction limit exceeded")
+	// ErrMemoryLimit is returned when a script holds too much memory
+	ErrMemoryLimit = errors.New("memory limit exceeded")
+)
+
+// Limits bound a script run; zero values are unlimited
+type Limits struct {
+	Timeout         time.Duration
+	MaxInstructions int64
+	MaxMemory       int64 // bytes
+}
+
+// Approximate sizes of Lua values, after the Go structures behind them
+const (
+	valueBytes    = 16
+	tableBytes    = 64
+	entryBytes    = 40
+	functionBytes = 64
+	stringBytes   = 16
+
+	// measureInterval is the fewest instructions between memory measurements
+	measureInterval = 1000
+)
+
+// quota is the context a Lua state runs under. gopher-lua asks for Done
+// before every instruction, so Done counts them and now and then measures the
+// memory the state holds. Once a limit is exceeded the context is cancelled:
+// every further instruction raises the error, so pcall can't swallow it and
+// the stack unwinds to the caller.
+//
+// Memory is approximated from the tables, strings and functions reachable
+// from the registry and the call stack. Measuring walks all of them, so the
+// interval between measurements grows with the heap to keep the cost per
+// instruction constant. Concatenation can double a string with every
+// instruction, far quicker than that, so between measurements the registers
+// of the running function are checked for strings longer than any measured.
+type quota struct {
+	context.Context
+	cancel    context.CancelFunc
+	L         *lua.LState
+	limits    Limits
+	executed  int64
+	nextCheck int64
+	memory    int64
+	longest   int64
+	err       error
+}
+
+// newQuota creates the context of a run with limits on L. Its Context field
+// is what Go code called by the script should see.
+func newQuota(ctx context.Context, L *lua.LState, limits Limits) *quota {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	q := &quota{L: L, limits: limits}
+	if limits.Timeout > 0 {
+		ctx, q.cancel = context.WithTimeout(ctx, limits.Timeout)
+	} else {
+		ctx, q.cancel = context.WithCancel(ctx)
+	}
+	q.Context = ctx
+	return q
+}
+
+// Done counts an instruction and checks the limits
+func (q *quota) Done() <-chan struct{} {
+	q.executed++
+	if q.err == nil {
+		if q.limits.MaxInstructions > 0 && q.executed > q.limits.MaxInstructions {
+			q.fail(fmt.Errorf("%w: more than %d instructions", ErrInstructionLimit, q.limits.MaxInstructions))
+		} else if q.limits.MaxMemory > 0 {
+			if q.executed >= q.nextCheck {
+				q.measure()
+			} else {
+				q.checkStrings()
+			}
+		}
+	}
+	return q.Context.Done()
+}
+
+// Err returns the exceeded limit, or the error of the parent context
+func (q *quota) Err() error {
+	if q.err != nil {
+		return q.err
+	}
+	return q.Context.Err()
+}
+
+// Exceeded returns the limit the run exceeded: ErrInstructionLimit,
+// ErrMemoryLimit or context.DeadlineExceeded for the timeout, each wrapped
+// with the details; nil when the run kept to its limits
+func (q *quota) Exceeded() error {
+	if q.err != nil {
+		return q.err
+	}
+	if errors.Is(q.Context.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("execution timeout: %w", q.Context.Err())
+	}
+	return nil
+}
+
+// Release stops the run's timer
+func (q *quota) Release() {
+	q.cancel()
+}
+
+// reserve fails the run when a script is about to allocate n more bytes than
+// it may hold
+func (q *quota) reserve(n int64) error {
+	if q.err == nil && q.limits.MaxMemory > 0 && q.memory+n > q.limits.MaxMemory {
+		q.fail(fmt.Errorf("%w: allocating %d bytes over %d", ErrMemoryLimit, n, q.limits.MaxMemory))
+	}
+	return q.err
+}
+
+// checkStrings reserves the strings in the registers of the running function
+// that are longer than the longest measured, as they were built since
+func (q *quota) checkStrings() {
+	for i := q.L.GetTop(); i > 0; i-- {
+		if s, ok := q.L.Get(i).(lua.LString); ok && int64(len(s)) > q.longest {
+			q.longest = int64(len(s))
+			if q.reserve(stringBytes+q.longest) != nil {
+				return
+			}
+		}
+	}
+}
+
+func (q *quota) fail(err error) {
+	q.err = err
+	q.cancel()
+}
+
+// measure approximates the memory held by the state
+func (q *quota) measure() {
+	m := &heapMeter{
+		L:       q.L,
+		seen:    make(map[interface{}]bool),
+		strings: make(map[string]bool),
+	}
+	m.visit(q.L.G.Registry)
+	m.visit(q.L.G.Global)
+	for level := 0; ; level++ {
+		dbg, ok := q.L.GetStack(level)
+		if !ok {
+			break
+		}
+		if fn, err := q.L.GetInfo("f", dbg, lua.LNil); err == nil {
+			m.visit(fn)
+		}
+		for n := 1; ; n++ {
+			name, v := q.L.GetLocal(dbg, n)
+			if name == "" {
+				break
+			}
+			m.visit(v)
+		}
+	}
+
+	q.memory, q.longest = m.bytes, m.longest
+	interval := m.values * 8
+	if interval < measureInterval {
+		interval = measureInterval
+	}
+	q.nextCheck = q.executed + interval
+	if q.memory > q.limits.MaxMemory {
+		q.fail(fmt.Errorf("%w: about %d bytes held, limit %d", ErrMemoryLimit, q.memory, q.limits.MaxMemory))
+	}
+}
+
+// heapMeter adds up the approximate size of the values it visits, each
+// table, function and string once
+type heapMeter struct {
+	L       *lua.LState
+	seen    map[interface{}]bool
+	strings map[string]bool
+	bytes   int64
+	values  int64
+	longest int64
+}
+
+func (m *heapMeter) visit(v lua.LValue) {
+	m.values++
+	switch t := v.(type) {
+	case lua.LString:
+		if !m.strings[string(t)] {
+			m.strings[string(t)] = true
+			m.bytes += stringBytes + int64(len(t))
+			if int64(len(t)) > m.longest {
+				m.longest = int64(len(t))
+			}
+		}
+	case *lua.LTable:
+		if m.seen[t] {
+			return
+		}
+		m.seen[t] = true
+		m.bytes += tableBytes
+		t.ForEach(func(k, v lua.LValue) {
+			m.bytes += entryBytes
+			m.visit(k)
+			m.visit(v)
+		})
+		if mt := m.L.GetMetatable(t); mt != lua.LNil {
+			m.visit(mt)
+		}
+	case *lua.LFunction:
+		if m.seen[t] {
+			return
+		}
+		m.seen[t] = true
+		m.bytes += functionBytes + int64(len(t.Upvalues))*valueBytes
+		for _, uv := range t.Upvalues {
+			m.visit(uv.Value())
+		}
+		if t.Env != nil {
+			m.visit(t.Env)
+		}
+	case *lua.LUserData:
+		if m.seen[t] {
+			return
+		}
+		m.seen[t] = true
+		m.bytes += tableBytes
+		if t.Metatable != nil {
+			m.visit(t.Metatable)
+		}
+	default:
+		m.bytes += valueBytes
+	}
+}
+
+// guardAllocations replaces the library functions that build a string of any
+// size in one call, string.rep, string.format and table.concat, with ones that
+// check the size against the running quota first
+func guardAllocations(L *lua.LState) {
+	guardAllocation(L, "string", "rep", repSize)
+	guardAllocation(L, "string", "format", formatSize)
+	guardAllocation(L, "table", "concat", concatSize)
+}
+
+func guardAllocation(L *lua.LState, lib, name string, size func(L *lua.LState) int64) {
+	t, ok := L.GetGlobal(lib).(*lua.LTable)
+	if !ok {
+		return
+	}
+	fn, ok := t.RawGetString(name).(*lua.LFunction)
+	if !ok {
+		return
+	}
+	t.RawSetString(name, L.NewFunction(func(L *lua.LState) int {
+		if q, ok := L.Context().(*quota); ok {
+			if err := q.reserve(size(L)); err != nil {
+				L.RaiseError("%s", err.Error())
+			}
+		}
+		return fn.GFunction(L)
+	}))
+}
+
+// repSize is the length of the string string.rep(s, n) builds
+func repSize(L *lua.LState) int64 {
+	n := int64(L.OptInt(2, 0))
+	if n <= 0 {
+		return 0
+	}
+	return int64(len(L.CheckString(1))) * n
+}
+
+// formatSize bounds the length of the string string.format builds: the
+// format, the string arguments and the width and precision of each verb
+func formatSize(L *lua.LState) int64 {
+	format := L.CheckString(1)
+	size := int64(len(format))
+	for i := 2; i <= L.GetTop(); i++ {
+		if s, ok := L.Get(i).(lua.LString); ok {
+			size += int64(len(s))
+		}
+	}
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("-+ #0", format[i]) >= 0 {
+			i++
+		}
+		size += formatNumber(format, &i)
+		if i < len(format) && format[i] == '.' {
+			i++
+			size += formatNumber(format, &i)
+		}
+	}
+	return size
+}
+
+// formatNumber reads the digits of a width or precision at format[*i:]
+func formatNumber(format string, i *int) int64 {
+	n := int64(0)
+	for ; *i < len(format) && format[*i] >= '0' && format[*i] <= '9'; *i++ {
+		if n < math.MaxInt32 {
+			n = n*10 + int64(format[*i]-'0')
+		}
+	}
+	return n
+}
+
+// concatSize is the length of the string table.concat(t, sep, i, j) builds,
+// up to the first value it can't join
+func concatSize(L *lua.LState) int64 {
+	t := L.CheckTable(1)
+	sep := int64(len(L.OptString(2, "")))
+	var size int64
+	for i, j := L.OptInt(3, 1), L.OptInt(4, t.Len()); i <= j; i++ {
+		switch v := t.RawGetInt(i).(type) {
+		case lua.LString:
+			size += int64(len(v)) + sep
+		case lua.LNumber:
+			size += int64(len(v.String())) + sep
+		default:
+			return size
+		}
+	}
+	return size
+}
+
+// scriptContext returns the context of the running script
+func scriptContext(L *lua.LState) context.Context {
+	switch ctx := L.Context().(type) {
+	case *quota:
+		return ctx.Context
+	case nil:
+		return context.Background()
+	default:
+		return ctx
+	}
+}
@@ -0,0 +1,139 @@
+package scripting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ffvi_editor/plugins"
+)
+
+func TestInstructionLimit(t *testing.T) {
+	vm := NewVM(10 * time.Second)
+	defer vm.Close()
+	vm.SetMaxInstructions(10000)
+
+	// pcall can't catch the limit: the chunk stops where it was hit
+	err := vm.Execute(context.Background(), `
+		local ok = pcall(function() while true do end end)
+		caught = true`)
+	if !errors.Is(err, ErrInstructionLimit) {
+		t.Fatalf("expected the instruction limit, got %v", err)
+	}
+	if v, _ := vm.GetGlobal("caught"); v != nil {
+		t.Error("the script went on after the limit")
+	}
+
+	// The next run starts with a fresh budget
+	if err = vm.Execute(context.Background(), `x = 1`); err != nil {
+		t.Fatalf("the VM isn't usable after the limit: %v", err)
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	vm := NewVM(10 * time.Second)
+	defer vm.Close()
+	vm.SetMaxMemory(1024 * 1024)
+
+	err := vm.Execute(context.Background(), `
+		local t = {}
+		for i = 1, 1000000 do t[i] = "item " .. i end`)
+	if !errors.Is(err, ErrMemoryLimit) {
+		t.Fatalf("expected the memory limit for a growing table, got %v", err)
+	}
+	err = vm.Execute(context.Background(), `s = string.rep("x", 1000000000)`)
+	if !errors.Is(err, ErrMemoryLimit) {
+		t.Fatalf("expected the memory limit for string.rep, got %v", err)
+	}
+	for name, script := range map[string]string{
+		"concatenation": `local s = "x" for i = 1, 27 do s = s .. s end`,
+		"table.concat":  `local t = {} for i = 1, 100 do t[i] = "x" end s = table.concat(t, string.rep("-", 100000))`,
+		"string.format": `s = string.format("%999999999s", "x")`,
+	} {
+		if err = vm.Execute(context.Background(), script); !errors.Is(err, ErrMemoryLimit) {
+			t.Errorf("expected the memory limit for %s, got %v", name, err)
+		}
+	}
+	if err = vm.Execute(context.Background(), `s = string.rep("x", 1000)`); err != nil {
+		t.Fatalf("a small string failed: %v", err)
+	}
+}
+
+func TestLimitsReportedToSandbox(t *testing.T) {
+	sm := plugins.NewSandboxManager()
+	if err := sm.SetPolicy(&plugins.SandboxPolicy{PluginID: "test-plugin", IsolationLevel: "basic", MaxInstructions: 500}); err != nil {
+		t.Fatal(err)
+	}
+	vm := NewVM(10 * time.Second)
+	defer vm.Close()
+	vm.SetSandbox(sm, "test-plugin")
+
+	if err := vm.Execute(context.Background(), `while true do end`); !errors.Is(err, ErrInstructionLimit) {
+		t.Fatalf("expected the policy's instruction limit, got %v", err)
+	}
+	violations := sm.GetViolationsByType("cpu_exceeded")
+	if len(violations) != 1 || violations[0].PluginID != "test-plugin" || !violations[0].Enforced {
+		t.Fatalf("expected one enforced violation, got %+v", violations)
+	}
+}
+
+func TestExecutedPluginRunsInSandbox(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugin.lua")
+	if err := os.WriteFile(path, []byte(`while true do end`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := plugins.NewManager(dir, nil)
+	m.SetRunner(RunPlugin)
+
+	// Loaded plugins are verified by an ID taken from the clock
+	if err := m.GetSecurityManager().GenerateKeyPair(); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	for _, id := range []int64{now, now + 1} {
+		if _, err := m.GetSecurityManager().SignPlugin(fmt.Sprintf("plugin_%d", id), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	plugin, err := m.LoadPlugin(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm := m.GetSandboxManager()
+	if err = sm.SetPolicy(&plugins.SandboxPolicy{
+		PluginID:           plugin.ID,
+		AllowedPermissions: []string{"execute"},
+		IsolationLevel:     "basic",
+		MaxInstructions:    500,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = m.ExecutePlugin(ctx, plugin.ID); !errors.Is(err, ErrInstructionLimit) {
+		t.Fatalf("expected the policy's instruction limit, got %v", err)
+	}
+	violations := sm.GetViolationsByType("cpu_exceeded")
+	if len(violations) != 1 || violations[0].PluginID != plugin.ID {
+		t.Fatalf("expected one violation by the plugin, got %+v", violations)
+	}
+}
+
+func TestSnippetDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := RunSnippet(ctx, `while true do end`)
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("the snippet ran on for %s after its deadline", elapsed)
+	}
+}
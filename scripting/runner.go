@@ -3,6 +3,7 @@ package scripting
 import (
 	"context"
 	"ffvi_editor/io/pr"
+	"ffvi_editor/plugins"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -16,70 +17,112 @@ type LuaResult map[string]interface{}
 
 // RunSnippet executes a Lua snippet with sandboxed VM and returns a LuaResult if a table is returned.
 func RunSnippet(ctx context.Context, code string) (LuaResult, error) {
+	return RunSnippetWithSave(ctx, code, nil)
+}
+
+// RunSnippetWithSave executes a Lua snippet with save data bindings.
+func RunSnippetWithSave(ctx context.Context, code string, save *pr.PR) (LuaResult, error) {
 	if code == "" {
 		return nil, fmt.Errorf("code is empty")
 	}
-	// Context timeout safeguard
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
-	defer cancel()
-
-	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	L := newSandboxState(save)
 	defer L.Close()
+	q := newQuota(ctx, L, snippetLimits)
+	defer q.Release()
+	L.SetContext(q)
 
-	openSafeLibs(L)
-
-	// Restrict package.path to local plugins directory
-	pluginPaths := []string{
-		"./?.lua",
-		"./?/init.lua",
-		"./plugins/?.lua",
-		"./plugins/?/init.lua",
-		"./plugins/?/v1_0_core.lua",
+	if err := trackScript(save, func() error { return L.DoString(code) }); err != nil {
+		return nil, snippetError(q, err)
 	}
-	_ = L.DoString(fmt.Sprintf(`package.path = package.path .. ';%s'`, strings.Join(pluginPaths, ";")))
-	disableUnsafeGlobals(L)
-
-	done := make(chan error, 1)
-	go func() {
-		done <- L.DoString(code)
-	}()
-
-	select {
-	case err := <-done:
-		if err != nil {
-			return nil, err
+	// If a value was returned, convert when table
+	if L.GetTop() >= 1 {
+		val := L.Get(-1)
+		if tbl, ok := val.(*lua.LTable); ok {
+			return tableToMap(tbl), nil
 		}
-		// If a value was returned, convert when table
-		if L.GetTop() >= 1 {
-			val := L.Get(-1)
-			if tbl, ok := val.(*lua.LTable); ok {
-				return tableToMap(tbl), nil
-			}
-		}
-		return nil, nil
-	case <-ctx.Done():
-		return nil, fmt.Errorf("lua execution timeout: %w", ctx.Err())
 	}
+	return nil, nil
 }
 
-// RunSnippetWithSave executes a Lua snippet with save data bindings.
-func RunSnippetWithSave(ctx context.Context, code string, save *pr.PR) (LuaResult, error) {
+// RunPlugin runs a plugin's script file in a VM of its own with the plugin's
+// API. With a sandbox the plugin's policy sets the VM's limits and the limits
+// the script exceeds are reported as violations. It is a plugins.Runner.
+func RunPlugin(ctx context.Context, plugin *plugins.Plugin, sandbox *plugins.SandboxManager) error {
+	vm := NewVM(0)
+	defer vm.Close()
+	if plugin.API != nil {
+		vm.SetAPI(plugin.API)
+	}
+	if sandbox != nil {
+		vm.SetSandbox(sandbox, plugin.ID)
+	}
+	return vm.ExecuteFile(ctx, plugin.GetPath())
+}
+
+// EvalWithSave executes a Lua chunk with save data bindings and returns every
+// value it produced as text. A bare expression such as "1 + 2" is evaluated
+// as if it were prefixed with return.
+func EvalWithSave(ctx context.Context, code string, save *pr.PR) ([]string, error) {
 	if code == "" {
 		return nil, fmt.Errorf("code is empty")
 	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
-	defer cancel()
+	L := newSandboxState(save)
+	defer L.Close()
+	q := newQuota(ctx, L, snippetLimits)
+	defer q.Release()
+	L.SetContext(q)
+
+	fn, err := L.LoadString("return " + code)
+	if err != nil {
+		if fn, err = L.LoadString(code); err != nil {
+			return nil, err
+		}
+	}
+
+	base := L.GetTop()
+	L.Push(fn)
+	if err = trackScript(save, func() error { return L.PCall(0, lua.MultRet, nil) }); err != nil {
+		return nil, snippetError(q, err)
+	}
+	results := make([]string, 0, L.GetTop()-base)
+	for i := base + 1; i <= L.GetTop(); i++ {
+		if tbl, ok := L.Get(i).(*lua.LTable); ok {
+			results = append(results, fmt.Sprint(map[string]interface{}(tableToMap(tbl))))
+		} else {
+			results = append(results, L.Get(i).String())
+		}
+	}
+	return results, nil
+}
+
+// snippetLimits bound the runs of RunSnippetWithSave and EvalWithSave
+var snippetLimits = Limits{
+	Timeout:         3 * time.Second,
+	MaxInstructions: DefaultMaxInstructions,
+	MaxMemory:       DefaultMaxMemory,
+}
+
+// snippetError returns the limit a failed snippet exceeded, or its error
+func snippetError(q *quota, err error) error {
+	if exceeded := q.Exceeded(); exceeded != nil {
+		return fmt.Errorf("lua %w", exceeded)
+	}
+	return err
+}
 
+// newSandboxState creates a Lua state with the safe libraries, package.path
+// restricted to the plugins directory and, when save is not nil, save bindings
+func newSandboxState(save *pr.PR) *lua.LState {
 	L := lua.NewState(lua.Options{SkipOpenLibs: true})
-	defer L.Close()
 
 	openSafeLibs(L)
+	guardAllocations(L)
 
 	// Restrict package.path to local plugins directory
 	pluginPaths := []string{
@@ -91,32 +134,13 @@ func RunSnippetWithSave(ctx context.Context, code string, save *pr.PR) (LuaResul
 	}
 	_ = L.DoString(fmt.Sprintf(`package.path = package.path .. ';%s'`, strings.Join(pluginPaths, ";")))
 	disableUnsafeGlobals(L)
+	registerEquipmentBindings(L)
 
 	// Register save bindings if save provided
 	if save != nil {
 		registerSaveBindings(L, save)
 	}
-
-	done := make(chan error, 1)
-	go func() {
-		done <- L.DoString(code)
-	}()
-
-	select {
-	case err := <-done:
-		if err != nil {
-			return nil, err
-		}
-		if L.GetTop() >= 1 {
-			val := L.Get(-1)
-			if tbl, ok := val.(*lua.LTable); ok {
-				return tableToMap(tbl), nil
-			}
-		}
-		return nil, nil
-	case <-ctx.Done():
-		return nil, fmt.Errorf("lua execution timeout: %w", ctx.Err())
-	}
+	return L
 }
 
 func tableToMap(tbl *lua.LTable) LuaResult {
@@ -205,6 +229,8 @@ func PluginPath() string {
 }
 
 // registerSaveBindings registers Go functions for save data manipulation in Lua.
+// getCharacterName and the setCharacter* helpers below take the index into the
+// save's character list; the functions of registerSaveAPI take names or IDs.
 func registerSaveBindings(L *lua.LState, save *pr.PR) {
 	// Create save table
 	saveTable := L.NewTable()
@@ -239,52 +265,22 @@ func registerSaveBindings(L *lua.LState, save *pr.PR) {
 	}))
 
 	L.SetField(saveTable, "setCharacterLevel", L.NewFunction(func(L *lua.LState) int {
-		idx := int(L.CheckNumber(1))
-		level := int(L.CheckNumber(2))
-		if idx < 0 || idx >= len(save.Characters) || save.Characters[idx] == nil {
-			L.Push(lua.LBool(false))
-			L.Push(lua.LString("invalid character index"))
-			return 2
-		}
-		save.Characters[idx].Set("level", float64(level))
-		L.Push(lua.LBool(true))
-		return 1
+		return setCharacterField(L, save, "level")
 	}))
 
 	L.SetField(saveTable, "setCharacterHP", L.NewFunction(func(L *lua.LState) int {
-		idx := int(L.CheckNumber(1))
-		hp := int(L.CheckNumber(2))
-		if idx < 0 || idx >= len(save.Characters) || save.Characters[idx] == nil {
-			L.Push(lua.LBool(false))
-			L.Push(lua.LString("invalid character index"))
-			return 2
-		}
-		save.Characters[idx].Set("current_hp", float64(hp))
-		L.Push(lua.LBool(true))
-		return 1
+		return setCharacterField(L, save, "hp")
 	}))
 
 	L.SetField(saveTable, "setCharacterMP", L.NewFunction(func(L *lua.LState) int {
-		idx := int(L.CheckNumber(1))
-		mp := int(L.CheckNumber(2))
-		if idx < 0 || idx >= len(save.Characters) || save.Characters[idx] == nil {
-			L.Push(lua.LBool(false))
-			L.Push(lua.LString("invalid character index"))
-			return 2
-		}
-		save.Characters[idx].Set("current_mp", float64(mp))
-		L.Push(lua.LBool(true))
-		return 1
+		return setCharacterField(L, save, "mp")
 	}))
 
-	// Inventory/party placeholder stubs
 	L.SetField(saveTable, "getGil", L.NewFunction(func(L *lua.LState) int {
-		val := save.UserData.Get("gil")
-		if val != nil {
-			if gil, ok := val.(float64); ok {
-				L.Push(lua.LNumber(gil))
-				return 1
-			}
+		gil, _ := pr.GetPath(pr.JoinPath(pr.PathMisc, "gil"))
+		if n, ok := gil.(int); ok {
+			L.Push(lua.LNumber(n))
+			return 1
 		}
 		L.Push(lua.LNumber(0))
 		return 1
@@ -292,9 +288,7 @@ func registerSaveBindings(L *lua.LState, save *pr.PR) {
 
 	L.SetField(saveTable, "setGil", L.NewFunction(func(L *lua.LState) int {
 		gil := int(L.CheckNumber(1))
-		save.UserData.Set("gil", float64(gil))
-		L.Push(lua.LBool(true))
-		return 1
+		return pushResult(L, pr.Changes.Set(pr.JoinPath(pr.PathMisc, "gil"), gil))
 	}))
 
 	L.SetField(saveTable, "log", L.NewFunction(func(L *lua.LState) int {
@@ -303,6 +297,46 @@ func registerSaveBindings(L *lua.LState, save *pr.PR) {
 		return 0
 	}))
 
+	registerSaveAPI(L, saveTable)
+
 	// Register global save table
 	L.SetGlobal("save", saveTable)
 }
+
+// trackScript runs a script that has save bindings as one tracked edit, so
+// everything it changes is undone together
+func trackScript(save *pr.PR, run func() error) error {
+	if save == nil {
+		return run()
+	}
+	return pr.Changes.Edit("Lua script", run)
+}
+
+// setCharacterField writes a field of the character at the index in argument
+// 1, or with the name in argument 1, to the number in argument 2 through the
+// change tracker
+func setCharacterField(L *lua.LState, save *pr.PR, field string) int {
+	value := int(L.CheckNumber(2))
+	if name, ok := L.Get(1).(lua.LString); ok {
+		return pushResult(L, pr.Changes.Set(pr.JoinPath(pr.PathCharacters, string(name), field), value))
+	}
+	idx := int(L.CheckNumber(1))
+	if idx < 0 || idx >= len(save.Characters) || save.Characters[idx] == nil {
+		L.Push(lua.LBool(false))
+		L.Push(lua.LString("invalid character index"))
+		return 2
+	}
+	name, _ := save.Characters[idx].Get("name").(string)
+	return pushResult(L, pr.Changes.Set(pr.JoinPath(pr.PathCharacters, name, field), value))
+}
+
+// pushResult pushes true, or false and the error message
+func pushResult(L *lua.LState, err error) int {
+	if err != nil {
+		L.Push(lua.LBool(false))
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	L.Push(lua.LBool(true))
+	return 1
+}
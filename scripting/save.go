@@ -0,0 +1,525 @@
+package scripting
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ffvi_editor/io/pr"
+	constsPR "ffvi_editor/models/consts/pr"
+	pri "ffvi_editor/models/pr"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// The save table reaches the whole loaded save through the logical save paths
+// of io/pr, so every write goes through the change tracker and can be undone.
+// Characters are given by name or character ID; items, espers, spells,
+// commands and skills by name or ID. Slots and indices count from 0 as in the
+// paths. Reads return the value, or nil and the error message; writes return
+// true, or false and the error message.
+//
+//	get(path), set(path, value), add(path [, value]), remove(path)
+//	names(category), fields(category)
+//	characters(), getCharacter(c), setCharacter(c, fields), setCharacterStat(c, stat, value)
+//	getSpells(c), learnSpell(c, spell [, percent]), forgetSpell(c, spell), learnAllSpells(c)
+//	getCommands(c), setCommand(c, slot, command)
+//	getEquipment(c), equip(c, slot, item)
+//	getInventory(), getItemCount(item), setItemCount(item, count), addItem(item [, count]), removeItem(item)
+//	getImportantItems(), hasImportantItem(item), addImportantItem(item), removeImportantItem(item)
+//	getEspers(), hasEsper(esper), addEsper(esper), removeEsper(esper)
+//	getSkills(kind), hasSkill(kind, name), learnSkill(kind, name), forgetSkill(kind, name), learnAllSkills(kind)
+//	getVeldt(), setVeldt(index, available)
+//	getParty(), setParty(members), setPartyMember(slot, c)
+//	getMap(), setMap(fields)
+//	getTransportation(), setTransportation(index, fields)
+//	getMisc(), setMisc(fields)
+//	itemName(id), itemID(name)
+//
+// Skill kinds are rages, lores, dances, blitzes and bushido.
+
+// skillKinds are the path categories getSkills and friends accept
+var skillKinds = []string{pr.PathRages, pr.PathLores, pr.PathDances, pr.PathBlitzes, pr.PathBushido}
+
+// registerSaveAPI adds the path based functions to the save table
+func registerSaveAPI(L *lua.LState, saveTable *lua.LTable) {
+	functions := map[string]lua.LGFunction{
+		// Paths
+		"get": func(L *lua.LState) int {
+			v, err := pr.GetPath(L.CheckString(1))
+			return pushValue(L, v, err)
+		},
+		"set": func(L *lua.LState) int {
+			return pushResult(L, pr.Changes.Set(L.CheckString(1), FromLua(L.CheckAny(2))))
+		},
+		"add": func(L *lua.LState) int {
+			return pushResult(L, pr.Changes.Add(L.CheckString(1), FromLua(L.Get(2))))
+		},
+		"remove": func(L *lua.LState) int {
+			return pushResult(L, pr.Changes.Remove(L.CheckString(1)))
+		},
+		"names": func(L *lua.LState) int {
+			return pushValue(L, pr.PathNames(L.CheckString(1)), nil)
+		},
+		"fields": func(L *lua.LState) int {
+			return pushValue(L, pr.PathFields(L.CheckString(1)), nil)
+		},
+
+		// Characters
+		"characters": func(L *lua.LState) int {
+			names := make([]string, 0, len(pri.Characters))
+			for _, c := range pri.Characters {
+				if c != nil && !c.IsNPC {
+					names = append(names, c.RootName)
+				}
+			}
+			return pushValue(L, names, nil)
+		},
+		"getCharacter": func(L *lua.LState) int {
+			name, err := characterArg(L, 1)
+			if err != nil {
+				return pushValue(L, nil, err)
+			}
+			fields := make([]string, 0)
+			for _, f := range pr.PathFields(pr.PathCharacters) {
+				if f != "equipment" && f != "spells" && f != "commands" {
+					fields = append(fields, f)
+				}
+			}
+			v, err := readFields(fields, pr.PathCharacters, name)
+			return pushValue(L, v, err)
+		},
+		"setCharacter": func(L *lua.LState) int {
+			name, err := characterArg(L, 1)
+			if err != nil {
+				return pushResult(L, err)
+			}
+			return pushResult(L, writeFields(L.CheckTable(2), pr.PathCharacters, name))
+		},
+		"setCharacterStat": func(L *lua.LState) int {
+			name, err := characterArg(L, 1)
+			if err != nil {
+				return pushResult(L, err)
+			}
+			return pushResult(L, pr.Changes.Set(pr.JoinPath(pr.PathCharacters, name, L.CheckString(2)), FromLua(L.CheckAny(3))))
+		},
+
+		// Spells
+		"getSpells": func(L *lua.LState) int {
+			name, err := characterArg(L, 1)
+			if err != nil {
+				return pushValue(L, nil, err)
+			}
+			v, err := readFields(pr.PathNames("spells"), pr.PathCharacters, name, "spells")
+			return pushValue(L, v, err)
+		},
+		"learnSpell": func(L *lua.LState) int {
+			name, err := characterArg(L, 1)
+			if err != nil {
+				return pushResult(L, err)
+			}
+			path := pr.JoinPath(pr.PathCharacters, name, "spells", nameArg(L, 2))
+			if L.GetTop() >= 3 {
+				return pushResult(L, pr.Changes.Set(path, FromLua(L.Get(3))))
+			}
+			return pushResult(L, pr.Changes.Add(path, nil))
+		},
+		"forgetSpell": func(L *lua.LState) int {
+			name, err := characterArg(L, 1)
+			if err != nil {
+				return pushResult(L, err)
+			}
+			return pushResult(L, pr.Changes.Remove(pr.JoinPath(pr.PathCharacters, name, "spells", nameArg(L, 2))))
+		},
+		"learnAllSpells": func(L *lua.LState) int {
+			name, err := characterArg(L, 1)
+			if err != nil {
+				return pushResult(L, err)
+			}
+			return pushResult(L, pr.Changes.Edit("Learn all spells", func() error {
+				for _, spell := range pr.PathNames("spells") {
+					if err := pr.AddPath(pr.JoinPath(pr.PathCharacters, name, "spells", spell), nil); err != nil {
+						return err
+					}
+				}
+				return nil
+			}))
+		},
+
+		// Commands and equipment
+		"getCommands": func(L *lua.LState) int {
+			name, err := characterArg(L, 1)
+			if err != nil {
+				return pushValue(L, nil, err)
+			}
+			c := pr.FindCharacter(name)
+			if c == nil {
+				return pushValue(L, nil, fmt.Errorf("unknown character %q", name))
+			}
+			commands := make([]interface{}, len(c.Commands))
+			for i := range commands {
+				if commands[i], err = pr.GetPath(pr.JoinPath(pr.PathCharacters, name, "commands", strconv.Itoa(i))); err != nil {
+					return pushValue(L, nil, err)
+				}
+			}
+			return pushValue(L, commands, nil)
+		},
+		"setCommand": func(L *lua.LState) int {
+			name, err := characterArg(L, 1)
+			if err != nil {
+				return pushResult(L, err)
+			}
+			path := pr.JoinPath(pr.PathCharacters, name, "commands", strconv.Itoa(L.CheckInt(2)))
+			return pushResult(L, pr.Changes.Set(path, FromLua(L.CheckAny(3))))
+		},
+		"getEquipment": func(L *lua.LState) int {
+			name, err := characterArg(L, 1)
+			if err != nil {
+				return pushValue(L, nil, err)
+			}
+			v, err := readFields(pr.PathFields("equipment"), pr.PathCharacters, name, "equipment")
+			return pushValue(L, v, err)
+		},
+		"equip": func(L *lua.LState) int {
+			name, err := characterArg(L, 1)
+			if err != nil {
+				return pushResult(L, err)
+			}
+			path := pr.JoinPath(pr.PathCharacters, name, "equipment", L.CheckString(2))
+			return pushResult(L, pr.Changes.Set(path, FromLua(L.CheckAny(3))))
+		},
+
+		// Inventory
+		"getInventory": func(L *lua.LState) int {
+			return pushValue(L, inventoryRows(pri.GetInventory()), nil)
+		},
+		"getItemCount": func(L *lua.LState) int {
+			v, err := pr.GetPath(pr.JoinPath(pr.PathInventory, nameArg(L, 1)))
+			return pushValue(L, v, err)
+		},
+		"setItemCount": func(L *lua.LState) int {
+			return pushResult(L, pr.Changes.Set(pr.JoinPath(pr.PathInventory, nameArg(L, 1)), L.CheckInt(2)))
+		},
+		"addItem": func(L *lua.LState) int {
+			return pushResult(L, pr.Changes.Add(pr.JoinPath(pr.PathInventory, nameArg(L, 1)), L.OptInt(2, 1)))
+		},
+		"removeItem": func(L *lua.LState) int {
+			return pushResult(L, pr.Changes.Remove(pr.JoinPath(pr.PathInventory, nameArg(L, 1))))
+		},
+		"getImportantItems": func(L *lua.LState) int {
+			return pushValue(L, inventoryRows(pri.GetImportantInventory()), nil)
+		},
+		"hasImportantItem": func(L *lua.LState) int {
+			v, err := pr.GetPath(pr.JoinPath(pr.PathImportantItems, nameArg(L, 1)))
+			if err == nil {
+				v = v.(int) > 0
+			}
+			return pushValue(L, v, err)
+		},
+		"addImportantItem": func(L *lua.LState) int {
+			return pushResult(L, pr.Changes.Add(pr.JoinPath(pr.PathImportantItems, nameArg(L, 1)), 1))
+		},
+		"removeImportantItem": func(L *lua.LState) int {
+			return pushResult(L, pr.Changes.Remove(pr.JoinPath(pr.PathImportantItems, nameArg(L, 1))))
+		},
+
+		// Espers
+		"getEspers": func(L *lua.LState) int {
+			v, err := learned(pr.PathEspers)
+			return pushValue(L, v, err)
+		},
+		"hasEsper": func(L *lua.LState) int {
+			v, err := pr.GetPath(pr.JoinPath(pr.PathEspers, nameArg(L, 1)))
+			return pushValue(L, v, err)
+		},
+		"addEsper": func(L *lua.LState) int {
+			return pushResult(L, pr.Changes.Add(pr.JoinPath(pr.PathEspers, nameArg(L, 1)), nil))
+		},
+		"removeEsper": func(L *lua.LState) int {
+			return pushResult(L, pr.Changes.Remove(pr.JoinPath(pr.PathEspers, nameArg(L, 1))))
+		},
+
+		// Skills
+		"getSkills": func(L *lua.LState) int {
+			kind, err := skillKind(L.CheckString(1))
+			if err != nil {
+				return pushValue(L, nil, err)
+			}
+			v, err := learned(kind)
+			return pushValue(L, v, err)
+		},
+		"hasSkill": func(L *lua.LState) int {
+			kind, err := skillKind(L.CheckString(1))
+			if err != nil {
+				return pushValue(L, nil, err)
+			}
+			v, err := pr.GetPath(pr.JoinPath(kind, nameArg(L, 2)))
+			return pushValue(L, v, err)
+		},
+		"learnSkill": func(L *lua.LState) int {
+			kind, err := skillKind(L.CheckString(1))
+			if err != nil {
+				return pushResult(L, err)
+			}
+			return pushResult(L, pr.Changes.Add(pr.JoinPath(kind, nameArg(L, 2)), nil))
+		},
+		"forgetSkill": func(L *lua.LState) int {
+			kind, err := skillKind(L.CheckString(1))
+			if err != nil {
+				return pushResult(L, err)
+			}
+			return pushResult(L, pr.Changes.Remove(pr.JoinPath(kind, nameArg(L, 2))))
+		},
+		"learnAllSkills": func(L *lua.LState) int {
+			kind, err := skillKind(L.CheckString(1))
+			if err != nil {
+				return pushResult(L, err)
+			}
+			return pushResult(L, pr.Changes.Edit("Learn all "+kind, func() error {
+				for _, name := range pr.PathNames(kind) {
+					if err := pr.AddPath(pr.JoinPath(kind, name), nil); err != nil {
+						return err
+					}
+				}
+				return nil
+			}))
+		},
+
+		// Veldt
+		"getVeldt": func(L *lua.LState) int {
+			return pushValue(L, pri.GetVeldt().Encounters, nil)
+		},
+		"setVeldt": func(L *lua.LState) int {
+			path := pr.JoinPath(pr.PathVeldt, strconv.Itoa(L.CheckInt(1)))
+			return pushResult(L, pr.Changes.Set(path, L.CheckBool(2)))
+		},
+
+		// Party
+		"getParty": func(L *lua.LState) int {
+			members := make([]interface{}, len(pri.GetParty().Members))
+			for i := range members {
+				var err error
+				if members[i], err = pr.GetPath(pr.JoinPath(pr.PathParty, strconv.Itoa(i))); err != nil {
+					return pushValue(L, nil, err)
+				}
+			}
+			return pushValue(L, members, nil)
+		},
+		"setParty": func(L *lua.LState) int {
+			members := L.CheckTable(1)
+			return pushResult(L, pr.Changes.Edit("Set party", func() error {
+				for i := range pri.GetParty().Members {
+					if err := setPartyMember(i, members.RawGetInt(i+1)); err != nil {
+						return err
+					}
+				}
+				return nil
+			}))
+		},
+		"setPartyMember": func(L *lua.LState) int {
+			slot := L.CheckInt(1)
+			return pushResult(L, pr.Changes.Edit("Set party", func() error {
+				return setPartyMember(slot, L.Get(2))
+			}))
+		},
+
+		// Map, transportation and misc
+		"getMap": func(L *lua.LState) int {
+			v, err := readFields(pr.PathFields(pr.PathMap), pr.PathMap)
+			return pushValue(L, v, err)
+		},
+		"setMap": func(L *lua.LState) int {
+			return pushResult(L, writeFields(L.CheckTable(1), pr.PathMap))
+		},
+		"getTransportation": func(L *lua.LState) int {
+			list := make([]interface{}, len(pri.Transportations))
+			for i, t := range pri.Transportations {
+				if t == nil {
+					list[i] = map[string]interface{}{}
+					continue
+				}
+				v, err := readFields(pr.PathFields(pr.PathTransportation), pr.PathTransportation, strconv.Itoa(i))
+				if err != nil {
+					return pushValue(L, nil, err)
+				}
+				list[i] = v
+			}
+			return pushValue(L, list, nil)
+		},
+		"setTransportation": func(L *lua.LState) int {
+			return pushResult(L, writeFields(L.CheckTable(2), pr.PathTransportation, strconv.Itoa(L.CheckInt(1))))
+		},
+		"getMisc": func(L *lua.LState) int {
+			v, err := readFields(pr.PathFields(pr.PathMisc), pr.PathMisc)
+			return pushValue(L, v, err)
+		},
+		"setMisc": func(L *lua.LState) int {
+			return pushResult(L, writeFields(L.CheckTable(1), pr.PathMisc))
+		},
+
+		// Lookups
+		"itemName": func(L *lua.LState) int {
+			L.Push(lua.LString(pr.ItemName(L.CheckInt(1))))
+			return 1
+		},
+		"itemID": func(L *lua.LState) int {
+			name := nameArg(L, 1)
+			id, err := pr.ResolveItemID(name, constsPR.ItemsByName, constsPR.ItemsByID)
+			if err != nil {
+				if important, e := pr.ResolveItemID(name, constsPR.ImportantItemsByName, constsPR.ImportantItemsByID); e == nil {
+					id, err = important, nil
+				}
+			}
+			return pushValue(L, id, err)
+		},
+	}
+
+	for name, fn := range functions {
+		L.SetField(saveTable, name, L.NewFunction(fn))
+	}
+}
+
+// characterArg returns the name of the character given by name or character
+// ID in argument n
+func characterArg(L *lua.LState, n int) (string, error) {
+	switch v := L.CheckAny(n).(type) {
+	case lua.LString:
+		return string(v), nil
+	case lua.LNumber:
+		for _, c := range pri.Characters {
+			if c != nil && c.ID == int(v) {
+				return c.RootName, nil
+			}
+		}
+		return "", fmt.Errorf("unknown character ID %d", int(v))
+	}
+	L.ArgError(n, "character name or ID expected")
+	return "", nil
+}
+
+// nameArg returns argument n, a name or a numeric ID, as a path segment
+func nameArg(L *lua.LState, n int) string {
+	switch v := L.CheckAny(n).(type) {
+	case lua.LString:
+		return string(v)
+	case lua.LNumber:
+		return strconv.Itoa(int(v))
+	}
+	L.ArgError(n, "name or ID expected")
+	return ""
+}
+
+// skillKind checks that kind is one of the skill categories
+func skillKind(kind string) (string, error) {
+	for _, k := range skillKinds {
+		if strings.EqualFold(k, strings.TrimSpace(kind)) {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown skill kind %q", kind)
+}
+
+// readFields reads the fields under a path prefix into a table
+func readFields(fields []string, prefix ...string) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		v, err := pr.GetPath(pr.JoinPath(append(prefix, f)...))
+		if err != nil {
+			return nil, err
+		}
+		values[f] = v
+	}
+	return values, nil
+}
+
+// writeFields sets the fields of a table under a path prefix as one edit.
+// Nested tables address deeper paths, as in {equipment = {weapon = "Atma
+// Weapon"}}. Fields are written in name order, stopping at the first error.
+func writeFields(tbl *lua.LTable, prefix ...string) error {
+	return pr.Changes.Edit("Lua script", func() error {
+		return writeTable(tbl, prefix)
+	})
+}
+
+func writeTable(tbl *lua.LTable, prefix []string) error {
+	keys := make([]string, 0, tableSize(tbl))
+	values := make(map[string]lua.LValue)
+	tbl.ForEach(func(k, v lua.LValue) {
+		key := k.String()
+		keys = append(keys, key)
+		values[key] = v
+	})
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := append(append([]string{}, prefix...), key)
+		if sub, ok := values[key].(*lua.LTable); ok {
+			if err := writeTable(sub, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := pr.SetPath(pr.JoinPath(path...), FromLua(values[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// learned returns the names checked in a collection such as espers
+func learned(category string) ([]string, error) {
+	names := make([]string, 0)
+	for _, name := range pr.PathNames(category) {
+		v, err := pr.GetPath(pr.JoinPath(category, name))
+		if err != nil {
+			return nil, err
+		}
+		if b, _ := v.(bool); b {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// inventoryRows lists the items held as {id, name, count} tables
+func inventoryRows(inv *pri.Inventory) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(inv.Rows))
+	for _, r := range inv.Rows {
+		if r == nil || r.ItemID == 0 || r.Count <= 0 {
+			continue
+		}
+		rows = append(rows, map[string]interface{}{
+			"id":    r.ItemID,
+			"name":  pr.ItemName(r.ItemID),
+			"count": r.Count,
+		})
+	}
+	return rows
+}
+
+// setPartyMember puts the character given by name or ID in a party slot;
+// nil, false and "" empty it
+func setPartyMember(slot int, member lua.LValue) error {
+	path := pr.JoinPath(pr.PathParty, strconv.Itoa(slot))
+	switch v := member.(type) {
+	case lua.LNumber:
+		for _, c := range pri.Characters {
+			if c != nil && c.ID == int(v) {
+				return pr.SetPath(path, c.Name)
+			}
+		}
+		return fmt.Errorf("unknown character ID %d", int(v))
+	case lua.LString:
+		if v != "" {
+			return pr.SetPath(path, string(v))
+		}
+	case *lua.LNilType, lua.LBool:
+		if lua.LVAsBool(v) {
+			return fmt.Errorf("party slot %d: expected a character", slot)
+		}
+	default:
+		return fmt.Errorf("party slot %d: expected a character, got %s", slot, member.Type())
+	}
+	return pr.RemovePath(path)
+}
@@ -0,0 +1,76 @@
+package scripting
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+	pri "ffvi_editor/models/pr"
+)
+
+// loadSave loads the test save for the save table
+func loadSave(t *testing.T) *pr.PR {
+	t.Helper()
+	save := pr.New()
+	if err := save.Load(testSave, global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	return save
+}
+
+func TestSaveTable(t *testing.T) {
+	save := loadSave(t)
+
+	_, err := RunSnippetWithSave(context.Background(), `
+		-- Characters by name or ID
+		assert(save.setCharacter("Terra", {level = 30, vigor = 50, equipment = {weapon = "Dagger"}}))
+		local terra = save.getCharacter(1)
+		assert(terra.level == 30 and terra.vigor == 50, "getCharacter by ID")
+		assert(save.getEquipment("Terra").weapon == "Dagger", "equipment")
+		local ok, msg = save.setCharacter("Terra", {level = 500})
+		assert(not ok and msg:find("out of range"), "range checks")
+
+		-- Spells, items, espers and skills by name or ID
+		assert(save.learnSpell("Terra", "Fire"))
+		assert(save.getSpells("Terra")["Fire"] == 100, "learnSpell")
+		assert(save.setItemCount("Potion", 42))
+		assert(save.getItemCount(save.itemID("Potion")) == 42, "items by ID")
+		assert(save.addEsper("Ramuh") and save.hasEsper("Ramuh"), "espers")
+		assert(save.learnAllSkills("blitzes"))
+		assert(#save.getSkills("blitzes") == #save.names("blitzes"), "skills")
+		assert(not save.getSkills("potions"), "unknown skill kind")
+
+		-- Party, map and misc
+		assert(save.setParty({"Terra", 1, false, ""}))
+		local party = save.getParty()
+		assert(party[1] == party[2], "party by name and ID")
+		assert(save.setMisc({gil = 777}))
+		assert(save.getGil() == 777 and save.getMisc().gil == 777, "misc")
+		assert(type(save.getMap().mapId) == "number", "map")`, save)
+	if err != nil {
+		t.Fatalf("script failed: %v", err)
+	}
+
+	if c := pri.GetCharacter("Terra"); c.Level != 30 {
+		t.Errorf("expected Terra at level 30, got %d", c.Level)
+	}
+}
+
+func TestBuiltInScripts(t *testing.T) {
+	for name, script := range BuiltInScripts() {
+		t.Run(name, func(t *testing.T) {
+			save := loadSave(t)
+			vm := NewVM(0)
+			defer vm.Close()
+			vm.SetOutput(io.Discard)
+			if err := vm.SetSave(save); err != nil {
+				t.Fatal(err)
+			}
+			if err := vm.Execute(context.Background(), script); err != nil {
+				t.Fatalf("%s failed: %v", name, err)
+			}
+		})
+	}
+}
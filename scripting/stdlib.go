@@ -197,15 +197,14 @@ const (
 	MaxStatsScript = `
 -- Maximize all character stats
 function maxAllStats()
-    for charID = 0, 15 do
-        setCharacterLevel(charID, 99)
-        setCharacterHP(charID, 9999)
-        setCharacterMP(charID, 999)
-        setCharacterStat(charID, "vigor", 128)
-        setCharacterStat(charID, "speed", 128)
-        setCharacterStat(charID, "stamina", 128)
-        setCharacterStat(charID, "magic", 128)
-        log("Maxed stats for character " .. charID)
+    for _, name in ipairs(save.characters()) do
+        save.setCharacter(name, {
+            level = 99,
+            maxHp = 9999, hp = 9999,
+            maxMp = 999, mp = 999,
+            vigor = 128, speed = 128, stamina = 128, magic = 128,
+        })
+        print("Maxed stats for " .. name)
     end
     print("All characters maximized!")
 end
@@ -217,8 +216,12 @@ maxAllStats()
 	GiveAllItemsScript = `
 -- Give 99 of all items
 function giveAllItems()
-    for itemID = 0, 255 do
-        setItemCount(itemID, 99)
+    for _, item in ipairs(save.names("inventory")) do
+        local ok, err = save.setItemCount(item, 99)
+        if not ok then
+            print("Stopped at " .. item .. ": " .. err)
+            return
+        end
     end
     print("Gave 99 of all items!")
 end
@@ -230,11 +233,9 @@ giveAllItems()
 	LearnAllMagicScript = `
 -- Learn all magic for all characters
 function learnAllMagic()
-    for charID = 0, 15 do
-        for spellID = 0, 53 do
-            learnMagic(charID, spellID)
-        end
-        log("Learned all magic for character " .. charID)
+    for _, name in ipairs(save.characters()) do
+        save.learnAllSpells(name)
+        print("Learned all magic for " .. name)
     end
     print("All characters know all magic!")
 end
@@ -246,14 +247,18 @@ learnAllMagic()
 	BalancedPartyScript = `
 -- Create balanced party
 function createBalancedParty()
-    -- Terra, Edgar, Celes, Sabin
-    setPartyMembers({0, 1, 6, 5})
-    
-    -- Set levels to 50
-    for _, charID in ipairs({0, 1, 6, 5}) do
-        setCharacterLevel(charID, 50)
+    local members = {"Terra", "Edgar", "Celes", "Sabin"}
+    for slot, name in ipairs(members) do
+        -- Characters who haven't joined yet can't be in the party
+        local ok, err = save.setPartyMember(slot - 1, name)
+        if not ok then
+            print("Skipped " .. name .. ": " .. err)
+        end
+
+        -- Set levels to 50
+        save.setCharacter(name, {level = 50})
     end
-    
+
     print("Created balanced party!")
 end
 
@@ -2,27 +2,56 @@ package scripting
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"sync"
 	"time"
+
+	"ffvi_editor/io/pr"
+	"ffvi_editor/plugins"
+
+	lua "github.com/yuin/gopher-lua"
 )
 
-// VM wraps a Lua VM with sandboxing and timeout support
+// DefaultTimeout bounds the runs of a VM created without a timeout
+const DefaultTimeout = 30 * time.Second
+
+// VM is a sandboxed Lua state. Only the safe standard libraries are open,
+// package.path is limited to the plugins directory and every run is bound by
+// the timeout, an instruction budget and a memory ceiling. Globals and
+// functions persist between runs. A plugin API set with SetAPI is published
+// as the "editor" module, and a save set with SetSave as the "save" table.
 type VM struct {
-	timeout   time.Duration
-	maxMemory int
-	modules   map[string]bool
-	api       interface{}
-	mu        sync.RWMutex
-	running   bool
+	timeout         time.Duration
+	maxMemory       int
+	maxInstructions int64
+	modules         map[string]bool
+	api             interface{}
+	save            *pr.PR
+	sandbox         *plugins.SandboxManager
+	pluginID        string
+	mu              sync.RWMutex
+	current         *scriptRun
+	state           *lua.LState
+	output          io.Writer
 }
 
-// NewVM creates a new Lua VM instance
+// NewVM creates a new Lua VM instance; a timeout of zero or less uses
+// DefaultTimeout
 func NewVM(timeout time.Duration) *VM {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
 	vm := &VM{
-		timeout:   timeout,
-		maxMemory: 50 * 1024 * 1024, // 50MB default
-		modules:   make(map[string]bool),
+		timeout:         timeout,
+		maxMemory:       DefaultMaxMemory,
+		maxInstructions: DefaultMaxInstructions,
+		modules:         make(map[string]bool),
+		state:           newSandboxState(nil),
+		output:          os.Stdout,
 	}
 
 	// Only allow safe modules
@@ -31,60 +60,71 @@ func NewVM(timeout time.Duration) *VM {
 	vm.modules["math"] = true
 	vm.modules["utf8"] = true
 
+	vm.state.SetGlobal("print", vm.state.NewFunction(vm.print))
 	return vm
 }
 
-// SetAPI sets the plugin API for Lua scripts
+// SetAPI sets the plugin API for Lua scripts. A plugins.PluginAPI is
+// published as the "editor" module; nil removes the module.
 func (vm *VM) SetAPI(api interface{}) {
 	vm.mu.Lock()
 	vm.api = api
+	L := vm.state
 	vm.mu.Unlock()
-}
+	if L == nil {
+		return
+	}
 
-// Execute executes Lua code with sandboxing
-func (vm *VM) Execute(ctx context.Context, code string) error {
-	if code == "" {
-		return fmt.Errorf("code is empty")
+	if pluginAPI, ok := api.(plugins.PluginAPI); ok && pluginAPI != nil {
+		_ = NewBindings(pluginAPI, vm).Register(context.Background())
+		return
 	}
+	L.SetGlobal("editor", lua.LNil)
+}
 
-	vm.mu.Lock()
-	if vm.running {
-		vm.mu.Unlock()
-		return fmt.Errorf("script already running")
+// SetSave publishes the save table of RunSnippetWithSave for a loaded save;
+// nil removes it
+func (vm *VM) SetSave(save *pr.PR) error {
+	L, err := vm.idleState()
+	if err != nil {
+		return err
 	}
-	vm.running = true
+	vm.mu.Lock()
+	vm.save = save
 	vm.mu.Unlock()
 
-	defer func() {
-		vm.mu.Lock()
-		vm.running = false
-		vm.mu.Unlock()
-	}()
+	if save == nil {
+		L.SetGlobal("save", lua.LNil)
+		return nil
+	}
+	registerSaveBindings(L, save)
+	return nil
+}
 
-	// Create context with timeout
-	execCtx, cancel := context.WithTimeout(ctx, vm.timeout)
-	defer cancel()
-
-	// Execute code in goroutine
-	done := make(chan error, 1)
-	go func() {
-		// Placeholder for actual Lua execution
-		// Real implementation would:
-		// 1. Create Lua state
-		// 2. Load restricted libraries
-		// 3. Register API bindings
-		// 4. Load and run code
-		// 5. Handle errors and cleanup
-		done <- nil
-	}()
+// SetSandbox runs the VM's scripts as a plugin: the plugin's sandbox policy
+// overrides the VM's limits where it sets them, and runs stopped by a limit
+// are reported to sm as violations. A nil sm stops that.
+func (vm *VM) SetSandbox(sm *plugins.SandboxManager, pluginID string) {
+	vm.mu.Lock()
+	vm.sandbox, vm.pluginID = sm, pluginID
+	vm.mu.Unlock()
+}
 
-	// Wait for execution or timeout
-	select {
-	case err := <-done:
-		return err
-	case <-execCtx.Done():
-		return fmt.Errorf("execution timeout: %w", execCtx.Err())
+// SetOutput sets where print writes; the default is standard output
+func (vm *VM) SetOutput(w io.Writer) {
+	vm.mu.Lock()
+	vm.output = w
+	vm.mu.Unlock()
+}
+
+// Execute executes Lua code with sandboxing
+func (vm *VM) Execute(ctx context.Context, code string) error {
+	if code == "" {
+		return fmt.Errorf("code is empty")
 	}
+	return vm.run(ctx, func(L *lua.LState) error {
+		return L.DoString(code)
+	})
 }
 
 // ExecuteFile executes a Lua file with sandboxing
@@ -92,43 +132,65 @@ func (vm *VM) ExecuteFile(ctx context.Context, filepath string) error {
 	if filepath == "" {
 		return fmt.Errorf("filepath is empty")
 	}
-
-	// Placeholder for actual file execution
-	// Real implementation would read file and call Execute()
-	return fmt.Errorf("not implemented: file execution requires actual Lua VM")
+	if _, err := os.Stat(filepath); err != nil {
+		return fmt.Errorf("failed to read script: %w", err)
+	}
+	return vm.run(ctx, func(L *lua.LState) error {
+		return L.DoFile(filepath)
+	})
 }
 
-// Call calls a Lua function with arguments
+// Call calls a Lua function with arguments and returns its first result
+// through FromLua. The name may be dotted, as in "editor.log".
 func (vm *VM) Call(ctx context.Context, functionName string, args ...interface{}) (interface{}, error) {
 	if functionName == "" {
 		return nil, fmt.Errorf("function name is empty")
 	}
 
-	// Placeholder for actual function call
-	// Real implementation would use Lua state to call function
-	return nil, fmt.Errorf("not implemented: function calls require actual Lua VM")
+	var result interface{}
+	err := vm.run(ctx, func(L *lua.LState) error {
+		fn, ok := lookup(L, functionName).(*lua.LFunction)
+		if !ok {
+			return fmt.Errorf("%s is not a function", functionName)
+		}
+		values := make([]lua.LValue, len(args))
+		for i, arg := range args {
+			values[i] = ToLua(L, arg)
+		}
+		if err := L.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, values...); err != nil {
+			return err
+		}
+		result = FromLua(L.Get(-1))
+		L.Pop(1)
+		return nil
+	})
+	return result, err
 }
 
-// SetGlobal sets a global variable in Lua
+// SetGlobal sets a global variable in Lua, converting the value with ToLua
 func (vm *VM) SetGlobal(name string, value interface{}) error {
 	if name == "" {
 		return fmt.Errorf("variable name is empty")
 	}
-
-	// Placeholder for actual global setting
-	// Real implementation would use Lua state to set global
+	L, err := vm.idleState()
+	if err != nil {
+		return err
+	}
+	L.SetGlobal(name, ToLua(L, value))
 	return nil
 }
 
-// GetGlobal gets a global variable from Lua
+// GetGlobal gets a global variable from Lua, converted with FromLua. The
+// name may be dotted.
 func (vm *VM) GetGlobal(name string) (interface{}, error) {
 	if name == "" {
 		return nil, fmt.Errorf("variable name is empty")
 	}
-
-	// Placeholder for actual global retrieval
-	// Real implementation would use Lua state to get global
-	return nil, fmt.Errorf("not implemented: global retrieval requires actual Lua VM")
+	L, err := vm.idleState()
+	if err != nil {
+		return nil, err
+	}
+	return FromLua(lookup(L, name)), nil
 }
 
 // Close closes the VM and cleans up resources
@@ -136,8 +198,13 @@ func (vm *VM) Close() error {
 	vm.mu.Lock()
 	defer vm.mu.Unlock()
 
+	if vm.state != nil {
+		vm.state.Close()
+		vm.state = nil
+	}
 	vm.api = nil
-	vm.running = false
+	vm.save = nil
+	vm.current = nil
 	return nil
 }
 
@@ -169,18 +236,47 @@ func (vm *VM) GetMaxMemory() int {
 	return vm.maxMemory
 }
 
+// SetMaxInstructions sets the number of Lua instructions a run may execute;
+// zero or less is unlimited
+func (vm *VM) SetMaxInstructions(n int64) {
+	vm.mu.Lock()
+	vm.maxInstructions = n
+	vm.mu.Unlock()
+}
+
+// GetMaxInstructions gets the instruction budget of a run
+func (vm *VM) GetMaxInstructions() int64 {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+	return vm.maxInstructions
+}
+
 // LoadLibrary loads a standard library module
 func (vm *VM) LoadLibrary(name string) error {
 	if !vm.modules[name] {
 		return fmt.Errorf("library %s is not allowed in sandbox", name)
 	}
 
-	// Placeholder for actual library loading
-	// Real implementation would load Lua standard libraries safely
+	open := map[string]lua.LGFunction{
+		"table":  lua.OpenTable,
+		"string": lua.OpenString,
+		"math":   lua.OpenMath,
+	}[name]
+	if open == nil {
+		return fmt.Errorf("library %s is not available", name)
+	}
+	L, err := vm.idleState()
+	if err != nil {
+		return err
+	}
+	open(L)
 	return nil
 }
 
-// RegisterFunction registers a Go function as a Lua global
+// RegisterFunction registers a Go function as a Lua global; a dotted name
+// such as "editor.log" sets a field of a global table, creating the tables
+// on the way. fn is a lua.LGFunction or any Go function, whose arguments
+// and results are converted as described by wrapFunction.
 func (vm *VM) RegisterFunction(name string, fn interface{}) error {
 	if name == "" {
 		return fmt.Errorf("function name is empty")
@@ -189,12 +285,217 @@ func (vm *VM) RegisterFunction(name string, fn interface{}) error {
 		return fmt.Errorf("function is nil")
 	}
 
-	// Placeholder for actual function registration
-	// Real implementation would register the Go function in Lua
+	lfn, err := wrapFunction(fn)
+	if err != nil {
+		return err
+	}
+	L, err := vm.idleState()
+	if err != nil {
+		return err
+	}
+
+	parts := strings.Split(name, ".")
+	if len(parts) == 1 {
+		L.SetGlobal(name, L.NewFunction(lfn))
+		return nil
+	}
+	tbl, ok := L.GetGlobal(parts[0]).(*lua.LTable)
+	if !ok {
+		tbl = L.NewTable()
+		L.SetGlobal(parts[0], tbl)
+	}
+	for _, part := range parts[1 : len(parts)-1] {
+		next, ok := tbl.RawGetString(part).(*lua.LTable)
+		if !ok {
+			next = L.NewTable()
+			tbl.RawSetString(part, next)
+		}
+		tbl = next
+	}
+	tbl.RawSetString(parts[len(parts)-1], L.NewFunction(lfn))
 	return nil
 }
+
+// Cancel stops the running script, if any; its run returns the error of a
+// cancelled context
+func (vm *VM) Cancel() {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+	if vm.current != nil {
+		vm.current.cancel()
+	}
+}
+
+// IsRunning reports whether a script is running
 func (vm *VM) IsRunning() bool {
 	vm.mu.RLock()
 	defer vm.mu.RUnlock()
-	return vm.running
+	return vm.current != nil
+}
+
+// run runs fn on the Lua state within the VM's limits. Runs with an editor
+// API or a save are one tracked edit, so their changes to the save undo
+// together.
+func (vm *VM) run(ctx context.Context, fn func(L *lua.LState) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	vm.mu.Lock()
+	if vm.state == nil {
+		vm.mu.Unlock()
+		return fmt.Errorf("VM is closed")
+	}
+	if vm.current != nil {
+		vm.mu.Unlock()
+		return fmt.Errorf("script already running")
+	}
+	L, tracked := vm.state, vm.api != nil || vm.save != nil
+	sandbox, pluginID := vm.sandbox, vm.pluginID
+	current := &scriptRun{}
+	q := newQuota(context.WithValue(ctx, runKey{}, current), L, vm.limitsLocked())
+	current.cancel = q.cancel
+	vm.current = current
+	vm.mu.Unlock()
+
+	defer func() {
+		vm.mu.Lock()
+		if vm.current == current {
+			vm.current = nil
+		}
+		vm.mu.Unlock()
+	}()
+
+	defer q.Release()
+	L.SetContext(q)
+	defer L.RemoveContext()
+
+	var err error
+	if tracked {
+		err = pr.Changes.Edit("Lua script", func() error { return fn(L) })
+	} else {
+		err = fn(L)
+	}
+	if err == nil {
+		return nil
+	}
+	if exceeded := q.Exceeded(); exceeded != nil {
+		if sandbox != nil {
+			sandbox.ReportViolation(pluginID, violationType(exceeded), exceeded.Error())
+		}
+		return exceeded
+	}
+	if errors.Is(q.Context.Err(), context.Canceled) {
+		return fmt.Errorf("script stopped: %w", context.Canceled)
+	}
+	return err
+}
+
+// limitsLocked returns the limits of a run, those of the sandbox policy first.
+// The caller must hold the lock.
+func (vm *VM) limitsLocked() Limits {
+	limits := Limits{
+		Timeout:         vm.timeout,
+		MaxInstructions: vm.maxInstructions,
+		MaxMemory:       int64(vm.maxMemory),
+	}
+	if vm.sandbox == nil {
+		return limits
+	}
+	if p := vm.sandbox.GetPolicy(vm.pluginID); p != nil && p.IsActive {
+		if p.TimeoutSeconds > 0 {
+			limits.Timeout = time.Duration(p.TimeoutSeconds) * time.Second
+		}
+		if p.MaxInstructions > 0 {
+			limits.MaxInstructions = p.MaxInstructions
+		}
+		if p.MaxMemoryMB > 0 {
+			limits.MaxMemory = int64(p.MaxMemoryMB) * 1024 * 1024
+		}
+	}
+	return limits
+}
+
+// violationType names an exceeded limit as a sandbox violation type
+func violationType(err error) string {
+	switch {
+	case errors.Is(err, ErrInstructionLimit):
+		return "cpu_exceeded"
+	case errors.Is(err, ErrMemoryLimit):
+		return "memory_exceeded"
+	}
+	return "timeout"
+}
+
+// scriptRun is a script running on the VM's state
+type scriptRun struct {
+	cancel context.CancelFunc
+}
+
+// runKey is the context key of the scriptRun that made a call
+type runKey struct{}
+
+// callHook calls a Lua hook callback with the event data. Events the running
+// script fires, with its context, call it within that script on the same
+// goroutine. The Lua state isn't safe for concurrent use, so events from
+// anywhere else are a run of their own and fail while a script is running.
+func (vm *VM) callHook(ctx context.Context, fn *lua.LFunction, data interface{}) error {
+	vm.mu.RLock()
+	L, current := vm.state, vm.current
+	vm.mu.RUnlock()
+	if L == nil {
+		return fmt.Errorf("VM is closed")
+	}
+
+	call := func(L *lua.LState) error {
+		return L.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true}, ToLua(L, data))
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if current != nil && ctx.Value(runKey{}) == current {
+		return call(L)
+	}
+	return vm.run(ctx, call)
+}
+
+// idleState returns the Lua state when no script is using it
+func (vm *VM) idleState() (*lua.LState, error) {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+	if vm.state == nil {
+		return nil, fmt.Errorf("VM is closed")
+	}
+	if vm.current != nil {
+		return nil, fmt.Errorf("script already running")
+	}
+	return vm.state, nil
+}
+
+// print writes its arguments to the VM's output like Lua's print
+func (vm *VM) print(L *lua.LState) int {
+	vm.mu.RLock()
+	w := vm.output
+	vm.mu.RUnlock()
+
+	parts := make([]string, L.GetTop())
+	for i := range parts {
+		parts[i] = L.ToStringMeta(L.Get(i + 1)).String()
+	}
+	fmt.Fprintln(w, strings.Join(parts, "\t"))
+	return 0
+}
+
+// lookup returns the value of a dotted global name, or nil
+func lookup(L *lua.LState, name string) lua.LValue {
+	parts := strings.Split(name, ".")
+	value := L.GetGlobal(parts[0])
+	for _, part := range parts[1:] {
+		tbl, ok := value.(*lua.LTable)
+		if !ok {
+			return lua.LNil
+		}
+		value = tbl.RawGetString(part)
+	}
+	return value
 }
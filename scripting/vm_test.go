@@ -0,0 +1,195 @@
+package scripting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ffvi_editor/global"
+	"ffvi_editor/io/pr"
+	modelsPR "ffvi_editor/models/pr"
+	"ffvi_editor/plugins"
+)
+
+const testSave = "../save_data/76561198072182150/7nCxyzTwG31W3Zlg70mo751W8ETH1n+Km0dWOzRU84Y="
+
+// newEditorVM returns a VM with the editor module for the loaded test save
+func newEditorVM(t *testing.T) *VM {
+	t.Helper()
+	save := pr.New()
+	if err := save.Load(testSave, global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	vm := NewVM(5 * time.Second)
+	t.Cleanup(func() { _ = vm.Close() })
+	vm.SetAPI(plugins.NewAPIImpl(save, []string{
+		plugins.CommonPermissions.ReadSave,
+		plugins.CommonPermissions.WriteSave,
+		plugins.CommonPermissions.Events,
+	}))
+	return vm
+}
+
+func TestVMExecute(t *testing.T) {
+	vm := NewVM(time.Second)
+	defer vm.Close()
+	var out bytes.Buffer
+	vm.SetOutput(&out)
+
+	if err := vm.Execute(context.Background(), `x = 6 * 7 print("x is", x)`); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := out.String(); got != "x is\t42\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+	if v, err := vm.GetGlobal("x"); err != nil || v != float64(42) {
+		t.Errorf("GetGlobal(x) = %v, %v", v, err)
+	}
+
+	if err := vm.Execute(context.Background(), `return os.exit(1)`); err == nil {
+		t.Error("expected os to be unavailable in the sandbox")
+	}
+	vm.SetMaxInstructions(0)
+	if err := vm.Execute(context.Background(), `while true do end`); err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
+func TestVMGlobalsAndFunctions(t *testing.T) {
+	vm := NewVM(time.Second)
+	defer vm.Close()
+
+	if err := vm.SetGlobal("config", map[string]interface{}{"levels": []int{10, 20}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.RegisterFunction("util.add", func(a, b int) int { return a + b }); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.Execute(context.Background(), `function total() return util.add(config.levels[1], config.levels[2]) end`); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := vm.Call(context.Background(), "total"); err != nil || v != float64(30) {
+		t.Errorf("Call(total) = %v, %v", v, err)
+	}
+	if _, err := vm.Call(context.Background(), "missing"); err == nil {
+		t.Error("expected an error calling a missing function")
+	}
+}
+
+func TestEditorModule(t *testing.T) {
+	vm := newEditorVM(t)
+
+	err := vm.Execute(context.Background(), `
+		local editor = require("editor")
+		local terra = editor.getCharacter("Terra")
+		assert(terra and terra.name == "Terra", "getCharacter")
+		terra.level = 42
+		assert(editor.setCharacter("Terra", {level = terra.level}))
+
+		local found = editor.findCharacter(function(c) return c.level == 42 end)
+		assert(found and found.name == "Terra", "findCharacter")
+
+		local ok, msg = editor.setCharacter("Terra", {noSuchField = 1})
+		assert(not ok and msg:find("noSuchField"), "unknown fields fail")
+
+		seen = nil
+		editor.registerHook("test", function(data) seen = data.value end)
+		editor.fireEvent("test", {value = "fired"})`)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if c := modelsPR.GetCharacter("Terra"); c == nil || c.Level != 42 {
+		t.Errorf("expected Terra at level 42, got %+v", c)
+	}
+	if v, _ := vm.GetGlobal("seen"); v != "fired" {
+		t.Errorf("expected the hook to see the event data, got %v", v)
+	}
+
+	if err = vm.Execute(context.Background(), `editor.findItems(function(row) error("boom") end)`); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected the predicate error, got %v", err)
+	}
+}
+
+func TestHookAndCancelFromAnotherGoroutine(t *testing.T) {
+	api := plugins.NewAPIImpl(pr.New(), []string{plugins.CommonPermissions.Events})
+	var logged []string
+	api.SetLogger(func(level, msg string) { logged = append(logged, msg) })
+	vm := NewVM(5 * time.Second)
+	defer vm.Close()
+	vm.SetAPI(api)
+	if err := vm.Execute(context.Background(), `editor.registerHook("test", function(data) seen = data.value end)`); err != nil {
+		t.Fatal(err)
+	}
+
+	vm.SetMaxInstructions(0)
+	done := make(chan error)
+	go func() { done <- vm.Execute(context.Background(), `while true do end`) }()
+	for !vm.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The hook can't share the state with the running script
+	_ = api.FireEvent(context.Background(), "test", map[string]interface{}{"value": "busy"})
+	if len(logged) != 1 || !strings.Contains(logged[0], "script already running") {
+		t.Errorf("expected the hook to fail while the script runs, logged %q", logged)
+	}
+
+	vm.Cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the run to be cancelled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Cancel didn't stop the script")
+	}
+
+	_ = api.FireEvent(context.Background(), "test", map[string]interface{}{"value": "idle"})
+	if v, _ := vm.GetGlobal("seen"); v != "idle" {
+		t.Errorf("expected the hook to run once the script stopped, got %v", v)
+	}
+}
+
+func TestEditorEditsSave(t *testing.T) {
+	save := pr.New()
+	if err := save.Load(testSave, global.PC); err != nil {
+		t.Fatalf("failed to load test save: %v", err)
+	}
+	vm := NewVM(5 * time.Second)
+	defer vm.Close()
+	vm.SetAPI(plugins.NewAPIImpl(save, []string{plugins.CommonPermissions.ReadSave, plugins.CommonPermissions.WriteSave}))
+
+	err := vm.Execute(context.Background(), `
+		local editor = require("editor")
+		local terra = editor.getCharacter("Terra")
+		terra.exp = 12345
+		assert(editor.setCharacter("Terra", terra))
+		local inv = editor.getInventory()
+		inv.rows[1].count = 42
+		assert(editor.setInventory(inv))`)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if c := modelsPR.GetCharacter("Terra"); c == nil || c.Exp != 12345 {
+		t.Fatalf("expected Terra's exp to be 12345, got %+v", c)
+	}
+	item := modelsPR.GetInventory().Rows[0].ItemID
+
+	file := filepath.Join(t.TempDir(), "save")
+	if err = save.Save(save.SlotID(), file, global.PC); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err = pr.New().Load(file, global.PC); err != nil {
+		t.Fatalf("failed to load the saved file: %v", err)
+	}
+	if c := modelsPR.GetCharacter("Terra"); c == nil || c.Exp != 12345 {
+		t.Errorf("expected the saved exp to be 12345, got %+v", c)
+	}
+	if r := modelsPR.GetInventory().Rows[0]; r.ItemID != item || r.Count != 42 {
+		t.Errorf("expected 42 of item %d saved, got %+v", item, r)
+	}
+}
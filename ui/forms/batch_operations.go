@@ -100,7 +100,11 @@ func NewBatchOperationsDialogWithCallback(parent fyne.Window, executeCallback fu
 		}
 
 		if bod.executeCallback != nil {
-			if err := bod.executeCallback(bod.selectedOps); err != nil {
+			// All the selected operations undo as one step
+			err := ipr.Changes.Edit("Batch operations", func() error {
+				return bod.executeCallback(bod.selectedOps)
+			})
+			if err != nil {
 				dialog.ShowError(err, bod.window)
 			}
 		}
@@ -0,0 +1,109 @@
+package dialogs
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"ffvi_editor/io/pr"
+	"ffvi_editor/io/report"
+)
+
+// ExportReportDialog writes the loaded save, or the changes made to it since
+// it was opened, as an HTML or Markdown report
+type ExportReportDialog struct {
+	window  fyne.Window
+	name    string
+	current *pr.Snapshot
+	opened  *pr.Snapshot
+}
+
+// NewExportReportDialog creates an export dialog for the loaded save. opened
+// is the snapshot taken when the save was loaded; without it only the full
+// save report is offered.
+func NewExportReportDialog(window fyne.Window, name string, current, opened *pr.Snapshot) *ExportReportDialog {
+	return &ExportReportDialog{
+		window:  window,
+		name:    name,
+		current: current,
+		opened:  opened,
+	}
+}
+
+// Show displays the report options, then the file picker
+func (d *ExportReportDialog) Show() {
+	title := widget.NewEntry()
+	title.SetText("Save Report: " + d.name)
+
+	formatSelect := widget.NewSelect([]string{"HTML", "Markdown"}, nil)
+	formatSelect.SetSelected("HTML")
+
+	changes := widget.NewCheck("Only changes since the save was opened", func(on bool) {
+		if on && title.Text == "Save Report: "+d.name {
+			title.SetText("Save Changes: " + d.name)
+		} else if !on && title.Text == "Save Changes: "+d.name {
+			title.SetText("Save Report: " + d.name)
+		}
+	})
+	if d.opened == nil {
+		changes.Disable()
+	}
+
+	form := container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("Title", title),
+			widget.NewFormItem("Format", formatSelect),
+		),
+		changes,
+	)
+
+	dialog.ShowCustomConfirm("Export Report", "Export...", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+		f := report.HTML
+		if formatSelect.Selected == "Markdown" {
+			f = report.Markdown
+		}
+		d.save(title.Text, f, changes.Checked)
+	}, d.window)
+}
+
+// save asks for the output file and renders the report into it
+func (d *ExportReportDialog) save(title string, f report.Format, changesOnly bool) {
+	fd := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, d.window)
+			return
+		}
+		if w == nil {
+			return
+		}
+		defer w.Close()
+
+		if changesOnly {
+			diffs := pr.NewComparator(d.opened, d.current).Compare()
+			err = report.NewDiffDocument(title, d.name+" (opened)", d.name, &diffs).Render(w, f)
+		} else {
+			err = report.NewSaveDocument(title, d.current).Render(w, f)
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to write report: %w", err), d.window)
+			return
+		}
+		dialog.ShowInformation("Export Report", "Report written to "+w.URI().Path(), d.window)
+	}, d.window)
+
+	base := d.name
+	if ext := filepath.Ext(base); ext != "" {
+		base = base[:len(base)-len(ext)]
+	}
+	fd.SetFileName(base + f.Extension())
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{f.Extension()}))
+	fd.Show()
+}
@@ -0,0 +1,425 @@
+package dialogs
+
+import (
+	"fmt"
+	"image/color"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"ffvi_editor/io/history"
+)
+
+// chartColors are cycled through for the series of a chart
+var chartColors = []color.Color{
+	color.NRGBA{R: 100, G: 180, B: 255, A: 255},
+	color.NRGBA{R: 255, G: 170, B: 60, A: 255},
+	color.NRGBA{R: 120, G: 220, B: 120, A: 255},
+	color.NRGBA{R: 240, G: 100, B: 120, A: 255},
+	color.NRGBA{R: 200, G: 140, B: 255, A: 255},
+	color.NRGBA{R: 240, G: 230, B: 90, A: 255},
+	color.NRGBA{R: 90, G: 220, B: 210, A: 255},
+}
+
+// TimelineDialog shows the progression of a save slot over the points in
+// the history store
+type TimelineDialog struct {
+	window    fyne.Window
+	store     *history.Store
+	slot      string
+	timeline  *history.Timeline
+	content   *fyne.Container
+	onCapture func() (*history.Entry, error) // Records the loaded save, if any
+}
+
+// NewTimelineDialog creates a timeline dialog for a slot; an empty slot
+// starts with the first recorded slot
+func NewTimelineDialog(window fyne.Window, store *history.Store, slot string) *TimelineDialog {
+	if slot == "" {
+		if slots := store.Slots(); len(slots) > 0 {
+			slot = slots[0]
+		}
+	}
+	return &TimelineDialog{
+		window:  window,
+		store:   store,
+		slot:    slot,
+		content: container.NewMax(),
+	}
+}
+
+// OnCapture sets the callback of the "Capture Current Save" button
+func (d *TimelineDialog) OnCapture(f func() (*history.Entry, error)) {
+	d.onCapture = f
+}
+
+// Show displays the timeline dialog
+func (d *TimelineDialog) Show() {
+	slots := d.store.Slots()
+	if d.slot != "" && !containsString(slots, d.slot) {
+		slots = append(slots, d.slot)
+	}
+	names := make([]string, len(slots))
+	for i, s := range slots {
+		names[i] = filepath.Base(s)
+	}
+	slotSelect := widget.NewSelect(names, func(name string) {
+		for _, s := range slots {
+			if filepath.Base(s) == name {
+				d.slot = s
+			}
+		}
+		d.refresh()
+	})
+	if d.slot != "" {
+		slotSelect.SetSelected(filepath.Base(d.slot))
+	}
+
+	captureBtn := widget.NewButton("Capture Current Save", func() {
+		entry, err := d.onCapture()
+		if err != nil {
+			dialog.ShowError(err, d.window)
+			return
+		}
+		if entry == nil {
+			dialog.ShowInformation("Timeline", "The save is unchanged since the last point.", d.window)
+			return
+		}
+		d.slot = entry.Slot
+		d.refresh()
+	})
+	if d.onCapture == nil {
+		captureBtn.Disable()
+	}
+
+	d.refresh()
+	top := container.NewBorder(nil, nil, widget.NewLabel("Slot:"), captureBtn, slotSelect)
+	dlg := dialog.NewCustom("Progression Timeline", "Close", container.NewBorder(top, nil, nil, nil, d.content), d.window)
+	dlg.Resize(fyne.NewSize(800, 600))
+	dlg.Show()
+}
+
+// refresh rebuilds the tabs for the selected slot
+func (d *TimelineDialog) refresh() {
+	d.timeline = d.store.Timeline(d.slot)
+	d.content.RemoveAll()
+	if d.slot == "" || len(d.timeline.Entries) == 0 {
+		d.content.Add(widget.NewLabel("No points recorded yet. Points are added when you save, capture the\n" +
+			"current save, run \"watch --history\" or import backups with \"history import\"."))
+		d.content.Refresh()
+		return
+	}
+
+	d.content.Add(container.NewAppTabs(
+		container.NewTabItem("Levels", d.levelsTab()),
+		container.NewTabItem("Gil", newLineChart(map[string][]history.Point{"Gil": d.timeline.Gil()})),
+		container.NewTabItem("Progress", d.progressTab()),
+		container.NewTabItem("Espers", d.espersTab()),
+		container.NewTabItem("Maps", d.mapsTab()),
+		container.NewTabItem("Compare", d.compareTab()),
+	))
+	d.content.Refresh()
+}
+
+// levelsTab charts the level curve of every character
+func (d *TimelineDialog) levelsTab() fyne.CanvasObject {
+	curves := d.timeline.LevelCurves()
+	legend := container.NewVBox()
+	for i, name := range d.timeline.CharacterNames() {
+		swatch := canvas.NewRectangle(chartColors[i%len(chartColors)])
+		swatch.SetMinSize(fyne.NewSize(12, 12))
+		points := curves[name]
+		legend.Add(container.NewHBox(swatch, widget.NewLabel(fmt.Sprintf("%s (%.0f)", name, points[len(points)-1].Value))))
+	}
+	return container.NewBorder(nil, nil, nil, container.NewVScroll(legend), newLineChart(curves))
+}
+
+// progressTab lists play time against steps and battles
+func (d *TimelineDialog) progressTab() fyne.CanvasObject {
+	progress := d.timeline.Progress()
+	table := widget.NewTable(
+		func() (int, int) { return len(progress) + 1, 4 },
+		func() fyne.CanvasObject { return widget.NewLabel("Template") },
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			if id.Row == 0 {
+				label.SetText([]string{"Recorded", "Play Time", "Steps", "Battles"}[id.Col])
+				return
+			}
+			p := progress[id.Row-1]
+			switch id.Col {
+			case 0:
+				label.SetText(p.Time.Format("2006-01-02 15:04"))
+			case 1:
+				s := int(p.PlayTime)
+				label.SetText(fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60))
+			case 2:
+				label.SetText(fmt.Sprintf("%d", p.Steps))
+			case 3:
+				label.SetText(fmt.Sprintf("%d", p.Battles))
+			}
+		},
+	)
+	table.SetColumnWidth(0, 160)
+	table.SetColumnWidth(1, 100)
+	table.SetColumnWidth(2, 100)
+	table.SetColumnWidth(3, 100)
+
+	steps := make([]history.Point, len(progress))
+	battles := make([]history.Point, len(progress))
+	for i, p := range progress {
+		steps[i] = history.Point{Time: p.Time, Value: float64(p.Steps)}
+		battles[i] = history.Point{Time: p.Time, Value: float64(p.Battles)}
+	}
+	charts := container.NewGridWithColumns(2,
+		widget.NewCard("Steps", "", newLineChart(map[string][]history.Point{"Steps": steps})),
+		widget.NewCard("Battles", "", newLineChart(map[string][]history.Point{"Battles": battles})),
+	)
+	return container.NewVSplit(table, charts)
+}
+
+// espersTab lists when each esper was acquired
+func (d *TimelineDialog) espersTab() fyne.CanvasObject {
+	events := d.timeline.EspersAcquired()
+	lines := make([]string, 0, len(events)+1)
+	first := d.timeline.Entries[0]
+	lines = append(lines, fmt.Sprintf("Owned at the first point (%s): %s",
+		first.Timestamp.Format("2006-01-02 15:04"), joinOrNone(first.Summary.Espers)))
+	for _, e := range events {
+		lines = append(lines, fmt.Sprintf("%s  %s", e.Time.Format("2006-01-02 15:04"), e.Name))
+	}
+	return container.NewVScroll(widget.NewLabel(strings.Join(lines, "\n")))
+}
+
+// mapsTab lists the maps the slot was saved on
+func (d *TimelineDialog) mapsTab() fyne.CanvasObject {
+	visits := d.timeline.MapsVisited()
+	lines := make([]string, 0, len(visits))
+	for _, v := range visits {
+		when := v.From.Format("2006-01-02 15:04")
+		if !v.To.Equal(v.From) {
+			when += " - " + v.To.Format("2006-01-02 15:04")
+		}
+		lines = append(lines, fmt.Sprintf("%s  map %d at (%.0f, %.0f)", when, v.MapID, v.X, v.Y))
+	}
+	return container.NewVScroll(widget.NewLabel(strings.Join(lines, "\n")))
+}
+
+// compareTab diffs any two points
+func (d *TimelineDialog) compareTab() fyne.CanvasObject {
+	entries := d.timeline.Entries
+	labels := make([]string, len(entries))
+	for i, e := range entries {
+		labels[i] = fmt.Sprintf("%s (%s)", e.Timestamp.Format("2006-01-02 15:04:05"), e.Source)
+	}
+	idOf := func(label string) string {
+		for i, l := range labels {
+			if l == label {
+				return entries[i].ID
+			}
+		}
+		return ""
+	}
+
+	from := widget.NewSelect(labels, nil)
+	to := widget.NewSelect(labels, nil)
+	if len(labels) > 1 {
+		from.SetSelected(labels[len(labels)-2])
+	}
+	to.SetSelected(labels[len(labels)-1])
+
+	result := widget.NewLabel("")
+	compareBtn := widget.NewButton("Compare", func() {
+		report, err := d.store.Diff(idOf(from.Selected), idOf(to.Selected))
+		if err != nil {
+			dialog.ShowError(err, d.window)
+			return
+		}
+		lines := []string{report.Statistics.String(), ""}
+		for _, diff := range report.GetSortedDiffs() {
+			lines = append(lines, fmt.Sprintf("%s %s %s: %v -> %v", diff.Category, diff.Name, diff.Field, diff.OldValue, diff.NewValue))
+		}
+		result.SetText(strings.Join(lines, "\n"))
+	})
+
+	form := container.NewVBox(
+		widget.NewForm(widget.NewFormItem("From", from), widget.NewFormItem("To", to)),
+		compareBtn,
+	)
+	return container.NewBorder(form, nil, nil, nil, container.NewVScroll(result))
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func joinOrNone(list []string) string {
+	if len(list) == 0 {
+		return "none"
+	}
+	return strings.Join(list, ", ")
+}
+
+// lineChart draws one or more series against time
+type lineChart struct {
+	widget.BaseWidget
+	series map[string][]history.Point
+}
+
+func newLineChart(series map[string][]history.Point) *lineChart {
+	c := &lineChart{series: series}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+func (c *lineChart) CreateRenderer() fyne.WidgetRenderer {
+	r := &lineChartRenderer{chart: c}
+	r.build()
+	return r
+}
+
+type lineChartRenderer struct {
+	chart   *lineChart
+	names   []string
+	lines   [][]*canvas.Line
+	axes    []*canvas.Line
+	labels  []*canvas.Text
+	objects []fyne.CanvasObject
+	minX    int64
+	maxX    int64
+	minY    float64
+	maxY    float64
+}
+
+// build creates the canvas objects; Layout positions them
+func (r *lineChartRenderer) build() {
+	r.names = make([]string, 0, len(r.chart.series))
+	for name := range r.chart.series {
+		r.names = append(r.names, name)
+	}
+	sort.Strings(r.names)
+
+	first := true
+	for _, name := range r.names {
+		for _, p := range r.chart.series[name] {
+			x := p.Time.Unix()
+			if first || x < r.minX {
+				r.minX = x
+			}
+			if first || x > r.maxX {
+				r.maxX = x
+			}
+			if first || p.Value < r.minY {
+				r.minY = p.Value
+			}
+			if first || p.Value > r.maxY {
+				r.maxY = p.Value
+			}
+			first = false
+		}
+	}
+	if r.minY > 0 {
+		r.minY = 0
+	}
+
+	axisColor := color.NRGBA{R: 150, G: 150, B: 150, A: 200}
+	r.axes = []*canvas.Line{canvas.NewLine(axisColor), canvas.NewLine(axisColor)}
+	r.labels = []*canvas.Text{
+		canvas.NewText(fmt.Sprintf("%.0f", r.maxY), axisColor),
+		canvas.NewText(fmt.Sprintf("%.0f", r.minY), axisColor),
+	}
+	for _, l := range r.labels {
+		l.TextSize = 10
+		r.objects = append(r.objects, l)
+	}
+	for _, a := range r.axes {
+		r.objects = append(r.objects, a)
+	}
+
+	r.lines = make([][]*canvas.Line, len(r.names))
+	for i, name := range r.names {
+		points := r.chart.series[name]
+		segments := len(points) - 1
+		if segments < 1 {
+			// A single point is drawn as a short flat line
+			segments = 1
+		}
+		for j := 0; j < segments; j++ {
+			line := canvas.NewLine(chartColors[i%len(chartColors)])
+			line.StrokeWidth = 2
+			r.lines[i] = append(r.lines[i], line)
+			r.objects = append(r.objects, line)
+		}
+	}
+}
+
+func (r *lineChartRenderer) Layout(size fyne.Size) {
+	const margin = 30
+	left, top := float32(margin), float32(10)
+	width, height := size.Width-left-10, size.Height-top-margin
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	r.axes[0].Position1 = fyne.NewPos(left, top)
+	r.axes[0].Position2 = fyne.NewPos(left, top+height)
+	r.axes[1].Position1 = fyne.NewPos(left, top+height)
+	r.axes[1].Position2 = fyne.NewPos(left+width, top+height)
+	r.labels[0].Move(fyne.NewPos(2, top-6))
+	r.labels[1].Move(fyne.NewPos(2, top+height-6))
+
+	pos := func(p history.Point) fyne.Position {
+		x, y := float32(0), float32(0)
+		if r.maxX > r.minX {
+			x = float32(p.Time.Unix()-r.minX) / float32(r.maxX-r.minX)
+		}
+		if r.maxY > r.minY {
+			y = float32((p.Value - r.minY) / (r.maxY - r.minY))
+		}
+		return fyne.NewPos(left+x*width, top+height-y*height)
+	}
+	for i, name := range r.names {
+		points := r.chart.series[name]
+		if len(points) == 0 {
+			continue
+		}
+		if len(points) == 1 {
+			p := pos(points[0])
+			r.lines[i][0].Position1 = p
+			r.lines[i][0].Position2 = fyne.NewPos(p.X+6, p.Y)
+			continue
+		}
+		for j := 0; j < len(points)-1; j++ {
+			r.lines[i][j].Position1 = pos(points[j])
+			r.lines[i][j].Position2 = pos(points[j+1])
+		}
+	}
+}
+
+func (r *lineChartRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(300, 200)
+}
+
+func (r *lineChartRenderer) Refresh() {
+	r.Layout(r.chart.Size())
+	for _, o := range r.objects {
+		o.Refresh()
+	}
+}
+
+func (r *lineChartRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *lineChartRenderer) Destroy() {}
@@ -1,7 +1,10 @@
 package editors
 
 import (
+	"fmt"
+
 	"ffvi_editor/models"
+	"ffvi_editor/models/pr"
 	"ffvi_editor/ui/forms/inputs"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -12,6 +15,7 @@ import (
 type (
 	Character struct {
 		widget.BaseWidget
+		c         *models.Character
 		name      binding.String
 		isEnabled binding.Bool
 		level     inputs.IntEntryBinding
@@ -30,6 +34,7 @@ type (
 func NewCharacter(c *models.Character) *Character {
 	e := &Character{
 		BaseWidget: widget.BaseWidget{},
+		c:          c,
 		name:       binding.BindString(&c.Name),
 		isEnabled:  binding.BindBool(&c.IsEnabled),
 		level:      inputs.NewIntEntryBinding(&c.Level),
@@ -48,12 +53,12 @@ func NewCharacter(c *models.Character) *Character {
 }
 
 func (e *Character) CreateRenderer() fyne.WidgetRenderer {
-	name := widget.NewEntryWithData(e.name)
-	name.Validator = nil
+	name := inputs.NewTextEntryWithData(e.name)
 	left := container.NewVBox(
 		inputs.NewLabeledEntry("Name:", name),
 		inputs.NewLabeledEntry("Experience:", inputs.NewIntEntryWithBinding(e.exp)),
 		inputs.NewLabeledEntry("Level:", inputs.NewIntEntryWithBinding(e.level)),
+		e.createLevelCheck(),
 		inputs.NewLabeledEntry("HP Current/Max:", container.NewGridWithColumns(2,
 			inputs.NewIntEntryWithBinding(e.currentHP),
 			inputs.NewIntEntryWithBinding(e.maxHP))),
@@ -64,16 +69,15 @@ func (e *Character) CreateRenderer() fyne.WidgetRenderer {
 		inputs.NewLabeledEntry("Agility:", inputs.NewIntEntryWithBinding(e.agility)),
 		inputs.NewLabeledEntry("Reset:", container.NewHBox(
 			container.NewPadded(widget.NewButton("Exp", func() {
-				// TODO
-				e.exp.Set(0)
+				e.exp.Set(pr.ExpForLevel(e.c.Level))
 			})),
 			container.NewPadded(widget.NewButton("HP", func() {
-				// TODO
-				e.maxHP.Set(0)
+				hp, _ := pr.EstimateHPMP(e.c, e.c.Level)
+				e.maxHP.Set(hp)
 			})),
 			container.NewPadded(widget.NewButton("MP", func() {
-				// TODO
-				e.maxMP.Set(0)
+				_, mp := pr.EstimateHPMP(e.c, e.c.Level)
+				e.maxMP.Set(mp)
 			})))),
 		inputs.NewLabeledEntry("Stamina:", inputs.NewIntEntryWithBinding(e.stamina)),
 		inputs.NewLabeledEntry("Magic:", inputs.NewIntEntryWithBinding(e.magic)),
@@ -82,6 +86,43 @@ func (e *Character) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(container.NewBorder(nil, nil, left, right))
 }
 
+// createLevelCheck warns when the level doesn't match the experience and
+// offers to fix it with any of the level fix modes
+func (e *Character) createLevelCheck() fyne.CanvasObject {
+	status := widget.NewLabel("")
+	modes := make([]string, len(pr.LevelFixModes))
+	for i, m := range pr.LevelFixModes {
+		modes[i] = m.Label()
+	}
+	mode := widget.NewSelect(modes, nil)
+	mode.SetSelectedIndex(0)
+	fix := widget.NewButton("Fix", func() {
+		// Fix a copy and push the result through the bindings so the
+		// entries show the new values
+		c := *e.c
+		pr.FixLevelExp(&c, pr.LevelFixModes[mode.SelectedIndex()])
+		e.level.Set(c.Level)
+		e.exp.Set(c.Exp)
+		e.currentHP.Set(c.HP.Current)
+		e.maxHP.Set(c.HP.Max)
+		e.currentMP.Set(c.MP.Current)
+		e.maxMP.Set(c.MP.Max)
+	})
+	row := container.NewHBox(status, mode, fix)
+
+	update := binding.NewDataListener(func() {
+		if pr.LevelMatchesExp(e.c) {
+			row.Hide()
+			return
+		}
+		status.SetText(fmt.Sprintf("%d EXP is level %d", e.c.Exp, pr.LevelForExp(e.c.Exp)))
+		row.Show()
+	})
+	e.level.AddListener(update)
+	e.exp.AddListener(update)
+	return row
+}
+
 const (
 	lvlToExp = `Level - Experience    
 01 - 0
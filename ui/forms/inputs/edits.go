@@ -0,0 +1,45 @@
+package inputs
+
+import (
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/widget"
+)
+
+var onEditDone func()
+
+// SetOnEditDone sets the function called when an entry of this package gains
+// or loses focus, which ends the edits made before, such as typing a value
+// or ticking boxes. It runs on the event goroutine, where the entries write
+// the fields they are bound to.
+func SetOnEditDone(fn func()) {
+	onEditDone = fn
+}
+
+func editDone() {
+	if onEditDone != nil {
+		onEditDone()
+	}
+}
+
+// TextEntry is a text entry that ends edits like IntEntry
+type TextEntry struct {
+	widget.Entry
+}
+
+func NewTextEntryWithData(data binding.String) *TextEntry {
+	entry := &TextEntry{}
+	entry.ExtendBaseWidget(entry)
+	entry.Bind(data)
+	entry.Entry.Validator = nil
+	return entry
+}
+
+func (e *TextEntry) FocusGained() {
+	editDone()
+	e.Entry.FocusGained()
+}
+
+func (e *TextEntry) FocusLost() {
+	e.Entry.FocusLost()
+	editDone()
+}
@@ -77,30 +77,42 @@ func (e *FloatEntry) Keyboard() mobile.KeyboardType {
 	return mobile.NumberKeyboard
 }
 
+func (e *FloatEntry) FocusGained() {
+	editDone()
+	e.Entry.FocusGained()
+}
+
+func (e *FloatEntry) FocusLost() {
+	e.Entry.FocusLost()
+	editDone()
+}
+
 func NewFloatEntryBinding(f *float64) FloatEntryBinding {
 	s := binding.NewString()
 	_ = s.Set(fmt.Sprintf("%f", *f))
-	b := FloatEntryBinding{
-		s: s,
-		i: binding.BindFloat(f),
+	fb := binding.BindFloat(f)
+	return FloatEntryBinding{
+		s: floatString{String: s, f: fb},
+		i: fb,
 	}
-	b.s.AddListener(b)
-	return b
 }
 
-func (b FloatEntryBinding) DataChanged() {
-	if s, err := b.s.Get(); err == nil {
-		if s == "" {
-			_ = b.i.Set(0)
-		} else {
-			var f float64
-			if f, err = strconv.ParseFloat(s, 64); err == nil {
-				_ = b.i.Set(f)
-			}
-		}
-	} else {
+// floatString is the text of a float entry, written like intString
+type floatString struct {
+	binding.String
+	f binding.Float
+}
 
+func (b floatString) Set(s string) error {
+	if old, err := b.String.Get(); err == nil && old == s {
+		return nil
+	}
+	if s == "" {
+		_ = b.f.Set(0)
+	} else if f, err := strconv.ParseFloat(s, 64); err == nil {
+		_ = b.f.Set(f)
 	}
+	return b.String.Set(s)
 }
 
 func (b FloatEntryBinding) Set(f float64) {
@@ -76,36 +76,55 @@ func (e *IntEntry) Keyboard() mobile.KeyboardType {
 	return mobile.NumberKeyboard
 }
 
+func (e *IntEntry) FocusGained() {
+	editDone()
+	e.Entry.FocusGained()
+}
+
+func (e *IntEntry) FocusLost() {
+	e.Entry.FocusLost()
+	editDone()
+}
+
 func NewIntEntryBinding(i *int) IntEntryBinding {
 	s := binding.NewString()
 	_ = s.Set(strconv.Itoa(*i))
-	b := IntEntryBinding{
-		s: s,
-		i: binding.BindInt(i),
+	ib := binding.BindInt(i)
+	return IntEntryBinding{
+		s: intString{String: s, i: ib},
+		i: ib,
 	}
-	b.s.AddListener(b)
-	return b
-}
-
-func (b IntEntryBinding) DataChanged() {
-	if s, err := b.s.Get(); err == nil {
-		if s == "" {
-			_ = b.i.Set(0)
-		} else {
-			var i int
-			if i, err = strconv.Atoi(s); err == nil {
-				_ = b.i.Set(i)
-			}
-		}
-	} else {
+}
+
+// intString is the text of an int entry. Setting it writes the int first,
+// on the setter's goroutine: a listener would write it from fyne's binding
+// goroutine, racing the editor's reads of the model.
+type intString struct {
+	binding.String
+	i binding.Int
+}
 
+func (b intString) Set(s string) error {
+	if old, err := b.String.Get(); err == nil && old == s {
+		return nil
 	}
+	if s == "" {
+		_ = b.i.Set(0)
+	} else if i, err := strconv.Atoi(s); err == nil {
+		_ = b.i.Set(i)
+	}
+	return b.String.Set(s)
 }
 
 func (b IntEntryBinding) Set(i int) {
 	_ = b.s.Set(strconv.Itoa(i))
 }
 
+// AddListener is notified whenever the entry's text changes
+func (b IntEntryBinding) AddListener(l binding.DataListener) {
+	b.s.AddListener(l)
+}
+
 /*
 package widget
 
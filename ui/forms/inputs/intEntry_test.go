@@ -0,0 +1,32 @@
+package inputs
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestIntEntryWritesOnTyping(t *testing.T) {
+	test.NewApp()
+	defer test.NewApp()
+
+	done := 0
+	SetOnEditDone(func() { done++ })
+	defer SetOnEditDone(nil)
+
+	level := 5
+	entry := NewIntEntryWithData(&level)
+	w := test.NewWindow(entry)
+	defer w.Close()
+
+	w.Canvas().Focus(entry)
+	entry.TypedRune('2')
+	// The bound field is written by the typing, not later by a listener
+	if level != 25 {
+		t.Errorf("level = %d right after typing, want 25", level)
+	}
+	w.Canvas().Unfocus()
+	if done != 2 {
+		t.Errorf("focusing and leaving the entry ended %d edits, want 2", done)
+	}
+}
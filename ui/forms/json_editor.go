@@ -1,10 +1,11 @@
 package forms
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
+	ioCsv "ffvi_editor/io/csv"
 	ioJson "ffvi_editor/io/json"
 	ipr "ffvi_editor/io/pr"
 
@@ -14,6 +15,9 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
+// maxImportErrors caps the import errors listed after an import
+const maxImportErrors = 10
+
 // JSONExportImportDialog handles JSON export/import of save data
 type JSONExportImportDialog struct {
 	dialog       dialog.Dialog
@@ -90,6 +94,13 @@ func (j *JSONExportImportDialog) Show() {
 
 	importDesc := widget.NewLabel("Select a JSON file to import data from:")
 
+	modes := make([]string, len(ioJson.ImportModes))
+	for i, m := range ioJson.ImportModes {
+		modes[i] = string(m)
+	}
+	modeSelect := widget.NewSelect(modes, nil)
+	modeSelect.SetSelected(string(ioJson.ModeMerge))
+
 	importBtn := widget.NewButton("Import from JSON", func() {
 		openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
 			if err != nil || reader == nil {
@@ -104,21 +115,20 @@ func (j *JSONExportImportDialog) Show() {
 				return
 			}
 
-			// Validate JSON structure
-			var export ioJson.SaveExport
-			if err := json.Unmarshal(jsonBytes, &export); err != nil {
-				dialog.ShowError(fmt.Errorf("invalid JSON format: %w", err), j.window)
-				return
-			}
-
 			// Import the data with actual importer
 			importer := ioJson.NewImporter(j.prData)
-			if err := importer.ImportFromJSON(jsonBytes, ioJson.FormatFull); err != nil {
+			opts := ioJson.ImportOptions{Mode: ioJson.ImportMode(modeSelect.Selected)}
+			report, err := importer.Import(jsonBytes, ioJson.FormatFull, opts)
+			if err != nil {
 				dialog.ShowError(fmt.Errorf("import failed: %w", err), j.window)
 				return
 			}
 
-			dialog.ShowInformation("Import Complete", fmt.Sprintf("Imported %d characters", len(export.Characters)), j.window)
+			errs := make([]string, len(importer.GetErrors()))
+			for i, e := range importer.GetErrors() {
+				errs[i] = e.Error()
+			}
+			j.showImportResult(report.Statistics.TotalDiffs, errs)
 		}, j.window)
 
 		openDialog.Show()
@@ -127,9 +137,83 @@ func (j *JSONExportImportDialog) Show() {
 	importBox := container.NewVBox(
 		importLabel,
 		importDesc,
+		widget.NewLabel("Mode:"),
+		modeSelect,
 		importBtn,
 	)
 
+	// Spreadsheet section
+	sheetNames := make([]string, len(ioCsv.Sheets))
+	for i, s := range ioCsv.Sheets {
+		sheetNames[i] = string(s)
+	}
+	sheetSelect := widget.NewSelect(sheetNames, nil)
+	sheetSelect.SetSelected(string(ioCsv.SheetInventory))
+
+	exportSheetBtn := widget.NewButton("Export Sheet", func() {
+		sheet := ioCsv.Sheet(sheetSelect.Selected)
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				return
+			}
+			defer writer.Close()
+
+			comma := ioCsv.CommaFor(writer.URI().Name())
+			if err = ioCsv.NewExporter(j.prData).Write(writer, sheet, comma); err != nil {
+				dialog.ShowError(fmt.Errorf("export failed: %w", err), j.window)
+				return
+			}
+			dialog.ShowInformation("Export Complete", fmt.Sprintf("Exported the %s sheet", sheet), j.window)
+		}, j.window)
+
+		saveDialog.SetFileName(string(sheet) + ".csv")
+		saveDialog.Show()
+	})
+
+	importSheetBtn := widget.NewButton("Import Sheet", func() {
+		sheet := ioCsv.Sheet(sheetSelect.Selected)
+		openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+
+			importer := ioCsv.NewImporter(j.prData)
+			opts := ioCsv.ImportOptions{Comma: ioCsv.CommaFor(reader.URI().Name())}
+			report, err := importer.Import(reader, sheet, opts)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("import failed: %w", err), j.window)
+				return
+			}
+
+			errs := make([]string, len(importer.GetErrors()))
+			for i, e := range importer.GetErrors() {
+				errs[i] = e.Error()
+			}
+			j.showImportResult(report.Statistics.TotalDiffs, errs)
+		}, j.window)
+
+		openDialog.Show()
+	})
+
+	// Only some sheets can be read back, e.g. skills are export-only
+	sheetSelect.OnChanged = func(selected string) {
+		for _, s := range ioCsv.ImportSheets {
+			if string(s) == selected {
+				importSheetBtn.Enable()
+				return
+			}
+		}
+		importSheetBtn.Disable()
+	}
+	sheetSelect.OnChanged(sheetSelect.Selected)
+
+	sheetBox := container.NewVBox(
+		widget.NewLabel("CSV or TSV (by file extension) for spreadsheets:"),
+		sheetSelect,
+		container.NewHBox(exportSheetBtn, importSheetBtn),
+	)
+
 	// Preview area
 	previewLabel := widget.NewLabel("Format: Full Save - Contains all character and equipment data")
 
@@ -159,6 +243,9 @@ func (j *JSONExportImportDialog) Show() {
 		widget.NewLabel("Import Save Data"),
 		importBox,
 		widget.NewLabel(""),
+		widget.NewLabel("Spreadsheets"),
+		sheetBox,
+		widget.NewLabel(""),
 		previewLabel,
 		buttons,
 	)
@@ -170,6 +257,23 @@ func (j *JSONExportImportDialog) Show() {
 	j.dialog.Show()
 }
 
+// showImportResult reports the number of changed fields and the first
+// import errors
+func (j *JSONExportImportDialog) showImportResult(changed int, errs []string) {
+	lines := []string{fmt.Sprintf("Changed %d field(s)", changed)}
+	if len(errs) > 0 {
+		lines = append(lines, fmt.Sprintf("%d field(s) could not be imported:", len(errs)))
+		for i, e := range errs {
+			if i == maxImportErrors {
+				lines = append(lines, fmt.Sprintf("... and %d more", len(errs)-i))
+				break
+			}
+			lines = append(lines, e)
+		}
+	}
+	dialog.ShowInformation("Import Complete", strings.Join(lines, "\n"), j.window)
+}
+
 // getExportFormat converts UI format string to export format enum
 func (j *JSONExportImportDialog) getExportFormat(formatName string) ioJson.ExportFormat {
 	switch formatName {
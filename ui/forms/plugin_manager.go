@@ -528,17 +528,20 @@ func (p *PluginManagerDialog) buildPluginOutputTab() *fyne.Container {
 	)
 }
 
-// executePlugin runs a plugin immediately
+// executePlugin runs a plugin immediately. It runs off the event goroutine
+// so a save from the plugin can wait for its confirmation.
 func (p *PluginManagerDialog) executePlugin(pluginID string) {
-	ctx := context.Background()
-	err := p.pluginManager.ExecutePlugin(ctx, pluginID)
+	go func() {
+		ctx := context.Background()
+		err := p.pluginManager.ExecutePlugin(ctx, pluginID)
 
-	if err != nil {
-		dialog.ShowError(fmt.Errorf("plugin execution failed: %w", err), p.window)
-		return
-	}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("plugin execution failed: %w", err), p.window)
+			return
+		}
 
-	dialog.ShowInformation("Success", fmt.Sprintf("Plugin '%s' executed successfully", pluginID), p.window)
+		dialog.ShowInformation("Success", fmt.Sprintf("Plugin '%s' executed successfully", pluginID), p.window)
+	}()
 }
 
 // showPluginSettings displays settings for a specific plugin
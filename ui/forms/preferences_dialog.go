@@ -13,8 +13,9 @@ import (
 
 // PreferencesDialog manages application preferences
 type PreferencesDialog struct {
-	window          fyne.Window
-	settingsManager *settings.Manager
+	window             fyne.Window
+	settingsManager    *settings.Manager
+	validationProfiles []string
 }
 
 // NewPreferencesDialog creates a new preferences dialog
@@ -25,6 +26,12 @@ func NewPreferencesDialog(window fyne.Window, settingsManager *settings.Manager)
 	}
 }
 
+// SetValidationProfiles sets the validation levels offered, including custom
+// rule set profiles
+func (p *PreferencesDialog) SetValidationProfiles(profiles []string) {
+	p.validationProfiles = profiles
+}
+
 // Show displays the preferences dialog
 func (p *PreferencesDialog) Show() {
 	currentSettings := p.settingsManager.Get()
@@ -192,8 +199,12 @@ func (p *PreferencesDialog) buildBackupTab(settings *settings.Settings) fyne.Can
 
 // buildValidationTab builds the validation preferences tab
 func (p *PreferencesDialog) buildValidationTab(settings *settings.Settings) fyne.CanvasObject {
+	profiles := p.validationProfiles
+	if len(profiles) == 0 {
+		profiles = []string{"strict", "normal", "permissive"}
+	}
 	validationLevelSelect := widget.NewSelect(
-		[]string{"Strict", "Normal", "Permissive"},
+		profiles,
 		func(value string) {
 			settings.ValidationLevel = value
 		},
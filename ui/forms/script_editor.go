@@ -1,7 +1,9 @@
 package forms
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 
 	"fyne.io/fyne/v2"
@@ -9,6 +11,8 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
+	"ffvi_editor/io/pr"
+	"ffvi_editor/plugins"
 	"ffvi_editor/scripting"
 )
 
@@ -18,11 +22,27 @@ type ScriptEditorDialog struct {
 	vm     *scripting.VM
 }
 
-// NewScriptEditorDialog creates a new script editor dialog
-func NewScriptEditorDialog(window fyne.Window) *ScriptEditorDialog {
+// scriptEditorID is the plugin ID the sandbox knows editor scripts by
+const scriptEditorID = "script-editor"
+
+// NewScriptEditorDialog creates a new script editor dialog. Scripts reach
+// the loaded save through the save table and the editor module, which uses
+// api; without a save both are missing. The limits scripts exceed are
+// reported to sandbox as violations of the script editor.
+func NewScriptEditorDialog(window fyne.Window, save *pr.PR, api *plugins.APIImpl, sandbox *plugins.SandboxManager) *ScriptEditorDialog {
+	vm := scripting.NewVM(0)
+	if sandbox != nil {
+		vm.SetSandbox(sandbox, scriptEditorID)
+	}
+	if save != nil {
+		_ = vm.SetSave(save)
+	}
+	if api != nil {
+		vm.SetAPI(api)
+	}
 	return &ScriptEditorDialog{
 		window: window,
-		vm:     scripting.NewVM(0),
+		vm:     vm,
 	}
 }
 
@@ -30,7 +50,7 @@ func NewScriptEditorDialog(window fyne.Window) *ScriptEditorDialog {
 func (s *ScriptEditorDialog) Show() {
 	// Script text area
 	scriptEntry := widget.NewMultiLineEntry()
-	scriptEntry.SetPlaceHolder("-- Write your Lua script here\n-- Example:\nsetCharacterLevel(0, 99)\nprint('Terra set to level 99!')")
+	scriptEntry.SetPlaceHolder("-- Write your Lua script here\n-- Example:\nsave.setCharacter('Terra', {level = 99})\nprint('Terra set to level 99!')")
 	scriptEntry.Wrapping = fyne.TextWrapWord
 	scriptEntry.SetMinRowsVisible(15)
 
@@ -56,7 +76,8 @@ func (s *ScriptEditorDialog) Show() {
 	outputEntry.Disable()
 
 	// Run button
-	runBtn := widget.NewButton("Run Script", func() {
+	var runBtn, stopBtn *widget.Button
+	runBtn = widget.NewButton("Run Script", func() {
 		script := scriptEntry.Text
 		if script == "" {
 			dialog.ShowError(fmt.Errorf("please enter a script"), s.window)
@@ -64,22 +85,31 @@ func (s *ScriptEditorDialog) Show() {
 		}
 
 		outputEntry.SetText("Executing script...\n")
-
-		// Execute script
-		ctx := context.Background()
-		err := s.vm.Execute(ctx, script)
-		if err != nil {
-			outputEntry.SetText(fmt.Sprintf("Error: %v\n\nNote: Full Lua VM requires github.com/yuin/gopher-lua library", err))
-			dialog.ShowError(err, s.window)
-		} else {
-			outputEntry.SetText("Script executed successfully!\n\nNote: Actual execution requires Lua VM integration.")
-		}
+		runBtn.Disable()
+		stopBtn.Enable()
+
+		// Execute script, collecting what it prints. It runs off the event
+		// goroutine so a save from the script can wait for its confirmation.
+		go func() {
+			defer runBtn.Enable()
+			defer stopBtn.Disable()
+			var output bytes.Buffer
+			s.vm.SetOutput(&output)
+			err := s.vm.Execute(context.Background(), script)
+			if errors.Is(err, context.Canceled) {
+				outputEntry.SetText(output.String() + "Script stopped by user.")
+			} else if err != nil {
+				outputEntry.SetText(fmt.Sprintf("%sError: %v", output.String(), err))
+				dialog.ShowError(err, s.window)
+			} else {
+				outputEntry.SetText(output.String() + "Script executed successfully!")
+			}
+		}()
 	})
 
 	// Stop button
-	stopBtn := widget.NewButton("Stop", func() {
-		// s.vm.Cancel() // TODO: Implement cancellation
-		outputEntry.SetText(outputEntry.Text + "\nScript stopped by user.")
+	stopBtn = widget.NewButton("Stop", func() {
+		s.vm.Cancel()
 	})
 	stopBtn.Disable()
 
@@ -137,6 +167,9 @@ func (s *ScriptEditorDialog) Show() {
 	)
 
 	d := dialog.NewCustom("Script Editor", "Close", content, s.window)
+	d.SetOnClosed(func() {
+		_ = s.vm.Close()
+	})
 	d.Resize(fyne.NewSize(800, 600))
 	d.Show()
 }
@@ -146,37 +179,50 @@ func (s *ScriptEditorDialog) showAPIReference() {
 	apiText := `
 Lua Scripting API Reference
 
-CHARACTER FUNCTIONS:
-  getCharacter(id) - Get character data
-  setCharacterLevel(id, level) - Set character level (1-99)
-  setCharacterHP(id, hp) - Set character HP (1-9999)
-  setCharacterMP(id, mp) - Set character MP (1-999)
-  setCharacterStat(id, stat, value) - Set stat (vigor, speed, stamina, magic)
-
-INVENTORY FUNCTIONS:
-  getItemCount(itemId) - Get item count
-  setItemCount(itemId, count) - Set item count (0-99)
-  addItem(itemId, count) - Add items to inventory
-
-PARTY FUNCTIONS:
-  getPartyMembers() - Get current party
-  setPartyMembers({id1, id2, ...}) - Set party members
-
-MAGIC FUNCTIONS:
-  hasMagic(charId, spellId) - Check if character has spell
-  learnMagic(charId, spellId) - Teach spell to character
+EDITOR MODULE (editor.* or require("editor")):
+  Models are tables with lowerCamel fields. Failing calls return nil
+  (or false) and an error message.
+  getCharacter(name) / setCharacter(name, tbl) - Read or merge a character
+  getInventory() / setInventory(tbl) - Read or change the inventory
+  getParty() / setParty(tbl) - Read or change the party
+  getEquipment() / setEquipment(tbl) - Read or change equipment
+  findCharacter(fn) - First character for which fn(ch) is true
+  findItems(fn) - Inventory rows for which fn(row) is true
+  getEquipmentInfo(id) / listEquipment(type) - Game equipment data
+  applyBatchOperation(op, params) - Run a batch operation
+  registerHook(event, fn) / fireEvent(event, data) - Events
+  showDialog(title, msg) / showConfirm(title, msg) / showInput(prompt)
+  log([level,] message) - Log message
+  getSetting(key) / setSetting(key, value) - Plugin settings
+  hasPermission(perm) - Check a permission
+  saveFile(path) - Write the save
+
+SAVE TABLE (save.*):
+  Characters by name or ID; items, espers, spells, commands and skills
+  by name or ID. Slots and indices count from 0. Failing calls return
+  nil (or false) and an error message.
+  get(path) / set(path, value) / add(path [, value]) / remove(path)
+  names(category) / fields(category) - Names and fields of a path category
+  characters() - Playable character names
+  getCharacter(c) / setCharacter(c, {level = 99, ...}) - Character fields
+  getSpells(c) / learnSpell(c, spell [, pct]) / forgetSpell(c, spell)
+  learnAllSpells(c) - Teach every spell
+  getCommands(c) / setCommand(c, slot, command)
+  getEquipment(c) / equip(c, slot, item)
+  getInventory() / getItemCount(item) / setItemCount(item, count)
+  addItem(item [, count]) / removeItem(item)
+  getImportantItems() / hasImportantItem(item) / addImportantItem(item)
+  getEspers() / hasEsper(esper) / addEsper(esper) / removeEsper(esper)
+  getSkills(kind) / learnSkill(kind, name) / learnAllSkills(kind)
+    kind: rages, lores, dances, blitzes, bushido
+  getVeldt() / setVeldt(index, available)
+  getParty() / setParty({c1, c2, c3, c4}) / setPartyMember(slot, c)
+  getMap() / setMap(fields), getMisc() / setMisc(fields)
+  getTransportation() / setTransportation(index, fields)
+  getGil() / setGil(gil), itemName(id) / itemID(name)
 
 UTILITY FUNCTIONS:
-  print(message) - Print to output
-  log(message) - Log message
-  min(a, b) - Get minimum value
-  max(a, b) - Get maximum value
-  clamp(value, min, max) - Clamp value to range
-
-CHARACTER IDs:
-  0=Terra, 1=Locke, 2=Cyan, 3=Shadow, 4=Edgar, 5=Sabin
-  6=Celes, 7=Strago, 8=Relm, 9=Setzer, 10=Mog, 11=Gau
-  12=Gogo, 13=Umaro
+  print(...) - Print to output
 `
 
 	apiEntry := widget.NewMultiLineEntry()
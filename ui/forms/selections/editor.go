@@ -14,6 +14,7 @@ type (
 		widget.BaseWidget
 		tabs            *container.AppTabs
 		validationPanel *forms.ValidationPanel
+		validator       *validation.Validator
 		onTabChanged    func(title string)
 	}
 )
@@ -29,13 +30,22 @@ func (s *Editor) SetOnTabChanged(cb func(title string)) {
 	s.onTabChanged = cb
 }
 
+// SetValidator sets the validator of the validation tab; without one the
+// default rules are used
+func (s *Editor) SetValidator(v *validation.Validator) {
+	s.validator = v
+}
+
 // GetValidationPanel returns the validation panel for refresh
 func (s *Editor) GetValidationPanel() *forms.ValidationPanel {
 	return s.validationPanel
 }
 
 func (s *Editor) CreateRenderer() fyne.WidgetRenderer {
-	validator := validation.NewValidator()
+	validator := s.validator
+	if validator == nil {
+		validator = validation.NewValidator()
+	}
 	s.validationPanel = forms.NewValidationPanel(validator)
 
 	s.tabs = container.NewAppTabs(
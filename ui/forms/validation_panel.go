@@ -41,11 +41,13 @@ func NewValidationPanel(validator *validation.Validator) *ValidationPanel {
 		),
 	}
 
-	vp.modeSelect = widget.NewSelect(
-		[]string{"Strict", "Normal", "Lenient"},
-		vp.onModeChanged,
-	)
-	vp.modeSelect.SetSelected("Normal")
+	// Custom profiles come from the validator's rule sets
+	vp.modeSelect = widget.NewSelect(validator.Profiles(), vp.onModeChanged)
+	selected := string(validator.GetConfig().Mode)
+	if validator.GetConfig().Mode == models.LenientMode {
+		selected = "permissive"
+	}
+	vp.modeSelect.SetSelected(selected)
 
 	vp.autoFixBtn = widget.NewButton("Auto-Fix All Issues", vp.onAutoFixAll)
 	vp.autoFixBtn.Disable()
@@ -171,16 +173,7 @@ func (vp *ValidationPanel) buildIssueItem(issue *models.ValidationIssue) fyne.Ca
 
 // onModeChanged handles validation mode change
 func (vp *ValidationPanel) onModeChanged(mode string) {
-	var configMode models.ValidationMode
-
-	switch mode {
-	case "Strict":
-		configMode = models.StrictMode
-	case "Normal":
-		configMode = models.NormalMode
-	case "Lenient":
-		configMode = models.LenientMode
-	}
+	configMode := models.ValidationModeForLevel(mode)
 
 	config := vp.validator.GetConfig()
 	config.Mode = configMode
@@ -198,10 +191,8 @@ func (vp *ValidationPanel) onAutoFixAll() {
 		return
 	}
 
-	fixed, err := vp.validator.AutoFixIssues(vp.currentData)
-	if err != nil {
-		return
-	}
+	// Issues whose fix failed are still listed after re-validating
+	fixed, _ := vp.validator.AutoFixIssues(vp.currentData)
 
 	if fixed > 0 {
 		// Re-validate to show results
@@ -220,8 +211,10 @@ func (vp *ValidationPanel) onFixIssue(issue *models.ValidationIssue) {
 		return
 	}
 
-	// Note: This would need specific fix implementations
-	// For now, we'll just re-validate
+	if err := vp.validator.FixIssue(vp.currentData, *issue); err != nil {
+		vp.summaryLabel.SetText(fmt.Sprintf("✗ Couldn't fix %s: %v", issue.Rule, err))
+		return
+	}
 	if vp.onIssueFixed != nil {
 		vp.onIssueFixed()
 	}
@@ -50,9 +50,7 @@ func (us *UndoStack) RecordChange(change models.Change) error {
 
 	// Add change to undo stack
 	us.undoStack = append(us.undoStack, change)
-	if len(us.undoStack) > us.maxDepth {
-		us.undoStack = us.undoStack[len(us.undoStack)-us.maxDepth:]
-	}
+	us.trim()
 
 	// Clear redo stack (can't redo after new change)
 	us.redoStack = make([]models.Change, 0)
@@ -60,6 +58,40 @@ func (us *UndoStack) RecordChange(change models.Change) error {
 	return nil
 }
 
+// RecordGroup adds the changes of a group as a single undo action. During a
+// batch the changes join the batch instead.
+func (us *UndoStack) RecordGroup(group models.ChangeGroup) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	for _, change := range group.Changes {
+		if !models.ValidateChange(change) {
+			return fmt.Errorf("invalid change: %+v", change)
+		}
+	}
+
+	if us.batchMode {
+		for _, change := range group.Changes {
+			change.Batch = true
+			change.BatchID = us.currentBatchID
+			us.currentBatchOps = append(us.currentBatchOps, change)
+		}
+		return nil
+	}
+
+	for _, change := range group.Changes {
+		change.Batch = true
+		change.BatchID = group.ID
+		change.BatchName = group.Name
+		us.undoStack = append(us.undoStack, change)
+	}
+	us.trim()
+
+	us.redoStack = make([]models.Change, 0)
+
+	return nil
+}
+
 // StartBatch begins a batch of operations
 func (us *UndoStack) StartBatch(name string) (string, error) {
 	us.mu.Lock()
@@ -91,10 +123,7 @@ func (us *UndoStack) EndBatch(name string) error {
 		us.undoStack = append(us.undoStack, change)
 	}
 
-	// Maintain max depth
-	if len(us.undoStack) > us.maxDepth {
-		us.undoStack = us.undoStack[len(us.undoStack)-us.maxDepth:]
-	}
+	us.trim()
 
 	// Clear redo stack
 	us.redoStack = make([]models.Change, 0)
@@ -278,6 +307,86 @@ func (us *UndoStack) Clear() {
 	us.currentBatchOps = make([]models.Change, 0)
 }
 
+// SetMaxDepth changes how many undo steps the stack keeps
+func (us *UndoStack) SetMaxDepth(maxDepth int) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	if maxDepth <= 0 {
+		maxDepth = 100 // Default
+	}
+	us.maxDepth = maxDepth
+	us.trim()
+}
+
+// History returns the undo and redo stacks as the groups they undo and redo
+// in, oldest first; the next redo is the last redo group
+func (us *UndoStack) History() (undo, redo []models.ChangeGroup) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	return groupChanges(us.undoStack), groupChanges(us.redoStack)
+}
+
+// SetHistory replaces both stacks with the groups returned by History
+func (us *UndoStack) SetHistory(undo, redo []models.ChangeGroup) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	us.undoStack = flattenGroups(undo)
+	us.trim()
+	us.redoStack = flattenGroups(redo)
+}
+
+// trim drops the oldest undo steps beyond maxDepth. A group is one step and
+// goes as a whole.
+func (us *UndoStack) trim() {
+	steps := groupChanges(us.undoStack)
+	if len(steps) <= us.maxDepth {
+		return
+	}
+	drop := 0
+	for _, step := range steps[:len(steps)-us.maxDepth] {
+		drop += len(step.Changes)
+	}
+	us.undoStack = us.undoStack[drop:]
+}
+
+// groupChanges splits stacked changes into the steps PopUndo and PopRedo
+// take them off in
+func groupChanges(changes []models.Change) []models.ChangeGroup {
+	groups := make([]models.ChangeGroup, 0)
+	for _, change := range changes {
+		if n := len(groups); n > 0 && change.Batch && change.BatchID != "" && groups[n-1].ID == change.BatchID {
+			groups[n-1].Changes = append(groups[n-1].Changes, change)
+			continue
+		}
+		group := models.ChangeGroup{
+			ID:      change.BatchID,
+			Name:    change.BatchName,
+			Changes: []models.Change{change},
+			Time:    change.Timestamp,
+		}
+		if !change.Batch || change.BatchID == "" {
+			group.ID, group.Name = "", change.FieldName
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func flattenGroups(groups []models.ChangeGroup) []models.Change {
+	changes := make([]models.Change, 0)
+	for _, group := range groups {
+		for _, change := range group.Changes {
+			if group.ID != "" {
+				change.Batch, change.BatchID, change.BatchName = true, group.ID, group.Name
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
 // generateBatchID creates a unique batch ID
 func generateBatchID() string {
 	return fmt.Sprintf("batch_%d", time.Now().UnixNano())
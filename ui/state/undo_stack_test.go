@@ -0,0 +1,53 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+
+	"ffvi_editor/models"
+)
+
+func group(id string, n int) models.ChangeGroup {
+	g := models.ChangeGroup{ID: id, Name: id}
+	for i := 0; i < n; i++ {
+		g.Changes = append(g.Changes, models.Change{Target: id, FieldName: fmt.Sprint(i), OldValue: i, NewValue: i + 1})
+	}
+	return g
+}
+
+func TestMaxDepthKeepsWholeGroups(t *testing.T) {
+	us := NewUndoStack(2)
+	for _, g := range []models.ChangeGroup{group("a", 3), group("b", 4), group("c", 2)} {
+		if err := us.RecordGroup(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	undo, _ := us.History()
+	if len(undo) != 2 || undo[0].ID != "b" || len(undo[0].Changes) != 4 || undo[1].ID != "c" {
+		t.Fatalf("kept %+v, want groups b and c", undo)
+	}
+
+	us.SetMaxDepth(1)
+	changes, name, err := us.PopUndo()
+	if err != nil || name != "c" || len(changes) != 2 {
+		t.Errorf("undid %q with %d changes (%v), want c with 2", name, len(changes), err)
+	}
+	if us.CanUndo() {
+		t.Error("group b should have been dropped as a whole")
+	}
+}
+
+func TestRecordGroupAcceptsTrackedNilValues(t *testing.T) {
+	us := NewUndoStack(10)
+	noop := func() error { return nil }
+	tracked := models.Change{Target: "characters", FieldName: "characters/Terra/name", NewValue: "Tina", Apply: noop, Revert: noop}
+	if err := us.RecordGroup(models.ChangeGroup{ID: "g", Name: "Rename", Changes: []models.Change{tracked}}); err != nil {
+		t.Fatalf("a tracked change without an old value was refused: %v", err)
+	}
+
+	tracked.Apply, tracked.Revert = nil, nil
+	if err := us.RecordGroup(models.ChangeGroup{ID: "h", Name: "Rename", Changes: []models.Change{tracked}}); err == nil {
+		t.Error("a record without values or functions was accepted")
+	}
+}
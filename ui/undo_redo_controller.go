@@ -7,6 +7,7 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
+	"ffvi_editor/io/pr"
 	"ffvi_editor/models"
 	"ffvi_editor/ui/state"
 )
@@ -19,6 +20,8 @@ type UndoRedoController struct {
 	statusLabel  *widget.Label
 	onUndo       func() // Callback when undo completes
 	onRedo       func() // Callback when redo completes
+	// Callback when changes are recorded, undone (reverted) or redone
+	onHistory func(changes []models.Change, reverted bool)
 }
 
 // NewUndoRedoController creates a new undo/redo controller
@@ -60,6 +63,20 @@ func (urc *UndoRedoController) RecordChange(change *models.Change) {
 	urc.updateMenuState()
 }
 
+// RecordGroup records a group of model changes as one undo step
+func (urc *UndoRedoController) RecordGroup(group models.ChangeGroup) {
+	err := urc.undoStack.RecordGroup(group)
+	urc.updateMenuState()
+	if err != nil {
+		// The edit is made either way; say that it can't be undone
+		fmt.Printf("Warning: %s can't be undone: %v\n", group.Name, err)
+		urc.statusLabel.SetText(fmt.Sprintf("%s can't be undone", group.Name))
+	}
+	if urc.onHistory != nil {
+		urc.onHistory(group.Changes, false)
+	}
+}
+
 // StartBatch starts a batch of changes
 func (urc *UndoRedoController) StartBatch(name string) string {
 	id, _ := urc.undoStack.StartBatch(name)
@@ -79,6 +96,8 @@ func (urc *UndoRedoController) CancelBatch() {
 
 // executeUndo performs an undo operation
 func (urc *UndoRedoController) executeUndo() {
+	// Edits made through widgets since the last step become a step of their own
+	pr.Changes.Commit("Edit")
 	if !urc.undoStack.CanUndo() {
 		return
 	}
@@ -87,17 +106,26 @@ func (urc *UndoRedoController) executeUndo() {
 	if len(changes) == 0 {
 		return
 	}
+	err := pr.Changes.Undo(changes)
 
 	// Call callback
+	if urc.onHistory != nil {
+		urc.onHistory(changes, true)
+	}
 	if urc.onUndo != nil {
 		urc.onUndo()
 	}
 
 	urc.updateMenuState()
+	if err != nil {
+		urc.statusLabel.SetText(fmt.Sprintf("Undo failed: %v", err))
+	}
 }
 
 // executeRedo performs a redo operation
 func (urc *UndoRedoController) executeRedo() {
+	// A pending widget edit is a new step, which leaves nothing to redo
+	pr.Changes.Commit("Edit")
 	if !urc.undoStack.CanRedo() {
 		return
 	}
@@ -106,13 +134,20 @@ func (urc *UndoRedoController) executeRedo() {
 	if len(changes) == 0 {
 		return
 	}
+	err := pr.Changes.Redo(changes)
 
 	// Call callback
+	if urc.onHistory != nil {
+		urc.onHistory(changes, false)
+	}
 	if urc.onRedo != nil {
 		urc.onRedo()
 	}
 
 	urc.updateMenuState()
+	if err != nil {
+		urc.statusLabel.SetText(fmt.Sprintf("Redo failed: %v", err))
+	}
 }
 
 // updateMenuState updates the enabled/disabled state of menu items
@@ -178,6 +213,18 @@ func (urc *UndoRedoController) SetOnRedo(callback func()) {
 	urc.onRedo = callback
 }
 
+// SetOnHistory sets the callback for recorded, undone and redone changes
+func (urc *UndoRedoController) SetOnHistory(callback func(changes []models.Change, reverted bool)) {
+	urc.onHistory = callback
+}
+
+// SetHistory replaces the undo/redo history, as returned by the undo stack's
+// History
+func (urc *UndoRedoController) SetHistory(undo, redo []models.ChangeGroup) {
+	urc.undoStack.SetHistory(undo, redo)
+	urc.updateMenuState()
+}
+
 // Clear clears all undo/redo history
 func (urc *UndoRedoController) Clear() {
 	urc.undoStack.Clear()
@@ -14,15 +14,19 @@ import (
 	"ffvi_editor/io"
 	"ffvi_editor/io/backup"
 	"ffvi_editor/io/config"
+	"ffvi_editor/io/history"
+	"ffvi_editor/io/journal"
 	"ffvi_editor/io/pr"
 	"ffvi_editor/io/validation"
 	"ffvi_editor/marketplace"
 	"ffvi_editor/models"
 	pri "ffvi_editor/models/pr"
 	"ffvi_editor/plugins"
+	"ffvi_editor/scripting"
 	"ffvi_editor/settings"
 	"ffvi_editor/ui/forms"
 	"ffvi_editor/ui/forms/dialogs"
+	"ffvi_editor/ui/forms/inputs"
 	"ffvi_editor/ui/forms/selections"
 	"ffvi_editor/ui/state"
 
@@ -239,12 +243,19 @@ type (
 		prev                fyne.CanvasObject
 		pr                  *pr.PR
 		backupManager       *backup.Manager
+		historyStore        *history.Store
+		journal             *journal.Store
+		savePath            string
+		openedSnapshot      *pr.Snapshot
+		saveType            global.SaveFileType
 		undoStack           *state.UndoStack
+		undoCtrl            *UndoRedoController
 		themeSwitcher       *ThemeSwitcher
 		settingsManager     *settings.Manager
 		achievementTracker  *achievements.Tracker
 		cloudManager        *cloud.Manager
 		pluginManager       *plugins.Manager
+		pluginAPI           *plugins.APIImpl
 		helpSystem          *docs.HelpSystem
 		marketplaceClient   *marketplace.Client
 		marketplaceRegistry *marketplace.Registry
@@ -267,6 +278,9 @@ var (
 	_ MenuItem = menuItem{}
 )
 
+// maxSaveIssues is how many issues the pre-save dialogs list
+const maxSaveIssues = 15
+
 func New() Gui {
 	if wd, err := os.Getwd(); err == nil {
 		var dir []os.DirEntry
@@ -289,6 +303,23 @@ func New() Gui {
 		backupMgr = nil
 	}
 
+	// Initialize the progression history; like backups, it is optional
+	historyStore, err := history.NewStore(filepath.Join(config.SaveDir(), "history"), history.DefaultMaxPoints)
+	if err != nil {
+		historyStore = nil
+	}
+
+	// The edit journal keeps unsaved edits and undo history across restarts;
+	// it is optional too
+	journalStore, err := journal.NewStore(filepath.Join(config.SaveDir(), "journal"), journal.DefaultMaxSteps)
+	if err != nil {
+		journalStore = nil
+	}
+
+	// Cloud sync keeps the last synced copy of each save to merge conflicts
+	cloudManager := cloud.New()
+	cloudManager.SetBaseFolder(filepath.Join(config.SaveDir(), "cloud", "synced"))
+
 	var (
 		a = app.NewWithID("com.ff6editor.app")
 		g = &gui{
@@ -296,17 +327,34 @@ func New() Gui {
 			window:             a.NewWindow(fmt.Sprintf("Final Fantasy VI Save Editor - v%s", browser.Version)),
 			canvas:             container.NewMax(),
 			backupManager:      backupMgr,
+			historyStore:       historyStore,
+			journal:            journalStore,
 			undoStack:          state.NewUndoStack(100),
 			themeSwitcher:      NewThemeSwitcher(a.Preferences()),
 			settingsManager:    settings.New(),
 			achievementTracker: achievements.NewTracker(),
-			cloudManager:       cloud.New(),
-			pluginManager:      plugins.NewManager(filepath.Join(config.SaveDir(), "plugins"), nil),
+			cloudManager:       cloudManager,
 			helpSystem:         docs.NewHelpSystem(),
 			marketplaceClient:  marketplace.NewClient("https://api.ff6-marketplace.local", "demo-key"),
 		}
 	)
 
+	// Merged cloud saves pass the editor's pre-save check before they are
+	// written; cloud sync runs off the event goroutine, so it can wait for
+	// the answer
+	cloudManager.SetSaveGate(g.newSaveGate, func(check validation.SaveCheck) bool {
+		return g.confirmAndWait("Validation Issues", "The merged cloud save has issues.\n\n"+check.Summary(maxSaveIssues)+"\n\nSave anyway?")
+	})
+
+	// Plugins reach whichever save is open through one API
+	g.pluginAPI = g.newPluginAPI(nil,
+		plugins.CommonPermissions.ReadSave,
+		plugins.CommonPermissions.WriteSave,
+		plugins.CommonPermissions.UIDisplay,
+		plugins.CommonPermissions.Events)
+	g.pluginManager = plugins.NewManager(filepath.Join(config.SaveDir(), "plugins"), g.pluginAPI)
+	g.pluginManager.SetRunner(scripting.RunPlugin)
+
 	// Apply FF6 custom theme
 	a.Settings().SetTheme(NewFF6Theme(theme.VariantDark))
 	// Initialize marketplace registry
@@ -330,6 +378,12 @@ func New() Gui {
 		settingsMgr = settings.New()
 	}
 	g.settingsManager = settingsMgr
+	if s := g.settingsManager.Get(); s != nil && s.MaxUndoSteps > 0 {
+		g.undoStack.SetMaxDepth(s.MaxUndoSteps)
+		if g.journal != nil {
+			g.journal.SetMaxSteps(s.MaxUndoSteps)
+		}
+	}
 
 	// Initialize ROM sprite extractor if ROM path is configured, or try default locations
 	s := g.settingsManager.Get()
@@ -376,17 +430,37 @@ func New() Gui {
 	// Register window resize listener for saving window size
 	// Integrate undo/redo keyboard handling
 	undoCtrl := NewUndoRedoController(g.undoStack)
-	// Update validation status after undo/redo
+	g.undoCtrl = undoCtrl
+	// Every model change made in the editor becomes an undo step
+	pr.Changes.Subscribe(undoCtrl.RecordGroup)
+	// Widgets change the models directly; an entry gaining or losing focus
+	// ends an edit, so commit it then and it is journaled without waiting
+	// for a save or tab switch. Both run on the event goroutine.
+	inputs.SetOnEditDone(func() {
+		if g.pr != nil {
+			pr.Changes.Commit("Edit")
+		}
+	})
+	// Journal every change so unsaved edits survive a crash
+	undoCtrl.SetOnHistory(func(changes []models.Change, reverted bool) {
+		if g.journal == nil {
+			return
+		}
+		undo, redo := g.undoStack.History()
+		if err := g.journal.Track(changes, reverted, undo, redo); err != nil {
+			fmt.Printf("Warning: Failed to write the edit journal: %v\n", err)
+		}
+	})
+	// Rebuild the editor after undo/redo so its widgets show the restored
+	// values; this refreshes the validation status too
 	undoCtrl.SetOnUndo(func() {
 		if g.pr != nil {
-			res := validation.NewValidator().Validate(g.pr)
-			g.validationStatus.SetText(fmt.Sprintf("Validation: %d errors, %d warnings", len(res.Errors), len(res.Warnings)))
+			g.showEditor()
 		}
 	})
 	undoCtrl.SetOnRedo(func() {
 		if g.pr != nil {
-			res := validation.NewValidator().Validate(g.pr)
-			g.validationStatus.SetText(fmt.Sprintf("Validation: %d errors, %d warnings", len(res.Errors), len(res.Warnings)))
+			g.showEditor()
 		}
 	})
 	// Create status bar labels
@@ -428,6 +502,13 @@ func New() Gui {
 
 	// Show welcome screen on startup
 	g.showWelcomeScreen()
+	// A clean exit closes the journal; one left open means the editor crashed
+	g.window.SetOnClosed(func() {
+		if g.journal != nil {
+			_ = g.journal.Close()
+		}
+	})
+	g.offerRecovery()
 	// Build Edit menu from controller
 	undoItem, redoItem := undoCtrl.BuildMenuItems()
 	g.window.SetMainMenu(fyne.NewMainMenu(
@@ -444,6 +525,24 @@ func New() Gui {
 					dialog.ShowError(fmt.Errorf("backup manager not available"), g.window)
 				}
 			}),
+			fyne.NewMenuItem("Progression Timeline...", func() {
+				if g.historyStore == nil {
+					dialog.ShowError(fmt.Errorf("history not available"), g.window)
+					return
+				}
+				d := dialogs.NewTimelineDialog(g.window, g.historyStore, g.savePath)
+				if g.pr != nil {
+					d.OnCapture(g.captureHistory)
+				}
+				d.Show()
+			}),
+			fyne.NewMenuItem("Export Report...", func() {
+				if g.pr == nil {
+					dialog.ShowError(fmt.Errorf("no save loaded"), g.window)
+					return
+				}
+				dialogs.NewExportReportDialog(g.window, filepath.Base(g.savePath), pr.TakeSnapshot(), g.openedSnapshot).Show()
+			}),
 		),
 		fyne.NewMenu("Edit",
 			undoItem,
@@ -481,7 +580,15 @@ func New() Gui {
 				d.Show()
 			}),
 			fyne.NewMenuItem("Lua Scripts...", func() {
-				d := forms.NewScriptEditorDialog(g.window)
+				var api *plugins.APIImpl
+				if g.pr != nil {
+					api = g.newPluginAPI(g.pr,
+						plugins.CommonPermissions.ReadSave,
+						plugins.CommonPermissions.WriteSave,
+						plugins.CommonPermissions.UIDisplay,
+						plugins.CommonPermissions.Events)
+				}
+				d := forms.NewScriptEditorDialog(g.window, g.pr, api, g.pluginManager.GetSandboxManager())
 				d.Show()
 			}),
 			fyne.NewMenuItem("Batch Operations...", func() {
@@ -660,7 +767,7 @@ func New() Gui {
 				if g.pr != nil {
 					// Show validation panel in main canvas
 					g.savePreviousCanvas()
-					validator := validation.NewValidator()
+					validator := g.newValidator()
 					// Update status bar with latest counts
 					res := validator.Validate(g.pr)
 					g.validationStatus.SetText(fmt.Sprintf("Validation: %d errors, %d warnings", len(res.Errors), len(res.Warnings)))
@@ -671,6 +778,13 @@ func New() Gui {
 					dialog.ShowError(fmt.Errorf("no save file loaded"), g.window)
 				}
 			}),
+			fyne.NewMenuItem("Repair Inventory", func() {
+				if g.pr != nil {
+					g.repairInventory()
+				} else {
+					dialog.ShowError(fmt.Errorf("no save file loaded"), g.window)
+				}
+			}),
 			fyne.NewMenuItemSeparator(),
 			fyne.NewMenuItem("Palette Viewer...", func() {
 				fmt.Println("DEBUG: Palette Viewer clicked")
@@ -776,6 +890,7 @@ func New() Gui {
 		fyne.NewMenu("Settings",
 			fyne.NewMenuItem("Preferences...", func() {
 				d := forms.NewPreferencesDialog(g.window, g.settingsManager)
+				d.SetValidationProfiles(g.newValidator().Profiles())
 				d.Show()
 			}),
 			fyne.NewMenuItemSeparator(),
@@ -822,32 +937,9 @@ func (g *gui) Load() {
 		defer func() { g.open.Disabled = false }()
 		// Load file
 		config.SetSaveDir(dir)
-		p := pr.New()
-		if err := p.Load(filepath.Join(dir, file), saveType); err != nil {
+		if err := g.openFile(filepath.Join(dir, file), saveType, false); err != nil {
 			g.restorePreviousCanvas()
 			dialog.NewError(err, g.window).Show()
-		} else {
-			// Success
-			g.prev = nil
-			g.save.Disabled = false
-			g.pr = p
-			// Update validation status on load
-			validator := validation.NewValidator()
-			res := validator.Validate(g.pr)
-			g.validationStatus.SetText(fmt.Sprintf("Validation: %d errors, %d warnings", len(res.Errors), len(res.Warnings)))
-			// Create editor and wire tab-change callback to refresh status
-			ed := selections.NewEditor()
-			ed.SetOnTabChanged(func(tabTitle string) {
-				if g.pr != nil {
-					res := validation.NewValidator().Validate(g.pr)
-					g.validationStatus.SetText(fmt.Sprintf("Validation: %d errors, %d warnings", len(res.Errors), len(res.Warnings)))
-					// If switching to Validation tab, refresh panel with latest data
-					if tabTitle == "Validation" && ed.GetValidationPanel() != nil {
-						ed.GetValidationPanel().ValidateSaveData(g.pr)
-					}
-				}
-			})
-			g.setCanvasContent(ed)
 		}
 	}, func() {
 		defer func() { g.open.Disabled = false }()
@@ -862,6 +954,7 @@ func (g *gui) Load() {
 }
 
 func (g *gui) Save() {
+	pr.Changes.Commit("Edit")
 	g.savePreviousCanvas()
 	g.open.Disabled = true
 	g.save.Disabled = true
@@ -872,40 +965,38 @@ func (g *gui) Save() {
 			g.save.Disabled = false
 		}()
 		config.SetSaveDir(dir)
-		// Pre-save validation and optional auto-fix
-		validator := validation.NewValidator()
-		result := validator.Validate(g.pr)
-		// Update status bar with latest counts
-		g.validationStatus.SetText(fmt.Sprintf("Validation: %d errors, %d warnings", len(result.Errors), len(result.Warnings)))
+		// Pre-save validation, auto-fixing first when the settings ask for it
+		check := g.newSaveGate().Check(g.pr)
+		g.validationStatus.SetText(fmt.Sprintf("Validation: %d errors, %d warnings", len(check.Result.Errors), len(check.Result.Warnings)))
 		proceedSave := func() {
 			if err := g.pr.Save(slot, filepath.Join(dir, file), saveType); err != nil {
 				g.restorePreviousCanvas()
 				dialog.NewError(err, g.window).Show()
 			} else {
 				// Success
+				g.savePath, g.saveType = filepath.Join(dir, file), saveType
+				g.recordHistory(g.savePath, saveType, history.SourceEditor, "Saved in the editor")
+				if g.journal != nil {
+					if err := g.journal.Saved(g.savePath, saveType); err != nil {
+						fmt.Printf("Warning: Failed to write the edit journal: %v\n", err)
+					}
+				}
 				g.restorePreviousCanvas()
 			}
 		}
-		if !result.Valid {
-			msg := fmt.Sprintf("Validation found %d errors and %d warnings. Auto-fix and continue?", len(result.Errors), len(result.Warnings))
-			dialog.NewConfirm("Validation Issues", msg, func(ok bool) {
+		switch check.Decision {
+		case validation.SaveBlocked:
+			g.restorePreviousCanvas()
+			dialog.ShowError(fmt.Errorf("%w\n\n%s", check.Err(), check.Summary(maxSaveIssues)), g.window)
+		case validation.SaveNeedsConfirm:
+			dialog.NewConfirm("Validation Issues", check.Summary(maxSaveIssues)+"\n\nSave anyway?", func(ok bool) {
 				if ok {
-					// Attempt auto-fix
-					_, _ = validator.AutoFixIssues(g.pr)
-					// Re-validate (optional)
-					newRes := validator.Validate(g.pr)
-					g.validationStatus.SetText(fmt.Sprintf("Validation: %d errors, %d warnings", len(newRes.Errors), len(newRes.Warnings)))
 					proceedSave()
 				} else {
-					// Secondary confirmation: proceed without fixes?
-					dialog.NewConfirm("Proceed Without Fixes?", "Save anyway without fixing validation issues?", func(confirm bool) {
-						if confirm {
-							proceedSave()
-						}
-					}, g.window).Show()
+					g.restorePreviousCanvas()
 				}
 			}, g.window).Show()
-		} else {
+		default:
 			proceedSave()
 		}
 	}, func() {
@@ -923,10 +1014,271 @@ func (g *gui) Save() {
 	}
 }
 
+// openFile loads a save into the editor. Edits a crash left in its journal
+// are replayed when replay is set; otherwise the user is asked first.
+func (g *gui) openFile(file string, saveType global.SaveFileType, replay bool) error {
+	p := pr.New()
+	if err := p.Load(file, saveType); err != nil {
+		return err
+	}
+	// The opened save is where change tracking starts
+	pr.Changes.Sync()
+	g.prev = nil
+	g.save.Disabled = false
+	g.pr = p
+	g.savePath, g.saveType = file, saveType
+	g.pluginAPI.SetSaveData(p, saveType)
+	g.openedSnapshot = pr.TakeSnapshot()
+	g.recordHistory(g.savePath, saveType, history.SourceEditor, "Opened in the editor")
+	// The history of the previous save doesn't apply to this one
+	g.undoCtrl.Clear()
+	g.resumeJournal(replay)
+	g.showEditor()
+	return nil
+}
+
+// offerRecovery offers to replay the newest journal a crash left with
+// unsaved edits onto its save; declining drops the edits
+func (g *gui) offerRecovery() {
+	if g.journal == nil {
+		return
+	}
+	unclean, err := g.journal.Unclean()
+	if err != nil {
+		fmt.Printf("Warning: Failed to read the edit journal: %v\n", err)
+	}
+	if len(unclean) == 0 {
+		return
+	}
+	j := unclean[0]
+	msg := fmt.Sprintf("The editor didn't close cleanly. %s has %d unsaved edit(s).\n\nLoad the save and replay them?", filepath.Base(j.File), len(j.Unsaved))
+	dialog.ShowConfirm("Recover Unsaved Edits", msg, func(ok bool) {
+		if !ok {
+			if err := g.journal.Discard(j); err != nil {
+				fmt.Printf("Warning: Failed to write the edit journal: %v\n", err)
+			}
+			return
+		}
+		config.SetSaveDir(filepath.Dir(j.File))
+		if err := g.openFile(j.File, j.SaveType, true); err != nil {
+			dialog.ShowError(err, g.window)
+		}
+	}, g.window)
+}
+
+// resumeJournal starts journaling the loaded save and brings its undo history
+// back. The history covers edits a crash left unsaved, so it is dropped along
+// with them when they aren't replayed.
+func (g *gui) resumeJournal(replay bool) {
+	if g.journal == nil {
+		return
+	}
+	previous, err := g.journal.Open(g.savePath, g.saveType)
+	if err != nil {
+		fmt.Printf("Warning: Failed to start the edit journal: %v\n", err)
+		return
+	}
+	switch {
+	case previous == nil:
+	case !previous.Recoverable():
+		g.restoreJournal(previous)
+	case replay:
+		g.replayJournal(previous)
+	default:
+		msg := fmt.Sprintf("%d edit(s) to this save were lost when the editor didn't close cleanly.\n\nReplay them?", len(previous.Unsaved))
+		dialog.ShowConfirm("Recover Unsaved Edits", msg, func(ok bool) {
+			if ok {
+				g.replayJournal(previous)
+				g.showEditor()
+			}
+		}, g.window)
+	}
+}
+
+// replayJournal writes the unsaved edits of a journal onto the loaded save
+// and restores its history
+func (g *gui) replayJournal(previous *journal.Journal) {
+	err := previous.Replay()
+	// The replayed edits are part of the restored history, not a new step
+	pr.Changes.Sync()
+	g.restoreJournal(previous)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("some edits couldn't be recovered: %w", err), g.window)
+	} else if s := g.settingsManager.Get(); s != nil && s.ShowUndoHistory {
+		g.undoCtrl.ShowHistory(g.window)
+	}
+}
+
+// restoreJournal carries a journal's history over to the loaded save
+func (g *gui) restoreJournal(previous *journal.Journal) {
+	if err := g.journal.Resume(previous); err != nil {
+		fmt.Printf("Warning: Failed to write the edit journal: %v\n", err)
+	}
+	g.undoCtrl.SetHistory(previous.History())
+}
+
+// showEditor shows a new editor for the loaded save and updates the
+// validation status
+func (g *gui) showEditor() {
+	validator := g.newValidator()
+	res := validator.Validate(g.pr)
+	g.validationStatus.SetText(fmt.Sprintf("Validation: %d errors, %d warnings", len(res.Errors), len(res.Warnings)))
+	// Create editor and wire tab-change callback to refresh status
+	ed := selections.NewEditor()
+	ed.SetValidator(validator)
+	ed.SetOnTabChanged(func(tabTitle string) {
+		if g.pr != nil {
+			// Edits made on the tab left behind become an undo step
+			pr.Changes.Commit("Edit")
+			res := validator.Validate(g.pr)
+			g.validationStatus.SetText(fmt.Sprintf("Validation: %d errors, %d warnings", len(res.Errors), len(res.Warnings)))
+			// If switching to Validation tab, refresh panel with latest data
+			if tabTitle == "Validation" && ed.GetValidationPanel() != nil {
+				ed.GetValidationPanel().ValidateSaveData(g.pr)
+			}
+		}
+	})
+	g.setCanvasContent(ed)
+}
+
 func (g *gui) Run() {
 	g.window.ShowAndRun()
 }
 
+// repairInventory fixes duplicate, empty, overfull and unknown item rows and
+// resyncs the inventory sort order, then reports what changed
+func (g *gui) repairInventory() {
+	var repair pr.InventoryRepair
+	err := pr.Changes.Edit("Repair inventory", func() (err error) {
+		repair, err = g.pr.RepairInventory()
+		return
+	})
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to repair the inventory: %w", err), g.window)
+		return
+	}
+	res := g.newValidator().Validate(g.pr)
+	g.validationStatus.SetText(fmt.Sprintf("Validation: %d errors, %d warnings", len(res.Errors), len(res.Warnings)))
+	if !repair.Changed() {
+		dialog.ShowInformation("Repair Inventory", "The inventory has no problems", g.window)
+		return
+	}
+
+	var lines []string
+	for _, p := range repair.Inventory {
+		lines = append(lines, "Inventory: "+p.String())
+	}
+	for _, p := range repair.ImportantItems {
+		lines = append(lines, "Important items: "+p.String())
+	}
+	if !repair.SortOrder.Empty() {
+		lines = append(lines, "Rebuilt the sort order: "+repair.SortOrder.String())
+	}
+	dialog.ShowInformation("Repair Inventory", strings.Join(lines, "\n"), g.window)
+}
+
+// newValidator creates a validator for the validation level in the settings,
+// with the user's rule files from the rules directory
+func (g *gui) newValidator() *validation.Validator {
+	v := validation.NewValidator()
+	if err := v.LoadRules(filepath.Join(config.SaveDir(), "rules")); err != nil {
+		fmt.Printf("Warning: Failed to load validation rules: %v\n", err)
+	}
+	if g.settingsManager != nil {
+		if s := g.settingsManager.Get(); s != nil {
+			cfg := v.GetConfig()
+			cfg.Mode = models.ValidationModeForLevel(s.ValidationLevel)
+			v.SetConfig(cfg)
+		}
+	}
+	return v
+}
+
+// newSaveGate creates the pre-save check with the auto-fix and warning
+// settings
+func (g *gui) newSaveGate() *validation.SaveGate {
+	s := settings.DefaultSettings()
+	if g.settingsManager != nil {
+		s = g.settingsManager.Get()
+	}
+	return validation.NewSaveGate(g.newValidator(), s.AutoFix, s.WarnOnSave)
+}
+
+// newPluginAPI creates an API for plugins and scripts editing save. Their
+// saves pass the same pre-save check as the editor's, ask about issues in a
+// dialog and are recorded in the plugin audit log.
+func (g *gui) newPluginAPI(save *pr.PR, permissions ...string) *plugins.APIImpl {
+	api := plugins.NewAPIImpl(save, permissions)
+	api.SetSaveData(save, g.saveType)
+	api.SetSaveGate(g.newSaveGate)
+	api.SetDialogFunctions(nil, g.confirmAndWait, nil)
+	if g.pluginManager != nil {
+		api.SetAuditLogger(g.pluginManager.GetAuditLogger())
+	}
+	return api
+}
+
+// confirmAndWait asks a yes/no question and blocks until it is answered.
+// Fyne runs dialog callbacks on the event goroutine, so it must be called
+// from another one.
+func (g *gui) confirmAndWait(title, message string) bool {
+	answer := make(chan bool)
+	dialog.ShowConfirm(title, message, func(ok bool) { answer <- ok }, g.window)
+	return <-answer
+}
+
+// recordHistory adds a save file that matches the loaded data to the
+// progression timeline. History is optional, so failures are only logged.
+func (g *gui) recordHistory(file string, saveType global.SaveFileType, source history.Source, description string) {
+	if g.historyStore == nil {
+		return
+	}
+	data, err := os.ReadFile(file)
+	if err == nil {
+		_, err = g.historyStore.Record(history.Capture{
+			Path:        file,
+			Data:        data,
+			SaveType:    saveType,
+			Source:      source,
+			Description: description,
+			Snapshot:    pr.TakeSnapshot(),
+		})
+	}
+	if err != nil {
+		fmt.Printf("Warning: Failed to record history: %v\n", err)
+	}
+}
+
+// captureHistory records the loaded data, including unsaved edits, as a
+// point of the loaded slot
+func (g *gui) captureHistory() (*history.Entry, error) {
+	if g.pr == nil || g.savePath == "" {
+		return nil, fmt.Errorf("no save file loaded")
+	}
+	tmp, err := os.MkdirTemp("", "ffvi_capture")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	file := filepath.Join(tmp, filepath.Base(g.savePath))
+	if err = g.pr.Save(g.pr.SlotID(), file, g.saveType); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return g.historyStore.Record(history.Capture{
+		Path:        g.savePath,
+		Data:        data,
+		SaveType:    g.saveType,
+		Source:      history.SourceManual,
+		Description: "Captured in the editor",
+		Snapshot:    pr.TakeSnapshot(),
+	})
+}
+
 // setCanvasContent safely replaces canvas content and refreshes layout
 func (g *gui) setCanvasContent(obj fyne.CanvasObject) {
 	g.canvas.RemoveAll()